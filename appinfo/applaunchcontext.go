@@ -5,11 +5,12 @@
 package appinfo
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"sync"
+	"time"
 
 	"github.com/linuxdeepin/go-x11-client"
 )
@@ -28,18 +29,39 @@ func init() {
 
 type AppLaunchContext struct {
 	sync.Mutex
-	conn        *x.Conn
-	count       uint
 	timestamp   uint32
 	cmdPrefixes []string
 	cmdSuffixes []string
 	env         []string
+
+	// snMu guards the fields below, which are also used by the timers of
+	// the startup sequences
+	snMu            sync.Mutex
+	count           uint
+	provider        StartupNotifyProvider
+	activationToken string
+	snTimeout       time.Duration
+	snCallback      StartupNotifyCallback
+	snPending       map[string]*pendingStartup
+}
+
+// pendingStartup is a startup sequence not ended yet.
+type pendingStartup struct {
+	// nil if there is no timeout
+	timer *time.Timer
 }
 
+var ErrNoStartupNotifyProvider = errors.New("no startup notify provider and activation token")
+
+// NewAppLaunchContext creates a launch context, conn may be nil in sessions
+// without an X server, startup notification then relies on activation tokens
+// or a provider set by SetStartupNotifyProvider.
 func NewAppLaunchContext(conn *x.Conn) *AppLaunchContext {
-	return &AppLaunchContext{
-		conn: conn,
+	ctx := &AppLaunchContext{}
+	if conn != nil {
+		ctx.provider = NewX11StartupNotifyProvider(conn)
 	}
+	return ctx
 }
 
 func (ctx *AppLaunchContext) SetEnv(env []string) {
@@ -74,40 +96,170 @@ func (ctx *AppLaunchContext) GetCmdSuffixes() []string {
 	return ctx.cmdSuffixes
 }
 
+// SetStartupNotifyProvider replaces the provider used to announce startup
+// sequences, nil disables the announcement.
+func (ctx *AppLaunchContext) SetStartupNotifyProvider(provider StartupNotifyProvider) {
+	ctx.snMu.Lock()
+	ctx.provider = provider
+	ctx.snMu.Unlock()
+}
+
+func (ctx *AppLaunchContext) GetStartupNotifyProvider() StartupNotifyProvider {
+	ctx.snMu.Lock()
+	defer ctx.snMu.Unlock()
+	return ctx.provider
+}
+
+// SetActivationToken sets the XDG_ACTIVATION_TOKEN used as the startup id of
+// the next launch. The token is consumed by GetStartupNotifyId.
+func (ctx *AppLaunchContext) SetActivationToken(token string) {
+	ctx.snMu.Lock()
+	ctx.activationToken = token
+	ctx.snMu.Unlock()
+}
+
+func (ctx *AppLaunchContext) GetActivationToken() string {
+	ctx.snMu.Lock()
+	defer ctx.snMu.Unlock()
+	return ctx.activationToken
+}
+
+// SetStartupNotifyTimeout sets how long a startup sequence may last before it
+// is removed and reported as StartupNotifyTimeout. Zero means no timeout.
+func (ctx *AppLaunchContext) SetStartupNotifyTimeout(timeout time.Duration) {
+	ctx.snMu.Lock()
+	ctx.snTimeout = timeout
+	ctx.snMu.Unlock()
+}
+
+func (ctx *AppLaunchContext) SetStartupNotifyCallback(cb StartupNotifyCallback) {
+	ctx.snMu.Lock()
+	ctx.snCallback = cb
+	ctx.snMu.Unlock()
+}
+
+// CanStartupNotify reports whether GetStartupNotifyId is able to produce a
+// startup id, either from an activation token or from the provider and the
+// timestamp.
+func (ctx *AppLaunchContext) CanStartupNotify() bool {
+	ctx.snMu.Lock()
+	defer ctx.snMu.Unlock()
+	return ctx.activationToken != "" ||
+		(ctx.provider != nil && ctx.timestamp != 0)
+}
+
 func (ctx *AppLaunchContext) GetStartupNotifyId(appInfo AppInfo, files []string) (string, error) {
-	execBase := filepath.Base(appInfo.GetExecutable())
-	snId := fmt.Sprintf("%s-%d-%s-%s-%d_TIME%d", prog, pid, hostname, execBase, ctx.count, ctx.timestamp)
-	ctx.count++
-
-	screenStr := strconv.Itoa(ctx.conn.ScreenNumber)
-	// send new msg
-	msg := &StartupNotifyMessage{
-		Type: "new",
-		KeyValues: map[string]string{
-			"ID":     snId,
-			"SCREEN": screenStr,
-			"NAME":   appInfo.GetName(),
-		},
+	info := &StartupNotifyInfo{
+		Name:      appInfo.GetName(),
+		WMClass:   appInfo.GetStartupWMClass(),
+		Timestamp: ctx.timestamp,
 	}
-	startupWMClass := appInfo.GetStartupWMClass()
-	if startupWMClass != "" {
-		msg.KeyValues["WMCLASS"] = startupWMClass
+	ctx.snMu.Lock()
+	provider := ctx.provider
+	token := ctx.activationToken
+	ctx.activationToken = ""
+	count := ctx.count
+	if token == "" && provider != nil {
+		ctx.count++
 	}
+	ctx.snMu.Unlock()
 
-	err := msg.Broadcast(ctx.conn)
-	if err != nil {
-		return "", err
+	if token != "" {
+		info.ID = token
+		info.IsActivationToken = true
+	} else {
+		if provider == nil {
+			return "", ErrNoStartupNotifyProvider
+		}
+		execBase := filepath.Base(appInfo.GetExecutable())
+		info.ID = fmt.Sprintf("%s-%d-%s-%s-%d_TIME%d", prog, pid, hostname, execBase, count, ctx.timestamp)
 	}
-	return snId, nil
+
+	if provider != nil {
+		err := provider.Initiate(info)
+		if err != nil {
+			return "", err
+		}
+	}
+	ctx.addPending(info.ID)
+	return info.ID, nil
+}
+
+// GetStartupNotifyEnv returns the environment variables which pass the
+// startup id to the child process.
+func GetStartupNotifyEnv(startupNotifyId string) []string {
+	return []string{
+		"DESKTOP_STARTUP_ID=" + startupNotifyId,
+		"XDG_ACTIVATION_TOKEN=" + startupNotifyId,
+	}
+}
+
+// LaunchComplete ends the startup sequence, it should be called when the
+// window of the launched application shows up.
+func (ctx *AppLaunchContext) LaunchComplete(startupNotifyId string) error {
+	return ctx.endStartup(startupNotifyId, StartupNotifyCompleted)
 }
 
 func (ctx *AppLaunchContext) LaunchFailed(startupNotifyId string) error {
-	// send remove msg
-	msg := &StartupNotifyMessage{
-		Type: "remove",
-		KeyValues: map[string]string{
-			"ID": startupNotifyId,
-		},
+	return ctx.endStartup(startupNotifyId, StartupNotifyFailed)
+}
+
+func (ctx *AppLaunchContext) addPending(id string) {
+	ctx.snMu.Lock()
+	defer ctx.snMu.Unlock()
+	if ctx.snPending == nil {
+		ctx.snPending = make(map[string]*pendingStartup)
+	}
+	// the id is reused, such as the same activation token
+	if old, ok := ctx.snPending[id]; ok && old.timer != nil {
+		old.timer.Stop()
+	}
+	pending := &pendingStartup{}
+	if ctx.snTimeout > 0 {
+		pending.timer = time.AfterFunc(ctx.snTimeout, func() {
+			_ = ctx.endPending(id, StartupNotifyTimeout, pending)
+		})
+	}
+	ctx.snPending[id] = pending
+}
+
+func (ctx *AppLaunchContext) endStartup(id string, result StartupNotifyResult) error {
+	return ctx.endPending(id, result, nil)
+}
+
+// endPending ends the startup sequence id, if expired is not nil, the
+// sequence is ended only if it is expired, so that the timer which fires
+// late does not end the sequence started again with the same id.
+func (ctx *AppLaunchContext) endPending(id string, result StartupNotifyResult, expired *pendingStartup) error {
+	ctx.snMu.Lock()
+	pending, ok := ctx.snPending[id]
+	if expired != nil && pending != expired {
+		ctx.snMu.Unlock()
+		return nil
+	}
+	var timer *time.Timer
+	if ok {
+		timer = pending.timer
+	}
+	delete(ctx.snPending, id)
+	cb := ctx.snCallback
+	provider := ctx.provider
+	ctx.snMu.Unlock()
+
+	if timer != nil {
+		timer.Stop()
+	}
+
+	// the sequence is already ended, such as by the timeout
+	if !ok {
+		return nil
+	}
+	var err error
+	if provider != nil {
+		err = provider.Remove(id)
+	}
+	if cb != nil {
+		cb(id, result)
 	}
-	return msg.Broadcast(ctx.conn)
+	return err
 }
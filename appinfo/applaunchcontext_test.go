@@ -5,7 +5,9 @@
 package appinfo
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +38,173 @@ func Test_AppLaunchContext(t *testing.T) {
 	assert.Equal(t, appLaunchTest.GetCmdPrefixes()[0], "uos")
 	assert.Equal(t, appLaunchTest.GetCmdSuffixes()[0], "uos")
 }
+
+type testAppInfo struct{}
+
+func (testAppInfo) GetId() string                                      { return "test" }
+func (testAppInfo) GetName() string                                    { return "Test" }
+func (testAppInfo) GetIcon() string                                    { return "" }
+func (testAppInfo) GetExecutable() string                              { return "/usr/bin/test" }
+func (testAppInfo) GetFileName() string                                { return "" }
+func (testAppInfo) GetCommandline() string                             { return "test" }
+func (testAppInfo) Launch(files []string, ctx *AppLaunchContext) error { return nil }
+func (testAppInfo) GetStartupWMClass() string                          { return "Test" }
+
+type testProvider struct {
+	mu        sync.Mutex
+	initiated []*StartupNotifyInfo
+	removed   []string
+}
+
+func (p *testProvider) Initiate(info *StartupNotifyInfo) error {
+	p.mu.Lock()
+	p.initiated = append(p.initiated, info)
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *testProvider) Remove(id string) error {
+	p.mu.Lock()
+	p.removed = append(p.removed, id)
+	p.mu.Unlock()
+	return nil
+}
+
+func Test_AppLaunchContextWithoutX(t *testing.T) {
+	ctx := NewAppLaunchContext(nil)
+	assert.Nil(t, ctx.GetStartupNotifyProvider())
+	assert.False(t, ctx.CanStartupNotify())
+	_, err := ctx.GetStartupNotifyId(testAppInfo{}, nil)
+	assert.Equal(t, ErrNoStartupNotifyProvider, err)
+
+	var results []StartupNotifyResult
+	ctx.SetStartupNotifyCallback(func(id string, result StartupNotifyResult) {
+		assert.Equal(t, "token-1", id)
+		results = append(results, result)
+	})
+	ctx.SetActivationToken("token-1")
+	assert.True(t, ctx.CanStartupNotify())
+	id, err := ctx.GetStartupNotifyId(testAppInfo{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", id)
+	assert.Equal(t, "", ctx.GetActivationToken())
+	assert.Equal(t, []string{"DESKTOP_STARTUP_ID=token-1", "XDG_ACTIVATION_TOKEN=token-1"},
+		GetStartupNotifyEnv(id))
+
+	assert.NoError(t, ctx.LaunchComplete(id))
+	// only the first end of a sequence is reported
+	assert.NoError(t, ctx.LaunchFailed(id))
+	assert.Equal(t, []StartupNotifyResult{StartupNotifyCompleted}, results)
+}
+
+func Test_AppLaunchContextProvider(t *testing.T) {
+	provider := &testProvider{}
+	ctx := NewAppLaunchContext(nil)
+	ctx.SetStartupNotifyProvider(provider)
+	ctx.SetTimestamp(100)
+	ctx.SetStartupNotifyTimeout(10 * time.Millisecond)
+
+	done := make(chan StartupNotifyResult, 1)
+	ctx.SetStartupNotifyCallback(func(id string, result StartupNotifyResult) {
+		done <- result
+	})
+
+	id, err := ctx.GetStartupNotifyId(testAppInfo{}, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, id, "-test-0_TIME100")
+	assert.Len(t, provider.initiated, 1)
+	assert.Equal(t, "Test", provider.initiated[0].Name)
+	assert.Equal(t, "Test", provider.initiated[0].WMClass)
+	assert.False(t, provider.initiated[0].IsActivationToken)
+
+	select {
+	case result := <-done:
+		assert.Equal(t, StartupNotifyTimeout, result)
+	case <-time.After(time.Second):
+		t.Fatal("startup sequence did not time out")
+	}
+	assert.Equal(t, []string{id}, provider.removed)
+
+	// removed only once
+	assert.NoError(t, ctx.LaunchComplete(id))
+	assert.NoError(t, ctx.LaunchFailed(id))
+	assert.Equal(t, []string{id}, provider.removed)
+}
+
+func Test_AppLaunchContextTimeoutRace(t *testing.T) {
+	ctx := NewAppLaunchContext(nil)
+	ctx.SetStartupNotifyProvider(&testProvider{})
+	ctx.SetStartupNotifyTimeout(time.Millisecond)
+
+	done := make(chan StartupNotifyResult, 10)
+	ctx.SetStartupNotifyCallback(func(id string, result StartupNotifyResult) {
+		done <- result
+	})
+	for i := 0; i < 10; i++ {
+		ctx.SetActivationToken("token")
+		_, err := ctx.GetStartupNotifyId(testAppInfo{}, nil)
+		assert.NoError(t, err)
+		// the provider is replaced while the timers may fire
+		ctx.SetStartupNotifyProvider(nil)
+		ctx.SetStartupNotifyTimeout(2 * time.Millisecond)
+	}
+	select {
+	case result := <-done:
+		assert.Equal(t, StartupNotifyTimeout, result)
+	case <-time.After(time.Second):
+		t.Fatal("startup sequence did not time out")
+	}
+}
+
+func Test_AppLaunchContextReuseToken(t *testing.T) {
+	ctx := NewAppLaunchContext(nil)
+	ctx.SetStartupNotifyTimeout(200 * time.Millisecond)
+	done := make(chan StartupNotifyResult, 10)
+	ctx.SetStartupNotifyCallback(func(id string, result StartupNotifyResult) {
+		done <- result
+	})
+
+	ctx.SetActivationToken("token")
+	_, err := ctx.GetStartupNotifyId(testAppInfo{}, nil)
+	assert.NoError(t, err)
+	time.Sleep(120 * time.Millisecond)
+	// the timer of the first sequence does not end the second one
+	ctx.SetActivationToken("token")
+	_, err = ctx.GetStartupNotifyId(testAppInfo{}, nil)
+	assert.NoError(t, err)
+	time.Sleep(120 * time.Millisecond)
+	assert.Empty(t, done)
+
+	select {
+	case result := <-done:
+		assert.Equal(t, StartupNotifyTimeout, result)
+	case <-time.After(time.Second):
+		t.Fatal("startup sequence did not time out")
+	}
+	assert.Empty(t, done)
+}
+
+func Test_AppLaunchContextConcurrent(t *testing.T) {
+	ctx := NewAppLaunchContext(nil)
+	ctx.SetStartupNotifyProvider(&testProvider{})
+	ctx.SetTimestamp(100)
+
+	var wg sync.WaitGroup
+	ids := make(chan string, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := ctx.GetStartupNotifyId(testAppInfo{}, nil)
+			assert.NoError(t, err)
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	unique := make(map[string]bool)
+	for id := range ids {
+		unique[id] = true
+	}
+	assert.Len(t, unique, 20)
+}
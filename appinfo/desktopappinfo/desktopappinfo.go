@@ -72,10 +72,7 @@ var xdgDataDirs []string
 var xdgAppDirs []string
 
 func init() {
-	xdgDataDirs = make([]string, 0, 3)
-	xdgDataDirs = append(xdgDataDirs, basedir.GetUserDataDir())
-	sysDataDirs := basedir.GetSystemDataDirs()
-	xdgDataDirs = append(xdgDataDirs, sysDataDirs...)
+	xdgDataDirs = basedir.GetDataDirs()
 
 	xdgAppDirs = make([]string, len(xdgDataDirs))
 	for i, dir := range xdgDataDirs {
@@ -623,12 +620,19 @@ func startCommand(ai *DesktopAppInfo, cmdline string, files []string, launchCont
 	var snId string
 	startupNotify := ai.GetStartupNotify()
 	if startupNotify && launchContext != nil &&
-		launchContext.GetTimestamp() != 0 {
-		snId, _ = launchContext.GetStartupNotifyId(ai, files)
-		cmd.Env = append(cmd.Env, "DESKTOP_STARTUP_ID="+snId)
+		launchContext.CanStartupNotify() {
+		snId, err = launchContext.GetStartupNotifyId(ai, files)
+		if err == nil {
+			cmd.Env = append(cmd.Env, appinfo.GetStartupNotifyEnv(snId)...)
+		} else {
+			snId = ""
+		}
 	}
 
 	err = cmd.Start()
+	if err != nil && snId != "" {
+		_ = launchContext.LaunchFailed(snId)
+	}
 	return cmd, err
 }
 
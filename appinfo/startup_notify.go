@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package appinfo
+
+import (
+	"strconv"
+
+	"github.com/linuxdeepin/go-x11-client"
+)
+
+// StartupNotifyInfo describes a startup sequence which is about to begin.
+type StartupNotifyInfo struct {
+	ID        string
+	Name      string
+	WMClass   string
+	Timestamp uint32
+	// IsActivationToken is true if ID is a caller supplied
+	// XDG_ACTIVATION_TOKEN instead of a generated X11 startup id.
+	IsActivationToken bool
+}
+
+// StartupNotifyProvider announces the begin and the end of startup sequences
+// to the display server. The X11 implementation broadcasts
+// _NET_STARTUP_INFO messages, other implementations may talk to a Wayland
+// compositor or do nothing at all.
+type StartupNotifyProvider interface {
+	Initiate(info *StartupNotifyInfo) error
+	Remove(id string) error
+}
+
+// StartupNotifyResult tells how a startup sequence ended.
+type StartupNotifyResult int
+
+const (
+	StartupNotifyCompleted StartupNotifyResult = iota
+	StartupNotifyFailed
+	StartupNotifyTimeout
+)
+
+func (r StartupNotifyResult) String() string {
+	switch r {
+	case StartupNotifyCompleted:
+		return "completed"
+	case StartupNotifyFailed:
+		return "failed"
+	case StartupNotifyTimeout:
+		return "timeout"
+	default:
+		return "unknown(" + strconv.Itoa(int(r)) + ")"
+	}
+}
+
+// StartupNotifyCallback is called once for every startup sequence when it
+// ends.
+type StartupNotifyCallback func(id string, result StartupNotifyResult)
+
+type x11StartupNotifyProvider struct {
+	conn *x.Conn
+}
+
+// NewX11StartupNotifyProvider returns a provider which implements the X11
+// part of the freedesktop startup notification spec.
+func NewX11StartupNotifyProvider(conn *x.Conn) StartupNotifyProvider {
+	return &x11StartupNotifyProvider{conn: conn}
+}
+
+func (p *x11StartupNotifyProvider) Initiate(info *StartupNotifyInfo) error {
+	// send new msg
+	msg := &StartupNotifyMessage{
+		Type: "new",
+		KeyValues: map[string]string{
+			"ID":     info.ID,
+			"SCREEN": strconv.Itoa(p.conn.ScreenNumber),
+			"NAME":   info.Name,
+		},
+	}
+	if info.WMClass != "" {
+		msg.KeyValues["WMCLASS"] = info.WMClass
+	}
+	return msg.Broadcast(p.conn)
+}
+
+func (p *x11StartupNotifyProvider) Remove(id string) error {
+	// send remove msg
+	msg := &StartupNotifyMessage{
+		Type: "remove",
+		KeyValues: map[string]string{
+			"ID": id,
+		},
+	}
+	return msg.Broadcast(p.conn)
+}
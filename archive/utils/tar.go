@@ -6,13 +6,85 @@ package utils
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
+
 	dutils "github.com/linuxdeepin/go-lib/utils"
 )
 
+// ErrStopWalk can be returned by the walk func of TarReaderWalk to stop
+// walking without error.
+var ErrStopWalk = errors.New("stop walk")
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type tarFileCloser struct {
+	file *os.File
+	gz   *gzip.Reader
+}
+
+func (c *tarFileCloser) Close() error {
+	if c.gz != nil {
+		_ = c.gz.Close()
+	}
+	return c.file.Close()
+}
+
+// OpenTarFile opens a tar archive for reading, the archive may be compressed
+// by gzip. The returned closer must be closed after reading.
+func OpenTarFile(file string) (*tar.Reader, io.Closer, error) {
+	fr, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closer := &tarFileCloser{file: fr}
+	br := bufio.NewReader(fr)
+	head, _ := br.Peek(len(gzipMagic))
+	if !bytes.Equal(head, gzipMagic) {
+		return tar.NewReader(br), closer, nil
+	}
+
+	closer.gz, err = gzip.NewReader(br)
+	if err != nil {
+		fr.Close()
+		return nil, nil, err
+	}
+	return tar.NewReader(closer.gz), closer, nil
+}
+
+// TarReaderWalk calls fn for each entry of reader without extracting it, the
+// content of the entry can be read from r before fn returns.
+func TarReaderWalk(reader *tar.Reader, fn func(h *tar.Header, r io.Reader) error) error {
+	if reader == nil {
+		return fmt.Errorf("Invalid tar reader")
+	}
+
+	for {
+		h, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(h, reader)
+		if err == ErrStopWalk {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func TarWriterCompressFiles(writer *tar.Writer, files []string) error {
 	if writer == nil {
 		return fmt.Errorf("Invalid tar writer")
@@ -30,3 +30,13 @@ func TestTarWriterCompressFile(t *testing.T) {
 	err := tarWriterCompressFile(nil, "", "")
 	assert.NotEqual(t, nil, err)
 }
+
+func TestTarReaderWalk(t *testing.T) {
+	err := TarReaderWalk(nil, nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestOpenTarFile(t *testing.T) {
+	_, _, err := OpenTarFile("testdata/not-exist.tar")
+	assert.NotEqual(t, nil, err)
+}
@@ -73,3 +73,48 @@ func SolarToLunar(year, month, day int) (LunarDayInfo, bool) {
 	}
 	return dayInfo, true
 }
+
+// LunarMonthInfo 农历月信息
+type LunarMonthInfo struct {
+	Year   int    // 农历年
+	Month  int    // 农历月，1 到 12
+	IsLeap bool   // 是否为闰月
+	Name   string // 农历月名，如 "闰四月"
+	Days   int    // 该月天数，29 或 30
+	Start  Day    // 该月初一的公历日期
+}
+
+// LunarToSolar 农历日期转换为公历日期，isLeap 表示 month 是否为闰月。
+// 该年没有这个闰月时返回 lunar.NoLeapMonthError，日期超出该月天数时返回 lunar.DayOutOfRangeError。
+func LunarToSolar(year, month, day int, isLeap bool) (Day, error) {
+	dt, err := lunar.ToSolarDate(year, month, day, isLeap)
+	if err != nil {
+		return Day{}, err
+	}
+	return Day{
+		Year:  dt.Year(),
+		Month: int(dt.Month()),
+		Day:   dt.Day(),
+	}, nil
+}
+
+// GetLunarYearMonths 获取农历年全部月份的信息，按时间顺序排列
+func GetLunarYearMonths(year int) []LunarMonthInfo {
+	months := lunar.GetYearMonths(year)
+	result := make([]LunarMonthInfo, 0, len(months))
+	for _, m := range months {
+		result = append(result, LunarMonthInfo{
+			Year:   m.LunarYear,
+			Month:  m.Name,
+			IsLeap: m.IsLeap,
+			Name:   m.MonthName(),
+			Days:   m.Days,
+			Start: Day{
+				Year:  m.ShuoTime.Year(),
+				Month: int(m.ShuoTime.Month()),
+				Day:   m.ShuoTime.Day(),
+			},
+		})
+	}
+	return result
+}
@@ -7,6 +7,7 @@ package calendar
 import (
 	"testing"
 
+	"github.com/linuxdeepin/go-lib/calendar/lunar"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,3 +52,42 @@ func Test_SolarToLunar(t *testing.T) {
 	assert.Equal(t, dayInfo.GanZhiMonth, "庚戌")
 	assert.Equal(t, dayInfo.GanZhiDay, "丁巳")
 }
+
+func Test_LunarToSolar(t *testing.T) {
+	day, err := LunarToSolar(2023, 2, 15, true)
+	assert.NoError(t, err)
+	assert.Equal(t, Day{Year: 2023, Month: 4, Day: 5}, day)
+
+	dayInfo, _ := SolarToLunar(day.Year, day.Month, day.Day)
+	assert.Equal(t, "闰二月", dayInfo.LunarMonthName)
+	assert.Equal(t, "十五", dayInfo.LunarDayName)
+
+	day, err = LunarToSolar(2023, 1, 1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, Day{Year: 2023, Month: 1, Day: 22}, day)
+
+	_, err = LunarToSolar(2024, 2, 1, true)
+	assert.Equal(t, lunar.NoLeapMonthError{Year: 2024, Month: 2}, err)
+	_, err = LunarToSolar(2023, 2, 30, true)
+	assert.IsType(t, lunar.DayOutOfRangeError{}, err)
+}
+
+func Test_GetLunarYearMonths(t *testing.T) {
+	months := GetLunarYearMonths(2023)
+	assert.Len(t, months, 13)
+	assert.Equal(t, LunarMonthInfo{
+		Year:   2023,
+		Month:  2,
+		IsLeap: true,
+		Name:   "闰二月",
+		Days:   29,
+		Start:  Day{Year: 2023, Month: 3, Day: 22},
+	}, months[2])
+
+	days := 0
+	for _, m := range GetLunarYearMonths(2022) {
+		days += m.Days
+	}
+	// 2022 年春节 2 月 1 日，2023 年春节 1 月 22 日
+	assert.Equal(t, 355, days)
+}
@@ -142,27 +142,38 @@ var solarFestivals = map[int][]festival{
 	},
 }
 
+// Festival 获取公历节日名称，多个节日以逗号分隔
 func (d *Day) Festival() string {
+	var festivals []string
+	for _, h := range DefaultProvider.GetHolidays(*d) {
+		festivals = append(festivals, h.Name)
+	}
+	return strings.Join(festivals, ",")
+}
+
+type defaultProvider struct{}
+
+// GetHolidays 默认的节日只有名称，不包含放假安排
+func (defaultProvider) GetHolidays(d Day) []Holiday {
 	year := d.Year
 	month := d.Month
 	day := d.Day
-	name := ""
-	var festivals []string
+	var holidays []Holiday
 	if (month == 5) || (month == 6) {
-		name = festivalForFatherAndMother(year, month, day)
+		name := festivalForFatherAndMother(year, month, day)
 		if name != "" {
-			festivals = append(festivals, name)
+			holidays = append(holidays, Holiday{Name: name})
 		}
 	}
 	key := month*100 + day
 	if solarFestival, ok := solarFestivals[key]; ok {
 		for _, festival := range solarFestival {
 			if festival.startYear <= year {
-				festivals = append(festivals, festival.name)
+				holidays = append(holidays, Holiday{Name: festival.name})
 			}
 		}
 	}
-	return strings.Join(festivals, ",")
+	return holidays
 }
 
 func festivalForFatherAndMother(year, month, day int) string {
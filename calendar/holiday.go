@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linuxdeepin/go-lib/locale"
+)
+
+// Holiday 节日或节假日安排
+type Holiday struct {
+	Name string
+	// IsHoliday 当天为法定节假日，需要放假
+	IsHoliday bool
+	// IsWorkdayOverride 当天为调休的工作日，即使是周末也需要上班
+	IsWorkdayOverride bool
+}
+
+// Provider 节日和节假日安排的提供者
+type Provider interface {
+	// GetHolidays 获取某天的节日和节假日安排，没有则返回 nil
+	GetHolidays(d Day) []Holiday
+}
+
+// DefaultProvider 内置的中国公历节日，包括母亲节和父亲节
+var DefaultProvider Provider = defaultProvider{}
+
+type multiProvider []Provider
+
+func (mp multiProvider) GetHolidays(d Day) []Holiday {
+	var result []Holiday
+	for _, p := range mp {
+		result = append(result, p.GetHolidays(d)...)
+	}
+	return result
+}
+
+// MultiProvider 合并多个 Provider 的结果，按参数顺序排列
+func MultiProvider(providers ...Provider) Provider {
+	return multiProvider(providers)
+}
+
+// Weekday 获取星期几
+func (d *Day) Weekday() time.Weekday {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC).Weekday()
+}
+
+// IsWorkday 判断是否需要上班，周末不上班，法定节假日放假，调休的工作日上班
+func (d *Day) IsWorkday(p Provider) bool {
+	holiday := false
+	for _, h := range p.GetHolidays(*d) {
+		if h.IsWorkdayOverride {
+			return true
+		}
+		if h.IsHoliday {
+			holiday = true
+		}
+	}
+	if holiday {
+		return false
+	}
+	weekday := d.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// FileProvider 从数据文件加载的节假日安排，如国务院办公厅每年公布的放假和调休安排。
+//
+// 数据文件为 JSON 格式，与 github.com/NateScarlet/holiday-cn 兼容：
+//
+//	{
+//	    "year": 2023,
+//	    "days": [
+//	        {"name": "春节", "date": "2023-01-21", "isOffDay": true},
+//	        {"name": "春节", "date": "2023-01-28", "isOffDay": false}
+//	    ]
+//	}
+//
+// isOffDay 为 true 表示放假，为 false 表示调休上班。
+type FileProvider struct {
+	mu   sync.RWMutex
+	days map[Day][]Holiday
+}
+
+type holidayFile struct {
+	Year int `json:"year"`
+	Days []struct {
+		Name     string `json:"name"`
+		Date     string `json:"date"`
+		IsOffDay bool   `json:"isOffDay"`
+	} `json:"days"`
+}
+
+// HolidayFileError 节假日数据文件格式错误
+type HolidayFileError struct {
+	File string
+	Err  error
+}
+
+func (err HolidayFileError) Error() string {
+	return fmt.Sprintf("invalid holiday file %q: %v", err.File, err.Err)
+}
+
+func NewFileProvider() *FileProvider {
+	return &FileProvider{
+		days: make(map[Day][]Holiday),
+	}
+}
+
+// LoadData 加载 JSON 格式的节假日数据，同一天已有的安排会被替换
+func (p *FileProvider) LoadData(data []byte) error {
+	var hf holidayFile
+	err := json.Unmarshal(data, &hf)
+	if err != nil {
+		return err
+	}
+
+	days := make(map[Day][]Holiday)
+	for _, item := range hf.Days {
+		t, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			return err
+		}
+		if item.Name == "" {
+			return fmt.Errorf("empty name of day %s", item.Date)
+		}
+		day := Day{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+		days[day] = append(days[day], Holiday{
+			Name:              item.Name,
+			IsHoliday:         item.IsOffDay,
+			IsWorkdayOverride: !item.IsOffDay,
+		})
+	}
+
+	p.mu.Lock()
+	for day, holidays := range days {
+		p.days[day] = holidays
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+// LoadFile 加载节假日数据文件
+func (p *FileProvider) LoadFile(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	err = p.LoadData(data)
+	if err != nil {
+		return HolidayFileError{File: file, Err: err}
+	}
+	return nil
+}
+
+// LoadDir 按文件名顺序加载目录中全部的 .json 文件
+func (p *FileProvider) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		err = p.LoadFile(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *FileProvider) GetHolidays(d Day) []Holiday {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	holidays := p.days[d]
+	if len(holidays) == 0 {
+		return nil
+	}
+	result := make([]Holiday, len(holidays))
+	copy(result, holidays)
+	return result
+}
+
+var (
+	regionProvidersMu sync.RWMutex
+	regionProviders   = map[string][]Provider{
+		"CN": {DefaultProvider},
+	}
+	// LoadProviders 为各地区注册的 Provider，再次加载时替换
+	loadedProviders = make(map[string]Provider)
+)
+
+func normalizeRegion(region string) string {
+	return strings.ToUpper(region)
+}
+
+// RegisterProvider 为地区注册 Provider，region 为 ISO 3166-1 的二位字母代码，如 "CN"。
+// 一个地区可以有多个 Provider，结果按注册顺序合并。
+func RegisterProvider(region string, p Provider) {
+	region = normalizeRegion(region)
+	regionProvidersMu.Lock()
+	regionProviders[region] = append(regionProviders[region], p)
+	regionProvidersMu.Unlock()
+}
+
+// GetProvider 获取地区的 Provider，地区没有注册 Provider 时返回 nil
+func GetProvider(region string) Provider {
+	region = normalizeRegion(region)
+	regionProvidersMu.RLock()
+	defer regionProvidersMu.RUnlock()
+	providers := regionProviders[region]
+	switch len(providers) {
+	case 0:
+		return nil
+	case 1:
+		return providers[0]
+	}
+	return MultiProvider(append([]Provider(nil), providers...)...)
+}
+
+// GetProviderForLocale 根据 locale 的地区获取 Provider，如 "zh_CN.UTF-8" 对应地区 "CN"
+func GetProviderForLocale(localeName string) Provider {
+	territory := locale.ExplodeLocale(localeName).Territory
+	if territory == "" {
+		return nil
+	}
+	return GetProvider(territory)
+}
+
+// setLoadedProvider 注册 LoadProviders 加载的 Provider，替换之前为该地区加载的 Provider
+func setLoadedProvider(region string, p Provider) {
+	region = normalizeRegion(region)
+	regionProvidersMu.Lock()
+	defer regionProvidersMu.Unlock()
+	old, ok := loadedProviders[region]
+	loadedProviders[region] = p
+	if ok {
+		providers := regionProviders[region]
+		for i, provider := range providers {
+			if provider == old {
+				providers[i] = p
+				return
+			}
+		}
+	}
+	regionProviders[region] = append(regionProviders[region], p)
+}
+
+// LoadProviders 加载目录中各地区的节假日数据，每个子目录以地区代码命名，
+// 如 dir/CN/2023.json，加载后注册为该地区的 Provider。
+// 再次调用时替换之前为该地区加载的 Provider，如在数据更新后重新加载。
+func LoadProviders(dir string) error {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() {
+			continue
+		}
+		p := NewFileProvider()
+		err = p.LoadDir(filepath.Join(dir, fileInfo.Name()))
+		if err != nil {
+			return err
+		}
+		setLoadedProvider(fileInfo.Name(), p)
+	}
+	return nil
+}
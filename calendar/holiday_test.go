@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package calendar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DefaultProvider(t *testing.T) {
+	assert.Equal(t, []Holiday{{Name: "建党节"}, {Name: "香港回归纪念日"}}, DefaultProvider.GetHolidays(Day{2022, 7, 1}))
+	assert.Equal(t, []Holiday{{Name: "建党节"}}, DefaultProvider.GetHolidays(Day{1990, 7, 1}))
+	assert.Equal(t, []Holiday{{Name: "母亲节"}}, DefaultProvider.GetHolidays(Day{2023, 5, 14}))
+	assert.Nil(t, DefaultProvider.GetHolidays(Day{2023, 5, 15}))
+
+	d := Day{2022, 7, 1}
+	assert.Equal(t, "建党节,香港回归纪念日", d.Festival())
+}
+
+func Test_FileProvider(t *testing.T) {
+	p := NewFileProvider()
+	require.NoError(t, p.LoadDir("testdata/holidays/CN"))
+
+	assert.Equal(t, []Holiday{{Name: "春节", IsHoliday: true}}, p.GetHolidays(Day{2023, 1, 23}))
+	assert.Equal(t, []Holiday{{Name: "春节", IsWorkdayOverride: true}}, p.GetHolidays(Day{2023, 1, 28}))
+	assert.Nil(t, p.GetHolidays(Day{2023, 1, 30}))
+
+	tests := []struct {
+		day     Day
+		workday bool
+	}{
+		{Day{2023, 1, 20}, true},  // 周五
+		{Day{2023, 1, 23}, false}, // 春节，周一
+		{Day{2023, 1, 28}, true},  // 调休，周六
+		{Day{2023, 2, 4}, false},  // 周六
+		{Day{2022, 12, 31}, false},
+		{Day{2023, 10, 8}, true}, // 调休，周日
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.workday, test.day.IsWorkday(p), test.day)
+	}
+
+	err := p.LoadData([]byte(`{"year": 2023, "days": [{"name": "春节", "date": "2023/01/22", "isOffDay": true}]}`))
+	assert.Error(t, err)
+	err = p.LoadFile("testdata/holidays/none.json")
+	assert.Error(t, err)
+}
+
+func Test_RegionProvider(t *testing.T) {
+	regionProvidersMu.Lock()
+	saved, savedLoaded := regionProviders, loadedProviders
+	regionProviders = map[string][]Provider{"CN": {DefaultProvider}}
+	loadedProviders = make(map[string]Provider)
+	regionProvidersMu.Unlock()
+	defer func() {
+		regionProvidersMu.Lock()
+		regionProviders, loadedProviders = saved, savedLoaded
+		regionProvidersMu.Unlock()
+	}()
+
+	assert.Nil(t, GetProvider("US"))
+	assert.Nil(t, GetProviderForLocale("en_US.UTF-8"))
+	assert.Nil(t, GetProviderForLocale("C"))
+	assert.Equal(t, DefaultProvider, GetProviderForLocale("zh_CN.UTF-8"))
+
+	require.NoError(t, LoadProviders("testdata/holidays"))
+	p := GetProviderForLocale("zh_CN.UTF-8")
+	require.NotNil(t, p)
+	assert.Equal(t, []Holiday{
+		{Name: "国庆节"},
+		{Name: "中秋节、国庆节", IsHoliday: true},
+	}, p.GetHolidays(Day{2023, 10, 1}))
+	assert.NotNil(t, GetProvider("cn"))
+
+	// the providers loaded again are replaced
+	require.NoError(t, LoadProviders("testdata/holidays"))
+	assert.Len(t, regionProviders["CN"], 2)
+	assert.Equal(t, []Holiday{
+		{Name: "国庆节"},
+		{Name: "中秋节、国庆节", IsHoliday: true},
+	}, GetProvider("CN").GetHolidays(Day{2023, 10, 1}))
+}
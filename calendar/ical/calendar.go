@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+const defaultProdId = "-//deepin//go-lib calendar//EN"
+
+var ErrNoCalendar = errors.New("no VCALENDAR component found")
+
+// Calendar is a VCALENDAR object.
+type Calendar struct {
+	// Props are the calendar properties, such as PRODID and VERSION.
+	Props     []*Property
+	Events    []*Event
+	Todos     []*Todo
+	TimeZones []*Component
+	// Components are the other components, such as VJOURNAL.
+	Components []*Component
+}
+
+// NewCalendar returns an empty calendar with the required properties.
+func NewCalendar() *Calendar {
+	return &Calendar{
+		Props: []*Property{
+			NewProperty("VERSION", "2.0"),
+			NewProperty("PRODID", defaultProdId),
+		},
+	}
+}
+
+// LoadFromFile parses the iCalendar file.
+func LoadFromFile(file string) (*Calendar, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// LoadFromData parses the iCalendar data.
+func LoadFromData(data []byte) (*Calendar, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse parses the iCalendar stream, the components of all VCALENDAR objects
+// are merged. A TZID is resolved by the IANA time zone database, if it is
+// unknown the fixed offset of the standard time of its VTIMEZONE is used.
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := readContentLines(r)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := parseComponents(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{}
+	found := false
+	for _, root := range roots {
+		if root.Name != "VCALENDAR" {
+			continue
+		}
+		if !found {
+			cal.Props = root.Props
+			found = true
+		}
+		for _, c := range root.Components {
+			if c.Name == "VTIMEZONE" {
+				cal.TimeZones = append(cal.TimeZones, c)
+			}
+		}
+	}
+	if !found {
+		return nil, ErrNoCalendar
+	}
+
+	resolve := cal.resolveTZID
+	for _, root := range roots {
+		if root.Name != "VCALENDAR" {
+			continue
+		}
+		for _, c := range root.Components {
+			switch c.Name {
+			case "VTIMEZONE":
+			case "VEVENT":
+				e, err := parseEvent(c, resolve)
+				if err != nil {
+					return nil, err
+				}
+				cal.Events = append(cal.Events, e)
+			case "VTODO":
+				t, err := parseTodo(c, resolve)
+				if err != nil {
+					return nil, err
+				}
+				cal.Todos = append(cal.Todos, t)
+			default:
+				cal.Components = append(cal.Components, c)
+			}
+		}
+	}
+	return cal, nil
+}
+
+func (cal *Calendar) resolveTZID(tzid string) (*time.Location, error) {
+	loc, err := time.LoadLocation(tzid)
+	if err == nil {
+		return loc, nil
+	}
+	for _, tz := range cal.TimeZones {
+		p := tz.Prop("TZID")
+		if p == nil || p.Value != tzid {
+			continue
+		}
+		for _, name := range []string{"STANDARD", "DAYLIGHT"} {
+			for _, c := range tz.Components {
+				if c.Name != name || c.Prop("TZOFFSETTO") == nil {
+					continue
+				}
+				offset, err := parseUTCOffset(c.Prop("TZOFFSETTO").Value)
+				if err != nil {
+					return nil, err
+				}
+				return time.FixedZone(tzid, offset), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown time zone %q", tzid)
+}
+
+// Component returns the VCALENDAR component of cal.
+func (cal *Calendar) Component() *Component {
+	c := NewComponent("VCALENDAR")
+	c.Props = cal.Props
+	c.Components = append(c.Components, cal.TimeZones...)
+	for _, e := range cal.Events {
+		c.Components = append(c.Components, e.Component())
+	}
+	for _, t := range cal.Todos {
+		c.Components = append(c.Components, t.Component())
+	}
+	c.Components = append(c.Components, cal.Components...)
+	return c
+}
+
+// SaveToWriter writes cal in the iCalendar format. The VTIMEZONE components
+// are not generated, an IANA TZID is understood by most applications.
+func (cal *Calendar) SaveToWriter(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	writeComponent(bw, cal.Component())
+	return bw.Flush()
+}
+
+// SaveToFile writes cal to file.
+func (cal *Calendar) SaveToFile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = cal.SaveToWriter(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (cal *Calendar) String() string {
+	var buf bytes.Buffer
+	_ = cal.SaveToWriter(&buf)
+	return buf.String()
+}
+
+// EventOccurrence is an occurrence of an event in a calendar.
+type EventOccurrence struct {
+	Event *Event
+	Occurrence
+}
+
+// ExpandEvents returns the occurrences of all events which overlap
+// [from, to), sorted by the start time. The occurrences replaced by the
+// events with the same UID and a RECURRENCE-ID are dropped.
+func (cal *Calendar) ExpandEvents(from, to time.Time) ([]EventOccurrence, error) {
+	overridden := make(map[string]map[int64]bool)
+	for _, e := range cal.Events {
+		if e.RecurrenceID.IsZero() {
+			continue
+		}
+		if overridden[e.UID] == nil {
+			overridden[e.UID] = make(map[int64]bool)
+		}
+		overridden[e.UID][e.RecurrenceID.UnixNano()] = true
+	}
+
+	var result []EventOccurrence
+	for _, e := range cal.Events {
+		list, err := e.Occurrences(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("event %q: %v", e.UID, err)
+		}
+		for _, o := range list {
+			if e.RecurrenceID.IsZero() && overridden[e.UID][o.Start.UnixNano()] {
+				continue
+			}
+			result = append(result, EventOccurrence{Event: e, Occurrence: o})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result, nil
+}
@@ -0,0 +1,382 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a VEVENT component.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Status       string
+	Categories   []string
+	Start        DateTime
+	End          DateTime      // zero if Duration is used
+	Duration     time.Duration // used if End is zero
+	RecurrenceID DateTime      // set for a modified occurrence of a recurring event
+	Stamp        time.Time
+	Created      time.Time
+	LastModified time.Time
+	Sequence     int
+	Recurrence
+
+	// Props are the other properties, and Components are the sub components
+	// such as VALARM, they are kept for writing back.
+	Props      []*Property
+	Components []*Component
+}
+
+// Todo is a VTODO component.
+type Todo struct {
+	UID             string
+	Summary         string
+	Description     string
+	Status          string
+	Categories      []string
+	Start           DateTime
+	Due             DateTime
+	Duration        time.Duration // used with Start if Due is zero
+	Completed       time.Time
+	PercentComplete int
+	Priority        int
+	RecurrenceID    DateTime
+	Stamp           time.Time
+	Created         time.Time
+	LastModified    time.Time
+	Sequence        int
+	Recurrence
+
+	Props      []*Property
+	Components []*Component
+}
+
+// Occurrence is an instance of a recurring event.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+// duration returns the length of the event, an all day event without end
+// lasts one day.
+func (e *Event) duration() time.Duration {
+	switch {
+	case !e.End.IsZero():
+		if e.Start.AllDay {
+			// whole days in spite of daylight saving time changes
+			return civilDate(e.End.Time).Sub(civilDate(e.Start.Time))
+		}
+		return e.End.Sub(e.Start.Time)
+	case e.Duration != 0:
+		return e.Duration
+	case e.Start.AllDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// GetEnd returns the end of the event, computed from Duration if End is zero.
+func (e *Event) GetEnd() time.Time {
+	return addDuration(e.Start.Time, e.duration())
+}
+
+// RecurrenceSet returns the start times of the occurrences of e.
+func (e *Event) RecurrenceSet() *RecurrenceSet {
+	return NewRecurrenceSet(e.Start, &e.Recurrence)
+}
+
+// Occurrences returns the occurrences of e which overlap [from, to).
+func (e *Event) Occurrences(from, to time.Time) ([]Occurrence, error) {
+	d := e.duration()
+	// the occurrences starting before from may still overlap
+	starts, err := e.RecurrenceSet().Between(addDuration(from, -d), to)
+	if err != nil {
+		return nil, err
+	}
+	var result []Occurrence
+	for _, start := range starts {
+		end := addDuration(start, d)
+		if end.After(from) || start.Equal(from) {
+			result = append(result, Occurrence{Start: start, End: end})
+		}
+	}
+	return result, nil
+}
+
+// RecurrenceSet returns the start times of the occurrences of t, the due
+// date is used if there is no start.
+func (t *Todo) RecurrenceSet() *RecurrenceSet {
+	start := t.Start
+	if start.IsZero() {
+		start = t.Due
+	}
+	return NewRecurrenceSet(start, &t.Recurrence)
+}
+
+func parseEvent(c *Component, resolve tzResolver) (*Event, error) {
+	e := &Event{}
+	for _, p := range c.Props {
+		var err error
+		switch p.Name {
+		case "UID":
+			e.UID = p.Text()
+		case "SUMMARY":
+			e.Summary = p.Text()
+		case "DESCRIPTION":
+			e.Description = p.Text()
+		case "LOCATION":
+			e.Location = p.Text()
+		case "STATUS":
+			e.Status = strings.ToUpper(p.Value)
+		case "CATEGORIES":
+			e.Categories = append(e.Categories, p.TextList()...)
+		case "DTSTART":
+			e.Start, err = parseDateTime(p, resolve)
+		case "DTEND":
+			e.End, err = parseDateTime(p, resolve)
+		case "DURATION":
+			e.Duration, err = ParseDuration(p.Value)
+		case "RECURRENCE-ID":
+			e.RecurrenceID, err = parseDateTime(p, resolve)
+		case "DTSTAMP":
+			e.Stamp, err = parseUTCTime(p)
+		case "CREATED":
+			e.Created, err = parseUTCTime(p)
+		case "LAST-MODIFIED":
+			e.LastModified, err = parseUTCTime(p)
+		case "SEQUENCE":
+			e.Sequence, err = strconv.Atoi(p.Value)
+		default:
+			var ok bool
+			ok, err = e.Recurrence.parseProperty(p, resolve)
+			if !ok {
+				e.Props = append(e.Props, p)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("VEVENT %s: %v", p.Name, err)
+		}
+	}
+	if e.Start.IsZero() {
+		return nil, fmt.Errorf("VEVENT %q: missing DTSTART", e.UID)
+	}
+	e.Components = c.Components
+	return e, nil
+}
+
+func parseTodo(c *Component, resolve tzResolver) (*Todo, error) {
+	t := &Todo{}
+	for _, p := range c.Props {
+		var err error
+		switch p.Name {
+		case "UID":
+			t.UID = p.Text()
+		case "SUMMARY":
+			t.Summary = p.Text()
+		case "DESCRIPTION":
+			t.Description = p.Text()
+		case "STATUS":
+			t.Status = strings.ToUpper(p.Value)
+		case "CATEGORIES":
+			t.Categories = append(t.Categories, p.TextList()...)
+		case "DTSTART":
+			t.Start, err = parseDateTime(p, resolve)
+		case "DUE":
+			t.Due, err = parseDateTime(p, resolve)
+		case "DURATION":
+			t.Duration, err = ParseDuration(p.Value)
+		case "COMPLETED":
+			t.Completed, err = parseUTCTime(p)
+		case "PERCENT-COMPLETE":
+			t.PercentComplete, err = strconv.Atoi(p.Value)
+		case "PRIORITY":
+			t.Priority, err = strconv.Atoi(p.Value)
+		case "RECURRENCE-ID":
+			t.RecurrenceID, err = parseDateTime(p, resolve)
+		case "DTSTAMP":
+			t.Stamp, err = parseUTCTime(p)
+		case "CREATED":
+			t.Created, err = parseUTCTime(p)
+		case "LAST-MODIFIED":
+			t.LastModified, err = parseUTCTime(p)
+		case "SEQUENCE":
+			t.Sequence, err = strconv.Atoi(p.Value)
+		default:
+			var ok bool
+			ok, err = t.Recurrence.parseProperty(p, resolve)
+			if !ok {
+				t.Props = append(t.Props, p)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("VTODO %s: %v", p.Name, err)
+		}
+	}
+	if t.IsRecurring() && t.Start.IsZero() && t.Due.IsZero() {
+		return nil, fmt.Errorf("VTODO %q: recurrence without DTSTART", t.UID)
+	}
+	t.Components = c.Components
+	return t, nil
+}
+
+func parseUTCTime(p *Property) (time.Time, error) {
+	dt, err := parseDateTime(p, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dt.Time, nil
+}
+
+// parseProperty parses p if it is a recurrence property.
+func (r *Recurrence) parseProperty(p *Property, resolve tzResolver) (bool, error) {
+	switch p.Name {
+	case "RRULE":
+		rule, err := ParseRRule(p.Value)
+		if err != nil {
+			return true, err
+		}
+		r.RRules = append(r.RRules, rule)
+	case "RDATE":
+		list, err := parseDateTimeList(p, resolve)
+		if err != nil {
+			return true, err
+		}
+		r.RDates = append(r.RDates, list...)
+	case "EXDATE":
+		list, err := parseDateTimeList(p, resolve)
+		if err != nil {
+			return true, err
+		}
+		r.ExDates = append(r.ExDates, list...)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (r *Recurrence) addProperties(c *Component) {
+	for _, rule := range r.RRules {
+		c.AddProp(NewProperty("RRULE", rule.String()))
+	}
+	for _, dt := range r.RDates {
+		c.AddProp(newDateTimeProperty("RDATE", dt))
+	}
+	for _, dt := range r.ExDates {
+		c.AddProp(newDateTimeProperty("EXDATE", dt))
+	}
+}
+
+type componentBuilder struct {
+	c *Component
+}
+
+func (b componentBuilder) text(name, value string) {
+	if value != "" {
+		b.c.AddProp(NewTextProperty(name, value))
+	}
+}
+
+func (b componentBuilder) dateTime(name string, dt DateTime) {
+	if !dt.IsZero() {
+		b.c.AddProp(newDateTimeProperty(name, dt))
+	}
+}
+
+func (b componentBuilder) utcTime(name string, t time.Time) {
+	if !t.IsZero() {
+		b.c.AddProp(NewProperty(name, t.UTC().Format(utcDateTimeLayout)))
+	}
+}
+
+func (b componentBuilder) categories(list []string) {
+	if len(list) == 0 {
+		return
+	}
+	values := make([]string, len(list))
+	for i, v := range list {
+		values[i] = EscapeText(v)
+	}
+	b.c.AddProp(NewProperty("CATEGORIES", strings.Join(values, ",")))
+}
+
+func (b componentBuilder) int(name string, n int) {
+	if n != 0 {
+		b.c.AddProp(NewProperty(name, strconv.Itoa(n)))
+	}
+}
+
+// Component returns the VEVENT component of e. DTSTAMP is the current
+// time if Stamp is zero, since it is required.
+func (e *Event) Component() *Component {
+	b := componentBuilder{NewComponent("VEVENT")}
+	b.text("UID", e.UID)
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	b.utcTime("DTSTAMP", stamp)
+	b.dateTime("DTSTART", e.Start)
+	if !e.End.IsZero() {
+		b.dateTime("DTEND", e.End)
+	} else if e.Duration != 0 {
+		b.c.AddProp(NewProperty("DURATION", FormatDuration(e.Duration)))
+	}
+	b.dateTime("RECURRENCE-ID", e.RecurrenceID)
+	e.Recurrence.addProperties(b.c)
+	b.text("SUMMARY", e.Summary)
+	b.text("DESCRIPTION", e.Description)
+	b.text("LOCATION", e.Location)
+	if e.Status != "" {
+		b.c.AddProp(NewProperty("STATUS", e.Status))
+	}
+	b.categories(e.Categories)
+	b.utcTime("CREATED", e.Created)
+	b.utcTime("LAST-MODIFIED", e.LastModified)
+	b.int("SEQUENCE", e.Sequence)
+	b.c.Props = append(b.c.Props, e.Props...)
+	b.c.Components = e.Components
+	return b.c
+}
+
+// Component returns the VTODO component of t. DTSTAMP is the current time
+// if Stamp is zero, since it is required.
+func (t *Todo) Component() *Component {
+	b := componentBuilder{NewComponent("VTODO")}
+	b.text("UID", t.UID)
+	stamp := t.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	b.utcTime("DTSTAMP", stamp)
+	b.dateTime("DTSTART", t.Start)
+	if !t.Due.IsZero() {
+		b.dateTime("DUE", t.Due)
+	} else if t.Duration != 0 {
+		b.c.AddProp(NewProperty("DURATION", FormatDuration(t.Duration)))
+	}
+	b.dateTime("RECURRENCE-ID", t.RecurrenceID)
+	t.Recurrence.addProperties(b.c)
+	b.text("SUMMARY", t.Summary)
+	b.text("DESCRIPTION", t.Description)
+	if t.Status != "" {
+		b.c.AddProp(NewProperty("STATUS", t.Status))
+	}
+	b.categories(t.Categories)
+	b.utcTime("COMPLETED", t.Completed)
+	b.int("PERCENT-COMPLETE", t.PercentComplete)
+	b.int("PRIORITY", t.Priority)
+	b.utcTime("CREATED", t.Created)
+	b.utcTime("LAST-MODIFIED", t.LastModified)
+	b.int("SEQUENCE", t.Sequence)
+	b.c.Props = append(b.c.Props, t.Props...)
+	b.c.Components = t.Components
+	return b.c
+}
@@ -0,0 +1,511 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"sort"
+	"time"
+)
+
+// the recurrences stop at the largest year of DATE values
+const maxYear = 9999
+
+// Iterator returns the occurrences of a recurrence in ascending order.
+type Iterator interface {
+	// Next returns the next occurrence, or false if there are no more.
+	Next() (time.Time, bool)
+}
+
+// periodGenerator returns the occurrences of the next period of a rule, or
+// false if there are no more periods.
+type periodGenerator func() ([]time.Time, bool)
+
+type ruleIterator struct {
+	rule    *RRule
+	start   time.Time
+	until   time.Time
+	next    periodGenerator
+	buf     []time.Time
+	count   int
+	started bool
+	done    bool
+}
+
+// Iterator returns the occurrences of r for the first occurrence start,
+// which is DTSTART. The occurrences are in the location of start, the
+// daily and coarser rules keep the time of day across daylight saving time
+// changes. A time of day skipped by the change is shifted forward by the
+// length of the gap, and a time of day repeated by the change is the first
+// one, as RFC 5545 section 3.3.5.
+func (r *RRule) Iterator(start time.Time) (Iterator, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	it := &ruleIterator{
+		rule:  r,
+		start: start,
+		until: r.until(start.Location()),
+	}
+	if r.RScale == RScaleChinese {
+		it.next = newLunarGenerator(r, start)
+	} else {
+		it.next = newGregorianGenerator(r, start)
+	}
+	return it, nil
+}
+
+// Between returns the occurrences of r in [from, to).
+func (r *RRule) Between(start, from, to time.Time) ([]time.Time, error) {
+	it, err := r.Iterator(start)
+	if err != nil {
+		return nil, err
+	}
+	return collectBetween(it, from, to), nil
+}
+
+func collectBetween(it Iterator, from, to time.Time) []time.Time {
+	var result []time.Time
+	for {
+		t, ok := it.Next()
+		if !ok || !t.Before(to) {
+			return result
+		}
+		if !t.Before(from) {
+			result = append(result, t)
+		}
+	}
+}
+
+// Next implements Iterator, DTSTART always counts as the first occurrence.
+func (it *ruleIterator) Next() (time.Time, bool) {
+	if it.done {
+		return time.Time{}, false
+	}
+	if !it.started {
+		it.started = true
+		return it.emit(it.start)
+	}
+	for len(it.buf) == 0 {
+		list, ok := it.next()
+		if !ok {
+			it.done = true
+			return time.Time{}, false
+		}
+		for _, t := range list {
+			if t.After(it.start) {
+				it.buf = append(it.buf, t)
+			}
+		}
+	}
+	t := it.buf[0]
+	it.buf = it.buf[1:]
+	return it.emit(t)
+}
+
+func (it *ruleIterator) emit(t time.Time) (time.Time, bool) {
+	if !it.until.IsZero() && t.After(it.until) {
+		it.done = true
+		return time.Time{}, false
+	}
+	it.count++
+	if it.rule.Count > 0 && it.count >= it.rule.Count {
+		it.done = true
+	}
+	return t, true
+}
+
+type gregorianGenerator struct {
+	rule     *RRule
+	start    time.Time
+	loc      *time.Location
+	interval int
+	period   int
+
+	byMonth    []int
+	byMonthDay []int
+	byDay      []WeekdayNum
+	byHour     []int
+	byMinute   []int
+	bySecond   []int
+	// BYDAY ordinals count in the year, otherwise in the month
+	byDayInYear bool
+
+	// the first period and the length of periods in seconds of the
+	// sub-daily rules
+	base    time.Time
+	stepSec int64
+}
+
+func newGregorianGenerator(r *RRule, start time.Time) periodGenerator {
+	g := &gregorianGenerator{
+		rule:       r,
+		start:      start,
+		loc:        start.Location(),
+		interval:   r.interval(),
+		byMonth:    sortedInts(r.ByMonth),
+		byMonthDay: r.ByMonthDay,
+		byDay:      r.ByDay,
+		byHour:     sortedInts(r.ByHour),
+		byMinute:   sortedInts(r.ByMinute),
+		bySecond:   sortedInts(r.BySecond),
+	}
+
+	// the missing rule parts are taken from DTSTART
+	if len(r.ByWeekNo) == 0 && len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		switch r.Freq {
+		case Yearly:
+			if len(g.byMonth) == 0 {
+				g.byMonth = []int{int(start.Month())}
+			}
+			g.byMonthDay = []int{start.Day()}
+		case Monthly:
+			g.byMonthDay = []int{start.Day()}
+		case Weekly:
+			g.byDay = []WeekdayNum{{Weekday: start.Weekday()}}
+		}
+	}
+	if r.Freq > Hourly && len(g.byHour) == 0 {
+		g.byHour = []int{start.Hour()}
+	}
+	if r.Freq > Minutely && len(g.byMinute) == 0 {
+		g.byMinute = []int{start.Minute()}
+	}
+	if r.Freq > Secondly && len(g.bySecond) == 0 {
+		g.bySecond = []int{start.Second()}
+	}
+	g.byDayInYear = r.Freq == Yearly && len(g.byMonth) == 0
+
+	y, m, d := start.Date()
+	var unit int64
+	switch r.Freq {
+	case Hourly:
+		g.base = localTime(y, m, d, start.Hour(), 0, 0, g.loc)
+		unit = 3600
+	case Minutely:
+		g.base = localTime(y, m, d, start.Hour(), start.Minute(), 0, g.loc)
+		unit = 60
+	case Secondly:
+		g.base = start.Truncate(time.Second)
+		unit = 1
+	}
+	if unit > 0 {
+		// the periods longer than all the years are the same
+		g.stepSec = maxSpanSec
+		if int64(g.interval) < maxSpanSec/unit {
+			g.stepSec = int64(g.interval) * unit
+		}
+	}
+	return g.next
+}
+
+// maxSpanSec is longer than the time from the year 0 to maxYear in
+// seconds, the sub-daily periods are counted in seconds instead of
+// time.Duration, which overflows after 292 years.
+const maxSpanSec = (maxYear + 1) * 366 * 86400
+
+// maxSubDailySkips limits the periods skipped to find the next occurrence
+// of a sub-daily rule, such as FREQ=SECONDLY;BYSECOND=60 which never
+// matches, the rule ends if it is exceeded.
+const maxSubDailySkips = 1 << 20
+
+func sortedInts(list []int) []int {
+	result := append([]int(nil), list...)
+	sort.Ints(result)
+	return result
+}
+
+func containsInt(list []int, v int) bool {
+	for _, n := range list {
+		if n == v {
+			return true
+		}
+	}
+	return false
+}
+
+// localTime returns the time of the date and the time of day in loc. The
+// time of day in the gap of a daylight saving time change is interpreted
+// with the offset before the gap, which shifts it forward by the gap.
+func localTime(year int, month time.Month, day, hour, min, sec int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, min, sec, 0, loc)
+	y, m, d := t.Date()
+	if y == year && m == month && d == day && t.Hour() == hour && t.Minute() == min && t.Second() == sec {
+		return t
+	}
+	// the time of day does not exist, time.Date may use either offset
+	_, offset := t.AddDate(0, 0, -1).Zone()
+	utc := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	return utc.Add(-time.Duration(offset) * time.Second).In(loc)
+}
+
+// civilDate returns the date of t as a time in UTC, which is used for the
+// day arithmetic.
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+func (g *gregorianGenerator) next() ([]time.Time, bool) {
+	if g.rule.Freq < Daily {
+		return g.nextSubDaily()
+	}
+	k := g.period * g.interval
+	g.period++
+
+	var days []time.Time
+	start := civilDate(g.start)
+	switch g.rule.Freq {
+	case Yearly:
+		year := g.start.Year() + k
+		if year > maxYear {
+			return nil, false
+		}
+		days = g.yearDays(year)
+	case Monthly:
+		first := start.AddDate(0, 0, 1-start.Day()).AddDate(0, k, 0)
+		if first.Year() > maxYear {
+			return nil, false
+		}
+		for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+			if g.dayMatches(d) {
+				days = append(days, d)
+			}
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) - int(g.rule.WeekStart) + 7) % 7
+		first := start.AddDate(0, 0, 7*k-offset)
+		if first.Year() > maxYear {
+			return nil, false
+		}
+		for i := 0; i < 7; i++ {
+			d := first.AddDate(0, 0, i)
+			if g.dayMatches(d) {
+				days = append(days, d)
+			}
+		}
+	case Daily:
+		d := start.AddDate(0, 0, k)
+		if d.Year() > maxYear {
+			return nil, false
+		}
+		if g.dayMatches(d) {
+			days = append(days, d)
+		}
+	}
+
+	var result []time.Time
+	for _, d := range days {
+		for _, h := range g.byHour {
+			for _, min := range g.byMinute {
+				for _, sec := range g.bySecond {
+					result = append(result, localTime(d.Year(), d.Month(), d.Day(), h, min, sec, g.loc))
+				}
+			}
+		}
+	}
+	return g.setPos(result), true
+}
+
+// yearDays returns the matched days of year. With BYWEEKNO the days of the
+// first and the last week may be in the adjacent years.
+func (g *gregorianGenerator) yearDays(year int) []time.Time {
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := first.AddDate(1, 0, 0)
+	if len(g.rule.ByWeekNo) > 0 {
+		first = g.firstWeekStart(year)
+		end = g.firstWeekStart(year + 1)
+	}
+	weeks := int(end.Sub(first).Hours()/24) / 7
+
+	var result []time.Time
+	for d := first; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if len(g.rule.ByWeekNo) > 0 {
+			weekNo := int(d.Sub(first).Hours()/24)/7 + 1
+			if !matchNum(g.rule.ByWeekNo, weekNo, weeks) {
+				continue
+			}
+		}
+		if g.dayMatches(d) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// firstWeekStart returns the first day of the week 1 of year, which is the
+// first week with at least 4 days in year.
+func (g *gregorianGenerator) firstWeekStart(year int) time.Time {
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan1.Weekday()) - int(g.rule.WeekStart) + 7) % 7
+	if offset <= 3 {
+		return jan1.AddDate(0, 0, -offset)
+	}
+	return jan1.AddDate(0, 0, 7-offset)
+}
+
+// matchNum reports whether the 1-based index n of total matches one of
+// list, the negative values count from the end.
+func matchNum(list []int, n, total int) bool {
+	for _, v := range list {
+		if v == n || v < 0 && total+v+1 == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *gregorianGenerator) dayMatches(d time.Time) bool {
+	year, month, day := d.Date()
+	if len(g.byMonth) > 0 && !containsInt(g.byMonth, int(month)) {
+		return false
+	}
+	if len(g.rule.ByYearDay) > 0 && !matchNum(g.rule.ByYearDay, d.YearDay(), daysInYear(year)) {
+		return false
+	}
+	if len(g.byMonthDay) > 0 && !matchNum(g.byMonthDay, day, daysIn(year, month)) {
+		return false
+	}
+	if len(g.byDay) > 0 && !g.weekdayMatches(d) {
+		return false
+	}
+	return true
+}
+
+func (g *gregorianGenerator) weekdayMatches(d time.Time) bool {
+	year, month, day := d.Date()
+	for _, wd := range g.byDay {
+		if wd.Weekday != d.Weekday() {
+			continue
+		}
+		if wd.N == 0 || (g.rule.Freq != Yearly && g.rule.Freq != Monthly) {
+			return true
+		}
+		// the position of the weekday in the year or the month
+		n, total := day, daysIn(year, month)
+		if g.byDayInYear {
+			n, total = d.YearDay(), daysInYear(year)
+		}
+		if wd.N > 0 && (n-1)/7+1 == wd.N || wd.N < 0 && (total-n)/7+1 == -wd.N {
+			return true
+		}
+	}
+	return false
+}
+
+// nextSubDaily returns the occurrences of the next hour, minute or second.
+// The periods are counted in absolute time.
+func (g *gregorianGenerator) nextSubDaily() ([]time.Time, bool) {
+	k := int64(g.period)
+	for i := 0; i < maxSubDailySkips; i++ {
+		p := g.periodStart(k)
+		if p.Year() > maxYear {
+			return nil, false
+		}
+		y, m, d := p.Date()
+		var boundary time.Time
+		switch {
+		case !g.dayMatches(civilDate(p)):
+			boundary = time.Date(y, m, d+1, 0, 0, 0, 0, g.loc)
+		case g.rule.Freq < Hourly && len(g.byHour) > 0 && !containsInt(g.byHour, p.Hour()):
+			boundary = time.Date(y, m, d, p.Hour()+1, 0, 0, 0, g.loc)
+		case g.rule.Freq < Minutely && len(g.byMinute) > 0 && !containsInt(g.byMinute, p.Minute()):
+			boundary = p.Truncate(time.Minute).Add(time.Minute)
+		case g.rule.Freq == Hourly && len(g.byHour) > 0 && !containsInt(g.byHour, p.Hour()),
+			g.rule.Freq == Minutely && len(g.byMinute) > 0 && !containsInt(g.byMinute, p.Minute()),
+			g.rule.Freq == Secondly && len(g.bySecond) > 0 && !containsInt(g.bySecond, p.Second()):
+			k++
+			continue
+		default:
+			g.period = int(k + 1)
+			return g.setPos(g.subDailyTimes(p)), true
+		}
+		if boundary.Year() > maxYear {
+			return nil, false
+		}
+		// skip the periods until the boundary
+		next := (boundary.Unix() - g.base.Unix() + g.stepSec - 1) / g.stepSec
+		if next <= k {
+			next = k + 1
+		}
+		k = next
+		g.period = int(k)
+	}
+	return nil, false
+}
+
+// periodStart returns the start of the sub-daily period k, the periods are
+// in absolute time.
+func (g *gregorianGenerator) periodStart(k int64) time.Time {
+	if k >= maxSpanSec/g.stepSec {
+		return time.Date(maxYear+1, time.January, 1, 0, 0, 0, 0, g.loc)
+	}
+	sec := k * g.stepSec
+	return g.base.UTC().AddDate(0, 0, int(sec/86400)).
+		Add(time.Duration(sec%86400) * time.Second).In(g.loc)
+}
+
+func (g *gregorianGenerator) subDailyTimes(p time.Time) []time.Time {
+	switch g.rule.Freq {
+	case Hourly:
+		hour := p.Add(-time.Duration(p.Minute())*time.Minute - time.Duration(p.Second())*time.Second)
+		var result []time.Time
+		for _, min := range g.byMinute {
+			for _, sec := range g.bySecond {
+				result = append(result, hour.Add(time.Duration(min)*time.Minute+time.Duration(sec)*time.Second))
+			}
+		}
+		return result
+	case Minutely:
+		minute := p.Add(-time.Duration(p.Second()) * time.Second)
+		var result []time.Time
+		for _, sec := range g.bySecond {
+			result = append(result, minute.Add(time.Duration(sec)*time.Second))
+		}
+		return result
+	}
+	return []time.Time{p}
+}
+
+// setPos sorts the occurrences of a period and applies BYSETPOS.
+func (g *gregorianGenerator) setPos(list []time.Time) []time.Time {
+	list = sortTimes(list)
+	if len(g.rule.BySetPos) == 0 {
+		return list
+	}
+	var result []time.Time
+	for _, pos := range g.rule.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(list) + pos
+		}
+		if i >= 0 && i < len(list) {
+			result = append(result, list[i])
+		}
+	}
+	return sortTimes(result)
+}
+
+// sortTimes sorts list and removes the duplicates.
+func sortTimes(list []time.Time) []time.Time {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Before(list[j])
+	})
+	result := list[:0]
+	for i, t := range list {
+		if i == 0 || !t.Equal(result[len(result)-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,394 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package ical reads and writes iCalendar (RFC 5545) data and expands the
+// recurrences of events and to-dos, including the Chinese lunar recurrences
+// of RFC 7529, such as "every year on the 15th day of the 8th lunar month".
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const maxLineOctets = 75
+
+// Params are the parameters of a property, the names are upper case.
+type Params map[string][]string
+
+// Get returns the first value of the parameter name.
+func (p Params) Get(name string) string {
+	values := p[strings.ToUpper(name)]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set sets the parameter name to value.
+func (p Params) Set(name, value string) {
+	p[strings.ToUpper(name)] = []string{value}
+}
+
+// Property is a content line of a component. Value is the raw value, use
+// Text to get the unescaped text.
+type Property struct {
+	Name   string
+	Params Params
+	Value  string
+}
+
+// NewProperty returns a property without parameters.
+func NewProperty(name, value string) *Property {
+	return &Property{Name: strings.ToUpper(name), Params: make(Params), Value: value}
+}
+
+// NewTextProperty returns a property with the escaped text.
+func NewTextProperty(name, text string) *Property {
+	return NewProperty(name, EscapeText(text))
+}
+
+// Text returns the unescaped text value.
+func (p *Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// TextList returns the unescaped values of a comma separated list.
+func (p *Property) TextList() []string {
+	var result []string
+	for _, v := range splitUnescaped(p.Value, ',') {
+		result = append(result, UnescapeText(v))
+	}
+	return result
+}
+
+// Component is a BEGIN/END block, such as VEVENT or VALARM.
+type Component struct {
+	Name       string
+	Props      []*Property
+	Components []*Component
+}
+
+// NewComponent returns an empty component.
+func NewComponent(name string) *Component {
+	return &Component{Name: strings.ToUpper(name)}
+}
+
+// Prop returns the first property name, or nil.
+func (c *Component) Prop(name string) *Property {
+	name = strings.ToUpper(name)
+	for _, p := range c.Props {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// PropsOf returns all properties name.
+func (c *Component) PropsOf(name string) []*Property {
+	name = strings.ToUpper(name)
+	var result []*Property
+	for _, p := range c.Props {
+		if p.Name == name {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// AddProp appends p.
+func (c *Component) AddProp(p *Property) {
+	c.Props = append(c.Props, p)
+}
+
+// SetProp replaces all properties named p.Name with p.
+func (c *Component) SetProp(p *Property) {
+	c.RemoveProp(p.Name)
+	c.AddProp(p)
+}
+
+// RemoveProp removes all properties name.
+func (c *Component) RemoveProp(name string) {
+	name = strings.ToUpper(name)
+	props := c.Props[:0]
+	for _, p := range c.Props {
+		if p.Name != name {
+			props = append(props, p)
+		}
+	}
+	c.Props = props
+}
+
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (err ParseError) Error() string {
+	return fmt.Sprintf("ical: line %d: %s", err.Line, err.Msg)
+}
+
+type contentLine struct {
+	num  int
+	prop *Property
+}
+
+// readContentLines reads the unfolded content lines.
+func readContentLines(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+
+	var result []contentLine
+	var cur strings.Builder
+	curNum := 0
+	flush := func() error {
+		if cur.Len() == 0 {
+			return nil
+		}
+		prop, err := parseContentLine(cur.String())
+		if err != nil {
+			return ParseError{Line: curNum, Msg: err.Error()}
+		}
+		result = append(result, contentLine{num: curNum, prop: prop})
+		cur.Reset()
+		return nil
+	}
+
+	num := 0
+	for scanner.Scan() {
+		num++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if num == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			// continuation of a folded line
+			cur.WriteString(line[1:])
+			continue
+		}
+		err := flush()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+		cur.WriteString(line)
+		curNum = num
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	err := flush()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseContentLine parses name *(";" param) ":" value.
+func parseContentLine(line string) (*Property, error) {
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid content line %q", line)
+	}
+	prop := NewProperty(line[:i], "")
+	for line[i] == ';' {
+		line = line[i+1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid parameter in %q", line)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+		var values []string
+		for {
+			var value string
+			if strings.HasPrefix(line, `"`) {
+				end := strings.IndexByte(line[1:], '"')
+				if end == -1 {
+					return nil, fmt.Errorf("unterminated quoted parameter %s", name)
+				}
+				value = line[1 : end+1]
+				line = line[end+2:]
+			} else {
+				end := strings.IndexAny(line, ",;:")
+				if end == -1 {
+					return nil, fmt.Errorf("missing value of property %s", prop.Name)
+				}
+				value = line[:end]
+				line = line[end:]
+			}
+			values = append(values, value)
+			if line == "" {
+				return nil, fmt.Errorf("missing value of property %s", prop.Name)
+			}
+			if line[0] != ',' {
+				break
+			}
+			line = line[1:]
+		}
+		prop.Params[name] = append(prop.Params[name], values...)
+		i = 0
+		if line[0] != ';' && line[0] != ':' {
+			return nil, fmt.Errorf("invalid parameter %s", name)
+		}
+	}
+	prop.Value = line[i+1:]
+	return prop, nil
+}
+
+// parseComponents builds the component tree from the content lines.
+func parseComponents(lines []contentLine) ([]*Component, error) {
+	var roots []*Component
+	var stack []*Component
+	for _, line := range lines {
+		prop := line.prop
+		switch prop.Name {
+		case "BEGIN":
+			c := NewComponent(prop.Value)
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			} else {
+				roots = append(roots, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, ParseError{Line: line.num, Msg: "unexpected END:" + prop.Value}
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, ParseError{Line: line.num, Msg: "property outside of a component"}
+			}
+			stack[len(stack)-1].AddProp(prop)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, ParseError{Line: lines[len(lines)-1].num, Msg: "missing END:" + stack[len(stack)-1].Name}
+	}
+	return roots, nil
+}
+
+func writeComponent(w *bufio.Writer, c *Component) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Props {
+		writeLine(w, formatProperty(p))
+	}
+	for _, child := range c.Components {
+		writeComponent(w, child)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+func formatProperty(p *Property) string {
+	var sb strings.Builder
+	sb.WriteString(p.Name)
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteByte(';')
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		for i, v := range p.Params[name] {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if strings.ContainsAny(v, ",;:") {
+				sb.WriteString(`"` + v + `"`)
+			} else {
+				sb.WriteString(v)
+			}
+		}
+	}
+	sb.WriteByte(':')
+	sb.WriteString(p.Value)
+	return sb.String()
+}
+
+// writeLine writes a content line folded at 75 octets, without splitting
+// UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		i := limit
+		for i > 0 && line[i]&0xc0 == 0x80 {
+			i--
+		}
+		w.WriteString(line[:i])
+		w.WriteString("\r\n ")
+		line = line[i:]
+		// the leading space counts
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// EscapeText escapes a TEXT value.
+func EscapeText(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', ';', ',':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// UnescapeText unescapes a TEXT value.
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// splitUnescaped splits s at sep not preceded by a backslash.
+func splitUnescaped(s string, sep byte) []string {
+	var result []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			result = append(result, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, s[start:])
+}
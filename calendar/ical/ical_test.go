@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContentLine(t *testing.T) {
+	p, err := parseContentLine(`ATTENDEE;ROLE=REQ-PARTICIPANT;DELEGATED-FROM="mailto:a@example.com","mailto:b@example.com":mailto:c@example.com`)
+	require.NoError(t, err)
+	assert.Equal(t, "ATTENDEE", p.Name)
+	assert.Equal(t, "REQ-PARTICIPANT", p.Params.Get("role"))
+	assert.Equal(t, []string{"mailto:a@example.com", "mailto:b@example.com"}, p.Params["DELEGATED-FROM"])
+	assert.Equal(t, "mailto:c@example.com", p.Value)
+
+	p, err = parseContentLine("summary:a:b")
+	require.NoError(t, err)
+	assert.Equal(t, "SUMMARY", p.Name)
+	assert.Equal(t, "a:b", p.Value)
+
+	for _, line := range []string{"NOVALUE", ":value", `X;A="b:value`, "X;A:value", "X;A=b"} {
+		_, err = parseContentLine(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestText(t *testing.T) {
+	s := "a,b;c\\d\ne"
+	assert.Equal(t, `a\,b\;c\\d\ne`, EscapeText(s))
+	assert.Equal(t, s, UnescapeText(EscapeText(s)))
+	assert.Equal(t, "a\nb", UnescapeText(`a\Nb`))
+
+	p := NewProperty("CATEGORIES", `A\,B,C`)
+	assert.Equal(t, []string{"A,B", "C"}, p.TextList())
+}
+
+func TestWriteLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	line := "DESCRIPTION:" + strings.Repeat("中文", 30)
+	writeLine(w, line)
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	assert.True(t, len(lines) > 1)
+	var sb strings.Builder
+	for i, l := range lines {
+		assert.True(t, len(l) <= maxLineOctets, l)
+		if i > 0 {
+			assert.Equal(t, byte(' '), l[0])
+			l = l[1:]
+		}
+		sb.WriteString(l)
+	}
+	assert.Equal(t, line, sb.String())
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		s string
+		d time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"-PT15M", -15 * time.Minute},
+		{"P1DT2H3M4S", 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{"PT0S", 0},
+	}
+	for _, test := range tests {
+		d, err := ParseDuration(test.s)
+		require.NoError(t, err, test.s)
+		assert.Equal(t, test.d, d, test.s)
+		assert.Equal(t, test.s, FormatDuration(test.d))
+	}
+	for _, s := range []string{"", "P", "PT", "1H", "PT1D", "P1H", "PTT1H"} {
+		_, err := ParseDuration(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseCalendar(t *testing.T) {
+	cal, err := LoadFromFile("testdata/events.ics")
+	require.NoError(t, err)
+	require.Len(t, cal.Events, 4)
+	require.Len(t, cal.Todos, 1)
+	assert.Len(t, cal.TimeZones, 1)
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	e := cal.Events[0]
+	assert.Equal(t, "weekly@example.com", e.UID)
+	assert.Equal(t, "Weekly meeting, room 1", e.Summary)
+	assert.Equal(t, "Line one\nLine two with a long text that has to be folded because it is longer than seventy five octets", e.Description)
+	assert.Equal(t, []string{"WORK", "MEETING"}, e.Categories)
+	assert.True(t, e.Start.Equal(time.Date(2022, 1, 3, 10, 0, 0, 0, shanghai)))
+	assert.Equal(t, "Asia/Shanghai", e.Start.Location().String())
+	assert.Equal(t, time.Hour, e.GetEnd().Sub(e.Start.Time))
+	assert.Len(t, e.RRules, 1)
+	assert.Len(t, e.ExDates, 1)
+	assert.Len(t, e.RDates, 1)
+	require.Len(t, e.Components, 1)
+	assert.Equal(t, "VALARM", e.Components[0].Name)
+
+	birthday := cal.Events[2]
+	assert.True(t, birthday.Start.AllDay)
+	assert.Equal(t, "生日", birthday.Summary)
+
+	// the unknown TZID uses the offset of its VTIMEZONE
+	windows := cal.Events[3]
+	_, offset := windows.Start.Zone()
+	assert.Equal(t, 8*3600, offset)
+
+	todo := cal.Todos[0]
+	assert.Equal(t, "Pay the bills", todo.Summary)
+	assert.Equal(t, 1, todo.Priority)
+	assert.Equal(t, 50, todo.PercentComplete)
+	require.Len(t, todo.Props, 1)
+	assert.Equal(t, "a:b", todo.Props[0].Params.Get("X-PARAM"))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"BEGIN:VEVENT\nEND:VEVENT\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:a\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;TZID=Nowhere/Unknown:20220101T000000\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20220101T000000\nRRULE:FREQ=SOMETIMES\nEND:VEVENT\nEND:VCALENDAR\n",
+		"VERSION:2.0\n",
+	}
+	for _, data := range tests {
+		_, err := LoadFromData([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	cal, err := LoadFromFile("testdata/events.ics")
+	require.NoError(t, err)
+
+	data := cal.String()
+	assert.True(t, strings.HasPrefix(data, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.Contains(t, data, "DTSTART;TZID=Asia/Shanghai:20220103T100000\r\n")
+	assert.Contains(t, data, "DTSTART;VALUE=DATE:20220910\r\n")
+	assert.Contains(t, data, "RRULE:RSCALE=CHINESE;FREQ=YEARLY\r\n")
+	assert.Contains(t, data, "SUMMARY:Weekly meeting\\, room 1\r\n")
+	assert.Contains(t, data, `X-CUSTOM;X-PARAM="a:b":value`)
+
+	cal2, err := LoadFromData([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, data, cal2.String())
+	assert.Equal(t, cal.Events[0].Description, cal2.Events[0].Description)
+	assert.True(t, cal.Events[0].Start.Equal(cal2.Events[0].Start.Time))
+}
+
+func TestNewCalendar(t *testing.T) {
+	cal := NewCalendar()
+	cal.Events = append(cal.Events, &Event{
+		UID:      "new@example.com",
+		Stamp:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Start:    NewDateTime(time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC)),
+		Duration: time.Hour,
+		Summary:  "New",
+		Recurrence: Recurrence{
+			RRules: []*RRule{{Freq: Daily, Count: 2, WeekStart: time.Monday}},
+		},
+	})
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:"+defaultProdId+"\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:new@example.com\r\n"+
+		"DTSTAMP:20220101T000000Z\r\n"+
+		"DTSTART:20220101T080000Z\r\n"+
+		"DURATION:PT1H\r\n"+
+		"RRULE:FREQ=DAILY;COUNT=2\r\n"+
+		"SUMMARY:New\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", cal.String())
+}
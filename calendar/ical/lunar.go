@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"time"
+
+	"github.com/linuxdeepin/go-lib/calendar/lunar"
+)
+
+// lunarDate is a date of the Chinese lunar calendar.
+type lunarDate struct {
+	year  int
+	month int
+	leap  bool
+	day   int
+}
+
+// solarToLunarDate converts the Gregorian date of t.
+func solarToLunarDate(t time.Time) lunarDate {
+	d := lunar.New(t.Year()).SolarDayToLunarDay(int(t.Month()), t.Day())
+	return lunarDate{
+		year:  d.LunarMonth.LunarYear,
+		month: d.LunarMonth.Name,
+		leap:  d.LunarMonth.IsLeap,
+		day:   d.LunarDay,
+	}
+}
+
+type lunarGenerator struct {
+	rule     *RRule
+	start    time.Time
+	interval int
+	period   int
+
+	startDate  lunarDate
+	months     []lunarMonthSpec
+	byMonthDay []int
+
+	// the lunar months from the month of DTSTART, for the monthly rules
+	seq     []*lunar.Month
+	seqYear int
+}
+
+type lunarMonthSpec struct {
+	month int
+	leap  bool
+}
+
+func newLunarGenerator(r *RRule, start time.Time) periodGenerator {
+	g := &lunarGenerator{
+		rule:       r,
+		start:      start,
+		interval:   r.interval(),
+		startDate:  solarToLunarDate(start),
+		byMonthDay: r.ByMonthDay,
+	}
+	for _, month := range r.ByMonth {
+		g.months = append(g.months, lunarMonthSpec{month: month})
+	}
+	for _, month := range r.ByLeapMonth {
+		g.months = append(g.months, lunarMonthSpec{month: month, leap: true})
+	}
+
+	// the missing rule parts are taken from DTSTART
+	if len(g.byMonthDay) == 0 {
+		g.byMonthDay = []int{g.startDate.day}
+	}
+	if r.Freq == Yearly && len(g.months) == 0 {
+		g.months = []lunarMonthSpec{{month: g.startDate.month, leap: g.startDate.leap}}
+	}
+	if r.Freq == Monthly {
+		g.seqYear = g.startDate.year
+		for _, m := range lunar.GetYearMonths(g.seqYear) {
+			if !m.ShuoTime.Before(civilDate(start).AddDate(0, 0, 1-g.startDate.day)) {
+				g.seq = append(g.seq, m)
+			}
+		}
+	}
+	return g.next
+}
+
+func (g *lunarGenerator) next() ([]time.Time, bool) {
+	k := g.period * g.interval
+	g.period++
+
+	var days []time.Time
+	if g.rule.Freq == Yearly {
+		year := g.startDate.year + k
+		if year > maxYear {
+			return nil, false
+		}
+		months := lunar.GetYearMonths(year)
+		for _, spec := range g.months {
+			i := findLunarMonth(months, spec)
+			if i == -1 {
+				// the leap month does not exist in this year
+				if !spec.leap {
+					continue
+				}
+				switch g.rule.skip() {
+				case SkipBackward:
+					i = findLunarMonth(months, lunarMonthSpec{month: spec.month})
+				case SkipForward:
+					i = findLunarMonth(months, lunarMonthSpec{month: spec.month})
+					if i != -1 {
+						i++
+					}
+				}
+				if i == -1 {
+					continue
+				}
+			}
+			days = append(days, g.monthDays(months, i)...)
+		}
+	} else {
+		for len(g.seq) <= k {
+			g.seqYear++
+			if g.seqYear > maxYear {
+				return nil, false
+			}
+			g.seq = append(g.seq, lunar.GetYearMonths(g.seqYear)...)
+		}
+		m := g.seq[k]
+		if g.monthMatches(m) {
+			days = g.monthDays(g.seq, k)
+		}
+	}
+
+	var result []time.Time
+	for _, d := range days {
+		result = append(result, localTime(d.Year(), d.Month(), d.Day(),
+			g.start.Hour(), g.start.Minute(), g.start.Second(), g.start.Location()))
+	}
+	return sortTimes(result), true
+}
+
+func findLunarMonth(months []*lunar.Month, spec lunarMonthSpec) int {
+	for i, m := range months {
+		if m.Name == spec.month && m.IsLeap == spec.leap {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *lunarGenerator) monthMatches(m *lunar.Month) bool {
+	if len(g.months) == 0 {
+		return true
+	}
+	for _, spec := range g.months {
+		if m.Name == spec.month && m.IsLeap == spec.leap {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays returns the Gregorian dates of BYMONTHDAY in the month
+// months[i], the days beyond the month length follow SKIP.
+func (g *lunarGenerator) monthDays(months []*lunar.Month, i int) []time.Time {
+	if i >= len(months) {
+		// the month after the last month of a year
+		year := months[len(months)-1].LunarYear + 1
+		next := lunar.GetYearMonths(year)
+		if len(next) == 0 {
+			return nil
+		}
+		months, i = next, 0
+	}
+	m := months[i]
+	first := civilDate(m.ShuoTime)
+	var result []time.Time
+	for _, day := range g.byMonthDay {
+		if day < 0 {
+			day = m.Days + day + 1
+			if day < 1 {
+				continue
+			}
+		}
+		if day > m.Days {
+			switch g.rule.skip() {
+			case SkipBackward:
+				day = m.Days
+			case SkipForward:
+				// the first day of the next month
+				day = m.Days + 1
+			default:
+				continue
+			}
+		}
+		result = append(result, first.AddDate(0, 0, day-1))
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolarToLunarDate(t *testing.T) {
+	assert.Equal(t, lunarDate{year: 2022, month: 12, day: 30}, solarToLunarDate(time.Date(2023, 1, 21, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, lunarDate{year: 2023, month: 2, leap: true, day: 1}, solarToLunarDate(time.Date(2023, 3, 22, 0, 0, 0, 0, time.UTC)))
+	// the leap 11th month of 2033 begins after the winter solstice
+	assert.Equal(t, lunarDate{year: 2033, month: 11, leap: true, day: 1}, solarToLunarDate(time.Date(2033, 12, 22, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, lunarDate{year: 2033, month: 12, day: 1}, solarToLunarDate(time.Date(2034, 1, 20, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestLunarRRule(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	const layout = "2006-01-02"
+
+	tests := []struct {
+		rule  string
+		start time.Time
+		want  []string
+	}{
+		// the Mid-Autumn Festival, 8/15
+		{"RSCALE=CHINESE;FREQ=YEARLY;COUNT=4", time.Date(2022, 9, 10, 8, 0, 0, 0, shanghai), []string{
+			"2022-09-10", "2023-09-29", "2024-09-17", "2025-10-06"}},
+		{"RSCALE=CHINESE;FREQ=YEARLY;BYMONTH=1;BYMONTHDAY=1;COUNT=3", time.Date(2022, 2, 1, 0, 0, 0, 0, shanghai), []string{
+			"2022-02-01", "2023-01-22", "2024-02-10"}},
+		// the last day of the 12th month, the Chinese New Year's Eve
+		{"RSCALE=CHINESE;FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=-1;COUNT=3", time.Date(2022, 1, 31, 0, 0, 0, 0, shanghai), []string{
+			"2022-01-31", "2023-01-21", "2024-02-09"}},
+		// born on the first day of the leap second month of 2023
+		{"RSCALE=CHINESE;FREQ=YEARLY;COUNT=3", time.Date(2023, 3, 22, 0, 0, 0, 0, shanghai), []string{
+			"2023-03-22", "2042-03-22", "2099-03-22"}},
+		{"RSCALE=CHINESE;FREQ=YEARLY;SKIP=BACKWARD;COUNT=3", time.Date(2023, 3, 22, 0, 0, 0, 0, shanghai), []string{
+			"2023-03-22", "2024-03-10", "2025-02-28"}},
+		{"RSCALE=CHINESE;FREQ=YEARLY;SKIP=FORWARD;COUNT=3", time.Date(2023, 3, 22, 0, 0, 0, 0, shanghai), []string{
+			"2023-03-22", "2024-04-09", "2025-03-29"}},
+		// 8/30 is omitted in the years whose 8th month has 29 days
+		{"RSCALE=CHINESE;FREQ=YEARLY;BYMONTH=8;BYMONTHDAY=30;COUNT=4", time.Date(2022, 9, 25, 0, 0, 0, 0, shanghai), []string{
+			"2022-09-25", "2023-10-14", "2024-10-02", "2029-10-07"}},
+		{"RSCALE=CHINESE;FREQ=MONTHLY;COUNT=4", time.Date(2023, 1, 22, 0, 0, 0, 0, shanghai), []string{
+			"2023-01-22", "2023-02-20", "2023-03-22", "2023-04-20"}},
+		// the leap month counts in the interval
+		{"RSCALE=CHINESE;FREQ=MONTHLY;BYMONTHDAY=15;INTERVAL=6;COUNT=3", time.Date(2023, 2, 5, 0, 0, 0, 0, shanghai), []string{
+			"2023-02-05", "2023-08-01", "2024-01-25"}},
+	}
+	for _, test := range tests {
+		r, err := ParseRRule(test.rule)
+		require.NoError(t, err, test.rule)
+		it, err := r.Iterator(test.start)
+		require.NoError(t, err)
+		got := takeN(t, it, len(test.want)+1)
+		assert.Equal(t, test.want, formatTimes(got, layout), test.rule)
+		for _, tm := range got {
+			assert.Equal(t, test.start.Hour(), tm.Hour())
+		}
+	}
+}
+
+func TestLunarBirthday(t *testing.T) {
+	cal, err := LoadFromFile("testdata/events.ics")
+	require.NoError(t, err)
+	birthday := cal.Events[2]
+
+	occurrences, err := birthday.Occurrences(time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2031, 1, 1, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, occurrences, 1)
+	assert.Equal(t, "2030-09-12", occurrences[0].Start.Format("2006-01-02"))
+	assert.Equal(t, 24*time.Hour, occurrences[0].End.Sub(occurrences[0].Start))
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"time"
+)
+
+// Recurrence holds the recurrence properties shared by events and to-dos.
+type Recurrence struct {
+	RRules  []*RRule
+	RDates  []DateTime
+	ExDates []DateTime
+}
+
+// IsRecurring reports whether there is any recurrence property.
+func (r *Recurrence) IsRecurring() bool {
+	return len(r.RRules) > 0 || len(r.RDates) > 0
+}
+
+// RecurrenceSet is the set of occurrences made of DTSTART, the RRULE and
+// RDATE properties, without the EXDATE properties.
+type RecurrenceSet struct {
+	Start   time.Time
+	RRules  []*RRule
+	RDates  []time.Time
+	ExDates []DateTime
+}
+
+// NewRecurrenceSet returns the recurrence set of the component starting at
+// start.
+func NewRecurrenceSet(start DateTime, r *Recurrence) *RecurrenceSet {
+	s := &RecurrenceSet{
+		Start:   start.Time,
+		RRules:  r.RRules,
+		ExDates: r.ExDates,
+	}
+	for _, dt := range r.RDates {
+		t := dt.Time
+		if dt.AllDay && !start.AllDay {
+			// a date means the time of day of DTSTART
+			t = time.Date(t.Year(), t.Month(), t.Day(),
+				start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		}
+		s.RDates = append(s.RDates, t)
+	}
+	s.RDates = sortTimes(s.RDates)
+	return s
+}
+
+// Iterator returns the occurrences in ascending order.
+func (s *RecurrenceSet) Iterator() (Iterator, error) {
+	it := &setIterator{
+		exTimes: make(map[int64]bool),
+		exDates: make(map[time.Time]bool),
+	}
+	for _, ex := range s.ExDates {
+		if ex.AllDay {
+			it.exDates[civilDate(ex.Time)] = true
+		} else {
+			it.exTimes[ex.UnixNano()] = true
+		}
+	}
+
+	if len(s.RRules) == 0 {
+		it.sources = append(it.sources, &sliceIterator{list: []time.Time{s.Start}})
+	}
+	for _, r := range s.RRules {
+		ruleIt, err := r.Iterator(s.Start)
+		if err != nil {
+			return nil, err
+		}
+		it.sources = append(it.sources, ruleIt)
+	}
+	it.sources = append(it.sources, &sliceIterator{list: s.RDates})
+	it.heads = make([]*time.Time, len(it.sources))
+	return it, nil
+}
+
+// Between returns the occurrences in [from, to).
+func (s *RecurrenceSet) Between(from, to time.Time) ([]time.Time, error) {
+	it, err := s.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	return collectBetween(it, from, to), nil
+}
+
+// All returns at most limit occurrences.
+func (s *RecurrenceSet) All(limit int) ([]time.Time, error) {
+	it, err := s.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	var result []time.Time
+	for len(result) < limit {
+		t, ok := it.Next()
+		if !ok {
+			break
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+type sliceIterator struct {
+	list []time.Time
+}
+
+func (it *sliceIterator) Next() (time.Time, bool) {
+	if len(it.list) == 0 {
+		return time.Time{}, false
+	}
+	t := it.list[0]
+	it.list = it.list[1:]
+	return t, true
+}
+
+// setIterator merges the sorted sources and drops the excluded and the
+// duplicated occurrences.
+type setIterator struct {
+	sources []Iterator
+	heads   []*time.Time
+	exTimes map[int64]bool
+	exDates map[time.Time]bool
+	last    *time.Time
+}
+
+func (it *setIterator) Next() (time.Time, bool) {
+	for {
+		min := -1
+		for i, src := range it.sources {
+			if it.heads[i] == nil {
+				t, ok := src.Next()
+				if !ok {
+					continue
+				}
+				it.heads[i] = &t
+			}
+			if min == -1 || it.heads[i].Before(*it.heads[min]) {
+				min = i
+			}
+		}
+		if min == -1 {
+			return time.Time{}, false
+		}
+		t := *it.heads[min]
+		it.heads[min] = nil
+		if it.last != nil && t.Equal(*it.last) {
+			continue
+		}
+		it.last = &t
+		if it.exTimes[t.UnixNano()] || it.exDates[civilDate(t)] {
+			continue
+		}
+		return t, true
+	}
+}
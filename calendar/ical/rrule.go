@@ -0,0 +1,446 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Secondly Frequency = iota
+	Minutely
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = []string{"SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+func (f Frequency) String() string {
+	if f < Secondly || f > Yearly {
+		return "Frequency(" + strconv.Itoa(int(f)) + ")"
+	}
+	return frequencyNames[f]
+}
+
+const (
+	// RScaleGregorian is the default calendar scale of recurrence rules.
+	RScaleGregorian = "GREGORIAN"
+	// RScaleChinese makes BYMONTH and BYMONTHDAY refer to the Chinese lunar
+	// calendar, a leap month is written as "5L".
+	RScaleChinese = "CHINESE"
+)
+
+// Skip tells what to do with an occurrence which does not exist in the
+// calendar scale, such as a leap month in a year without it (RFC 7529).
+const (
+	SkipOmit     = "OMIT"
+	SkipBackward = "BACKWARD"
+	SkipForward  = "FORWARD"
+)
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY value, such as "-1FR" for the last Friday. N is 0
+// for every such weekday in the period.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Weekday]
+}
+
+// RRule is a recurrence rule.
+type RRule struct {
+	Freq     Frequency
+	Interval int // 0 is the same as 1
+	Count    int // 0 means no limit
+	// Until is the inclusive end of the recurrence. If UntilDate is set,
+	// only the date of Until is used.
+	Until     time.Time
+	UntilDate bool
+
+	BySecond   []int
+	ByMinute   []int
+	ByHour     []int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByYearDay  []int
+	ByWeekNo   []int
+	ByMonth    []int
+	// ByLeapMonth are the leap months of BYMONTH, only for RScaleChinese.
+	ByLeapMonth []int
+	BySetPos    []int
+	// WeekStart is the first day of weeks, ParseRRule sets it to
+	// time.Monday if WKST is absent.
+	WeekStart time.Weekday
+
+	RScale string
+	Skip   string
+
+	// UNTIL without "Z" is a local time of DTSTART's time zone.
+	untilFloating bool
+}
+
+var (
+	ErrInvalidRRule     = errors.New("invalid recurrence rule")
+	ErrUnsupportedScale = errors.New("unsupported recurrence calendar scale")
+)
+
+// ParseRRule parses the value of a RRULE property, such as
+// "FREQ=WEEKLY;BYDAY=MO,WE".
+func ParseRRule(s string) (*RRule, error) {
+	r := &RRule{WeekStart: time.Monday}
+	hasFreq := false
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%v: %q", ErrInvalidRRule, part)
+		}
+		name, value := strings.ToUpper(kv[0]), kv[1]
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq, err = parseFrequency(value)
+			hasFreq = true
+		case "INTERVAL":
+			r.Interval, err = parsePositiveInt(value)
+		case "COUNT":
+			r.Count, err = parsePositiveInt(value)
+		case "UNTIL":
+			err = r.parseUntil(value)
+		case "BYSECOND":
+			r.BySecond, err = parseIntList(value, 0, 60, false)
+		case "BYMINUTE":
+			r.ByMinute, err = parseIntList(value, 0, 59, false)
+		case "BYHOUR":
+			r.ByHour, err = parseIntList(value, 0, 23, false)
+		case "BYDAY":
+			r.ByDay, err = parseWeekdayNums(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(value, 1, 31, true)
+		case "BYYEARDAY":
+			r.ByYearDay, err = parseIntList(value, 1, 366, true)
+		case "BYWEEKNO":
+			r.ByWeekNo, err = parseIntList(value, 1, 53, true)
+		case "BYMONTH":
+			r.ByMonth, r.ByLeapMonth, err = parseMonths(value)
+		case "BYSETPOS":
+			r.BySetPos, err = parseIntList(value, 1, 366, true)
+		case "WKST":
+			r.WeekStart, err = parseWeekday(value)
+		case "RSCALE":
+			r.RScale = strings.ToUpper(value)
+		case "SKIP":
+			r.Skip = strings.ToUpper(value)
+		default:
+			// ignore the unknown rule parts for compatibility
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s: %v", ErrInvalidRRule, name, err)
+		}
+	}
+	if !hasFreq {
+		return nil, fmt.Errorf("%v: missing FREQ", ErrInvalidRRule)
+	}
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func parseFrequency(s string) (Frequency, error) {
+	s = strings.ToUpper(s)
+	for i, name := range frequencyNames {
+		if name == s {
+			return Frequency(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown frequency %q", s)
+}
+
+func parsePositiveInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+// parseIntList parses a comma separated list of numbers in [min, max], or
+// in [-max, -min] if allowNeg.
+func parseIntList(s string, min, max int, allowNeg bool) ([]int, error) {
+	var result []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "+"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", v)
+		}
+		abs := n
+		if allowNeg && n < 0 {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, fmt.Errorf("%d is out of range", n)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func parseMonths(s string) (months, leapMonths []int, err error) {
+	for _, v := range strings.Split(s, ",") {
+		leap := strings.HasSuffix(strings.ToUpper(v), "L")
+		if leap {
+			v = v[:len(v)-1]
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 13 {
+			return nil, nil, fmt.Errorf("invalid month %q", v)
+		}
+		if leap {
+			leapMonths = append(leapMonths, n)
+		} else {
+			months = append(months, n)
+		}
+	}
+	return
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToUpper(s)
+	for i, name := range weekdayNames {
+		if name == s {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
+func parseWeekdayNums(s string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, v := range strings.Split(s, ",") {
+		if len(v) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", v)
+		}
+		wd, err := parseWeekday(v[len(v)-2:])
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		if len(v) > 2 {
+			n, err = strconv.Atoi(strings.TrimPrefix(v[:len(v)-2], "+"))
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid weekday %q", v)
+			}
+		}
+		result = append(result, WeekdayNum{N: n, Weekday: wd})
+	}
+	return result, nil
+}
+
+func (r *RRule) parseUntil(s string) error {
+	dt, err := parseDateTimeValue(s, "", false, nil)
+	if err != nil {
+		return err
+	}
+	r.UntilDate = dt.AllDay
+	r.untilFloating = dt.Floating && !dt.AllDay
+	r.Until = dt.Time
+	if dt.Floating {
+		// keep the wall time, it is moved to DTSTART's location later
+		r.Until = time.Date(dt.Year(), dt.Month(), dt.Day(),
+			dt.Hour(), dt.Minute(), dt.Second(), 0, time.UTC)
+	}
+	return nil
+}
+
+// Validate checks the rule parts which must not be used together.
+func (r *RRule) Validate() error {
+	if r.Freq < Secondly || r.Freq > Yearly || r.Interval < 0 || r.Count < 0 {
+		return ErrInvalidRRule
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("%v: COUNT and UNTIL must not be used together", ErrInvalidRRule)
+	}
+	switch r.RScale {
+	case "", RScaleGregorian:
+		if len(r.ByLeapMonth) > 0 {
+			return fmt.Errorf("%v: leap months need RSCALE", ErrInvalidRRule)
+		}
+	case RScaleChinese:
+		return r.validateLunar()
+	default:
+		return fmt.Errorf("%v %q", ErrUnsupportedScale, r.RScale)
+	}
+	switch r.Skip {
+	case "", SkipOmit, SkipBackward, SkipForward:
+	default:
+		return fmt.Errorf("%v: invalid SKIP %q", ErrInvalidRRule, r.Skip)
+	}
+	for _, month := range r.ByMonth {
+		if month > 12 {
+			return fmt.Errorf("%v: invalid month %d", ErrInvalidRRule, month)
+		}
+	}
+	if len(r.ByWeekNo) > 0 && r.Freq != Yearly {
+		return fmt.Errorf("%v: BYWEEKNO is only valid for YEARLY", ErrInvalidRRule)
+	}
+	if len(r.ByYearDay) > 0 && (r.Freq == Daily || r.Freq == Weekly || r.Freq == Monthly) {
+		return fmt.Errorf("%v: BYYEARDAY is not valid for %v", ErrInvalidRRule, r.Freq)
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return fmt.Errorf("%v: BYMONTHDAY is not valid for WEEKLY", ErrInvalidRRule)
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && (r.Freq != Monthly && r.Freq != Yearly || len(r.ByWeekNo) > 0) {
+			return fmt.Errorf("%v: BYDAY %v is not valid for %v", ErrInvalidRRule, wd, r.Freq)
+		}
+	}
+	return nil
+}
+
+// only the month and day based yearly and monthly rules are supported in
+// the lunar calendar.
+func (r *RRule) validateLunar() error {
+	if r.Freq != Yearly && r.Freq != Monthly {
+		return fmt.Errorf("%v: %v is not supported with RSCALE=%s", ErrInvalidRRule, r.Freq, r.RScale)
+	}
+	if len(r.BySecond) > 0 || len(r.ByMinute) > 0 || len(r.ByHour) > 0 || len(r.ByDay) > 0 ||
+		len(r.ByYearDay) > 0 || len(r.ByWeekNo) > 0 || len(r.BySetPos) > 0 {
+		return fmt.Errorf("%v: only BYMONTH and BYMONTHDAY are supported with RSCALE=%s",
+			ErrInvalidRRule, r.RScale)
+	}
+	for _, month := range append(r.ByMonth, r.ByLeapMonth...) {
+		if month > 12 {
+			return fmt.Errorf("%v: invalid month %d", ErrInvalidRRule, month)
+		}
+	}
+	for _, day := range r.ByMonthDay {
+		if day > 30 || day < -30 {
+			return fmt.Errorf("%v: invalid lunar day %d", ErrInvalidRRule, day)
+		}
+	}
+	switch r.Skip {
+	case "", SkipOmit, SkipBackward, SkipForward:
+		return nil
+	}
+	return fmt.Errorf("%v: invalid SKIP %q", ErrInvalidRRule, r.Skip)
+}
+
+func (r *RRule) interval() int {
+	if r.Interval <= 0 {
+		return 1
+	}
+	return r.Interval
+}
+
+func (r *RRule) skip() string {
+	if r.Skip == "" {
+		return SkipOmit
+	}
+	return r.Skip
+}
+
+// String returns the RRULE value.
+func (r *RRule) String() string {
+	var parts []string
+	add := func(name, value string) {
+		parts = append(parts, name+"="+value)
+	}
+	addInts := func(name string, list []int) {
+		if len(list) == 0 {
+			return
+		}
+		values := make([]string, len(list))
+		for i, n := range list {
+			values[i] = strconv.Itoa(n)
+		}
+		add(name, strings.Join(values, ","))
+	}
+
+	if r.RScale != "" {
+		add("RSCALE", r.RScale)
+	}
+	add("FREQ", r.Freq.String())
+	if r.Interval > 1 {
+		add("INTERVAL", strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		add("COUNT", strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		switch {
+		case r.UntilDate:
+			add("UNTIL", r.Until.Format(dateLayout))
+		case r.untilFloating:
+			add("UNTIL", r.Until.Format(dateTimeLayout))
+		default:
+			add("UNTIL", r.Until.UTC().Format(utcDateTimeLayout))
+		}
+	}
+	addInts("BYSECOND", r.BySecond)
+	addInts("BYMINUTE", r.ByMinute)
+	addInts("BYHOUR", r.ByHour)
+	if len(r.ByDay) > 0 {
+		values := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			values[i] = wd.String()
+		}
+		add("BYDAY", strings.Join(values, ","))
+	}
+	addInts("BYMONTHDAY", r.ByMonthDay)
+	addInts("BYYEARDAY", r.ByYearDay)
+	addInts("BYWEEKNO", r.ByWeekNo)
+	if len(r.ByMonth) > 0 || len(r.ByLeapMonth) > 0 {
+		var values []string
+		for _, n := range r.ByMonth {
+			values = append(values, strconv.Itoa(n))
+		}
+		for _, n := range r.ByLeapMonth {
+			values = append(values, strconv.Itoa(n)+"L")
+		}
+		add("BYMONTH", strings.Join(values, ","))
+	}
+	addInts("BYSETPOS", r.BySetPos)
+	if r.WeekStart != time.Monday {
+		add("WKST", weekdayNames[r.WeekStart])
+	}
+	if r.Skip != "" {
+		add("SKIP", r.Skip)
+	}
+	return strings.Join(parts, ";")
+}
+
+// until returns the inclusive end of the rule for a DTSTART in loc.
+func (r *RRule) until(loc *time.Location) time.Time {
+	u := r.Until
+	switch {
+	case u.IsZero():
+		return u
+	case r.UntilDate:
+		return time.Date(u.Year(), u.Month(), u.Day(), 23, 59, 59, 999999999, loc)
+	case r.untilFloating:
+		return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+	}
+	return u
+}
@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func formatTimes(list []time.Time, layout string) []string {
+	var result []string
+	for _, t := range list {
+		result = append(result, t.Format(layout))
+	}
+	return result
+}
+
+func takeN(t *testing.T, it Iterator, n int) []time.Time {
+	var result []time.Time
+	for len(result) < n {
+		next, ok := it.Next()
+		if !ok {
+			break
+		}
+		result = append(result, next)
+	}
+	return result
+}
+
+func TestParseRRule(t *testing.T) {
+	r, err := ParseRRule("FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU;WKST=SU")
+	require.NoError(t, err)
+	assert.Equal(t, Monthly, r.Freq)
+	assert.Equal(t, 2, r.Interval)
+	assert.Equal(t, 10, r.Count)
+	assert.Equal(t, []WeekdayNum{{1, time.Sunday}, {-1, time.Sunday}}, r.ByDay)
+	assert.Equal(t, time.Sunday, r.WeekStart)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU;WKST=SU", r.String())
+
+	r, err = ParseRRule("FREQ=DAILY;UNTIL=19971224T000000Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1997, 12, 24, 0, 0, 0, 0, time.UTC), r.Until)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=19971224T000000Z", r.String())
+
+	r, err = ParseRRule("RSCALE=CHINESE;FREQ=YEARLY;BYMONTH=5L;BYMONTHDAY=30;SKIP=BACKWARD")
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, r.ByLeapMonth)
+	assert.Equal(t, "RSCALE=CHINESE;FREQ=YEARLY;BYMONTHDAY=30;BYMONTH=5L;SKIP=BACKWARD", r.String())
+
+	for _, s := range []string{
+		"",
+		"COUNT=3",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=19971224T000000Z",
+		"FREQ=DAILY;BYHOUR=24",
+		"FREQ=MONTHLY;BYWEEKNO=1",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=DAILY;BYDAY=1MO",
+		"FREQ=YEARLY;BYMONTH=5L",
+		"RSCALE=HEBREW;FREQ=YEARLY",
+		"RSCALE=CHINESE;FREQ=DAILY",
+		"RSCALE=CHINESE;FREQ=YEARLY;BYDAY=MO",
+	} {
+		_, err = ParseRRule(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// the examples of RFC 5545 section 3.8.5.3
+func TestRRuleExamples(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	start := time.Date(1997, 9, 2, 9, 0, 0, 0, loc)
+	const layout = "2006-01-02 15:04"
+
+	tests := []struct {
+		rule  string
+		start time.Time
+		want  []string
+	}{
+		{"FREQ=DAILY;COUNT=3", start, []string{
+			"1997-09-02 09:00", "1997-09-03 09:00", "1997-09-04 09:00"}},
+		{"FREQ=DAILY;UNTIL=19970905T000000Z", start, []string{
+			"1997-09-02 09:00", "1997-09-03 09:00", "1997-09-04 09:00"}},
+		{"FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=TU,TH", start, []string{
+			"1997-09-02 09:00", "1997-09-04 09:00", "1997-09-16 09:00", "1997-09-18 09:00"}},
+		{"FREQ=MONTHLY;BYDAY=1FR", time.Date(1997, 9, 5, 9, 0, 0, 0, loc), []string{
+			"1997-09-05 09:00", "1997-10-03 09:00", "1997-11-07 09:00"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-3", time.Date(1997, 9, 28, 9, 0, 0, 0, loc), []string{
+			"1997-09-28 09:00", "1997-10-29 09:00", "1997-11-28 09:00", "1997-12-29 09:00"}},
+		{"FREQ=YEARLY;BYDAY=20MO", time.Date(1997, 5, 19, 9, 0, 0, 0, loc), []string{
+			"1997-05-19 09:00", "1998-05-18 09:00", "1999-05-17 09:00"}},
+		{"FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", time.Date(1997, 5, 12, 9, 0, 0, 0, loc), []string{
+			"1997-05-12 09:00", "1998-05-11 09:00", "1999-05-17 09:00"}},
+		{"FREQ=YEARLY;BYMONTH=3;BYDAY=TH", time.Date(1997, 3, 13, 9, 0, 0, 0, loc), []string{
+			"1997-03-13 09:00", "1997-03-20 09:00", "1997-03-27 09:00", "1998-03-05 09:00"}},
+		{"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", time.Date(1998, 2, 13, 9, 0, 0, 0, loc), []string{
+			"1998-02-13 09:00", "1998-03-13 09:00", "1998-11-13 09:00", "1999-08-13 09:00"}},
+		{"FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", time.Date(1996, 11, 5, 9, 0, 0, 0, loc), []string{
+			"1996-11-05 09:00", "2000-11-07 09:00", "2004-11-02 09:00"}},
+		{"FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", time.Date(1997, 9, 4, 9, 0, 0, 0, loc), []string{
+			"1997-09-04 09:00", "1997-10-07 09:00", "1997-11-06 09:00"}},
+		{"FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T170000Z", start, []string{
+			"1997-09-02 09:00", "1997-09-02 12:00"}},
+		{"FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40", start, []string{
+			"1997-09-02 09:00", "1997-09-02 09:20", "1997-09-02 09:40", "1997-09-02 10:00"}},
+		{"FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16", time.Date(1997, 9, 2, 16, 20, 0, 0, loc), []string{
+			"1997-09-02 16:20", "1997-09-02 16:40", "1997-09-03 09:00", "1997-09-03 09:20"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", time.Date(2007, 1, 15, 9, 0, 0, 0, loc), []string{
+			"2007-01-15 09:00", "2007-01-30 09:00", "2007-02-15 09:00", "2007-03-15 09:00", "2007-03-30 09:00"}},
+		{"FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", time.Date(1997, 8, 5, 9, 0, 0, 0, loc), []string{
+			"1997-08-05 09:00", "1997-08-17 09:00", "1997-08-19 09:00", "1997-08-31 09:00"}},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", time.Date(2000, 2, 29, 9, 0, 0, 0, loc), []string{
+			"2000-02-29 09:00", "2004-02-29 09:00", "2008-02-29 09:00"}},
+	}
+	for _, test := range tests {
+		r, err := ParseRRule(test.rule)
+		require.NoError(t, err, test.rule)
+		it, err := r.Iterator(test.start)
+		require.NoError(t, err)
+		got := takeN(t, it, len(test.want)+1)
+		if r.Count == 0 && r.Until.IsZero() {
+			got = got[:len(test.want)]
+		}
+		assert.Equal(t, test.want, formatTimes(got, layout), test.rule)
+	}
+}
+
+func TestRRuleTimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	r, err := ParseRRule("FREQ=DAILY;COUNT=3")
+	require.NoError(t, err)
+
+	// the time of day is kept when daylight saving time ends
+	list, err := r.Between(time.Date(2022, 11, 5, 9, 0, 0, 0, loc),
+		time.Date(2022, 11, 1, 0, 0, 0, 0, loc), time.Date(2022, 12, 1, 0, 0, 0, 0, loc))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2022-11-05 09:00 EDT", "2022-11-06 09:00 EST", "2022-11-07 09:00 EST"},
+		formatTimes(list, "2006-01-02 15:04 MST"))
+	assert.Equal(t, 25*time.Hour, list[1].Sub(list[0]))
+
+	// the time skipped by daylight saving time is shifted forward by the gap
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	for _, test := range []struct {
+		start time.Time
+		want  []string
+	}{
+		{time.Date(2023, 3, 11, 2, 30, 0, 0, loc),
+			[]string{"2023-03-11 02:30 EST", "2023-03-12 03:30 EDT", "2023-03-13 02:30 EDT"}},
+		{time.Date(2023, 3, 25, 2, 30, 0, 0, berlin),
+			[]string{"2023-03-25 02:30 CET", "2023-03-26 03:30 CEST", "2023-03-27 02:30 CEST"}},
+		// the time repeated is the first one
+		{time.Date(2023, 11, 4, 1, 30, 0, 0, loc),
+			[]string{"2023-11-04 01:30 EDT", "2023-11-05 01:30 EDT", "2023-11-06 01:30 EST"}},
+	} {
+		it, err := r.Iterator(test.start)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, formatTimes(takeN(t, it, 3), "2006-01-02 15:04 MST"))
+	}
+
+	// the hourly rules count the hours in the absolute time
+	r, err = ParseRRule("FREQ=HOURLY;COUNT=3")
+	require.NoError(t, err)
+	it, err := r.Iterator(time.Date(2023, 3, 12, 1, 30, 0, 0, loc))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2023-03-12 01:30 EST", "2023-03-12 03:30 EDT", "2023-03-12 04:30 EDT"},
+		formatTimes(takeN(t, it, 3), "2006-01-02 15:04 MST"))
+
+	// floating UNTIL is a local time of DTSTART
+	r, err = ParseRRule("FREQ=DAILY;UNTIL=20221106T090000")
+	require.NoError(t, err)
+	it, err = r.Iterator(time.Date(2022, 11, 5, 9, 0, 0, 0, loc))
+	require.NoError(t, err)
+	assert.Len(t, takeN(t, it, 10), 2)
+}
+
+func TestRRuleNeverMatches(t *testing.T) {
+	start := time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC)
+	for _, rule := range []string{
+		"FREQ=HOURLY;BYMONTH=2;BYMONTHDAY=30",
+		"FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=30",
+		"FREQ=SECONDLY;BYMONTH=2;BYMONTHDAY=30",
+		"FREQ=SECONDLY;BYSECOND=60",
+		"FREQ=HOURLY;INTERVAL=2147483647;BYMONTH=2;BYMONTHDAY=30",
+	} {
+		r, err := ParseRRule(rule)
+		require.NoError(t, err, rule)
+		it, err := r.Iterator(start)
+		require.NoError(t, err)
+
+		done := make(chan []time.Time, 1)
+		go func() {
+			done <- takeN(t, it, 2)
+		}()
+		select {
+		case list := <-done:
+			// only DTSTART
+			assert.Equal(t, []time.Time{start}, list, rule)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%s: the iteration is not finished", rule)
+		}
+	}
+
+	// the periods of the hourly rule are still right after 292 years
+	r, err := ParseRRule("FREQ=HOURLY;INTERVAL=24;BYMONTH=2;BYMONTHDAY=29")
+	require.NoError(t, err)
+	list, err := r.Between(start, time.Date(2400, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2401, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2400-02-29 09:00"}, formatTimes(list, "2006-01-02 15:04"))
+}
+
+func TestRecurrenceSet(t *testing.T) {
+	cal, err := LoadFromFile("testdata/events.ics")
+	require.NoError(t, err)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	const layout = "01-02 15:04"
+
+	// RRULE on Monday and Wednesday, without the EXDATE and with the RDATE
+	list, err := cal.Events[0].RecurrenceSet().All(100)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01-03 10:00", "01-08 15:00", "01-10 10:00",
+		"01-12 10:00", "01-17 10:00", "01-19 10:00"}, formatTimes(list, layout))
+
+	// the occurrences overlapping the range
+	occurrences, err := cal.Events[0].Occurrences(time.Date(2022, 1, 10, 10, 30, 0, 0, shanghai),
+		time.Date(2022, 1, 17, 10, 0, 0, 0, shanghai))
+	require.NoError(t, err)
+	require.Len(t, occurrences, 2)
+	assert.Equal(t, "01-10 10:00", occurrences[0].Start.Format(layout))
+	assert.Equal(t, "01-10 11:00", occurrences[0].End.Format(layout))
+	assert.Equal(t, "01-12 10:00", occurrences[1].Start.Format(layout))
+
+	// the occurrence on 01-10 is moved by the event with RECURRENCE-ID
+	result, err := cal.ExpandEvents(time.Date(2022, 1, 10, 0, 0, 0, 0, shanghai),
+		time.Date(2022, 1, 13, 0, 0, 0, 0, shanghai))
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "Moved meeting", result[0].Event.Summary)
+	assert.Equal(t, "01-10 14:00", result[0].Start.Format(layout))
+	assert.Equal(t, "01-10 14:30", result[0].End.Format(layout))
+	assert.Equal(t, "01-12 10:00", result[1].Start.Format(layout))
+
+	// the last day of each month
+	list, err = cal.Todos[0].RecurrenceSet().All(10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2022-01-31", "2022-02-28", "2022-03-31"}, formatTimes(list, "2006-01-02"))
+}
+
+func TestExDateAllDay(t *testing.T) {
+	s := NewRecurrenceSet(NewDateTime(time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC)), &Recurrence{
+		RRules:  []*RRule{{Freq: Daily, Count: 4, WeekStart: time.Monday}},
+		RDates:  []DateTime{NewDate(2022, 1, 10)},
+		ExDates: []DateTime{NewDate(2022, 1, 2)},
+	})
+	list, err := s.All(10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01-01 09:00", "01-03 09:00", "01-04 09:00", "01-10 09:00"},
+		formatTimes(list, "01-02 15:04"))
+}
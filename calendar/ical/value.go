@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout        = "20060102"
+	dateTimeLayout    = "20060102T150405"
+	utcDateTimeLayout = "20060102T150405Z"
+)
+
+// DateTime is a DATE or DATE-TIME value.
+type DateTime struct {
+	time.Time
+	// AllDay is set for DATE values, which have no time of day.
+	AllDay bool
+	// Floating is set for local times not bound to any time zone, they
+	// are parsed in time.Local.
+	Floating bool
+}
+
+// NewDate returns an all day value.
+func NewDate(year int, month time.Month, day int) DateTime {
+	return DateTime{
+		Time:     time.Date(year, month, day, 0, 0, 0, 0, time.Local),
+		AllDay:   true,
+		Floating: true,
+	}
+}
+
+// NewDateTime returns a value bound to the location of t.
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t}
+}
+
+// tzResolver returns the location of a TZID parameter.
+type tzResolver func(tzid string) (*time.Location, error)
+
+func loadLocation(tzid string) (*time.Location, error) {
+	return time.LoadLocation(tzid)
+}
+
+// parseDateTime parses the value of p, which may have the VALUE and TZID
+// parameters.
+func parseDateTime(p *Property, resolve tzResolver) (DateTime, error) {
+	tzid := p.Params.Get("TZID")
+	isDate := strings.EqualFold(p.Params.Get("VALUE"), "DATE")
+	return parseDateTimeValue(p.Value, tzid, isDate, resolve)
+}
+
+func parseDateTimeValue(value, tzid string, isDate bool, resolve tzResolver) (DateTime, error) {
+	if isDate || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			return DateTime{}, fmt.Errorf("invalid date %q", value)
+		}
+		return DateTime{Time: t, AllDay: true, Floating: true}, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcDateTimeLayout, value)
+		if err != nil {
+			return DateTime{}, fmt.Errorf("invalid date-time %q", value)
+		}
+		return DateTime{Time: t}, nil
+	}
+
+	loc := time.Local
+	if tzid != "" {
+		if resolve == nil {
+			resolve = loadLocation
+		}
+		var err error
+		loc, err = resolve(tzid)
+		if err != nil {
+			return DateTime{}, err
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return DateTime{}, fmt.Errorf("invalid date-time %q", value)
+	}
+	return DateTime{Time: t, Floating: tzid == ""}, nil
+}
+
+// parseDateTimeList parses a property with comma separated values, such as
+// EXDATE. The PERIOD values of RDATE are reduced to their start.
+func parseDateTimeList(p *Property, resolve tzResolver) ([]DateTime, error) {
+	tzid := p.Params.Get("TZID")
+	valueType := strings.ToUpper(p.Params.Get("VALUE"))
+	var result []DateTime
+	for _, v := range strings.Split(p.Value, ",") {
+		if valueType == "PERIOD" {
+			v = strings.SplitN(v, "/", 2)[0]
+		}
+		dt, err := parseDateTimeValue(v, tzid, valueType == "DATE", resolve)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dt)
+	}
+	return result, nil
+}
+
+// newDateTimeProperty returns the property name with the value of dt.
+func newDateTimeProperty(name string, dt DateTime) *Property {
+	p := NewProperty(name, "")
+	p.Value = formatDateTime(dt, p.Params)
+	return p
+}
+
+func formatDateTime(dt DateTime, params Params) string {
+	if dt.AllDay {
+		params.Set("VALUE", "DATE")
+		return dt.Format(dateLayout)
+	}
+	if dt.Floating {
+		return dt.Format(dateTimeLayout)
+	}
+	loc := dt.Location()
+	if loc == time.UTC || loc == time.Local || loc.String() == "" {
+		return dt.UTC().Format(utcDateTimeLayout)
+	}
+	params.Set("TZID", loc.String())
+	return dt.Format(dateTimeLayout)
+}
+
+// ParseDuration parses a DURATION value, such as "PT1H30M" or "-P1W". A day
+// is 24 hours.
+func ParseDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q", s)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, invalid
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime {
+				return 0, invalid
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, invalid
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, invalid
+		}
+		var unit time.Duration
+		switch {
+		case s[i] == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case s[i] == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case s[i] == 'H' && inTime:
+			unit = time.Hour
+		case s[i] == 'M' && inTime:
+			unit = time.Minute
+		case s[i] == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+		d += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+	return sign * d, nil
+}
+
+// FormatDuration returns the DURATION value of d, which is rounded to seconds.
+func FormatDuration(d time.Duration) string {
+	var sb strings.Builder
+	if d < 0 {
+		sb.WriteByte('-')
+		d = -d
+	}
+	sb.WriteByte('P')
+	secs := int64(d / time.Second)
+	days := secs / 86400
+	secs %= 86400
+	if days > 0 {
+		if days%7 == 0 && secs == 0 {
+			return sb.String() + strconv.FormatInt(days/7, 10) + "W"
+		}
+		sb.WriteString(strconv.FormatInt(days, 10) + "D")
+	}
+	if secs > 0 || days == 0 {
+		sb.WriteByte('T')
+		if h := secs / 3600; h > 0 {
+			sb.WriteString(strconv.FormatInt(h, 10) + "H")
+		}
+		if m := secs % 3600 / 60; m > 0 {
+			sb.WriteString(strconv.FormatInt(m, 10) + "M")
+		}
+		if s := secs % 60; s > 0 || secs == 0 {
+			sb.WriteString(strconv.FormatInt(s, 10) + "S")
+		}
+	}
+	return sb.String()
+}
+
+// addDuration adds d to t, whole days are added to the date so that the
+// time of day is kept across daylight saving time changes.
+func addDuration(t time.Time, d time.Duration) time.Time {
+	if d%(24*time.Hour) == 0 {
+		return t.AddDate(0, 0, int(d/(24*time.Hour)))
+	}
+	return t.Add(d)
+}
+
+// parseUTCOffset parses an UTC-OFFSET value, such as "+0800".
+func parseUTCOffset(s string) (int, error) {
+	invalid := fmt.Errorf("invalid utc offset %q", s)
+	if len(s) != 5 && len(s) != 7 {
+		return 0, invalid
+	}
+	sign := 1
+	switch s[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, invalid
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil {
+		return 0, invalid
+	}
+	secs := 0
+	if len(s) == 7 {
+		secs = n % 100
+		n /= 100
+	}
+	return sign * (n/100*3600 + n%100*60 + secs), nil
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lunar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidLunarDate = errors.New("invalid lunar date")
+
+// NoLeapMonthError 农历年没有指定的闰月
+type NoLeapMonthError struct {
+	Year  int
+	Month int
+}
+
+func (err NoLeapMonthError) Error() string {
+	return fmt.Sprintf("lunar year %d has no leap month %d", err.Year, err.Month)
+}
+
+// DayOutOfRangeError 农历日超出了该月的天数，比如小月的三十
+type DayOutOfRangeError struct {
+	Year   int
+	Month  int
+	IsLeap bool
+	Day    int
+	Days   int
+}
+
+func (err DayOutOfRangeError) Error() string {
+	leap := ""
+	if err.IsLeap {
+		leap = "leap "
+	}
+	return fmt.Sprintf("lunar %smonth %d of year %d has only %d days, no day %d",
+		leap, err.Month, err.Year, err.Days, err.Day)
+}
+
+// MonthName 获取农历月名称，如 "闰四月"
+func (m *Month) MonthName() string {
+	monthName := monthNames[m.Name-1]
+	if m.IsLeap {
+		return "闰" + monthName + "月"
+	}
+	return monthName + "月"
+}
+
+// GetYearMonths 获取农历年的全部月份，按时间顺序排列，有闰月时共 13 个月
+// 每月的 ShuoTime 的日期即该月初一的公历日期
+func GetYearMonths(year int) []*Month {
+	// 冬至所在的十一月及其后的月份以下一年的 Calendar 为准
+	next := New(year + 1)
+	var result []*Month
+	for _, m := range New(year).Months {
+		if m.LunarYear == year && deltaDays(m.ShuoTime, next.Months[0].ShuoTime) > 0 {
+			result = append(result, m)
+		}
+	}
+	for _, m := range next.Months {
+		if m.LunarYear == year {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// GetLeapMonth 获取农历年的闰月，没有闰月则返回 0
+func GetLeapMonth(year int) int {
+	for _, m := range GetYearMonths(year) {
+		if m.IsLeap {
+			return m.Name
+		}
+	}
+	return 0
+}
+
+// GetMonth 获取农历年中指定的月份
+func GetMonth(year, month int, isLeap bool) (*Month, error) {
+	if month < 1 || month > 12 {
+		return nil, ErrInvalidLunarDate
+	}
+	for _, m := range GetYearMonths(year) {
+		if m.Name == month && m.IsLeap == isLeap {
+			return m, nil
+		}
+	}
+	if isLeap {
+		return nil, NoLeapMonthError{Year: year, Month: month}
+	}
+	return nil, ErrInvalidLunarDate
+}
+
+// ToSolarDate 农历日期转换为公历日期，返回的时间为 UTC 的零点
+func ToSolarDate(year, month, day int, isLeap bool) (time.Time, error) {
+	if day < 1 || day > 30 {
+		return time.Time{}, ErrInvalidLunarDate
+	}
+	m, err := GetMonth(year, month, isLeap)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if day > m.Days {
+		return time.Time{}, DayOutOfRangeError{
+			Year:   year,
+			Month:  month,
+			IsLeap: isLeap,
+			Day:    day,
+			Days:   m.Days,
+		}
+	}
+	y, mm, d := m.ShuoTime.Date()
+	return time.Date(y, mm, d+day-1, 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lunar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetYearMonths(t *testing.T) {
+	months := GetYearMonths(2023)
+	require.Len(t, months, 13)
+	assert.Equal(t, "2023-01-22", months[0].ShuoTime.Format("2006-01-02"))
+	assert.Equal(t, "闰二月", months[2].MonthName())
+	assert.Equal(t, "腊月", months[12].MonthName())
+	assert.Equal(t, "2024-01-11", months[12].ShuoTime.Format("2006-01-02"))
+
+	assert.Len(t, GetYearMonths(2022), 12)
+	assert.Equal(t, 2, GetLeapMonth(2023))
+	assert.Equal(t, 4, GetLeapMonth(2012))
+	assert.Equal(t, 0, GetLeapMonth(2024))
+
+	// 闰十一月在冬至之后
+	months = GetYearMonths(2033)
+	require.Len(t, months, 13)
+	assert.Equal(t, "闰冬月", months[11].MonthName())
+	assert.Equal(t, "2033-12-22", months[11].ShuoTime.Format("2006-01-02"))
+	assert.Equal(t, 2033, months[12].LunarYear)
+	assert.Equal(t, 11, GetLeapMonth(2033))
+}
+
+func TestToSolarDate(t *testing.T) {
+	tests := []struct {
+		year, month, day int
+		isLeap           bool
+		want             string
+	}{
+		{2012, 1, 1, false, "2012-01-23"},
+		{2012, 4, 1, true, "2012-05-21"},
+		{2022, 8, 15, false, "2022-09-10"},
+		{2022, 12, 30, false, "2023-01-21"},
+		{2023, 2, 1, true, "2023-03-22"},
+		{2033, 11, 1, true, "2033-12-22"},
+		{2033, 12, 1, false, "2034-01-20"},
+	}
+	for _, test := range tests {
+		dt, err := ToSolarDate(test.year, test.month, test.day, test.isLeap)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, dt.Format("2006-01-02"))
+
+		d := New(dt.Year()).SolarDayToLunarDay(int(dt.Month()), dt.Day())
+		assert.Equal(t, test.year, d.LunarMonth.LunarYear)
+		assert.Equal(t, test.month, d.LunarMonth.Name)
+		assert.Equal(t, test.isLeap, d.LunarMonth.IsLeap)
+		assert.Equal(t, test.day, d.LunarDay)
+	}
+
+	_, err := ToSolarDate(2022, 2, 1, true)
+	assert.Equal(t, NoLeapMonthError{Year: 2022, Month: 2}, err)
+	// 2023 年闰二月只有 29 天
+	_, err = ToSolarDate(2023, 2, 30, true)
+	assert.Equal(t, DayOutOfRangeError{Year: 2023, Month: 2, IsLeap: true, Day: 30, Days: 29}, err)
+	_, err = ToSolarDate(2022, 13, 1, false)
+	assert.Equal(t, ErrInvalidLunarDate, err)
+	_, err = ToSolarDate(2022, 1, 31, false)
+	assert.Equal(t, ErrInvalidLunarDate, err)
+}
+
+func TestToSolarDateRoundTrip(t *testing.T) {
+	for dt := time.Date(2033, 1, 1, 0, 0, 0, 0, time.UTC); dt.Year() < 2035; dt = dt.AddDate(0, 0, 1) {
+		d := New(dt.Year()).SolarDayToLunarDay(int(dt.Month()), dt.Day())
+		got, err := ToSolarDate(d.LunarMonth.LunarYear, d.LunarMonth.Name, d.LunarDay, d.LunarMonth.IsLeap)
+		require.NoError(t, err)
+		assert.Equal(t, dt, got)
+	}
+}
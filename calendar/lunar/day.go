@@ -21,11 +21,7 @@ var monthNames = []string{"正", "二", "三", "四", "五", "六", "七", "八"
 
 // MonthName 获取当天的农历月名称
 func (d *Day) MonthName() string {
-	monthName := monthNames[d.LunarMonth.Name-1]
-	if d.LunarMonth.IsLeap {
-		return "闰" + monthName + "月"
-	}
-	return monthName + "月"
+	return d.LunarMonth.MonthName()
 }
 
 // 农历日名
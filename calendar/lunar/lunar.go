@@ -6,6 +6,7 @@ package lunar
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/linuxdeepin/go-lib/calendar/util"
@@ -45,10 +46,15 @@ func newCalendar(year int) *Calendar {
 	return cc
 }
 
-var ccCache = make(map[int]*Calendar)
+var (
+	ccCache   = make(map[int]*Calendar)
+	ccCacheMu sync.Mutex
+)
 
 // New 从缓存获取 Calendar 对象，没有则先创建
 func New(year int) *Calendar {
+	ccCacheMu.Lock()
+	defer ccCacheMu.Unlock()
 	if cc, ok := ccCache[year]; ok {
 		return cc
 	} else {
@@ -74,8 +80,9 @@ func (cc *Calendar) calcProcData() {
 	}
 	cc.solarTermYearDays = solarTermYearDays
 
+	// 以冬至所在的农历月为第一个月，朔日与冬至同一天时冬至也在该月内
 	tmpNewMoonJD := getNewMoonJD(util.JDBeijingTime2UTC(cc.SolarTermJDs[0]))
-	if tmpNewMoonJD > cc.SolarTermJDs[0] {
+	if int(util.JDUTC2BeijingTime(tmpNewMoonJD)+0.5) > int(cc.SolarTermJDs[0]+0.5) {
 		tmpNewMoonJD -= 29.53
 	}
 	cc.NewMoonJDs = get15NewMoonJDs(tmpNewMoonJD)
@@ -166,6 +173,11 @@ func (cc *Calendar) calcLeapMonth() {
 			// 对后面的农历月调整月名
 			for i < 14 {
 				cc.Months[i].Name--
+				if cc.Months[i].Name == 0 {
+					// 闰十一月或闰十二月之后的月份仍属于上一农历年
+					cc.Months[i].Name = 12
+					cc.Months[i].LunarYear--
+				}
 				i++
 			}
 		}
@@ -191,7 +203,14 @@ func (cc *Calendar) SolarDayToLunarDay(month, day int) *Day {
 	var lunarYear int
 	var lunarMonth *Month
 	var lunarDay int
-	for _, m := range cc.Months {
+	months := cc.Months
+	if month >= 11 {
+		// 冬至之后的闰月只有下一年的 Calendar 才能算出
+		if next := New(cc.Year + 1); deltaDays(next.Months[0].ShuoTime, dt) >= 0 {
+			months = next.Months
+		}
+	}
+	for _, m := range months {
 		dd := deltaDays(m.ShuoTime, dt) + 1
 		if 1 <= dd && dd <= m.Days {
 			lunarYear = m.LunarYear
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lunar
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/linuxdeepin/go-lib/calendar/util"
+)
+
+// 朔望月的平均长度，单位为天
+const synodicMonth = 29.530588
+
+// SolarTerm 节气及其交节时刻
+type SolarTerm struct {
+	Order int // 节气序号，0 为春分
+	Name  string
+	Time  time.Time
+}
+
+// GetSolarTerms 获取 [start, end) 之间的全部节气，按时间排列，
+// 交节时刻使用 loc 时区，loc 为 nil 时使用 UTC
+func GetSolarTerms(start, end time.Time, loc *time.Location) []SolarTerm {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var result []SolarTerm
+	// GetSolarTermJD 的 year 年包括从小寒到冬至的节气
+	for year := start.UTC().Year(); year <= end.UTC().Year(); year++ {
+		for order := 0; order < 24; order++ {
+			t := util.GetDateTimeFromJulianDay(GetSolarTermJD(year, order))
+			if t.Before(start) || !t.Before(end) {
+				continue
+			}
+			result = append(result, SolarTerm{
+				Order: order,
+				Name:  SolarTermNames[order],
+				Time:  t.In(loc),
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result
+}
+
+// MoonPhase 月相
+type MoonPhase int
+
+const (
+	NewMoon      MoonPhase = iota // 朔
+	FirstQuarter                  // 上弦
+	FullMoon                      // 望
+	LastQuarter                   // 下弦
+)
+
+var moonPhaseNames = []string{"朔", "上弦", "望", "下弦"}
+
+func (p MoonPhase) String() string {
+	if NewMoon <= p && p <= LastQuarter {
+		return moonPhaseNames[p]
+	}
+	return ""
+}
+
+// MoonPhaseTime 月相及其发生时刻
+type MoonPhaseTime struct {
+	Phase MoonPhase
+	Time  time.Time
+}
+
+// 月球与太阳的地心黄经差，范围为 [0, 2π]
+func getMoonElongation(jd float64) float64 {
+	return Mod2Pi(GetMoonEclipticLongitudeEC(jd) - GetEarthEclipticLongitudeForSun(jd))
+}
+
+// getMoonPhaseJD 计算 jd0 附近月相 phase 的时间，返回儒略日力学时间 TD
+func getMoonPhaseJD(phase MoonPhase, jd0 float64) float64 {
+	angle := float64(phase) * math.Pi / 2
+	return NewtonIteration(func(x float64) float64 {
+		return ModPi(getMoonElongation(x) - angle)
+	}, jd0)
+}
+
+// GetMoonPhases 获取 [start, end) 之间的全部朔、上弦、望、下弦，按时间排列，
+// 时刻使用 loc 时区，loc 为 nil 时使用 UTC
+func GetMoonPhases(start, end time.Time, loc *time.Location) []MoonPhaseTime {
+	if loc == nil {
+		loc = time.UTC
+	}
+	jd := util.GetJulianDayFromDateTime(start)
+	elongation := getMoonElongation(jd)
+	// start 之后的第一个月相
+	k := int(elongation/(math.Pi/2)) + 1
+	jd += (float64(k)*math.Pi/2 - elongation) / (2 * math.Pi) * synodicMonth
+
+	var result []MoonPhaseTime
+	for {
+		phase := MoonPhase(k % 4)
+		jd = getMoonPhaseJD(phase, jd)
+		t := util.GetDateTimeFromJulianDay(jd)
+		if !t.Before(end) {
+			break
+		}
+		if !t.Before(start) {
+			result = append(result, MoonPhaseTime{
+				Phase: phase,
+				Time:  t.In(loc),
+			})
+		}
+		k++
+		jd += synodicMonth / 4
+	}
+	return result
+}
+
+// GetMoonPhaseFraction 获取 t 时刻的月相在朔望月中的位置，范围为 [0, 1)，
+// 0 为朔，0.25 为上弦，0.5 为望，0.75 为下弦
+func GetMoonPhaseFraction(t time.Time) float64 {
+	fraction := getMoonElongation(util.GetJulianDayFromDateTime(t)) / (2 * math.Pi)
+	if fraction >= 1 {
+		fraction = 0
+	}
+	return fraction
+}
+
+// GetMoonIllumination 获取 t 时刻月面被照亮的比例，范围为 [0, 1]
+func GetMoonIllumination(t time.Time) float64 {
+	return (1 - math.Cos(getMoonElongation(util.GetJulianDayFromDateTime(t)))) / 2
+}
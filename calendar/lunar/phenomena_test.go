@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lunar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertTimeNear(t *testing.T, expected, actual time.Time) {
+	assert.WithinDuration(t, expected, actual, time.Minute)
+}
+
+func TestGetSolarTerms(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	terms := GetSolarTerms(time.Date(2022, 12, 23, 0, 0, 0, 0, cst),
+		time.Date(2023, 3, 21, 5, 24, 0, 0, cst), cst)
+	require.Len(t, terms, 5)
+	assert.Equal(t, XiaoHan, terms[0].Order)
+	assert.Equal(t, "小寒", terms[0].Name)
+	assertTimeNear(t, time.Date(2023, 1, 5, 23, 5, 0, 0, cst), terms[0].Time)
+	assert.Equal(t, cst, terms[0].Time.Location())
+	assert.Equal(t, JingZhe, terms[4].Order)
+
+	terms = GetSolarTerms(time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC), nil)
+	require.Len(t, terms, 1)
+	assert.Equal(t, "春分", terms[0].Name)
+	assertTimeNear(t, time.Date(2023, 3, 20, 21, 24, 0, 0, time.UTC), terms[0].Time)
+
+	// 与 Calendar 中的节气一致，Calendar 使用北京时间
+	cc := New(2012)
+	terms = GetSolarTerms(time.Date(2011, 12, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC), cst)
+	require.Len(t, terms, 26)
+	for i, st := range terms[1:] {
+		assert.Equal(t, cc.SolarTermTimes[i].Format("2006-01-02 15:04:05"), st.Time.Format("2006-01-02 15:04:05"))
+	}
+}
+
+func TestGetMoonPhases(t *testing.T) {
+	phases := GetMoonPhases(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.Len(t, phases, 4)
+	expected := []MoonPhaseTime{
+		{FullMoon, time.Date(2023, 1, 6, 23, 8, 0, 0, time.UTC)},
+		{LastQuarter, time.Date(2023, 1, 15, 2, 10, 0, 0, time.UTC)},
+		{NewMoon, time.Date(2023, 1, 21, 20, 53, 0, 0, time.UTC)},
+		{FirstQuarter, time.Date(2023, 1, 28, 15, 19, 0, 0, time.UTC)},
+	}
+	for i, p := range phases {
+		assert.Equal(t, expected[i].Phase, p.Phase)
+		assertTimeNear(t, expected[i].Time, p.Time)
+	}
+	assert.Equal(t, "望", phases[0].Phase.String())
+
+	// 与 Calendar 中的朔日一致
+	cc := New(2012)
+	loc := time.FixedZone("CST", 8*3600)
+	var newMoons []time.Time
+	for _, p := range GetMoonPhases(cc.Months[0].ShuoTime.Add(-8*time.Hour-time.Minute),
+		cc.Months[len(cc.Months)-1].ShuoTime.Add(-8*time.Hour+time.Minute), loc) {
+		if p.Phase == NewMoon {
+			newMoons = append(newMoons, p.Time)
+		}
+	}
+	require.Len(t, newMoons, len(cc.Months))
+	for i, m := range cc.Months {
+		assert.Equal(t, m.ShuoTime.Format("2006-01-02 15:04"), newMoons[i].Format("2006-01-02 15:04"))
+	}
+}
+
+func TestGetMoonPhaseFraction(t *testing.T) {
+	fullMoon := time.Date(2023, 1, 6, 23, 8, 0, 0, time.UTC)
+	assert.InDelta(t, 0.5, GetMoonPhaseFraction(fullMoon), 0.001)
+	assert.InDelta(t, 1, GetMoonIllumination(fullMoon), 0.001)
+
+	newMoon := time.Date(2023, 1, 21, 20, 53, 0, 0, time.UTC)
+	assert.InDelta(t, 0, GetMoonIllumination(newMoon), 0.001)
+	assert.InDelta(t, 0.25, GetMoonPhaseFraction(time.Date(2023, 1, 28, 15, 19, 0, 0, time.UTC)), 0.001)
+	assert.InDelta(t, 0.75, GetMoonPhaseFraction(time.Date(2023, 1, 15, 2, 10, 0, 0, time.UTC)), 0.001)
+}
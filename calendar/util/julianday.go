@@ -80,3 +80,13 @@ func GetDateTimeFromJulianDay(jd float64) time.Time {
 	h, m, s := GetTimeFromJulianDay(jd)
 	return time.Date(yy, time.Month(mm), dd, h, m, s, 0, time.UTC)
 }
+
+// GetJulianDayFromDateTime 将 time.Time 转换为儒略日，与 GetDateTimeFromJulianDay 相反
+// 其中包含了 UTC 到 TT 的转换
+func GetJulianDayFromDateTime(t time.Time) float64 {
+	t = t.UTC()
+	sec := float64(t.Hour()*3600+t.Minute()*60+t.Second()) + float64(t.Nanosecond())/1e9
+	jd := ToJulianDateHMS(t.Year(), int(t.Month()), t.Day(), 0, 0, sec)
+	//  UTC -> TT
+	return jd + GetDeltaT(t.Year(), int(t.Month()))/86400
+}
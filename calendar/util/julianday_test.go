@@ -5,7 +5,9 @@
 package util
 
 import (
+	"math"
 	"testing"
+	"time"
 )
 
 func Test_GetDateFromJulianDay(t *testing.T) {
@@ -53,3 +55,14 @@ func Test_GetDateTimeFromJulianDay(t *testing.T) {
 		t.Error("fail")
 	}
 }
+
+func Test_GetJulianDayFromDateTime(t *testing.T) {
+	dt := time.Date(2016, 2, 19, 22, 29, 22, 0, time.FixedZone("CST", 8*3600))
+	jd := GetJulianDayFromDateTime(dt)
+	if math.Abs(jd-2457438.10454) > 2e-5 {
+		t.Error("fail", jd)
+	}
+	if !GetDateTimeFromJulianDay(jd + 1e-6).Equal(dt) {
+		t.Error("fail", GetDateTimeFromJulianDay(jd))
+	}
+}
@@ -12,10 +12,10 @@ import "C"
 
 import (
 	"fmt"
-	"github.com/linuxdeepin/go-lib/utils"
 )
 
-// BlurImage generate blur effect to an image file.
+// BlurImage generate blur effect to an image file, which requires GDK, see
+// graphic.BlurImage for the one in pure Go.
 func BlurImage(srcFile, dstFile string, sigma, numSteps float64, f Format) (err error) {
 	srcPixbuf, err := NewPixbufFromFile(srcFile)
 	defer FreePixbuf(srcPixbuf)
@@ -33,14 +33,10 @@ func BlurImage(srcFile, dstFile string, sigma, numSteps float64, f Format) (err
 // BlurImageCache generate and save the blurred image file to cache
 // directory, if target file already exists, just return it.
 func BlurImageCache(srcFile string, sigma, numSteps float64, f Format) (dstFile string, useCache bool, err error) {
-	dstFile = generateCacheFilePath(fmt.Sprintf("BlurImageCache%s%f%f%s", srcFile, sigma, numSteps, f))
-	if utils.IsFileExist(dstFile) {
-		// return cache file
-		useCache = true
-		return
-	}
-	err = BlurImage(srcFile, dstFile, sigma, numSteps, f)
-	return
+	params := fmt.Sprintf("BlurImageCache%f,%f,%s", sigma, numSteps, f)
+	return getCacheFile(srcFile, params, f, func(dstFile string) error {
+		return BlurImage(srcFile, dstFile, sigma, numSteps, f)
+	})
 }
 
 // Blur generate blur effect to pixbuf object.
@@ -17,7 +17,6 @@ import (
 	"unsafe"
 
 	x "github.com/linuxdeepin/go-x11-client"
-	"github.com/linuxdeepin/go-lib/utils"
 )
 
 // Format defines the type of image format.
@@ -343,25 +342,17 @@ func ThumbnailImage(srcFile, destFile string, maxWidth, maxHeight int, interpTyp
 }
 
 func ScaleImageCache(srcFile string, newWidth, newHeght int, interpType GdkInterpType, f Format) (destFile string, useCache bool, err error) {
-	destFile = generateCacheFilePath(fmt.Sprintf("ScaleImageCache%s%d%d%d%s", srcFile, newWidth, newHeght, interpType, f))
-	if utils.IsFileExist(destFile) {
-		// return cache file
-		useCache = true
-		return
-	}
-	err = ScaleImage(srcFile, destFile, newWidth, newHeght, interpType, f)
-	return
+	params := fmt.Sprintf("ScaleImageCache%d,%d,%d,%s", newWidth, newHeght, interpType, f)
+	return getCacheFile(srcFile, params, f, func(destFile string) error {
+		return ScaleImage(srcFile, destFile, newWidth, newHeght, interpType, f)
+	})
 }
 
 func ScaleImagePreferCache(srcFile string, newWidth, newHeght int, interpType GdkInterpType, f Format) (destFile string, useCache bool, err error) {
-	destFile = generateCacheFilePath(fmt.Sprintf("ScaleImageCache%s%d%d%d%s", srcFile, newWidth, newHeght, interpType, f))
-	if utils.IsFileExist(destFile) {
-		// return cache file
-		useCache = true
-		return
-	}
-	err = ScaleImagePrefer(srcFile, destFile, newWidth, newHeght, interpType, f)
-	return
+	params := fmt.Sprintf("ScaleImagePreferCache%d,%d,%d,%s", newWidth, newHeght, interpType, f)
+	return getCacheFile(srcFile, params, f, func(destFile string) error {
+		return ScaleImagePrefer(srcFile, destFile, newWidth, newHeght, interpType, f)
+	})
 }
 
 func ScaleSimple(srcPixbuf *C.GdkPixbuf, newWidth, newHeght int, interpType GdkInterpType) (destPixbuf *C.GdkPixbuf, err error) {
@@ -6,15 +6,17 @@ package gdkpixbuf
 
 import (
 	"github.com/linuxdeepin/go-lib/graphic"
-	"github.com/linuxdeepin/go-lib/utils"
 )
 
-// function links to lib/graphic
-
-func generateCacheFilePath(keyword string) string {
-	return utils.GenerateCacheFilePathWithPrefix("graphic", keyword)
+// getCacheFile returns the cached image generated from srcFile by the
+// operation with the params, create is called to generate it if it is
+// not cached or srcFile is changed.
+func getCacheFile(srcFile, params string, f Format, create func(dstFile string) error) (dstFile string, useCache bool, err error) {
+	return graphic.ImageCache.Derived(srcFile, params, string(f), create)
 }
 
+// function links to lib/graphic
+
 // Rgb2Hsv convert color format from RGB(r, g, b=[0..255]) to HSV(h=[0..360), s,v=[0..1]).
 func Rgb2Hsv(r, g, b uint8) (h, s, v float64) {
 	return graphic.Rgb2Hsv(r, g, b)
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// gettext-extract scans Go source files for the calls of the gettext
+// functions and writes a .pot template.
+//
+// Usage:
+//
+//	gettext-extract [-o messages.pot] [-k NAME:1c,2] FILE_OR_DIR...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/linuxdeepin/go-lib/gettext/extract"
+)
+
+type keywordsFlag []string
+
+func (k *keywordsFlag) String() string {
+	return strings.Join(*k, " ")
+}
+
+func (k *keywordsFlag) Set(value string) error {
+	*k = append(*k, value)
+	return nil
+}
+
+var (
+	optOutput         = flag.String("o", "messages.pot", "the output file, - for the standard output")
+	optPackageName    = flag.String("package-name", "", "the package name in the header")
+	optPackageVersion = flag.String("package-version", "", "the package version in the header")
+	optBugsAddress    = flag.String("msgid-bugs-address", "", "the address to report the msgid bugs")
+	optKeywords       keywordsFlag
+)
+
+func main() {
+	flag.Var(&optKeywords, "k", "an additional keyword in the xgettext syntax, such as MyTr:1c,2")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [options] FILE_OR_DIR...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	e := extract.NewExtractor()
+	e.PackageName = *optPackageName
+	e.PackageVersion = *optPackageVersion
+	e.BugsAddress = *optBugsAddress
+	for _, s := range optKeywords {
+		kw, err := extract.ParseKeyword(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		e.Keywords = append(e.Keywords, kw)
+	}
+
+	for _, arg := range flag.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if info.IsDir() {
+			err = e.ParseDir(arg)
+		} else {
+			err = e.ParseFile(arg, nil)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	out := os.Stdout
+	if *optOutput != "-" {
+		f, err := os.Create(*optOutput)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out = f
+	}
+	err := e.WritePot(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = out.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
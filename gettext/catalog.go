@@ -0,0 +1,419 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gettext
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// contextSeparator separates the message context and the msgid in the
+// keys of a .mo file.
+const contextSeparator = "\x04"
+
+var ErrInvalidMo = errors.New("invalid .mo file")
+
+// PoSyntaxError is returned when a .po file can not be parsed.
+type PoSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (err PoSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Msg)
+}
+
+// Catalog holds the translated messages of one language and one domain,
+// it is loaded from a .mo or .po file and safe for concurrent use.
+type Catalog struct {
+	// Header are the fields of the header entry, such as "Language".
+	Header   map[string]string
+	nplurals int
+	plural   pluralExpr
+	messages map[string][]string
+}
+
+func newCatalog() *Catalog {
+	return &Catalog{
+		Header:   make(map[string]string),
+		nplurals: 2,
+		plural:   germanicPlural,
+		messages: make(map[string][]string),
+	}
+}
+
+func messageKey(context, msgid string) string {
+	if context == "" {
+		return msgid
+	}
+	return context + contextSeparator + msgid
+}
+
+// Language returns the value of the Language header.
+func (c *Catalog) Language() string {
+	return c.Header["Language"]
+}
+
+// Len returns the number of messages, not including the header entry.
+func (c *Catalog) Len() int {
+	return len(c.messages)
+}
+
+func (c *Catalog) lookup(context, msgid string) ([]string, bool) {
+	strs, ok := c.messages[messageKey(context, msgid)]
+	return strs, ok
+}
+
+// pluralIndex returns the index of the plural form for n.
+func (c *Catalog) pluralIndex(n int) int {
+	if n < 0 {
+		n = -n
+	}
+	idx := int(c.plural(uint64(n)))
+	if idx < 0 || idx >= c.nplurals {
+		return 0
+	}
+	return idx
+}
+
+// PGettext returns the translation of msgid in the context, context may be
+// empty. The second result reports whether the message is translated.
+func (c *Catalog) PGettext(context, msgid string) (string, bool) {
+	strs, ok := c.lookup(context, msgid)
+	if !ok || strs[0] == "" {
+		return msgid, false
+	}
+	return strs[0], true
+}
+
+// NPGettext returns the plural translation of msgid for n in the context.
+// The second result reports whether the message is translated.
+func (c *Catalog) NPGettext(context, msgid, msgidPlural string, n int) (string, bool) {
+	strs, ok := c.lookup(context, msgid)
+	if ok {
+		idx := c.pluralIndex(n)
+		if idx < len(strs) && strs[idx] != "" {
+			return strs[idx], true
+		}
+	}
+	if n == 1 {
+		return msgid, false
+	}
+	return msgidPlural, false
+}
+
+func (c *Catalog) parseHeader(header string) error {
+	for _, line := range strings.Split(header, "\n") {
+		idx := strings.IndexByte(line, ':')
+		if idx == -1 {
+			continue
+		}
+		c.Header[strings.TrimSpace(line[:idx])] = strings.TrimSpace(line[idx+1:])
+	}
+	if pf, ok := c.Header["Plural-Forms"]; ok {
+		nplurals, expr, err := parsePluralForms(pf)
+		if err != nil {
+			return err
+		}
+		c.nplurals = nplurals
+		c.plural = expr
+	}
+	return nil
+}
+
+// LoadMoFile loads the catalog from a .mo file compiled by msgfmt.
+func LoadMoFile(file string) (*Catalog, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMo(data)
+}
+
+// ParseMo parses the data of a .mo file, both byte orders are supported.
+// Only the UTF-8 charset is supported.
+func ParseMo(data []byte) (*Catalog, error) {
+	if len(data) < 28 {
+		return nil, ErrInvalidMo
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil, ErrInvalidMo
+	}
+	if order.Uint32(data[4:])>>16 > 1 {
+		// unknown major revision
+		return nil, ErrInvalidMo
+	}
+	count := order.Uint32(data[8:])
+	origOffset := order.Uint32(data[12:])
+	transOffset := order.Uint32(data[16:])
+
+	readString := func(tableOffset, i uint32) (string, error) {
+		pos := uint64(tableOffset) + uint64(i)*8
+		if pos+8 > uint64(len(data)) {
+			return "", ErrInvalidMo
+		}
+		length := uint64(order.Uint32(data[pos:]))
+		offset := uint64(order.Uint32(data[pos+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", ErrInvalidMo
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	c := newCatalog()
+	for i := uint32(0); i < count; i++ {
+		orig, err := readString(origOffset, i)
+		if err != nil {
+			return nil, err
+		}
+		trans, err := readString(transOffset, i)
+		if err != nil {
+			return nil, err
+		}
+		if orig == "" {
+			err = c.parseHeader(trans)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// the msgid_plural follows the msgid
+		if idx := strings.IndexByte(orig, 0); idx != -1 {
+			orig = orig[:idx]
+		}
+		c.messages[orig] = strings.Split(trans, "\x00")
+	}
+	return c, nil
+}
+
+// LoadPoFile loads the catalog from a .po file.
+func LoadPoFile(file string) (*Catalog, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParsePo(f)
+}
+
+// ParsePo parses a .po file, the untranslated, fuzzy and obsolete entries
+// are ignored as msgfmt does.
+func ParsePo(r io.Reader) (*Catalog, error) {
+	entries, err := readPoEntries(r)
+	if err != nil {
+		return nil, err
+	}
+	c := newCatalog()
+	for _, e := range entries {
+		if e.ID == "" && e.Context == "" {
+			if len(e.Strs) > 0 {
+				err = c.parseHeader(e.Strs[0])
+				// the fuzzy header of a template has the placeholder
+				// "nplurals=INTEGER; plural=EXPRESSION;"
+				if err != nil && !e.Fuzzy {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if e.Fuzzy || !e.translated() {
+			continue
+		}
+		c.messages[messageKey(e.Context, e.ID)] = e.Strs
+	}
+	return c, nil
+}
+
+// poEntry is an entry of a .po or .pot file.
+type poEntry struct {
+	// TranslatorComments are the "# " lines.
+	TranslatorComments []string
+	// ExtractedComments are the "#." lines.
+	ExtractedComments []string
+	// References are the source references of the "#:" lines.
+	References []string
+	// Flags are the "#," flags, such as c-format.
+	Flags []string
+	Fuzzy bool
+
+	Context  string
+	ID       string
+	IDPlural string
+	Strs     []string
+}
+
+func (e *poEntry) translated() bool {
+	for _, s := range e.Strs {
+		if s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type poReader struct {
+	scanner *bufio.Scanner
+	lineNum int
+	// the keyword of the strings being read, such as "msgid" or "msgstr[1]"
+	keyword string
+	entry   *poEntry
+	entries []*poEntry
+	// whether the entry has any msg keyword
+	hasMsg bool
+}
+
+func readPoEntries(r io.Reader) ([]*poEntry, error) {
+	pr := &poReader{
+		scanner: bufio.NewScanner(r),
+		entry:   &poEntry{},
+	}
+	pr.scanner.Buffer(nil, 1024*1024)
+	for pr.scanner.Scan() {
+		pr.lineNum++
+		line := strings.TrimSpace(pr.scanner.Text())
+		if pr.lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		err := pr.handleLine(line)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := pr.scanner.Err(); err != nil {
+		return nil, err
+	}
+	pr.finishEntry()
+	return pr.entries, nil
+}
+
+func (pr *poReader) error(msg string) error {
+	return PoSyntaxError{Line: pr.lineNum, Msg: msg}
+}
+
+func (pr *poReader) finishEntry() {
+	if pr.hasMsg {
+		pr.entries = append(pr.entries, pr.entry)
+	}
+	pr.entry = &poEntry{}
+	pr.hasMsg = false
+	pr.keyword = ""
+}
+
+func (pr *poReader) handleLine(line string) error {
+	if line == "" {
+		return nil
+	}
+	if strings.HasPrefix(line, "#") {
+		if pr.hasMsg {
+			pr.finishEntry()
+		}
+		pr.handleComment(line)
+		return nil
+	}
+	if strings.HasPrefix(line, `"`) {
+		if pr.keyword == "" {
+			return pr.error("unexpected string")
+		}
+		s, err := strconv.Unquote(line)
+		if err != nil {
+			return pr.error("invalid string")
+		}
+		pr.appendString(s)
+		return nil
+	}
+
+	idx := strings.IndexAny(line, " \t")
+	if idx == -1 {
+		return pr.error("missing string")
+	}
+	keyword := line[:idx]
+	s, err := strconv.Unquote(strings.TrimSpace(line[idx:]))
+	if err != nil {
+		return pr.error("invalid string")
+	}
+
+	switch {
+	case keyword == "msgctxt", keyword == "msgid" && pr.keyword != "msgctxt":
+		if pr.hasMsg {
+			pr.finishEntry()
+		}
+	case keyword == "msgid":
+	case keyword == "msgid_plural":
+		if pr.keyword != "msgid" {
+			return pr.error("msgid_plural without msgid")
+		}
+	case keyword == "msgstr":
+		if pr.keyword != "msgid" {
+			return pr.error("msgstr without msgid")
+		}
+	case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+		n, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+		if err != nil || n != len(pr.entry.Strs) {
+			return pr.error("invalid plural index")
+		}
+	default:
+		return pr.error(fmt.Sprintf("unknown keyword %q", keyword))
+	}
+	pr.keyword = keyword
+	pr.hasMsg = true
+	if strings.HasPrefix(keyword, "msgstr") {
+		pr.entry.Strs = append(pr.entry.Strs, "")
+	}
+	pr.appendString(s)
+	return nil
+}
+
+func (pr *poReader) appendString(s string) {
+	e := pr.entry
+	switch pr.keyword {
+	case "msgctxt":
+		e.Context += s
+	case "msgid":
+		e.ID += s
+	case "msgid_plural":
+		e.IDPlural += s
+	default:
+		e.Strs[len(e.Strs)-1] += s
+	}
+}
+
+func (pr *poReader) handleComment(line string) {
+	e := pr.entry
+	switch {
+	case strings.HasPrefix(line, "#~"):
+		// obsolete entry
+	case strings.HasPrefix(line, "#."):
+		e.ExtractedComments = append(e.ExtractedComments, strings.TrimSpace(line[2:]))
+	case strings.HasPrefix(line, "#:"):
+		e.References = append(e.References, strings.Fields(line[2:])...)
+	case strings.HasPrefix(line, "#,"):
+		for _, flag := range strings.Split(line[2:], ",") {
+			flag = strings.TrimSpace(flag)
+			if flag == "fuzzy" {
+				e.Fuzzy = true
+			}
+			if flag != "" {
+				e.Flags = append(e.Flags, flag)
+			}
+		}
+	case strings.HasPrefix(line, "#|"):
+		// previous msgid
+	default:
+		e.TranslatorComments = append(e.TranslatorComments, strings.TrimSpace(line[1:]))
+	}
+}
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gettext
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildMo builds a .mo file from the key and translation pairs, the keys
+// are sorted as msgfmt does.
+func buildMo(order binary.ByteOrder, messages map[string]string) []byte {
+	var keys []string
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	n := uint32(len(keys))
+	origOffset := uint32(28)
+	transOffset := origOffset + n*8
+	dataOffset := transOffset + n*8
+
+	var table, strs bytes.Buffer
+	writeU32 := func(buf *bytes.Buffer, v uint32) {
+		var b [4]byte
+		order.PutUint32(b[:], v)
+		buf.Write(b[:])
+	}
+	var origs, transs [][2]uint32
+	for _, k := range keys {
+		origs = append(origs, [2]uint32{uint32(len(k)), dataOffset + uint32(strs.Len())})
+		strs.WriteString(k)
+		strs.WriteByte(0)
+	}
+	for _, k := range keys {
+		v := messages[k]
+		transs = append(transs, [2]uint32{uint32(len(v)), dataOffset + uint32(strs.Len())})
+		strs.WriteString(v)
+		strs.WriteByte(0)
+	}
+	for _, e := range append(origs, transs...) {
+		writeU32(&table, e[0])
+		writeU32(&table, e[1])
+	}
+
+	var buf bytes.Buffer
+	for _, v := range []uint32{0x950412de, 0, n, origOffset, transOffset, 0, 0} {
+		writeU32(&buf, v)
+	}
+	buf.Write(table.Bytes())
+	buf.Write(strs.Bytes())
+	return buf.Bytes()
+}
+
+func TestCompilePlural(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []uint64 // the forms of n from 0
+	}{
+		{"0", []uint64{0, 0, 0}},
+		{"n != 1", []uint64{1, 0, 1}},
+		{"n>1", []uint64{0, 0, 1}},
+		// Arabic
+		{"n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 && n%100<=99 ? 4 : 5",
+			[]uint64{0, 1, 2, 3, 3, 3, 3, 3, 3, 3, 3, 4, 4}},
+		// Russian
+		{"(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)",
+			[]uint64{2, 0, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 0, 1}},
+		{"!(n == 1)", []uint64{1, 0, 1}},
+		{"n/2 + n*0 - 0", []uint64{0, 0, 1, 1, 2}},
+	}
+	for _, test := range tests {
+		expr, err := compilePlural(test.expr)
+		require.NoError(t, err, test.expr)
+		for n, want := range test.want {
+			assert.Equal(t, want, expr(uint64(n)), "%s n=%d", test.expr, n)
+		}
+	}
+
+	for _, s := range []string{"", "n ==", "n ? 1", "(n", "n 1", "x", "n % % 2"} {
+		_, err := compilePlural(s)
+		assert.Error(t, err, s)
+	}
+
+	expr, err := compilePlural("n % 0")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), expr(3))
+
+	nplurals, _, err := parsePluralForms("nplurals=3; plural=n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2;")
+	require.NoError(t, err)
+	assert.Equal(t, 3, nplurals)
+	_, _, err = parsePluralForms("nplurals=0; plural=0;")
+	assert.Error(t, err)
+}
+
+func TestLoadMoFile(t *testing.T) {
+	c, err := LoadMoFile("testdata/locale/ar/LC_MESSAGES/test.mo")
+	require.NoError(t, err)
+	assert.Equal(t, "ar", c.Language())
+	s, ok := c.PGettext("", "Back")
+	assert.True(t, ok)
+	assert.Equal(t, "الخلف", s)
+	s, ok = c.PGettext("", "notfound")
+	assert.False(t, ok)
+	assert.Equal(t, "notfound", s)
+
+	c, err = LoadMoFile("testdata/plural/locale/es/LC_MESSAGES/test.mo")
+	require.NoError(t, err)
+	s, _ = c.NPGettext("", "%d apple", "%d apples", 1)
+	assert.Equal(t, "%d manzana", s)
+	s, _ = c.NPGettext("", "%d apple", "%d apples", 2)
+	assert.Equal(t, "%d manzanas", s)
+	s, ok = c.NPGettext("", "%d pear", "%d pears", 2)
+	assert.False(t, ok)
+	assert.Equal(t, "%d pears", s)
+
+	_, err = ParseMo([]byte("not a mo file, but long enough"))
+	assert.Equal(t, ErrInvalidMo, err)
+}
+
+func TestParseMoContext(t *testing.T) {
+	messages := map[string]string{
+		"": "Content-Type: text/plain; charset=UTF-8\n" +
+			"Plural-Forms: nplurals=3; plural=n==1 ? 0 : n==2 ? 1 : 2;\n",
+		"Open":                              "Open (no context)",
+		"file\x04Open":                      "Open (file)",
+		"file\x04%d file\x00%d files":       "one\x00two\x00many",
+		"network state\x04Open":             "Open (network)",
+		"untranslated\x04a\x00b":            "\x00",
+		"empty translation is untranslated": "",
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		c, err := ParseMo(buildMo(order, messages))
+		require.NoError(t, err)
+		assert.Equal(t, 6, c.Len())
+
+		s, _ := c.PGettext("", "Open")
+		assert.Equal(t, "Open (no context)", s)
+		s, _ = c.PGettext("file", "Open")
+		assert.Equal(t, "Open (file)", s)
+		s, _ = c.PGettext("network state", "Open")
+		assert.Equal(t, "Open (network)", s)
+		s, ok := c.PGettext("unknown", "Open")
+		assert.False(t, ok)
+		assert.Equal(t, "Open", s)
+
+		for n, want := range []string{"many", "one", "two", "many"} {
+			s, _ = c.NPGettext("file", "%d file", "%d files", n)
+			assert.Equal(t, want, s)
+		}
+		s, ok = c.NPGettext("untranslated", "a", "b", 2)
+		assert.False(t, ok)
+		assert.Equal(t, "b", s)
+		_, ok = c.PGettext("", "empty translation is untranslated")
+		assert.False(t, ok)
+	}
+}
+
+func TestParsePo(t *testing.T) {
+	c, err := LoadPoFile("testdata/context/locale/zh_CN/LC_MESSAGES/test.po")
+	require.NoError(t, err)
+	assert.Equal(t, "zh_CN", c.Language())
+	assert.Equal(t, "go-lib", c.Header["Project-Id-Version"])
+	// the fuzzy and obsolete entries are ignored
+	assert.Equal(t, 5, c.Len())
+
+	s, _ := c.PGettext("file", "Open")
+	assert.Equal(t, "打开", s)
+	s, _ = c.PGettext("network state", "Open")
+	assert.Equal(t, "开放", s)
+	s, _ = c.PGettext("", "Open")
+	assert.Equal(t, "开启", s)
+	s, _ = c.NPGettext("file", "%d file", "%d files", 3)
+	assert.Equal(t, "%d 个文件", s)
+	s, ok := c.PGettext("", "Close")
+	assert.False(t, ok)
+	assert.Equal(t, "Close", s)
+	s, _ = c.PGettext("", "Multiline")
+	assert.Equal(t, "多\n行", s)
+
+	c, err = LoadPoFile("testdata/zh_CN.po")
+	require.NoError(t, err)
+	s, _ = c.PGettext("", "Back")
+	assert.Equal(t, "返回", s)
+
+	tests := []string{
+		`msgstr "a"`,
+		"msgid \"a\"\nmsgstr[1] \"b\"",
+		"msgid \"a\"\nmsgstr \"b",
+		`"a"`,
+		`msgfoo "a"`,
+		"msgid",
+	}
+	for _, data := range tests {
+		_, err = ParsePo(strings.NewReader(data))
+		assert.Error(t, err, data)
+	}
+	_, err = ParsePo(strings.NewReader("msgid \"a\"\n\nmsgstr[2] \"b\""))
+	assert.Equal(t, PoSyntaxError{Line: 3, Msg: "invalid plural index"}, err)
+}
+
+func TestTranslator(t *testing.T) {
+	BindDomainDir("test", "testdata/locale", "testdata/plural/locale")
+	defer func() {
+		domainDirsMu.Lock()
+		delete(domainDirs, "test")
+		domainDirsMu.Unlock()
+	}()
+
+	tr := NewTranslator("test", "ar")
+	assert.Equal(t, "الخلف", tr.Tr("Back"))
+	assert.Equal(t, "notfound", tr.Tr("notfound"))
+	assert.Equal(t, "%d apples", tr.NTr("%d apple", "%d apples", 2))
+
+	// zh_CN.UTF-8 falls back to zh_CN
+	tr = NewTranslator("test", "zh_CN.UTF-8")
+	assert.Equal(t, "返回", tr.Tr("Back"))
+	assert.Equal(t, "test", tr.Domain())
+
+	// the languages are tried in order
+	tr = NewTranslator("test", "fr:es:ar")
+	assert.Equal(t, []string{"fr", "es", "ar"}, tr.Languages())
+	assert.Equal(t, "%d manzanas", tr.NTr("%d apple", "%d apples", 2))
+	assert.Equal(t, "الخلف", tr.Tr("Back"))
+
+	tr = NewTranslator("test", "C")
+	assert.Equal(t, "Back", tr.Tr("Back"))
+	assert.Equal(t, "%d apple", tr.NTr("%d apple", "%d apples", 1))
+
+	BindDomainDir("test", "testdata/context/locale")
+	tr = NewTranslator("test", "zh_CN")
+	assert.Equal(t, "打开", tr.PTr("file", "Open"))
+	assert.Equal(t, "开放", tr.PTr("network state", "Open"))
+	assert.Equal(t, "开启", tr.Tr("Open"))
+	assert.Equal(t, "%d 个文件", tr.NPTr("file", "%d file", "%d files", 1))
+	assert.Equal(t, "%d files", tr.NPTr("unknown", "%d file", "%d files", 2))
+
+	c, err := LoadPoFile("testdata/context/locale/zh_CN/LC_MESSAGES/test.po")
+	require.NoError(t, err)
+	tr = NewTranslatorFromCatalogs("test", c)
+	assert.Equal(t, "打开", tr.PTr("file", "Open"))
+}
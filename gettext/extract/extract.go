@@ -0,0 +1,410 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package extract extracts the messages of the gettext package from Go
+// source files into .pot templates, as xgettext does.
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keyword is a translation function recognized by the Extractor. The
+// argument positions start from 1 as the --keyword option of xgettext,
+// 0 means the function has no such argument.
+type Keyword struct {
+	Name    string
+	Context int
+	MsgID   int
+	Plural  int
+}
+
+// DefaultKeywords are the functions of the gettext package and the methods
+// of its Translator.
+var DefaultKeywords = []Keyword{
+	{Name: "Tr", MsgID: 1},
+	{Name: "NTr", MsgID: 1, Plural: 2},
+	{Name: "PTr", Context: 1, MsgID: 2},
+	{Name: "NPTr", Context: 1, MsgID: 2, Plural: 3},
+	{Name: "DGettext", MsgID: 2},
+	{Name: "DNGettext", MsgID: 2, Plural: 3},
+	{Name: "DPGettext", Context: 2, MsgID: 3},
+	{Name: "DNPGettext", Context: 2, MsgID: 3, Plural: 4},
+}
+
+// ParseKeyword parses a keyword in the syntax of the --keyword option of
+// xgettext, such as "NPTr:1c,2,3". A name without arguments is the same
+// as "NAME:1".
+func ParseKeyword(s string) (Keyword, error) {
+	idx := strings.IndexByte(s, ':')
+	if idx == -1 {
+		if s == "" {
+			return Keyword{}, fmt.Errorf("invalid keyword %q", s)
+		}
+		return Keyword{Name: s, MsgID: 1}, nil
+	}
+	kw := Keyword{Name: s[:idx]}
+	for _, arg := range strings.Split(s[idx+1:], ",") {
+		isContext := strings.HasSuffix(arg, "c")
+		n, err := strconv.Atoi(strings.TrimSuffix(arg, "c"))
+		if err != nil || n < 1 {
+			return Keyword{}, fmt.Errorf("invalid keyword %q", s)
+		}
+		switch {
+		case isContext && kw.Context == 0:
+			kw.Context = n
+		case !isContext && kw.MsgID == 0:
+			kw.MsgID = n
+		case !isContext && kw.Plural == 0:
+			kw.Plural = n
+		default:
+			return Keyword{}, fmt.Errorf("invalid keyword %q", s)
+		}
+	}
+	if kw.Name == "" || kw.MsgID == 0 {
+		return Keyword{}, fmt.Errorf("invalid keyword %q", s)
+	}
+	return kw, nil
+}
+
+// translatorCommentPrefix marks the comments for translators, the comment
+// right before a call is written to the template if it has this prefix.
+const translatorCommentPrefix = "TRANSLATORS:"
+
+// Extractor extracts the messages from the calls of the translation
+// functions in Go source files and writes a .pot template.
+type Extractor struct {
+	Keywords []Keyword
+
+	// the header fields of the template
+	PackageName    string
+	PackageVersion string
+	BugsAddress    string
+	// CreationDate is the POT-Creation-Date, now if it is zero.
+	CreationDate time.Time
+
+	fset    *token.FileSet
+	entries []*message
+	index   map[string]*message
+}
+
+// message is an entry of the template.
+type message struct {
+	// ExtractedComments are the "#." lines.
+	ExtractedComments []string
+	// References are the source references of the "#:" lines.
+	References []string
+
+	Context  string
+	ID       string
+	IDPlural string
+}
+
+func NewExtractor() *Extractor {
+	return &Extractor{
+		Keywords: append([]Keyword(nil), DefaultKeywords...),
+		fset:     token.NewFileSet(),
+		index:    make(map[string]*message),
+	}
+}
+
+func (e *Extractor) findKeyword(name string) *Keyword {
+	for i := len(e.Keywords) - 1; i >= 0; i-- {
+		if e.Keywords[i].Name == name {
+			return &e.Keywords[i]
+		}
+	}
+	return nil
+}
+
+// ParseFile extracts the messages of a Go source file, src is used as the
+// source if it is not nil, as go/parser.ParseFile does. The references
+// use filename as is.
+func (e *Extractor) ParseFile(filename string, src interface{}) error {
+	f, err := parser.ParseFile(e.fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// the translator comments by the line where they end
+	comments := make(map[int]string)
+	for _, cg := range f.Comments {
+		text := strings.TrimSpace(cg.Text())
+		if strings.HasPrefix(text, translatorCommentPrefix) {
+			comments[e.fset.Position(cg.End()).Line] = text
+		}
+	}
+
+	ast.Inspect(f, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var name string
+		switch fn := call.Fun.(type) {
+		case *ast.Ident:
+			name = fn.Name
+		case *ast.SelectorExpr:
+			name = fn.Sel.Name
+		}
+		kw := e.findKeyword(name)
+		if kw == nil {
+			return true
+		}
+		e.addCall(kw, call, comments)
+		return true
+	})
+	return nil
+}
+
+// ParseDir extracts the messages of the Go files in dir and its sub
+// directories, the testdata, vendor and hidden directories are skipped.
+func (e *Extractor) ParseDir(dir string) error {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != dir && (name == "testdata" || name == "vendor" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		err = e.ParseFile(file, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringArg returns the value of a string literal or a concatenation of
+// string literals.
+func stringArg(expr ast.Expr) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(x.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if x.Op != token.ADD {
+			return "", false
+		}
+		a, ok := stringArg(x.X)
+		if !ok {
+			return "", false
+		}
+		b, ok := stringArg(x.Y)
+		return a + b, ok
+	case *ast.ParenExpr:
+		return stringArg(x.X)
+	}
+	return "", false
+}
+
+func (e *Extractor) addCall(kw *Keyword, call *ast.CallExpr, comments map[int]string) {
+	arg := func(n int) (string, bool) {
+		if n == 0 {
+			return "", true
+		}
+		if n > len(call.Args) {
+			return "", false
+		}
+		return stringArg(call.Args[n-1])
+	}
+	msgid, ok1 := arg(kw.MsgID)
+	context, ok2 := arg(kw.Context)
+	plural, ok3 := arg(kw.Plural)
+	if !ok1 || !ok2 || !ok3 || msgid == "" {
+		// not a constant message
+		return
+	}
+
+	pos := e.fset.Position(call.Pos())
+	key := context + "\x04" + msgid
+	entry := e.index[key]
+	if entry == nil {
+		entry = &message{
+			Context: context,
+			ID:      msgid,
+		}
+		e.index[key] = entry
+		e.entries = append(e.entries, entry)
+	}
+	if entry.IDPlural == "" {
+		entry.IDPlural = plural
+	}
+	entry.References = append(entry.References,
+		fmt.Sprintf("%s:%d", filepath.ToSlash(pos.Filename), pos.Line))
+	if comment, ok := comments[pos.Line-1]; ok {
+		entry.ExtractedComments = appendUnique(entry.ExtractedComments, comment)
+	}
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// Len returns the number of the extracted messages.
+func (e *Extractor) Len() int {
+	return len(e.entries)
+}
+
+func (e *Extractor) header() string {
+	creationDate := e.CreationDate
+	if creationDate.IsZero() {
+		creationDate = time.Now()
+	}
+	packageName := e.PackageName
+	if packageName == "" {
+		packageName = "PACKAGE"
+	}
+	packageVersion := e.PackageVersion
+	if packageVersion == "" {
+		packageVersion = "VERSION"
+	}
+	header := "Project-Id-Version: " + packageName + " " + packageVersion + "\n" +
+		"Report-Msgid-Bugs-To: " + e.BugsAddress + "\n" +
+		"POT-Creation-Date: " + creationDate.Format("2006-01-02 15:04-0700") + "\n" +
+		"PO-Revision-Date: YEAR-MO-DA HO:MI+ZONE\n" +
+		"Last-Translator: FULL NAME <EMAIL@ADDRESS>\n" +
+		"Language-Team: LANGUAGE <LL@li.org>\n" +
+		"Language: \n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: text/plain; charset=UTF-8\n" +
+		"Content-Transfer-Encoding: 8bit\n"
+	for _, entry := range e.entries {
+		if entry.IDPlural != "" {
+			header += "Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;\n"
+			break
+		}
+	}
+	return header
+}
+
+// WritePot writes the template of the extracted messages in the order of
+// their first occurrences.
+func (e *Extractor) WritePot(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString("# SOME DESCRIPTIVE TITLE.\n" +
+		"# Copyright (C) YEAR THE PACKAGE'S COPYRIGHT HOLDER\n" +
+		"# This file is distributed under the same license as the PACKAGE package.\n" +
+		"# FIRST AUTHOR <EMAIL@ADDRESS>, YEAR.\n" +
+		"#\n" +
+		"#, fuzzy\n")
+	writePoString(&buf, "msgid", "")
+	writePoString(&buf, "msgstr", e.header())
+
+	for _, entry := range e.entries {
+		buf.WriteByte('\n')
+		for _, comment := range entry.ExtractedComments {
+			buf.WriteString("#. " + comment + "\n")
+		}
+		writeReferences(&buf, entry.References)
+		if entry.Context != "" {
+			writePoString(&buf, "msgctxt", entry.Context)
+		}
+		writePoString(&buf, "msgid", entry.ID)
+		if entry.IDPlural != "" {
+			writePoString(&buf, "msgid_plural", entry.IDPlural)
+			writePoString(&buf, "msgstr[0]", "")
+			writePoString(&buf, "msgstr[1]", "")
+		} else {
+			writePoString(&buf, "msgstr", "")
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeReferences writes the "#:" lines, which are wrapped at 79 columns
+// as xgettext does.
+func writeReferences(buf *bytes.Buffer, refs []string) {
+	line := "#:"
+	for _, ref := range refs {
+		if len(line) > 2 && len(line)+1+len(ref) > 79 {
+			buf.WriteString(line + "\n")
+			line = "#:"
+		}
+		line += " " + ref
+	}
+	if len(line) > 2 {
+		buf.WriteString(line + "\n")
+	}
+}
+
+// writePoString writes the keyword and s in the .po format, a string with
+// newlines is split after each newline.
+func writePoString(buf *bytes.Buffer, keyword, s string) {
+	buf.WriteString(keyword + " ")
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		buf.WriteString(quotePoString(s) + "\n")
+		return
+	}
+	buf.WriteString("\"\"\n")
+	for len(s) > 0 {
+		idx := strings.IndexByte(s, '\n')
+		var part string
+		if idx == -1 {
+			part, s = s, ""
+		} else {
+			part, s = s[:idx+1], s[idx+1:]
+		}
+		buf.WriteString(quotePoString(part) + "\n")
+	}
+}
+
+func quotePoString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
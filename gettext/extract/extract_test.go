@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package extract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linuxdeepin/go-lib/gettext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyword(t *testing.T) {
+	kw, err := ParseKeyword("NPTr:1c,2,3")
+	require.NoError(t, err)
+	assert.Equal(t, Keyword{Name: "NPTr", Context: 1, MsgID: 2, Plural: 3}, kw)
+
+	kw, err = ParseKeyword("MyTr")
+	require.NoError(t, err)
+	assert.Equal(t, Keyword{Name: "MyTr", MsgID: 1}, kw)
+
+	for _, s := range []string{"", ":1", "a:1c", "a:0", "a:x", "a:1,2,3", "a:1c,2c,3"} {
+		_, err = ParseKeyword(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestExtractor(t *testing.T) {
+	e := NewExtractor()
+	e.PackageName = "go-lib"
+	e.PackageVersion = "1.0"
+	e.CreationDate = time.Date(2022, 1, 2, 3, 4, 0, 0, time.UTC)
+	require.NoError(t, e.ParseDir("testdata"))
+	assert.Equal(t, 7, e.Len())
+
+	var buf bytes.Buffer
+	require.NoError(t, e.WritePot(&buf))
+	pot := buf.String()
+	assert.Contains(t, pot, "\"Project-Id-Version: go-lib 1.0\\n\"\n")
+	assert.Contains(t, pot, "\"POT-Creation-Date: 2022-01-02 03:04+0000\\n\"\n")
+	assert.Contains(t, pot, "#: testdata/applet.go:15 testdata/applet.go:26\nmsgid \"Open\"\n")
+	assert.Contains(t, pot, "#. TRANSLATORS: the state of a wireless network\n"+
+		"#: testdata/applet.go:18\n"+
+		"msgctxt \"network state\"\n"+
+		"msgid \"Open\"\n"+
+		"msgstr \"\"\n")
+	assert.Contains(t, pot, "msgid \"\"\n\"Say \\\"hello\\\"\\n\"\n\"in two lines\"\nmsgstr \"\"\n")
+
+	var got []message
+	for _, entry := range e.entries {
+		got = append(got, message{Context: entry.Context, ID: entry.ID, IDPlural: entry.IDPlural})
+	}
+	assert.Equal(t, []message{
+		{ID: "Open"},
+		{Context: "file", ID: "Open"},
+		{Context: "network state", ID: "Open"},
+		{ID: "%d file", IDPlural: "%d files"},
+		{Context: "file", ID: "%d file", IDPlural: "%d files"},
+		{ID: "Say \"hello\"\nin two lines"},
+		{Context: "menu", ID: "%d item", IDPlural: "%d items"},
+	}, got)
+
+	// the template is a valid .po file without translations
+	c, err := gettext.ParsePo(strings.NewReader(pot))
+	require.NoError(t, err)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestExtractorKeywords(t *testing.T) {
+	const src = `package main
+
+func main() {
+	T("a")
+	T(x)
+	obj.Tr("b", 1)
+	L("ctx", "c")
+	N()
+}
+`
+	e := NewExtractor()
+	e.Keywords = []Keyword{{Name: "T", MsgID: 1}, {Name: "L", Context: 1, MsgID: 2}, {Name: "N", MsgID: 1}}
+	require.NoError(t, e.ParseFile("main.go", src))
+	require.Len(t, e.entries, 2)
+	assert.Equal(t, "a", e.entries[0].ID)
+	assert.Equal(t, []string{"main.go:4"}, e.entries[0].References)
+	assert.Equal(t, "ctx", e.entries[1].Context)
+
+	assert.Error(t, e.ParseFile("bad.go", "package"))
+}
+
+func TestWriteReferences(t *testing.T) {
+	var buf bytes.Buffer
+	var refs []string
+	for i := 0; i < 10; i++ {
+		refs = append(refs, "some/long/path/file.go:100")
+	}
+	writeReferences(&buf, refs)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		assert.True(t, strings.HasPrefix(line, "#: "))
+		assert.True(t, len(line) <= 79, line)
+	}
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+
+	. "github.com/linuxdeepin/go-lib/gettext"
+)
+
+func main() {
+	tr := NewTranslator("applet", "zh_CN")
+	fmt.Println(Tr("Open"))
+	fmt.Println(PTr("file", "Open"))
+	// TRANSLATORS: the state of a wireless network
+	fmt.Println(tr.PTr("network state", "Open"))
+	fmt.Println(fmt.Sprintf(NTr("%d file", "%d files", 2), 2))
+	fmt.Println(NPTr("file", "%d file", "%d files", 2))
+	fmt.Println(DGettext("other", "Say \"hello\"\n"+
+		"in two lines"))
+	fmt.Println(DNPGettext("other", "menu", "%d item", "%d items", 2))
+	msg := "not a literal"
+	fmt.Println(Tr(msg))
+	fmt.Println(Tr("Open"))
+}
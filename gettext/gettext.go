@@ -9,6 +9,24 @@ package gettext
 #include <stdlib.h>
 #include <libintl.h>
 void _init_i18n() { setlocale(LC_ALL, ""); }
+
+// the same as pgettext_aux and npgettext_aux of gettext.h, domain may be NULL
+static const char *_pgettext(const char *domain, const char *ctxt_id, const char *msgid) {
+	const char *translation = dcgettext(domain, ctxt_id, LC_MESSAGES);
+	if (translation == ctxt_id) {
+		return msgid;
+	}
+	return translation;
+}
+
+static const char *_npgettext(const char *domain, const char *ctxt_id, const char *msgid,
+			const char *plural, unsigned long n) {
+	const char *translation = dcngettext(domain, ctxt_id, plural, n, LC_MESSAGES);
+	if (translation == ctxt_id || translation == plural) {
+		return n == 1 ? msgid : plural;
+	}
+	return translation;
+}
 */
 import "C"
 
@@ -79,6 +97,9 @@ func Bindtextdomain(domain, dirname string) string {
 	_dirname := C.CString(dirname)
 	defer C.free(unsafe.Pointer(_domain))
 	defer C.free(unsafe.Pointer(_dirname))
+	if dirname != "" {
+		BindDomainDir(domain, dirname)
+	}
 	return C.GoString(C.bindtextdomain(_domain, _dirname))
 }
 
@@ -113,6 +134,49 @@ func DNGettext(domain, msgid, plural string, n int) string {
 	return C.GoString(C.dngettext(cDomain, cMsgid, cPlural, C.ulong(n)))
 }
 
+// PTr returns the translation of msgid in the message context, as pgettext does.
+func PTr(context, msgid string) string {
+	return DPGettext("", context, msgid)
+}
+
+// NPTr returns the plural translation of msgid in the message context, as
+// npgettext does.
+func NPTr(context, msgid, plural string, n int) string {
+	return DNPGettext("", context, msgid, plural, n)
+}
+
+// cDomain returns NULL for the empty domain, which is the current text domain.
+func cDomain(domain string) *C.char {
+	if domain == "" {
+		return nil
+	}
+	return C.CString(domain)
+}
+
+// DPGettext returns the translation of msgid in the message context of domain.
+func DPGettext(domain, context, msgid string) string {
+	_d := cDomain(domain)
+	defer C.free(unsafe.Pointer(_d))
+	_ctxtId := C.CString(context + contextSeparator + msgid)
+	defer C.free(unsafe.Pointer(_ctxtId))
+	_id := C.CString(msgid)
+	defer C.free(unsafe.Pointer(_id))
+	return C.GoString(C._pgettext(_d, _ctxtId, _id))
+}
+
+// DNPGettext returns the plural translation of msgid in the message context of domain.
+func DNPGettext(domain, context, msgid, plural string, n int) string {
+	_d := cDomain(domain)
+	defer C.free(unsafe.Pointer(_d))
+	_ctxtId := C.CString(context + contextSeparator + msgid)
+	defer C.free(unsafe.Pointer(_ctxtId))
+	_id := C.CString(msgid)
+	defer C.free(unsafe.Pointer(_id))
+	_plural := C.CString(plural)
+	defer C.free(unsafe.Pointer(_plural))
+	return C.GoString(C._npgettext(_d, _ctxtId, _id, _plural, C.ulong(n)))
+}
+
 // QueryLang return user lang.
 // the rule is document at man gettext(3)
 func QueryLang() string {
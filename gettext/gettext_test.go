@@ -5,8 +5,11 @@
 package gettext
 
 import (
+	"encoding/binary"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -87,6 +90,40 @@ func Test_DNGettext(t *testing.T) {
 	assert.Equal(t, DNGettext("test", "%d person", "%d persons", 2), "%d人")
 }
 
+func Test_PTr(t *testing.T) {
+	dir := t.TempDir()
+	msgDir := filepath.Join(dir, "zh_CN", "LC_MESSAGES")
+	err := os.MkdirAll(msgDir, 0755)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(msgDir, "context.mo"), buildMo(binary.LittleEndian, map[string]string{
+		"":                            "Content-Type: text/plain; charset=UTF-8\nPlural-Forms: nplurals=1; plural=0;\n",
+		"Open":                        "开启",
+		"file\x04Open":                "打开",
+		"network state\x04Open":       "开放",
+		"file\x04%d file\x00%d files": "%d 个文件",
+	}), 0644)
+	assert.NoError(t, err)
+
+	_ = os.Setenv("LC_ALL", "en_US.UTF-8")
+	_ = os.Setenv("LANGUAGE", "zh_CN")
+	InitI18n()
+	Bindtextdomain("context", dir)
+	Textdomain("context")
+
+	assert.Equal(t, "打开", PTr("file", "Open"))
+	assert.Equal(t, "开放", PTr("network state", "Open"))
+	assert.Equal(t, "开启", Tr("Open"))
+	assert.Equal(t, "Open", PTr("unknown", "Open"))
+	assert.Equal(t, "%d 个文件", NPTr("file", "%d file", "%d files", 2))
+	assert.Equal(t, "%d file", NPTr("unknown", "%d file", "%d files", 1))
+	assert.Equal(t, "%d files", NPTr("unknown", "%d file", "%d files", 2))
+
+	Textdomain("test")
+	assert.Equal(t, "打开", DPGettext("context", "file", "Open"))
+	assert.Equal(t, "Open", DPGettext("test", "file", "Open"))
+	assert.Equal(t, "%d 个文件", DNPGettext("context", "file", "%d file", "%d files", 1))
+}
+
 func Test_QueryLang(t *testing.T) {
 	_ = os.Setenv("LC_ALL", "zh_CN.UTF-8")
 	_ = os.Setenv("LC_MESSAGE", "zh_TW.")
@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gettext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pluralExpr is a compiled plural expression of the Plural-Forms header,
+// such as "n==1 ? 0 : 1". It returns the index of the plural form for n.
+type pluralExpr func(n uint64) uint64
+
+// germanicPlural is used when a catalog has no Plural-Forms header.
+func germanicPlural(n uint64) uint64 {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// parsePluralForms parses the value of the Plural-Forms header, such as
+// "nplurals=2; plural=n != 1;".
+func parsePluralForms(value string) (nplurals int, expr pluralExpr, err error) {
+	var pluralStr string
+	for _, field := range strings.Split(value, ";") {
+		field = strings.TrimSpace(field)
+		idx := strings.IndexByte(field, '=')
+		if idx == -1 {
+			continue
+		}
+		key := strings.TrimSpace(field[:idx])
+		val := strings.TrimSpace(field[idx+1:])
+		switch key {
+		case "nplurals":
+			nplurals, err = strconv.Atoi(val)
+			if err != nil || nplurals < 1 {
+				return 0, nil, fmt.Errorf("invalid nplurals %q", val)
+			}
+		case "plural":
+			pluralStr = val
+		}
+	}
+	if nplurals == 0 || pluralStr == "" {
+		return 0, nil, fmt.Errorf("invalid plural forms %q", value)
+	}
+	expr, err = compilePlural(pluralStr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return nplurals, expr, nil
+}
+
+// PluralSyntaxError is returned when a plural expression can not be parsed.
+type PluralSyntaxError struct {
+	Expr string
+	Pos  int
+}
+
+func (err PluralSyntaxError) Error() string {
+	return fmt.Sprintf("syntax error in plural expression %q at offset %d", err.Expr, err.Pos)
+}
+
+// compilePlural compiles the C expression of the plural forms, the
+// operators are the same as GNU gettext supports.
+func compilePlural(s string) (pluralExpr, error) {
+	p := &pluralParser{src: s}
+	p.next()
+	expr := p.parseTernary()
+	if p.err == nil && p.tok != "" {
+		p.fail()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return expr, nil
+}
+
+type pluralParser struct {
+	src string
+	pos int
+	// the current token, "" at the end of the input
+	tok    string
+	tokPos int
+	err    error
+}
+
+func (p *pluralParser) fail() {
+	if p.err == nil {
+		p.err = PluralSyntaxError{Expr: p.src, Pos: p.tokPos}
+	}
+}
+
+func (p *pluralParser) next() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) != -1 {
+		p.pos++
+	}
+	p.tokPos = p.pos
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9':
+		end := p.pos
+		for end < len(p.src) && p.src[end] >= '0' && p.src[end] <= '9' {
+			end++
+		}
+		p.tok = p.src[p.pos:end]
+		p.pos = end
+		return
+	case c == 'n':
+		p.tok = "n"
+		p.pos++
+		return
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "&&", "||"} {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			p.tok = op
+			p.pos += 2
+			return
+		}
+	}
+	if strings.IndexByte("?:<>+-*/%!()", c) != -1 {
+		p.tok = string(c)
+		p.pos++
+		return
+	}
+	p.tok = ""
+	p.fail()
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (p *pluralParser) parseTernary() pluralExpr {
+	cond := p.parseBinary(0)
+	if p.tok != "?" {
+		return cond
+	}
+	p.next()
+	a := p.parseTernary()
+	if p.tok != ":" {
+		p.fail()
+		return cond
+	}
+	p.next()
+	b := p.parseTernary()
+	if p.err != nil {
+		return cond
+	}
+	return func(n uint64) uint64 {
+		if cond(n) != 0 {
+			return a(n)
+		}
+		return b(n)
+	}
+}
+
+// binary operators by precedence, from low to high
+var pluralBinaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", ">", "<=", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func applyPluralOp(op string, a, b pluralExpr) pluralExpr {
+	switch op {
+	case "||":
+		return func(n uint64) uint64 { return boolValue(a(n) != 0 || b(n) != 0) }
+	case "&&":
+		return func(n uint64) uint64 { return boolValue(a(n) != 0 && b(n) != 0) }
+	case "==":
+		return func(n uint64) uint64 { return boolValue(a(n) == b(n)) }
+	case "!=":
+		return func(n uint64) uint64 { return boolValue(a(n) != b(n)) }
+	case "<":
+		return func(n uint64) uint64 { return boolValue(a(n) < b(n)) }
+	case ">":
+		return func(n uint64) uint64 { return boolValue(a(n) > b(n)) }
+	case "<=":
+		return func(n uint64) uint64 { return boolValue(a(n) <= b(n)) }
+	case ">=":
+		return func(n uint64) uint64 { return boolValue(a(n) >= b(n)) }
+	case "+":
+		return func(n uint64) uint64 { return a(n) + b(n) }
+	case "-":
+		return func(n uint64) uint64 { return a(n) - b(n) }
+	case "*":
+		return func(n uint64) uint64 { return a(n) * b(n) }
+	case "/", "%":
+		return func(n uint64) uint64 {
+			y := b(n)
+			if y == 0 {
+				// a division by zero is an error in libintl, use the
+				// first form instead of crashing
+				return 0
+			}
+			if op == "/" {
+				return a(n) / y
+			}
+			return a(n) % y
+		}
+	}
+	return nil
+}
+
+func (p *pluralParser) parseBinary(level int) pluralExpr {
+	if level == len(pluralBinaryOps) {
+		return p.parseUnary()
+	}
+	left := p.parseBinary(level + 1)
+	for p.err == nil {
+		op := ""
+		for _, o := range pluralBinaryOps[level] {
+			if p.tok == o {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			break
+		}
+		p.next()
+		right := p.parseBinary(level + 1)
+		left = applyPluralOp(op, left, right)
+	}
+	return left
+}
+
+func (p *pluralParser) parseUnary() pluralExpr {
+	switch {
+	case p.tok == "!":
+		p.next()
+		x := p.parseUnary()
+		return func(n uint64) uint64 { return boolValue(x(n) == 0) }
+	case p.tok == "(":
+		p.next()
+		x := p.parseTernary()
+		if p.tok != ")" {
+			p.fail()
+			return x
+		}
+		p.next()
+		return x
+	case p.tok == "n":
+		p.next()
+		return func(n uint64) uint64 { return n }
+	case p.tok != "" && p.tok[0] >= '0' && p.tok[0] <= '9':
+		v, err := strconv.ParseUint(p.tok, 10, 64)
+		if err != nil {
+			p.fail()
+		}
+		p.next()
+		return func(uint64) uint64 { return v }
+	}
+	p.fail()
+	return func(uint64) uint64 { return 0 }
+}
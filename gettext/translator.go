@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gettext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/linuxdeepin/go-lib/locale"
+)
+
+// DefaultLocaleDir is the directory searched for the domains without a
+// bound directory.
+const DefaultLocaleDir = "/usr/share/locale"
+
+var (
+	domainDirsMu sync.RWMutex
+	domainDirs   = make(map[string][]string)
+
+	catalogCacheMu sync.Mutex
+	// the loaded catalogs by file, nil if the file does not exist or
+	// can not be parsed
+	catalogCache = make(map[string]*Catalog)
+)
+
+// BindDomainDir sets the locale directories searched for the .mo and .po
+// files of domain by the pure Go Translator, the directories are searched
+// in order. It does not change the state of libintl, Bindtextdomain also
+// binds the directory for the Translator.
+func BindDomainDir(domain string, dirs ...string) {
+	domainDirsMu.Lock()
+	domainDirs[domain] = append([]string(nil), dirs...)
+	domainDirsMu.Unlock()
+	ClearCatalogCache()
+}
+
+func getDomainDirs(domain string) []string {
+	domainDirsMu.RLock()
+	defer domainDirsMu.RUnlock()
+	dirs, ok := domainDirs[domain]
+	if !ok {
+		return []string{DefaultLocaleDir}
+	}
+	return dirs
+}
+
+// ClearCatalogCache drops the loaded catalogs, so that the next
+// NewTranslator reloads the changed files.
+func ClearCatalogCache() {
+	catalogCacheMu.Lock()
+	catalogCache = make(map[string]*Catalog)
+	catalogCacheMu.Unlock()
+}
+
+func loadCatalogCached(file string) *Catalog {
+	catalogCacheMu.Lock()
+	defer catalogCacheMu.Unlock()
+	c, ok := catalogCache[file]
+	if ok {
+		return c
+	}
+	var err error
+	if strings.HasSuffix(file, ".po") {
+		c, err = LoadPoFile(file)
+	} else {
+		c, err = LoadMoFile(file)
+	}
+	if err != nil {
+		// libintl ignores the missing and broken files too
+		c = nil
+	}
+	catalogCache[file] = c
+	return c
+}
+
+// FindCatalog finds the catalog of domain for lang in the bound
+// directories. The variants of lang returned by locale.GetLocaleVariants
+// are tried from the most specific one, such as "zh_CN.UTF-8", "zh_CN"
+// and "zh". In each directory, DIR/LANG/LC_MESSAGES/DOMAIN.mo is preferred
+// to the .po file. It returns nil if no catalog is found.
+func FindCatalog(domain, lang string) *Catalog {
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+	dirs := getDomainDirs(domain)
+	for _, variant := range locale.GetLocaleVariants(lang) {
+		for _, dir := range dirs {
+			base := filepath.Join(dir, variant, "LC_MESSAGES", domain)
+			for _, ext := range []string{".mo", ".po"} {
+				file := base + ext
+				if _, err := os.Stat(file); err != nil {
+					continue
+				}
+				if c := loadCatalogCached(file); c != nil {
+					return c
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Translator translates the messages of a domain into a specific language
+// without changing the process-global locale, so that a service can
+// translate the messages for each caller. It is safe for concurrent use.
+type Translator struct {
+	domain   string
+	langs    []string
+	catalogs []*Catalog
+}
+
+// NewTranslator returns a Translator of domain for lang. lang may be a
+// colon-separated list as the LANGUAGE environment variable, such as
+// "zh_TW:zh_CN", the languages are tried in order. The untranslated
+// messages are returned as is.
+func NewTranslator(domain, lang string) *Translator {
+	t := &Translator{domain: domain}
+	for _, l := range strings.Split(lang, ":") {
+		if l == "" {
+			continue
+		}
+		t.langs = append(t.langs, l)
+		if c := FindCatalog(domain, l); c != nil {
+			t.catalogs = append(t.catalogs, c)
+		}
+	}
+	return t
+}
+
+// NewTranslatorFromCatalogs returns a Translator which looks up the
+// catalogs in order.
+func NewTranslatorFromCatalogs(domain string, catalogs ...*Catalog) *Translator {
+	return &Translator{
+		domain:   domain,
+		catalogs: catalogs,
+	}
+}
+
+// Domain returns the text domain of t.
+func (t *Translator) Domain() string {
+	return t.domain
+}
+
+// Languages returns the languages of t.
+func (t *Translator) Languages() []string {
+	return t.langs
+}
+
+// Tr returns the translation of msgid.
+func (t *Translator) Tr(msgid string) string {
+	return t.PTr("", msgid)
+}
+
+// NTr returns the translation of msgid or msgidPlural according to n.
+func (t *Translator) NTr(msgid, msgidPlural string, n int) string {
+	return t.NPTr("", msgid, msgidPlural, n)
+}
+
+// PTr returns the translation of msgid in the message context, as
+// pgettext does.
+func (t *Translator) PTr(context, msgid string) string {
+	for _, c := range t.catalogs {
+		if s, ok := c.PGettext(context, msgid); ok {
+			return s
+		}
+	}
+	return msgid
+}
+
+// NPTr returns the plural translation of msgid in the message context, as
+// npgettext does.
+func (t *Translator) NPTr(context, msgid, msgidPlural string, n int) string {
+	for _, c := range t.catalogs {
+		if s, ok := c.NPGettext(context, msgid, msgidPlural, n); ok {
+			return s
+		}
+	}
+	if n == 1 {
+		return msgid
+	}
+	return msgidPlural
+}
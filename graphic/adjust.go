@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image"
+	"image/color"
+)
+
+// AdjustBrightness returns the image whose colors are multiplied by the
+// factor as the brightness() of CSS filters, 1 keeps the image unchanged
+// and 0 makes it black.
+func AdjustBrightness(srcimg image.Image, factor float64) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	adjustBrightness(dstimg, factor)
+	return
+}
+
+// AdjustContrast returns the image whose contrast is multiplied by the
+// factor as the contrast() of CSS filters, 1 keeps the image unchanged
+// and 0 makes it gray.
+func AdjustContrast(srcimg image.Image, factor float64) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	adjustContrast(dstimg, factor)
+	return
+}
+
+// AdjustSaturation returns the image whose saturation is multiplied by the
+// factor as the saturate() of CSS filters, 1 keeps the image unchanged, 0
+// makes it grayscale and the larger ones make it more vivid.
+func AdjustSaturation(srcimg image.Image, factor float64) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	adjustSaturation(dstimg, factor)
+	return
+}
+
+// Tint returns the image whose colors are mixed with the color c by the
+// amount in [0..1], the alpha of the image is kept. For example, tinting
+// by black darkens the image.
+func Tint(srcimg image.Image, c color.Color, amount float64) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	tint(dstimg, c, amount)
+	return
+}
+
+func adjustBrightness(img *image.RGBA, factor float64) {
+	mapChannels(img, func(v float64) float64 {
+		return v * factor
+	})
+}
+
+func adjustContrast(img *image.RGBA, factor float64) {
+	mapChannels(img, func(v float64) float64 {
+		return (v-0.5)*factor + 0.5
+	})
+}
+
+func adjustSaturation(img *image.RGBA, factor float64) {
+	// the matrix of saturate() in the Filter Effects spec
+	s := factor
+	mapColors(img, func(r, g, b float64) (float64, float64, float64) {
+		return (0.213+0.787*s)*r + (0.715-0.715*s)*g + (0.072-0.072*s)*b,
+			(0.213-0.213*s)*r + (0.715+0.285*s)*g + (0.072-0.072*s)*b,
+			(0.213-0.213*s)*r + (0.715-0.715*s)*g + (0.072+0.928*s)*b
+	})
+}
+
+func tint(img *image.RGBA, c color.Color, amount float64) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	// the transparent colors tint less
+	amount *= float64(n.A) / 0xff
+	tr, tg, tb := float64(n.R)/0xff, float64(n.G)/0xff, float64(n.B)/0xff
+	mapColors(img, func(r, g, b float64) (float64, float64, float64) {
+		return r + (tr-r)*amount, g + (tg-g)*amount, b + (tb-b)*amount
+	})
+}
+
+// mapChannels maps each of the r, g, b channels by fn, see mapColors.
+func mapChannels(img *image.RGBA, fn func(v float64) float64) {
+	var lut [256]float64
+	for i := range lut {
+		lut[i] = fn(float64(i) / 0xff)
+	}
+	index := func(v float64) int {
+		if v >= 1 {
+			return 0xff
+		}
+		return int(v*0xff + 0.5)
+	}
+	mapColors(img, func(r, g, b float64) (float64, float64, float64) {
+		return lut[index(r)], lut[index(g)], lut[index(b)]
+	})
+}
+
+// mapColors maps the colors of the pixels by fn in place, the colors are
+// not premultiplied and in [0..1], the results are clamped. The alpha is
+// kept.
+func mapColors(img *image.RGBA, fn func(r, g, b float64) (float64, float64, float64)) {
+	w, h := GetSize(img)
+	parallel(h, func(start, end int) {
+		for y := start; y < end; y++ {
+			i := img.PixOffset(img.Rect.Min.X, y+img.Rect.Min.Y)
+			row := img.Pix[i : i+w*4]
+			for x := 0; x < len(row); x += 4 {
+				pix := row[x : x+4 : x+4]
+				a := pix[3]
+				if a == 0 {
+					continue
+				}
+				fa := float64(a) / 0xff
+				r, g, b := fn(float64(pix[0])/0xff/fa, float64(pix[1])/0xff/fa,
+					float64(pix[2])/0xff/fa)
+				pix[0] = premultiply(r, fa, a)
+				pix[1] = premultiply(g, fa, a)
+				pix[2] = premultiply(b, fa, a)
+			}
+		}
+	})
+}
+
+// premultiply clamps the channel v in [0..1] and multiplies it by the
+// alpha fa, the result is not greater than a.
+func premultiply(v, fa float64, a uint8) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return a
+	}
+	return clampChannel(float32(v*fa*0xff), a)
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"bufio"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"time"
+)
+
+// the delays shorter are shown as defaultFrameDelay, as the browsers do
+const (
+	minFrameDelay     = 20 * time.Millisecond
+	defaultFrameDelay = 100 * time.Millisecond
+)
+
+// Animation is the frames of an animated image.
+type Animation struct {
+	// Frames are the frames composed with the previous ones, all of them
+	// are in the size of the image.
+	Frames []*image.RGBA
+	// Delays are how long the frames are shown.
+	Delays []time.Duration
+	// LoopCount is the same as gif.GIF, 0 means looping forever, -1 means
+	// showing the frames once, otherwise the animation is looped
+	// LoopCount+1 times.
+	LoopCount int
+}
+
+// LoadAnimation load all the frames of the GIF animation, the other images
+// are loaded by LoadImage as the animations of one frame.
+func LoadAnimation(imgfile string) (*Animation, error) {
+	f, err := os.Open(imgfile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if sniff(br).name != string(FormatGif) {
+		img, err := LoadImage(imgfile)
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{
+			Frames:    []*image.RGBA{convertToRGBA(img)},
+			Delays:    []time.Duration{0},
+			LoopCount: -1,
+		}, nil
+	}
+	return decodeGIFAnimation(br)
+}
+
+func decodeGIFAnimation(r io.Reader) (*Animation, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	canvas := image.NewRGBA(bounds)
+	anim := &Animation{LoopCount: g.LoopCount}
+	var previous *image.RGBA
+	for i, frame := range g.Image {
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		result := image.NewRGBA(bounds)
+		copy(result.Pix, canvas.Pix)
+		anim.Frames = append(anim.Frames, result)
+		delay := time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		if delay < minFrameDelay {
+			delay = defaultFrameDelay
+		}
+		anim.Delays = append(anim.Delays, delay)
+
+		// prepare the canvas for the next frame
+		switch disposal {
+		case gif.DisposalBackground:
+			// the background is transparent as the browsers do
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas, previous = previous, nil
+		}
+	}
+	return anim, nil
+}
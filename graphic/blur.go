@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// the larger sigmas are approximated by 3 box blurs, whose time does not
+// depend on the radius
+const maxExactBlurSigma = 3
+
+// BlurImage generate blur effect to an image file, see GaussianBlur.
+func BlurImage(srcfile, dstfile string, sigma float64, f Format) (err error) {
+	srcimg, err := LoadImage(srcfile)
+	if err != nil {
+		return
+	}
+	dstimg := GaussianBlur(srcimg, sigma)
+	err = SaveImage(dstfile, dstimg, f)
+	dstimg.Pix = nil
+	return
+}
+
+// BlurImageCache generate and save the blurred image file to cache
+// directory, if target file already exists, just return it.
+func BlurImageCache(srcfile string, sigma float64, f Format) (dstfile string, useCache bool, err error) {
+	params := fmt.Sprintf("BlurImageCache%g,%s", sigma, f)
+	return getCacheFile(srcfile, params, f, func(dstfile string) error {
+		return BlurImage(srcfile, dstfile, sigma, f)
+	})
+}
+
+// GaussianBlur returns the image blurred by the Gaussian function with the
+// standard deviation sigma in pixels. It is exact for the small sigmas and
+// approximated by 3 box blurs for the large ones, so that the large sigmas
+// are as fast. The pixels out of the image are the same as the edges.
+func GaussianBlur(srcimg image.Image, sigma float64) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	gaussianBlur(dstimg, sigma)
+	return
+}
+
+// BoxBlur returns the image blurred by averaging the (2*radius+1) x
+// (2*radius+1) pixels around each pixel.
+func BoxBlur(srcimg image.Image, radius int) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	boxBlur(dstimg, []int{radius})
+	return
+}
+
+func gaussianBlur(img *image.RGBA, sigma float64) {
+	if sigma <= 0 {
+		return
+	}
+	if sigma > maxExactBlurSigma {
+		boxBlur(img, getBoxesForGauss(sigma, 3))
+		return
+	}
+
+	radius := int(math.Ceil(sigma * 3))
+	weights := make([]float32, 2*radius+1)
+	var sum float64
+	for i := range weights {
+		x := float64(i - radius)
+		w := math.Exp(-x * x / (2 * sigma * sigma))
+		weights[i] = float32(w)
+		sum += w
+	}
+	for i := range weights {
+		weights[i] /= float32(sum)
+	}
+	blurPasses(img, func(src, dst []uint8, start, step, n int) {
+		convolveLine(src, dst, start, step, n, weights)
+	})
+}
+
+// getBoxesForGauss returns the radii of n box blurs which approximate the
+// Gaussian blur of sigma together.
+func getBoxesForGauss(sigma float64, n int) []int {
+	wIdeal := math.Sqrt(12*sigma*sigma/float64(n) + 1)
+	wl := int(math.Floor(wIdeal))
+	if wl%2 == 0 {
+		wl--
+	}
+	wu := wl + 2
+	fn, fwl := float64(n), float64(wl)
+	mIdeal := (12*sigma*sigma - fn*fwl*fwl - 4*fn*fwl - 3*fn) / (-4*fwl - 4)
+	m := int(math.Round(mIdeal))
+
+	radii := make([]int, n)
+	for i := range radii {
+		if i < m {
+			radii[i] = (wl - 1) / 2
+		} else {
+			radii[i] = (wu - 1) / 2
+		}
+	}
+	return radii
+}
+
+func boxBlur(img *image.RGBA, radii []int) {
+	for _, r := range radii {
+		if r <= 0 {
+			continue
+		}
+		blurPasses(img, func(src, dst []uint8, start, step, n int) {
+			boxBlurLine(src, dst, start, step, n, r)
+		})
+	}
+}
+
+// blurPasses blurs the image horizontally and then vertically by blurLine,
+// which blurs the n pixels of a line from src to dst, the pixels are at
+// start, start+step and so on.
+func blurPasses(img *image.RGBA, blurLine func(src, dst []uint8, start, step, n int)) {
+	w, h := GetSize(img)
+	if w == 0 || h == 0 {
+		return
+	}
+	tmp := make([]uint8, len(img.Pix))
+	parallel(h, func(start, end int) {
+		for y := start; y < end; y++ {
+			blurLine(img.Pix, tmp, y*img.Stride, 4, w)
+		}
+	})
+	// the columns in a band are next to each other in memory
+	parallel(w, func(start, end int) {
+		for x := start; x < end; x++ {
+			blurLine(tmp, img.Pix, x*4, img.Stride, h)
+		}
+	})
+}
+
+// boxBlurLine averages the 2*r+1 pixels around each pixel by the sliding
+// sums, the pixels out of the line are the same as the edges.
+func boxBlurLine(src, dst []uint8, start, step, n, r int) {
+	last := start + (n-1)*step
+	clamp := func(i int) int {
+		if i < 0 {
+			return start
+		}
+		if i >= n {
+			return last
+		}
+		return start + i*step
+	}
+
+	size := 2*r + 1
+	var sums [4]int
+	for i := -r; i <= r; i++ {
+		p := clamp(i)
+		for c := 0; c < 4; c++ {
+			sums[c] += int(src[p+c])
+		}
+	}
+	for i := 0; i < n; i++ {
+		p := start + i*step
+		alpha := uint8((sums[3] + size/2) / size)
+		for c := 0; c < 3; c++ {
+			v := uint8((sums[c] + size/2) / size)
+			if v > alpha {
+				v = alpha
+			}
+			dst[p+c] = v
+		}
+		dst[p+3] = alpha
+
+		out, in := clamp(i-r), clamp(i+r+1)
+		for c := 0; c < 4; c++ {
+			sums[c] += int(src[in+c]) - int(src[out+c])
+		}
+	}
+}
+
+// convolveLine convolves the pixels of the line with the weights, the
+// pixels out of the line are the same as the edges.
+func convolveLine(src, dst []uint8, start, step, n int, weights []float32) {
+	r := len(weights) / 2
+	for i := 0; i < n; i++ {
+		var sums [4]float32
+		for j, weight := range weights {
+			k := i + j - r
+			if k < 0 {
+				k = 0
+			} else if k >= n {
+				k = n - 1
+			}
+			p := start + k*step
+			sums[0] += float32(src[p]) * weight
+			sums[1] += float32(src[p+1]) * weight
+			sums[2] += float32(src[p+2]) * weight
+			sums[3] += float32(src[p+3]) * weight
+		}
+		p := start + i*step
+		alpha := clampChannel(sums[3], 0xff)
+		dst[p] = clampChannel(sums[0], alpha)
+		dst[p+1] = clampChannel(sums[1], alpha)
+		dst[p+2] = clampChannel(sums[2], alpha)
+		dst[p+3] = alpha
+	}
+}
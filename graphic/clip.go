@@ -25,13 +25,10 @@ func ClipImage(srcfile, dstfile string, x, y, w, h int, f Format) (err error) {
 // ClipImageCache clip any recognized format image and save to cache
 // directory, if already exists, just return it.
 func ClipImageCache(srcfile string, x, y, w, h int, f Format) (dstfile string, useCache bool, err error) {
-	dstfile = generateCacheFilePath(fmt.Sprintf("ClipImageCache%s%d%d%d%d%s", srcfile, x, y, w, h, f))
-	if isFileExists(dstfile) {
-		useCache = true
-		return
-	}
-	err = ClipImage(srcfile, dstfile, x, y, w, h, f)
-	return
+	params := fmt.Sprintf("ClipImageCache%d,%d,%d,%d,%s", x, y, w, h, f)
+	return getCacheFile(srcfile, params, f, func(dstfile string) error {
+		return ClipImage(srcfile, dstfile, x, y, w, h, f)
+	})
 }
 
 // ClipImage clip image object.
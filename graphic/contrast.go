@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image/color"
+	"math"
+)
+
+// The minimum contrast ratios of the text required by WCAG 2.
+const (
+	ContrastRatioAA      = 4.5
+	ContrastRatioAALarge = 3
+	ContrastRatioAAA     = 7
+)
+
+// the iterations of the binary search in GetReadableForeground
+const readableSearchSteps = 16
+
+// RelativeLuminance returns the relative luminance of the color defined by
+// WCAG 2, in [0..1]. The alpha of the color is ignored.
+func RelativeLuminance(c color.Color) float64 {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return 0.2126*linearize(n.R) + 0.7152*linearize(n.G) + 0.0722*linearize(n.B)
+}
+
+// linearize converts the sRGB channel to the linear one.
+func linearize(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.03928 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// ContrastRatio returns the contrast ratio of the two colors defined by
+// WCAG 2, in [1..21]. The alpha of the colors is ignored.
+func ContrastRatio(c1, c2 color.Color) float64 {
+	return contrastRatio(RelativeLuminance(c1), RelativeLuminance(c2))
+}
+
+func contrastRatio(l1, l2 float64) float64 {
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// GetReadableForeground return the foreground color readable on the
+// background, whose contrast ratio is minRatio at least, such as
+// ContrastRatioAA. The foreground is returned as is if it is readable,
+// otherwise it is darkened or lightened in HSV as little as possible to keep
+// its hue, and black or white which is more readable is returned if
+// minRatio can not be reached.
+func GetReadableForeground(fg, bg color.Color, minRatio float64) color.RGBA {
+	n := color.NRGBAModel.Convert(fg).(color.NRGBA)
+	c := color.RGBA{n.R, n.G, n.B, 0xff}
+	bgLum := RelativeLuminance(bg)
+	if contrastRatio(RelativeLuminance(c), bgLum) >= minRatio {
+		return c
+	}
+
+	h, s, v := Rgb2Hsv(c.R, c.G, c.B)
+	// to black by decreasing the value, and to white by increasing the
+	// value and decreasing the saturation, the luminance changes
+	// monotonically in both of the ways
+	darken := func(t float64) color.RGBA {
+		r, g, b := Hsv2Rgb(h, s, v*(1-t))
+		return color.RGBA{r, g, b, 0xff}
+	}
+	lighten := func(t float64) color.RGBA {
+		r, g, b := Hsv2Rgb(h, s*(1-t), v+(1-v)*t)
+		return color.RGBA{r, g, b, 0xff}
+	}
+
+	var result color.RGBA
+	bestT := math.Inf(1)
+	for _, adjust := range []func(float64) color.RGBA{darken, lighten} {
+		if contrastRatio(RelativeLuminance(adjust(1)), bgLum) < minRatio {
+			continue
+		}
+		lo, hi := 0.0, 1.0
+		for i := 0; i < readableSearchSteps; i++ {
+			mid := (lo + hi) / 2
+			if contrastRatio(RelativeLuminance(adjust(mid)), bgLum) >= minRatio {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		if hi < bestT {
+			result, bestT = adjust(hi), hi
+		}
+	}
+	if !math.IsInf(bestT, 1) {
+		return result
+	}
+
+	black, white := color.RGBA{0, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}
+	if contrastRatio(0, bgLum) >= contrastRatio(1, bgLum) {
+		return black
+	}
+	return white
+}
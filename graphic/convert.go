@@ -20,11 +20,7 @@ func ConvertImage(srcfile, dstfile string, f Format) (err error) {
 // ConvertImageCache converts from any recognized format to cache
 // directory, if already exists, just return it.
 func ConvertImageCache(srcfile string, f Format) (dstfile string, useCache bool, err error) {
-	dstfile = generateCacheFilePath(fmt.Sprintf("ConvertImageCache%s%s", srcfile, f))
-	if isFileExists(dstfile) {
-		useCache = true
-		return
-	}
-	err = ConvertImage(srcfile, dstfile, f)
-	return
+	return getCacheFile(srcfile, fmt.Sprintf("ConvertImageCache%s", f), f, func(dstfile string) error {
+		return ConvertImage(srcfile, dstfile, f)
+	})
 }
@@ -9,7 +9,8 @@ import (
 	"image"
 )
 
-// GetDominantColorOfImage return the dominant hsv color of an image.
+// GetDominantColorOfImage return the dominant hsv color of an image, which
+// is the average color of the pixels, see GetPalette for the main colors.
 func GetDominantColorOfImage(imgfile string) (h, s, v float64, err error) {
 	img, err := LoadImage(imgfile)
 	if err != nil {
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Effect is an operation of the images in the pipeline, see ApplyEffects.
+type Effect struct {
+	// the name and the parameters, which are the key of the cached results
+	name  string
+	apply func(img *image.RGBA) (*image.RGBA, error)
+}
+
+func (e Effect) String() string {
+	return e.name
+}
+
+func formatColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
+
+// EffectGaussianBlur blurs the image, see GaussianBlur.
+func EffectGaussianBlur(sigma float64) Effect {
+	return Effect{fmt.Sprintf("gaussian-blur(%g)", sigma), func(img *image.RGBA) (*image.RGBA, error) {
+		gaussianBlur(img, sigma)
+		return img, nil
+	}}
+}
+
+// EffectBoxBlur blurs the image, see BoxBlur.
+func EffectBoxBlur(radius int) Effect {
+	return Effect{fmt.Sprintf("box-blur(%d)", radius), func(img *image.RGBA) (*image.RGBA, error) {
+		boxBlur(img, []int{radius})
+		return img, nil
+	}}
+}
+
+// EffectBrightness adjusts the brightness, see AdjustBrightness.
+func EffectBrightness(factor float64) Effect {
+	return Effect{fmt.Sprintf("brightness(%g)", factor), func(img *image.RGBA) (*image.RGBA, error) {
+		adjustBrightness(img, factor)
+		return img, nil
+	}}
+}
+
+// EffectContrast adjusts the contrast, see AdjustContrast.
+func EffectContrast(factor float64) Effect {
+	return Effect{fmt.Sprintf("contrast(%g)", factor), func(img *image.RGBA) (*image.RGBA, error) {
+		adjustContrast(img, factor)
+		return img, nil
+	}}
+}
+
+// EffectSaturation adjusts the saturation, see AdjustSaturation.
+func EffectSaturation(factor float64) Effect {
+	return Effect{fmt.Sprintf("saturation(%g)", factor), func(img *image.RGBA) (*image.RGBA, error) {
+		adjustSaturation(img, factor)
+		return img, nil
+	}}
+}
+
+// EffectTint mixes the colors with c, see Tint.
+func EffectTint(c color.Color, amount float64) Effect {
+	return Effect{fmt.Sprintf("tint(%s,%g)", formatColor(c), amount), func(img *image.RGBA) (*image.RGBA, error) {
+		tint(img, c, amount)
+		return img, nil
+	}}
+}
+
+// EffectRoundCorners clips the corners, see RoundCorners.
+func EffectRoundCorners(radius float64) Effect {
+	return Effect{fmt.Sprintf("round-corners(%g)", radius), func(img *image.RGBA) (*image.RGBA, error) {
+		roundCorners(img, radius)
+		return img, nil
+	}}
+}
+
+// EffectDropShadow draws the image over its shadow, see DropShadow.
+func EffectDropShadow(dx, dy int, sigma float64, c color.Color) Effect {
+	return Effect{fmt.Sprintf("drop-shadow(%d,%d,%g,%s)", dx, dy, sigma, formatColor(c)),
+		func(img *image.RGBA) (*image.RGBA, error) {
+			return DropShadow(img, dx, dy, sigma, c), nil
+		}}
+}
+
+// EffectScale resizes the image, see Scale.
+func EffectScale(width, height int, filter ...Filter) Effect {
+	return Effect{fmt.Sprintf("scale(%d,%d,%s)", width, height, getFilter(filter)),
+		func(img *image.RGBA) (*image.RGBA, error) {
+			return Scale(img, width, height, filter...), nil
+		}}
+}
+
+// EffectFill fills the size with the image, see Fill.
+func EffectFill(width, height int, style FillStyle, filter ...Filter) Effect {
+	return Effect{fmt.Sprintf("fill(%d,%d,%s,%s)", width, height, style, getFilter(filter)),
+		func(img *image.RGBA) (*image.RGBA, error) {
+			return Fill(img, width, height, style, filter...)
+		}}
+}
+
+// ApplyEffectsImage applies the effects to the image file in order and
+// save the result in the format.
+func ApplyEffectsImage(srcfile, dstfile string, f Format, effects ...Effect) (err error) {
+	srcimg, err := LoadImage(srcfile)
+	if err != nil {
+		return
+	}
+	dstimg, err := ApplyEffects(srcimg, effects...)
+	if err != nil {
+		return
+	}
+	err = SaveImage(dstfile, dstimg, f)
+	dstimg.Pix = nil
+	return
+}
+
+// ApplyEffectsImageCache applies the effects to the image file and save
+// the result to cache directory, if already exists, just return it.
+func ApplyEffectsImageCache(srcfile string, f Format, effects ...Effect) (dstfile string, useCache bool, err error) {
+	names := make([]string, len(effects))
+	for i, e := range effects {
+		names[i] = e.String()
+	}
+	params := fmt.Sprintf("ApplyEffectsImageCache%s,%s", strings.Join(names, ","), f)
+	return getCacheFile(srcfile, params, f, func(dstfile string) error {
+		return ApplyEffectsImage(srcfile, dstfile, f, effects...)
+	})
+}
+
+// ApplyEffects applies the effects to the image in order, for example,
+// the blurred and darkened background of the screen is
+//
+//	ApplyEffects(img, EffectFill(1920, 1080, FillScale, FilterBox),
+//		EffectGaussianBlur(30), EffectBrightness(0.7))
+//
+// The image is not changed, the effects are applied to its copy in place
+// as possible.
+func ApplyEffects(srcimg image.Image, effects ...Effect) (dstimg *image.RGBA, err error) {
+	dstimg = convertToRGBA(srcimg)
+	for _, e := range effects {
+		dstimg, err = e.apply(dstimg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s: %v", e, err)
+		}
+	}
+	return
+}
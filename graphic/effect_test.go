@@ -0,0 +1,273 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGaussianBlur(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	solid := newTestImage(40, 30, func(x, y int) color.Color { return red })
+	// a vertical white line in black
+	line := newTestImage(101, 5, func(x, y int) color.Color {
+		if x == 50 {
+			return color.White
+		}
+		return color.Black
+	})
+
+	for _, sigma := range []float64{0.5, 2, 3, 8, 20, 100} {
+		blurred := GaussianBlur(solid, sigma)
+		assert.Equal(t, solid.Pix, blurred.Pix, "sigma %v", sigma)
+
+		// the profile of the line is the Gaussian function
+		blurred = GaussianBlur(line, sigma)
+		assert.Equal(t, line.Rect, blurred.Rect)
+		sum := 0
+		for x := 0; x < 101; x++ {
+			c := blurred.RGBAAt(x, 2)
+			sum += int(c.R)
+			assert.Equal(t, uint8(0xff), c.A)
+			if x > 0 && x <= 50 {
+				assert.True(t, c.R >= blurred.RGBAAt(x-1, 2).R)
+			}
+		}
+		if sigma <= 20 {
+			// the energy is kept when the edges are not reached
+			assert.InDelta(t, 0xff, sum, 20, "sigma %v", sigma)
+			peak := 0xff / (sigma * math.Sqrt(2*math.Pi))
+			if sigma >= 2 {
+				assert.InDelta(t, peak, float64(blurred.RGBAAt(50, 2).R), peak*0.1+1, "sigma %v", sigma)
+			}
+		}
+	}
+	assert.Equal(t, line.Pix, GaussianBlur(line, 0).Pix)
+	assert.Equal(t, image.Rect(0, 0, 0, 0), GaussianBlur(image.NewRGBA(image.Rectangle{}), 5).Rect)
+
+	// the transparent pixels do not make the colors dark
+	half := newTestImage(20, 20, func(x, y int) color.Color {
+		if x < 10 {
+			return red
+		}
+		return color.Transparent
+	})
+	blurred := GaussianBlur(half, 4)
+	c := blurred.RGBAAt(10, 10)
+	assert.True(t, c.A > 0x40 && c.A < 0xc0)
+	assert.Equal(t, c.A, c.R)
+	assert.Zero(t, c.G)
+}
+
+func TestBoxBlur(t *testing.T) {
+	img := newTestImage(9, 1, func(x, y int) color.Color {
+		if x == 4 {
+			return color.RGBA{90, 90, 90, 0xff}
+		}
+		return color.Black
+	})
+	blurred := BoxBlur(img, 1)
+	var values []uint8
+	for x := 0; x < 9; x++ {
+		values = append(values, blurred.RGBAAt(x, 0).R)
+	}
+	// blurred in both of the directions, the row is repeated vertically
+	assert.Equal(t, []uint8{0, 0, 0, 30, 30, 30, 0, 0, 0}, values)
+	assert.Equal(t, img.Pix, BoxBlur(img, 0).Pix)
+}
+
+func TestGetBoxesForGauss(t *testing.T) {
+	for _, sigma := range []float64{4, 10, 33.3} {
+		radii := getBoxesForGauss(sigma, 3)
+		require.Len(t, radii, 3)
+		// the variance of the box of width w is (w*w-1)/12
+		variance := 0.0
+		for _, r := range radii {
+			w := float64(2*r + 1)
+			variance += (w*w - 1) / 12
+		}
+		assert.InDelta(t, sigma, math.Sqrt(variance), sigma*0.05)
+	}
+}
+
+func TestAdjustColors(t *testing.T) {
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	orange := color.RGBA{0xff, 0x80, 0x00, 0xff}
+	halfRed := color.RGBA{0x80, 0, 0, 0x80}
+	img := newTestImage(3, 1, func(x, y int) color.Color {
+		return []color.Color{gray, orange, halfRed}[x]
+	})
+
+	assert.Equal(t, img.Pix, AdjustBrightness(img, 1).Pix)
+	assert.Equal(t, img.Pix, AdjustContrast(img, 1).Pix)
+	assert.Equal(t, img.Pix, AdjustSaturation(img, 1).Pix)
+	assert.Equal(t, img.Pix, Tint(img, color.White, 0).Pix)
+
+	result := AdjustBrightness(img, 0.5)
+	assert.Equal(t, color.RGBA{0x40, 0x40, 0x40, 0xff}, result.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0x80, 0x40, 0x00, 0xff}, result.RGBAAt(1, 0))
+	assert.Equal(t, color.RGBA{0x40, 0, 0, 0x80}, result.RGBAAt(2, 0))
+	result = AdjustBrightness(img, 3)
+	assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, result.RGBAAt(0, 0))
+	// not greater than the alpha
+	assert.Equal(t, color.RGBA{0x80, 0, 0, 0x80}, result.RGBAAt(2, 0))
+
+	result = AdjustContrast(img, 0)
+	for x := 0; x < 2; x++ {
+		assert.Equal(t, color.RGBA{0x80, 0x80, 0x80, 0xff}, result.RGBAAt(x, 0))
+	}
+	result = AdjustContrast(img, 2)
+	assert.Equal(t, color.RGBA{0xff, 0x81, 0x00, 0xff}, result.RGBAAt(1, 0))
+
+	result = AdjustSaturation(img, 0)
+	c := result.RGBAAt(1, 0)
+	assert.True(t, c.R == c.G && c.G == c.B)
+	assert.Equal(t, gray, result.RGBAAt(0, 0))
+	_, s0, _ := Rgb2Hsv(0xc0, 0x80, 0x60)
+	more := AdjustSaturation(newTestImage(1, 1, func(x, y int) color.Color {
+		return color.RGBA{0xc0, 0x80, 0x60, 0xff}
+	}), 1.5).RGBAAt(0, 0)
+	_, s1, _ := Rgb2Hsv(more.R, more.G, more.B)
+	assert.True(t, s1 > s0)
+
+	result = Tint(img, color.Black, 0.5)
+	assert.Equal(t, color.RGBA{0x40, 0x40, 0x40, 0xff}, result.RGBAAt(0, 0))
+	result = Tint(img, color.RGBA{0, 0, 0xff, 0xff}, 1)
+	assert.Equal(t, color.RGBA{0, 0, 0xff, 0xff}, result.RGBAAt(1, 0))
+	assert.Equal(t, color.RGBA{0, 0, 0x80, 0x80}, result.RGBAAt(2, 0))
+	// the transparent color tints less
+	result = Tint(img, color.NRGBA{0, 0, 0, 0x80}, 1)
+	assert.Equal(t, color.RGBA{0x40, 0x40, 0x40, 0xff}, result.RGBAAt(0, 0))
+}
+
+func TestRoundCorners(t *testing.T) {
+	white := newTestImage(40, 30, func(x, y int) color.Color { return color.White })
+	img := RoundCorners(white, 10)
+	for _, p := range []image.Point{{0, 0}, {39, 0}, {0, 29}, {39, 29}, {1, 1}} {
+		assert.Equal(t, uint8(0), img.RGBAAt(p.X, p.Y).A, p)
+	}
+	for _, p := range []image.Point{{10, 0}, {0, 10}, {20, 15}, {29, 29}, {5, 5}} {
+		assert.Equal(t, uint8(0xff), img.RGBAAt(p.X, p.Y).A, p)
+	}
+	// antialiased
+	c := img.RGBAAt(2, 3)
+	assert.True(t, c.A > 0 && c.A < 0xff)
+	assert.Equal(t, c.A, c.R)
+	// mirrored
+	assert.Equal(t, c, img.RGBAAt(37, 26))
+
+	// the radius is limited to the half of the height
+	img = RoundCorners(white, 100)
+	assert.Equal(t, uint8(0), img.RGBAAt(3, 3).A)
+	assert.True(t, img.RGBAAt(0, 14).A > 0xf0)
+	assert.Equal(t, uint8(0xff), img.RGBAAt(15, 0).A)
+	assert.Equal(t, white.Pix, RoundCorners(white, 0).Pix)
+}
+
+func TestDropShadow(t *testing.T) {
+	white := newTestImage(20, 10, func(x, y int) color.Color { return color.White })
+	img := DropShadow(white, 4, 6, 2, color.Black)
+	// the margin of the shadow is 6, so the image is moved right by 2
+	assert.Equal(t, image.Rect(0, 0, 2+20+4+6, 10+6+6), img.Rect)
+	for y := 0; y < 10; y++ {
+		for x := 2; x < 22; x++ {
+			assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, img.RGBAAt(x, y))
+		}
+	}
+	assert.Equal(t, uint8(0), img.RGBAAt(0, 0).A)
+	// the shadow is black and blurred
+	c := img.RGBAAt(16, 12)
+	assert.True(t, c.A > 0xf0)
+	assert.Zero(t, c.R)
+	c = img.RGBAAt(26, 12)
+	assert.True(t, c.A > 0x40 && c.A < 0xc0)
+	assert.Zero(t, c.R)
+	assert.Equal(t, uint8(0), img.RGBAAt(31, 21).A)
+	assert.Equal(t, uint8(0), img.RGBAAt(0, 21).A)
+
+	// the shadow above the image
+	img = DropShadow(white, -5, -5, 0, color.NRGBA{0, 0, 0, 0x80})
+	assert.Equal(t, image.Rect(0, 0, 25, 15), img.Rect)
+	assert.Equal(t, color.RGBA{0, 0, 0, 0x80}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, img.RGBAAt(5, 5))
+	assert.Equal(t, uint8(0), img.RGBAAt(24, 0).A)
+}
+
+func TestApplyEffects(t *testing.T) {
+	img := newTestImage(64, 48, func(x, y int) color.Color {
+		return color.RGBA{uint8(x * 4), uint8(y * 5), 0x80, 0xff}
+	})
+	origin := append([]uint8(nil), img.Pix...)
+
+	result, err := ApplyEffects(img)
+	require.NoError(t, err)
+	assert.Equal(t, img.Pix, result.Pix)
+
+	result, err = ApplyEffects(img, EffectFill(32, 32, FillScale, FilterBox),
+		EffectGaussianBlur(5), EffectBrightness(0.7), EffectRoundCorners(4))
+	require.NoError(t, err)
+	// the same as the effects one by one
+	expected, err := Fill(img, 32, 32, FillScale, FilterBox)
+	require.NoError(t, err)
+	expected = RoundCorners(AdjustBrightness(GaussianBlur(expected, 5), 0.7), 4)
+	assert.Equal(t, expected.Pix, result.Pix)
+	// the image is not changed
+	assert.Equal(t, origin, img.Pix)
+
+	_, err = ApplyEffects(img, EffectFill(32, 32, "unknown"))
+	assert.Error(t, err)
+
+	assert.Equal(t, "gaussian-blur(2.5)", EffectGaussianBlur(2.5).String())
+	assert.Equal(t, "tint(#000000ff,0.3)", EffectTint(color.Black, 0.3).String())
+	assert.Equal(t, "scale(10,20,box)", EffectScale(10, 20, FilterBox).String())
+}
+
+func TestApplyEffectsImage(t *testing.T) {
+	resultFile := filepath.Join(t.TempDir(), "effects.png")
+	err := ApplyEffectsImage(originImg, resultFile, FormatPng,
+		EffectFill(192, 108, FillScale, FilterBox), EffectGaussianBlur(20), EffectTint(color.Black, 0.3))
+	require.NoError(t, err)
+	w, h, err := GetImageSize(resultFile)
+	require.NoError(t, err)
+	assert.Equal(t, 192, w)
+	assert.Equal(t, 108, h)
+
+	err = ApplyEffectsImage(originImgNotImage, resultFile, FormatPng, EffectGaussianBlur(20))
+	assert.Error(t, err)
+}
+
+func TestBlurImage(t *testing.T) {
+	resultFile := filepath.Join(t.TempDir(), "blur.png")
+	err := BlurImage(originImgPngSmall, resultFile, 50, FormatPng)
+	require.NoError(t, err)
+	w, h, err := GetImageSize(resultFile)
+	require.NoError(t, err)
+	assert.Equal(t, 200, w)
+	assert.Equal(t, 200, h)
+}
+
+func TestApplyEffectsImageCache(t *testing.T) {
+	effects := []Effect{EffectScale(192, 108, FilterBox), EffectGaussianBlur(10)}
+	_, _, err := ApplyEffectsImageCache(originImg, FormatPng, effects...)
+	if err != nil {
+		t.Skip("Apply effects image cache failed:" + err.Error())
+		return
+	}
+	dstfile, useCache, err := ApplyEffectsImageCache(originImg, FormatPng, effects...)
+	require.NoError(t, err)
+	assert.True(t, useCache)
+
+	// the other effects are cached in the other file
+	otherfile, _, err := ApplyEffectsImageCache(originImg, FormatPng, effects[0], EffectGaussianBlur(11))
+	require.NoError(t, err)
+	assert.NotEqual(t, dstfile, otherfile)
+}
@@ -0,0 +1,348 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package exif reads the EXIF metadata of the JPEG and TIFF images, such
+// as the orientation, the capture time and the camera.
+package exif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoExif is returned when the image has no EXIF metadata.
+	ErrNoExif = errors.New("exif: no exif metadata")
+	// ErrFormat is returned when the image is neither JPEG nor TIFF, or
+	// the metadata is malformed.
+	ErrFormat = errors.New("exif: invalid format")
+)
+
+// Metadata is the EXIF metadata of an image.
+type Metadata struct {
+	Orientation Orientation
+	// DateTime is the time when the photo is taken, or the time when the
+	// image is changed if it is unknown. It is zero if both are unknown.
+	DateTime time.Time
+	// Make and Model are the manufacturer and the model of the camera.
+	Make  string
+	Model string
+	// Width and Height are the dimensions of the image as stored, before
+	// the orientation is applied, zero if unknown.
+	Width  int
+	Height int
+}
+
+// the tags used
+const (
+	tagImageWidth         = 0x0100
+	tagImageLength        = 0x0101
+	tagMake               = 0x010f
+	tagModel              = 0x0110
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagPixelXDimension    = 0xa002
+	tagPixelYDimension    = 0xa003
+)
+
+// the types of the values
+const (
+	typeByte      = 1
+	typeASCII     = 2
+	typeShort     = 3
+	typeLong      = 4
+	typeRational  = 5
+	typeUndefined = 7
+	typeSLong     = 9
+	typeSRational = 10
+)
+
+var typeSizes = map[uint16]uint32{
+	typeByte:      1,
+	typeASCII:     1,
+	typeShort:     2,
+	typeLong:      4,
+	typeRational:  8,
+	typeUndefined: 1,
+	typeSLong:     4,
+	typeSRational: 8,
+}
+
+// the IFDs have at most this many entries, so that the malformed data
+// are not read for long
+const maxIFDEntries = 1000
+
+// DecodeFile reads the EXIF metadata of the JPEG or TIFF file.
+func DecodeFile(file string) (*Metadata, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Decode reads the EXIF metadata of the JPEG or TIFF image from r.
+func Decode(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, ErrFormat
+	}
+	switch {
+	case magic[0] == 0xff && magic[1] == 0xd8:
+		data, err := readJPEGExif(br)
+		if err != nil {
+			return nil, err
+		}
+		return decodeTIFF(bytes.NewReader(data))
+	case string(magic) == "II*\x00" || string(magic) == "MM\x00*":
+		// the offsets of TIFF are from the beginning of the image, which
+		// is read at random without reading all of it if possible
+		if rs, ok := r.(interface {
+			io.ReaderAt
+			io.Seeker
+		}); ok {
+			pos, err := rs.Seek(0, io.SeekCurrent)
+			if err == nil {
+				start := pos - int64(br.Buffered())
+				return decodeTIFF(io.NewSectionReader(rs, start, 1<<62))
+			}
+		}
+		data, err := ioutil.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		return decodeTIFF(bytes.NewReader(data))
+	}
+	return nil, ErrFormat
+}
+
+// readJPEGExif returns the TIFF data of the EXIF APP1 segment.
+func readJPEGExif(r *bufio.Reader) ([]byte, error) {
+	// SOI
+	if _, err := r.Discard(2); err != nil {
+		return nil, ErrFormat
+	}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, ErrNoExif
+		}
+		if b != 0xff {
+			return nil, ErrFormat
+		}
+		marker, err := r.ReadByte()
+		// the fill bytes
+		for err == nil && marker == 0xff {
+			marker, err = r.ReadByte()
+		}
+		if err != nil {
+			return nil, ErrNoExif
+		}
+
+		switch {
+		case marker == 0xd9 || marker == 0xda:
+			// EOI and SOS, the metadata are before the image data
+			return nil, ErrNoExif
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			// no length
+			continue
+		}
+
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			return nil, ErrFormat
+		}
+		length := int(binary.BigEndian.Uint16(lenBuf[:])) - 2
+		if length < 0 {
+			return nil, ErrFormat
+		}
+		if marker != 0xe1 {
+			if _, err := r.Discard(length); err != nil {
+				return nil, ErrFormat
+			}
+			continue
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, ErrFormat
+		}
+		// APP1 is also used by XMP
+		if bytes.HasPrefix(data, []byte("Exif\x00\x00")) {
+			return data[6:], nil
+		}
+	}
+}
+
+type tiffReader struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+}
+
+type ifdEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	// the value itself if it fits in 4 bytes, or the offset of the value
+	value []byte
+}
+
+func decodeTIFF(r io.ReaderAt) (*Metadata, error) {
+	var header [8]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return nil, ErrFormat
+	}
+	tr := &tiffReader{r: r}
+	switch string(header[:4]) {
+	case "II*\x00":
+		tr.order = binary.LittleEndian
+	case "MM\x00*":
+		tr.order = binary.BigEndian
+	default:
+		return nil, ErrFormat
+	}
+
+	ifd0, err := tr.readIFD(tr.order.Uint32(header[4:]))
+	if err != nil {
+		return nil, err
+	}
+	md := &Metadata{Orientation: OrientationNormal}
+	if v, ok := tr.getUint(ifd0, tagOrientation); ok && Orientation(v).IsValid() {
+		md.Orientation = Orientation(v)
+	}
+	md.Make = tr.getString(ifd0, tagMake)
+	md.Model = tr.getString(ifd0, tagModel)
+	if v, ok := tr.getUint(ifd0, tagImageWidth); ok {
+		md.Width = int(v)
+	}
+	if v, ok := tr.getUint(ifd0, tagImageLength); ok {
+		md.Height = int(v)
+	}
+	md.DateTime = parseDateTime(tr.getString(ifd0, tagDateTime), "")
+
+	if offset, ok := tr.getUint(ifd0, tagExifIFD); ok {
+		// the metadata of IFD0 are still useful if the Exif IFD is broken
+		exifIFD, err := tr.readIFD(offset)
+		if err == nil {
+			original := parseDateTime(tr.getString(exifIFD, tagDateTimeOriginal),
+				tr.getString(exifIFD, tagOffsetTimeOriginal))
+			if !original.IsZero() {
+				md.DateTime = original
+			}
+			if v, ok := tr.getUint(exifIFD, tagPixelXDimension); ok && v > 0 {
+				md.Width = int(v)
+			}
+			if v, ok := tr.getUint(exifIFD, tagPixelYDimension); ok && v > 0 {
+				md.Height = int(v)
+			}
+		}
+	}
+	return md, nil
+}
+
+func (tr *tiffReader) readIFD(offset uint32) (map[uint16]ifdEntry, error) {
+	var countBuf [2]byte
+	if _, err := tr.r.ReadAt(countBuf[:], int64(offset)); err != nil {
+		return nil, ErrFormat
+	}
+	count := int(tr.order.Uint16(countBuf[:]))
+	if count > maxIFDEntries {
+		return nil, ErrFormat
+	}
+	buf := make([]byte, count*12)
+	if _, err := tr.r.ReadAt(buf, int64(offset)+2); err != nil {
+		return nil, ErrFormat
+	}
+
+	entries := make(map[uint16]ifdEntry, count)
+	for i := 0; i < count; i++ {
+		b := buf[i*12 : i*12+12]
+		e := ifdEntry{
+			tag:   tr.order.Uint16(b[0:2]),
+			typ:   tr.order.Uint16(b[2:4]),
+			count: tr.order.Uint32(b[4:8]),
+			value: b[8:12],
+		}
+		entries[e.tag] = e
+	}
+	return entries, nil
+}
+
+// getValue returns the bytes of the value of the entry.
+func (tr *tiffReader) getValue(e ifdEntry) ([]byte, bool) {
+	size, ok := typeSizes[e.typ]
+	if !ok || e.count > 1<<16 {
+		return nil, false
+	}
+	size *= e.count
+	if size <= 4 {
+		return e.value[:size], true
+	}
+	data := make([]byte, size)
+	if _, err := tr.r.ReadAt(data, int64(tr.order.Uint32(e.value))); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (tr *tiffReader) getUint(ifd map[uint16]ifdEntry, tag uint16) (uint32, bool) {
+	e, ok := ifd[tag]
+	if !ok || e.count == 0 {
+		return 0, false
+	}
+	switch e.typ {
+	case typeShort:
+		return uint32(tr.order.Uint16(e.value)), true
+	case typeLong:
+		return tr.order.Uint32(e.value), true
+	}
+	return 0, false
+}
+
+func (tr *tiffReader) getString(ifd map[uint16]ifdEntry, tag uint16) string {
+	e, ok := ifd[tag]
+	if !ok || e.typ != typeASCII {
+		return ""
+	}
+	data, ok := tr.getValue(e)
+	if !ok {
+		return ""
+	}
+	if i := bytes.IndexByte(data, 0); i != -1 {
+		data = data[:i]
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseDateTime parses the time such as "2022:01:02 15:04:05", offset is
+// the time zone such as "+08:00", the local time zone is used if it is
+// empty.
+func parseDateTime(s, offset string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if offset != "" {
+		t, err := time.Parse("2006:01:02 15:04:05-07:00", s+offset)
+		if err == nil {
+			return t
+		}
+	}
+	t, err := time.ParseInLocation("2006:01:02 15:04:05", s, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	tag   uint16
+	typ   uint16
+	value interface{}
+}
+
+// buildIFD returns the IFD at offset, the values longer than 4 bytes are
+// put after the IFD.
+func buildIFD(order binary.ByteOrder, offset uint32, entries []testEntry) []byte {
+	var ifd, values bytes.Buffer
+	valuesOffset := offset + 2 + uint32(len(entries))*12 + 4
+	_ = binary.Write(&ifd, order, uint16(len(entries)))
+	for _, e := range entries {
+		var data []byte
+		var count uint32
+		switch v := e.value.(type) {
+		case string:
+			data = append([]byte(v), 0)
+			count = uint32(len(data))
+		case uint16:
+			data = make([]byte, 2)
+			order.PutUint16(data, v)
+			count = 1
+		case uint32:
+			data = make([]byte, 4)
+			order.PutUint32(data, v)
+			count = 1
+		}
+		_ = binary.Write(&ifd, order, e.tag)
+		_ = binary.Write(&ifd, order, e.typ)
+		_ = binary.Write(&ifd, order, count)
+		if len(data) <= 4 {
+			ifd.Write(data)
+			ifd.Write(make([]byte, 4-len(data)))
+		} else {
+			_ = binary.Write(&ifd, order, valuesOffset+uint32(values.Len()))
+			values.Write(data)
+		}
+	}
+	// no next IFD
+	ifd.Write(make([]byte, 4))
+	return append(ifd.Bytes(), values.Bytes()...)
+}
+
+func buildTIFF(order binary.ByteOrder, ifd0, exifIFD []testEntry) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II*\x00")
+	} else {
+		buf.WriteString("MM\x00*")
+	}
+	_ = binary.Write(&buf, order, uint32(8))
+
+	// the Exif IFD is put after IFD0, whose size does not depend on the
+	// offset
+	size := len(buildIFD(order, 0, append(ifd0, testEntry{tagExifIFD, typeLong, uint32(0)})))
+	ifd0 = append(ifd0, testEntry{tagExifIFD, typeLong, uint32(8 + size)})
+	buf.Write(buildIFD(order, 8, ifd0))
+	buf.Write(buildIFD(order, uint32(8+size), exifIFD))
+	return buf.Bytes()
+}
+
+func testIFDs() ([]testEntry, []testEntry) {
+	ifd0 := []testEntry{
+		{tagMake, typeASCII, "Deepin"},
+		{tagModel, typeASCII, "Phone X"},
+		{tagOrientation, typeShort, uint16(6)},
+		{tagDateTime, typeASCII, "2022:05:06 07:08:09"},
+	}
+	exifIFD := []testEntry{
+		{tagDateTimeOriginal, typeASCII, "2022:01:02 03:04:05"},
+		{tagOffsetTimeOriginal, typeASCII, "+08:00"},
+		{tagPixelXDimension, typeLong, uint32(4)},
+		{tagPixelYDimension, typeShort, uint16(2)},
+	}
+	return ifd0, exifIFD
+}
+
+// a 4x2 image whose left half is white and right half is black
+func newTestImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+// buildJPEG returns a JPEG with the EXIF segment of the TIFF data.
+func buildJPEG(t *testing.T, tiff []byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, newTestImage(), nil))
+	data := buf.Bytes()
+
+	var result bytes.Buffer
+	result.Write(data[:2])
+	// a comment before the EXIF segment
+	result.Write([]byte{0xff, 0xfe, 0, 6})
+	result.WriteString("test")
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	result.Write([]byte{0xff, 0xe1})
+	_ = binary.Write(&result, binary.BigEndian, uint16(len(segment)+2))
+	result.Write(segment)
+	result.Write(data[2:])
+	return result.Bytes()
+}
+
+func TestDecodeJPEG(t *testing.T) {
+	ifd0, exifIFD := testIFDs()
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		data := buildJPEG(t, buildTIFF(order, ifd0, exifIFD))
+		md, err := Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, OrientationRotate90, md.Orientation)
+		assert.Equal(t, "Deepin", md.Make)
+		assert.Equal(t, "Phone X", md.Model)
+		assert.Equal(t, 4, md.Width)
+		assert.Equal(t, 2, md.Height)
+		assert.True(t, time.Date(2022, 1, 1, 19, 4, 5, 0, time.UTC).Equal(md.DateTime), md.DateTime)
+	}
+}
+
+func TestDecodeTIFF(t *testing.T) {
+	ifd0 := []testEntry{
+		{tagImageWidth, typeLong, uint32(640)},
+		{tagImageLength, typeShort, uint16(480)},
+		{tagDateTime, typeASCII, "2022:05:06 07:08:09"},
+		{tagOrientation, typeShort, uint16(42)},
+	}
+	data := buildTIFF(binary.BigEndian, ifd0, nil)
+	// also read the files at random
+	file := filepath.Join(t.TempDir(), "test.tiff")
+	require.NoError(t, ioutil.WriteFile(file, data, 0644))
+
+	for _, decode := range []func() (*Metadata, error){
+		func() (*Metadata, error) { return Decode(bytes.NewBuffer(data)) },
+		func() (*Metadata, error) { return DecodeFile(file) },
+	} {
+		md, err := decode()
+		require.NoError(t, err)
+		// the invalid orientation is ignored
+		assert.Equal(t, OrientationNormal, md.Orientation)
+		assert.Equal(t, 640, md.Width)
+		assert.Equal(t, 480, md.Height)
+		assert.Equal(t, time.Date(2022, 5, 6, 7, 8, 9, 0, time.Local), md.DateTime)
+		assert.Empty(t, md.Make)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, newTestImage(), nil))
+	_, err := Decode(&buf)
+	assert.Equal(t, ErrNoExif, err)
+
+	_, err = Decode(bytes.NewReader([]byte("\x89PNG\r\n\x1a\n")))
+	assert.Equal(t, ErrFormat, err)
+
+	// the broken IFD
+	_, err = Decode(bytes.NewReader([]byte("II*\x00\xff\xff\x00\x00")))
+	assert.Equal(t, ErrFormat, err)
+
+	_, err = DecodeFile("testdata/not-exist")
+	assert.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	img := newTestImage()
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	black := color.RGBA{0, 0, 0, 0xff}
+
+	assert.Same(t, img, Apply(img, OrientationNormal))
+	assert.Same(t, img, Apply(img, 0))
+
+	var tests = []struct {
+		o      Orientation
+		bounds image.Rectangle
+		// the colors of the top left and the bottom right
+		topLeft, bottomRight color.RGBA
+	}{
+		{OrientationFlipH, image.Rect(0, 0, 4, 2), black, white},
+		{OrientationRotate180, image.Rect(0, 0, 4, 2), black, white},
+		{OrientationFlipV, image.Rect(0, 0, 4, 2), white, black},
+		{OrientationTranspose, image.Rect(0, 0, 2, 4), white, black},
+		{OrientationRotate90, image.Rect(0, 0, 2, 4), white, black},
+		{OrientationTransverse, image.Rect(0, 0, 2, 4), black, white},
+		{OrientationRotate270, image.Rect(0, 0, 2, 4), black, white},
+	}
+	for _, test := range tests {
+		result := Apply(img, test.o).(*image.RGBA)
+		require.Equal(t, test.bounds, result.Bounds(), test.o)
+		max := test.bounds.Max
+		assert.Equal(t, test.topLeft, result.RGBAAt(0, 0), test.o)
+		assert.Equal(t, test.bottomRight, result.RGBAAt(max.X-1, max.Y-1), test.o)
+	}
+
+	// rotating right 4 times is the same
+	var result image.Image = img
+	for i := 0; i < 4; i++ {
+		result = Apply(result, OrientationRotate90)
+	}
+	assert.Equal(t, img.Pix, result.(*image.RGBA).Pix)
+}
+
+func TestStripJPEG(t *testing.T) {
+	ifd0, exifIFD := testIFDs()
+	data := buildJPEG(t, buildTIFF(binary.LittleEndian, ifd0, exifIFD))
+
+	var buf bytes.Buffer
+	require.NoError(t, StripJPEG(&buf, bytes.NewReader(data)))
+	assert.NotContains(t, buf.String(), "Exif")
+	assert.NotContains(t, buf.String(), "test")
+	_, err := Decode(bytes.NewReader(buf.Bytes()))
+	assert.Equal(t, ErrNoExif, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	orig, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, orig, img)
+
+	assert.Equal(t, ErrFormat, StripJPEG(&buf, bytes.NewReader([]byte("not a jpeg"))))
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package exif
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Orientation is how the image is transformed to be shown upright.
+type Orientation int
+
+// The orientations defined by EXIF, the rotations are clockwise.
+const (
+	OrientationNormal     Orientation = 1
+	OrientationFlipH      Orientation = 2
+	OrientationRotate180  Orientation = 3
+	OrientationFlipV      Orientation = 4
+	OrientationTranspose  Orientation = 5
+	OrientationRotate90   Orientation = 6
+	OrientationTransverse Orientation = 7
+	OrientationRotate270  Orientation = 8
+)
+
+// IsValid reports whether o is one of the orientations defined.
+func (o Orientation) IsValid() bool {
+	return o >= OrientationNormal && o <= OrientationRotate270
+}
+
+// SwapsSize reports whether the width and the height of the image are
+// swapped when the orientation is applied.
+func (o Orientation) SwapsSize() bool {
+	return o >= OrientationTranspose && o <= OrientationRotate270
+}
+
+// Apply returns the image transformed by the orientation to be shown
+// upright, img itself is returned if nothing is to do.
+func Apply(img image.Image, o Orientation) image.Image {
+	if !o.IsValid() || o == OrientationNormal {
+		return img
+	}
+
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok || src.Rect.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if o.SwapsSize() {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// the pixel of the source shown at x, y
+			var sx, sy int
+			switch o {
+			case OrientationFlipH:
+				sx, sy = w-1-x, y
+			case OrientationRotate180:
+				sx, sy = w-1-x, h-1-y
+			case OrientationFlipV:
+				sx, sy = x, h-1-y
+			case OrientationTranspose:
+				sx, sy = y, x
+			case OrientationRotate90:
+				sx, sy = y, h-1-x
+			case OrientationTransverse:
+				sx, sy = w-1-y, h-1-x
+			case OrientationRotate270:
+				sx, sy = w-1-y, x
+			}
+			i := src.PixOffset(sx, sy)
+			copy(dst.Pix[dst.PixOffset(x, y):], src.Pix[i:i+4])
+		}
+	}
+	return dst
+}
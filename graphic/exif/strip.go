@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package exif
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// StripJPEG copies the JPEG image from r to w without the metadata, the
+// EXIF, XMP and IPTC segments and the comments, the image data is copied
+// as is. The color profile is kept. Note that the orientation is removed
+// too, the image should be rotated if its orientation is not normal.
+func StripJPEG(w io.Writer, r io.Reader) error {
+	br := bufio.NewReader(r)
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil || soi != [2]byte{0xff, 0xd8} {
+		return ErrFormat
+	}
+	if _, err := w.Write(soi[:]); err != nil {
+		return err
+	}
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil || b != 0xff {
+			return ErrFormat
+		}
+		marker, err := br.ReadByte()
+		for err == nil && marker == 0xff {
+			marker, err = br.ReadByte()
+		}
+		if err != nil {
+			return ErrFormat
+		}
+
+		if marker == 0xd9 || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			// no length
+			if _, err := w.Write([]byte{0xff, marker}); err != nil {
+				return err
+			}
+			if marker == 0xd9 {
+				return nil
+			}
+			continue
+		}
+
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(br, lenBuf[:]); err != nil {
+			return ErrFormat
+		}
+		length := int64(binary.BigEndian.Uint16(lenBuf[:])) - 2
+		if length < 0 {
+			return ErrFormat
+		}
+		// APP1 for EXIF and XMP, APP13 for IPTC, COM for the comments
+		if marker == 0xe1 || marker == 0xed || marker == 0xfe {
+			if _, err := br.Discard(int(length)); err != nil {
+				return ErrFormat
+			}
+			continue
+		}
+
+		if _, err := w.Write([]byte{0xff, marker, lenBuf[0], lenBuf[1]}); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, br, length); err != nil {
+			return err
+		}
+		if marker == 0xda {
+			// SOS, the rest is the image data
+			_, err := io.Copy(w, br)
+			return err
+		}
+	}
+}
@@ -18,16 +18,17 @@ type FillStyle string
 const (
 	FillTile   FillStyle = "tile"   // 平铺
 	FillCenter FillStyle = "center" // 居中
+	FillScale  FillStyle = "scale"  // 缩放, 保持比例并裁剪超出的部分
 )
 
 // FillImage generate a new image file in target width and height through
 // source image file, there are many fill sytles to choice from.
-func FillImage(srcfile, dstfile string, width, height int, style FillStyle, f Format) (err error) {
+func FillImage(srcfile, dstfile string, width, height int, style FillStyle, f Format, filter ...Filter) (err error) {
 	srcimg, err := LoadImage(srcfile)
 	if err != nil {
 		return
 	}
-	dstimg, err := Fill(srcimg, width, height, style)
+	dstimg, err := Fill(srcimg, width, height, style, filter...)
 	if err != nil {
 		return
 	}
@@ -39,25 +40,24 @@ func FillImage(srcfile, dstfile string, width, height int, style FillStyle, f Fo
 // FillImageCache generate a new image in target width and height through
 // source image, and save it to cache directory, if already exists,
 // just return it.
-func FillImageCache(srcfile string, width, height int, style FillStyle, f Format) (dstfile string, useCache bool, err error) {
-	dstfile = generateCacheFilePath(fmt.Sprintf("FillImageCache%s%d%d%s%s", srcfile, width, height, style, f))
-	if isFileExists(dstfile) {
-		// return cache file
-		useCache = true
-		return
-	}
-	err = FillImage(srcfile, dstfile, width, height, style, f)
-	return
+func FillImageCache(srcfile string, width, height int, style FillStyle, f Format, filter ...Filter) (dstfile string, useCache bool, err error) {
+	params := fmt.Sprintf("FillImageCache%d,%d,%s,%s,%s", width, height, style, f, getFilter(filter))
+	return getCacheFile(srcfile, params, f, func(dstfile string) error {
+		return FillImage(srcfile, dstfile, width, height, style, f, filter...)
+	})
 }
 
 // FillImage generate a new image in target width and height through
-// source image, there are many fill sytles to choice from.
-func Fill(srcimg image.Image, width, height int, style FillStyle) (dstimg *image.RGBA, err error) {
+// source image, there are many fill sytles to choice from. The filter is
+// only used by FillScale, see Scale.
+func Fill(srcimg image.Image, width, height int, style FillStyle, filter ...Filter) (dstimg *image.RGBA, err error) {
 	switch style {
 	case FillTile:
 		dstimg = doFillImageInTileStyle(srcimg, width, height)
 	case FillCenter:
 		dstimg = doFillImageInCenterStyle(srcimg, width, height)
+	case FillScale:
+		dstimg, err = ScalePrefer(srcimg, width, height, filter...)
 	default:
 		err = fmt.Errorf("unknown fill style %v", style)
 		return
@@ -6,6 +6,7 @@ package graphic
 
 import (
 	"bufio"
+	"bytes"
 	"os"
 )
 
@@ -21,8 +22,12 @@ var formats = []format{
 	{"tiff", "MM\x00\x2A"}, // little-endian
 	{"tiff", "II\x2A\x00"}, // big-endian
 	{"gif", "GIF8?a"},
+	{"webp", "RIFF????WEBPVP8"},
 }
 
+// the SVG images should have the <svg> tag in the beginning
+const svgSniffLen = 1024
+
 // Sniff determines the format of r's data.
 func sniff(r *bufio.Reader) format {
 	for _, f := range formats {
@@ -31,9 +36,25 @@ func sniff(r *bufio.Reader) format {
 			return f
 		}
 	}
+	if isSVG(r) {
+		return format{name: "svg"}
+	}
 	return format{}
 }
 
+// isSVG reports whether r's data is an SVG image, which is XML with the
+// <svg> tag after the XML declaration, the comments or the DOCTYPE.
+func isSVG(r *bufio.Reader) bool {
+	// the error is ignored since the file may be shorter
+	b, _ := r.Peek(svgSniffLen)
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	b = bytes.TrimLeft(b, " \t\r\n")
+	if !bytes.HasPrefix(b, []byte("<")) {
+		return false
+	}
+	return bytes.Contains(b, []byte("<svg"))
+}
+
 // Match reports whether magic matches b. Magic may contain "?" wildcards.
 func match(magic string, b []byte) bool {
 	if len(magic) != len(b) {
@@ -13,4 +13,9 @@ const (
 	FormatJpeg Format = "jpeg"
 	FormatBmp  Format = "bmp"
 	FormatTiff Format = "tiff"
+	FormatGif  Format = "gif"
+	FormatWebp Format = "webp"
+	// FormatSvg can be loaded but not saved, the images are saved in png
+	// instead.
+	FormatSvg Format = "svg"
 )
@@ -5,7 +5,13 @@
 package graphic
 
 import (
+	"image"
+	"image/color"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/linuxdeepin/go-lib/graphic/exif"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,6 +32,12 @@ const (
 	originIconHeight  = 48
 
 	originImgNotImage = "testdata/origin_not_image"
+
+	originImgExifRotate90 = "testdata/exif_rotate90_40x20.jpg"
+
+	originImgWebpIcon1 = "testdata/origin_icon_1_48x48.webp"
+	originImgSvgIcon   = "testdata/origin_icon_32x32.svg"
+	originImgGifAnim   = "testdata/anim_3frames_16x16.gif"
 )
 
 // data uri for originImgPngIcon2
@@ -45,6 +57,160 @@ func TestLoadImage(t *testing.T) {
 	}
 }
 
+func TestLoadImageOrientation(t *testing.T) {
+	// the left half is white, rotated to the top half
+	img, err := LoadImage(originImgExifRotate90)
+	require.NoError(t, err)
+	w, h := GetSize(img)
+	assert.Equal(t, 20, w)
+	assert.Equal(t, 40, h)
+	r, g, b, _ := img.At(10, 5).RGBA()
+	assert.True(t, r > 0xf000 && g > 0xf000 && b > 0xf000)
+	r, g, b, _ = img.At(10, 35).RGBA()
+	assert.True(t, r < 0x1000 && g < 0x1000 && b < 0x1000)
+
+	w, h, err = GetImageSize(originImgExifRotate90)
+	require.NoError(t, err)
+	assert.Equal(t, 20, w)
+	assert.Equal(t, 40, h)
+}
+
+func TestStripImageMetadata(t *testing.T) {
+	resultFile := filepath.Join(t.TempDir(), "strip.jpg")
+	err := StripImageMetadata(originImgExifRotate90, resultFile)
+	require.NoError(t, err)
+	_, err = exif.DecodeFile(resultFile)
+	assert.Equal(t, exif.ErrNoExif, err)
+	// rotated since the orientation is removed
+	w, h, err := GetImageSize(resultFile)
+	require.NoError(t, err)
+	assert.Equal(t, 20, w)
+	assert.Equal(t, 40, h)
+
+	// copied as is
+	err = StripImageMetadata(originImg, resultFile)
+	require.NoError(t, err)
+	w, h, err = GetImageSize(resultFile)
+	require.NoError(t, err)
+	assert.Equal(t, originImgWidth, w)
+	assert.Equal(t, originImgHeight, h)
+}
+
+func TestLoadImageWebp(t *testing.T) {
+	img, err := LoadImage(originImgWebpIcon1)
+	require.NoError(t, err)
+	origin, err := LoadImage(originImgPngIcon1)
+	require.NoError(t, err)
+	// saved losslessly
+	assert.Equal(t, convertToRGBA(origin).Pix, convertToRGBA(img).Pix)
+}
+
+func TestLoadImageSvg(t *testing.T) {
+	img, err := LoadImage(originImgSvgIcon)
+	require.NoError(t, err)
+	w, h := GetSize(img)
+	assert.Equal(t, 32, w)
+	assert.Equal(t, 32, h)
+	r, g, b, a := img.At(16, 4).RGBA()
+	assert.Equal(t, []uint32{0xffff, 0, 0, 0xffff}, []uint32{r, g, b, a})
+	r, g, b, a = img.At(16, 24).RGBA()
+	assert.Equal(t, []uint32{0, 0, 0xffff, 0xffff}, []uint32{r, g, b, a})
+	_, _, _, a = img.At(1, 30).RGBA()
+	assert.Equal(t, uint32(0), a)
+
+	w, h, err = GetImageSize(originImgSvgIcon)
+	require.NoError(t, err)
+	assert.Equal(t, 32, w)
+	assert.Equal(t, 32, h)
+	format, err := GetImageFormat(originImgSvgIcon)
+	require.NoError(t, err)
+	assert.Equal(t, FormatSvg, format)
+	assert.True(t, IsSupportedImage(originImgSvgIcon))
+}
+
+func TestLoadImageAtSize(t *testing.T) {
+	var datas = []struct {
+		file          string
+		width, height int
+		resultWidth   int
+		resultHeight  int
+	}{
+		{originImgSvgIcon, 64, 64, 64, 64},
+		{originImgSvgIcon, 0, 128, 128, 128},
+		{originImgSvgIcon, 0, 0, 32, 32},
+		{originImg, 480, 0, 480, 270},
+		{originImg, 0, 540, 960, 540},
+		{originImg, 100, 100, 100, 100},
+		{originImgPngIcon1, 0, 0, originIconWidth, originIconHeight},
+	}
+	for _, data := range datas {
+		img, err := LoadImageAtSize(data.file, data.width, data.height)
+		require.NoError(t, err)
+		w, h := GetSize(img)
+		assert.Equal(t, data.resultWidth, w, data.file)
+		assert.Equal(t, data.resultHeight, h, data.file)
+	}
+
+	// rendered instead of scaled, the bottom edge of the rect is sharp
+	img, err := LoadImageAtSize(originImgSvgIcon, 256, 256)
+	require.NoError(t, err)
+	_, _, _, a := img.At(10, 127).RGBA()
+	assert.Equal(t, uint32(0xffff), a)
+	_, _, _, a = img.At(10, 128).RGBA()
+	assert.Equal(t, uint32(0), a)
+}
+
+func TestLoadAnimation(t *testing.T) {
+	anim, err := LoadAnimation(originImgGifAnim)
+	require.NoError(t, err)
+	require.Len(t, anim.Frames, 3)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond,
+		500 * time.Millisecond}, anim.Delays)
+	assert.Equal(t, 0, anim.LoopCount)
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	for _, frame := range anim.Frames {
+		assert.Equal(t, image.Rect(0, 0, 16, 16), frame.Bounds())
+		assert.Equal(t, red, frame.RGBAAt(0, 0))
+		assert.Equal(t, red, frame.RGBAAt(15, 15))
+	}
+	assert.Equal(t, red, anim.Frames[0].RGBAAt(5, 5))
+	assert.Equal(t, blue, anim.Frames[1].RGBAAt(5, 5))
+	// the square is disposed to the background
+	assert.Equal(t, color.RGBA{}, anim.Frames[2].RGBAAt(5, 5))
+
+	anim, err = LoadAnimation(originImgPngIcon1)
+	require.NoError(t, err)
+	require.Len(t, anim.Frames, 1)
+	assert.Equal(t, -1, anim.LoopCount)
+
+	_, err = LoadAnimation(originImgNotImage)
+	assert.Error(t, err)
+}
+
+func TestSaveImage(t *testing.T) {
+	origin, err := LoadImage(originImgPngIcon1)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	for _, f := range []Format{FormatPng, FormatBmp, FormatTiff, FormatGif, FormatWebp} {
+		resultFile := filepath.Join(dir, "icon."+string(f))
+		err = SaveImage(resultFile, origin, f)
+		require.NoError(t, err)
+		format, err := GetImageFormat(resultFile)
+		require.NoError(t, err)
+		assert.Equal(t, f, format)
+	}
+
+	// svg can not be saved
+	resultFile := filepath.Join(dir, "icon.svg")
+	err = SaveImage(resultFile, origin, FormatSvg)
+	require.NoError(t, err)
+	format, err := GetImageFormat(resultFile)
+	require.NoError(t, err)
+	assert.Equal(t, FormatPng, format)
+}
+
 func TestCompositeImage(t *testing.T) {
 	resultFile := "testdata/test_compositeimage.png"
 	err := CompositeImage(originImgPngSmall, originImgPngIcon1, resultFile, 0, 0, FormatPng)
@@ -454,6 +620,14 @@ func TestSniffImageFormat(t *testing.T) {
 			file:       "testdata/sniff_format.tiff",
 			formatName: "tiff",
 		},
+		{
+			file:       originImgWebpIcon1,
+			formatName: "webp",
+		},
+		{
+			file:       originImgSvgIcon,
+			formatName: "svg",
+		},
 	}
 
 	for _, data := range datas {
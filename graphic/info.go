@@ -5,20 +5,42 @@
 package graphic
 
 import (
+	"bufio"
 	"image"
+	"io"
 	"os"
+	"github.com/linuxdeepin/go-lib/graphic/svg"
 	dutils "github.com/linuxdeepin/go-lib/utils"
 )
 
-// GetImageSize return image's width and height.
+// GetImageSize return image's width and height, they are swapped if the
+// image is rotated by its EXIF orientation, the same as LoadImage.
 func GetImageSize(imgfile string) (w, h int, err error) {
 	f, err := os.Open(imgfile)
 	if err != nil {
 		return
 	}
 	defer f.Close()
-	config, _, err := image.DecodeConfig(f)
-	return config.Width, config.Height, err
+	config, _, err := decodeConfig(f)
+	if err != nil {
+		return
+	}
+	if getOrientation(f).SwapsSize() {
+		return config.Height, config.Width, nil
+	}
+	return config.Width, config.Height, nil
+}
+
+// decodeConfig is the same as image.DecodeConfig, but the SVG images
+// which do not start with the XML declaration or the <svg> tag are also
+// recognized.
+func decodeConfig(r io.Reader) (image.Config, string, error) {
+	br := bufio.NewReader(r)
+	if sniff(br).name == string(FormatSvg) {
+		config, err := svg.DecodeConfig(br)
+		return config, string(FormatSvg), err
+	}
+	return image.DecodeConfig(br)
 }
 
 func GetSize(img image.Image) (w, h int) {
@@ -34,7 +56,7 @@ func GetImageFormat(imgfile string) (format Format, err error) {
 		return
 	}
 	defer f.Close()
-	_, name, err := image.DecodeConfig(f)
+	_, name, err := decodeConfig(f)
 	format = Format(name)
 	return
 }
@@ -46,7 +68,7 @@ func IsSupportedImage(imgfile string) bool {
 		return false
 	}
 	defer f.Close()
-	_, _, err = image.DecodeConfig(f)
+	_, _, err = decodeConfig(f)
 	return err == nil
 }
 
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package jpeg
+
+import "math"
+
+// idctTables are the cosines of the inverse DCT to n*n pixels, n is 1, 2,
+// 4 or 8. The n pixels are sampled from the 8 pixels of the full size at
+// the centers of the n parts, which are
+//
+//	f(x) = 1/2 * sum(C(u) * F(u) * cos((2x+1)*u*pi/(2n))), u < n
+//
+// where C(0) is 1/sqrt(2) and the others are 1.
+var idctTables = func() (tables [9][]float32) {
+	for _, n := range []int{1, 2, 4, 8} {
+		t := make([]float32, n*n)
+		for x := 0; x < n; x++ {
+			for u := 0; u < n; u++ {
+				c := 0.5
+				if u == 0 {
+					c = 0.5 / math.Sqrt2
+				}
+				t[x*n+u] = float32(c * math.Cos(float64((2*x+1)*u)*math.Pi/float64(2*n)))
+			}
+		}
+		tables[n] = t
+	}
+	return
+}()
+
+// reconstruct dequantizes the block in the natural order and transforms it
+// to the pixels of the component.
+func (d *decoder) reconstruct(c *component, bx, by int, block *[64]int32) {
+	n := d.n
+	q := &d.quant[c.tq]
+	t := idctTables[n]
+	pix := c.pix[by*n*c.stride+bx*n:]
+
+	if n == 1 {
+		pix[0] = clampPixel(float32(block[0]*q[0])/8 + 128)
+		return
+	}
+
+	// the rows and then the columns, only the low frequencies are used
+	var tmp [64]float32
+	for v := 0; v < n; v++ {
+		for x := 0; x < n; x++ {
+			var sum float32
+			for u := 0; u < n; u++ {
+				sum += t[x*n+u] * float32(block[v*8+u]*q[v*8+u])
+			}
+			tmp[v*n+x] = sum
+		}
+	}
+	for y := 0; y < n; y++ {
+		row := pix[y*c.stride:]
+		for x := 0; x < n; x++ {
+			var sum float32
+			for v := 0; v < n; v++ {
+				sum += t[y*n+v] * tmp[v*n+x]
+			}
+			row[x] = clampPixel(sum + 128)
+		}
+	}
+}
+
+// reconstructProgressive transforms the coefficients of all the blocks
+// after the scans of the progressive image.
+func (d *decoder) reconstructProgressive() error {
+	var block [64]int32
+	for i := range d.comps {
+		c := &d.comps[i]
+		for by := 0; by < c.blocksY; by++ {
+			if err := d.ctx.Err(); err != nil {
+				return err
+			}
+			for bx := 0; bx < c.blocksX; bx++ {
+				coefs := c.coefs[(by*c.blocksX+bx)*64:][:64]
+				for k, v := range coefs {
+					block[k] = int32(v)
+				}
+				d.reconstruct(c, bx, by, &block)
+			}
+		}
+		c.coefs = nil
+	}
+	return nil
+}
+
+func clampPixel(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
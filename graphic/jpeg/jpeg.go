@@ -0,0 +1,430 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package jpeg decodes the JPEG images scaled down while decoding, by the
+// DCT scaling as libjpeg does. Only the low frequencies of each 8x8 block
+// are transformed back to 4x4, 2x2 or 1x1 pixels, so the large photos are
+// decoded into the thumbnails fast and with little memory.
+//
+// The baseline and the progressive images in 8 bits with the Huffman
+// coding are supported, in grayscale, YCbCr or RGB. The others, such as the
+// CMYK images, are reported by ErrUnsupported, they can be decoded by
+// image/jpeg in the full size.
+package jpeg
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+var (
+	// ErrFormat is returned if the data is not a valid JPEG image.
+	ErrFormat = errors.New("jpeg: invalid format")
+	// ErrUnsupported is returned if the image is valid but can not be
+	// scaled, see the package document.
+	ErrUnsupported = errors.New("jpeg: unsupported image")
+	// ErrDenom is returned if the scale denominator is not 1, 2, 4 or 8.
+	ErrDenom = errors.New("jpeg: invalid scale denominator")
+)
+
+// the markers
+const (
+	markerSOF0 = 0xc0 // baseline
+	markerSOF1 = 0xc1 // extended sequential, Huffman
+	markerSOF2 = 0xc2 // progressive, Huffman
+	markerDHT  = 0xc4
+	markerRST0 = 0xd0
+	markerRST7 = 0xd7
+	markerSOI  = 0xd8
+	markerEOI  = 0xd9
+	markerSOS  = 0xda
+	markerDQT  = 0xdb
+	markerDRI  = 0xdd
+	markerAPP0 = 0xe0
+	markerAPPE = 0xee
+)
+
+// DecodeScaled reads a JPEG image from r and returns it scaled down by
+// 1/denom, the denom is 1, 2, 4 or 8. The size of the result is the size
+// of the image divided by denom and rounded up. The images not scaled are
+// decoded by image/jpeg.
+func DecodeScaled(r io.Reader, denom int) (image.Image, error) {
+	return DecodeScaledContext(context.Background(), r, denom)
+}
+
+// DecodeScaledContext decodes the image as DecodeScaled, the decoding is
+// stopped with the error of ctx once ctx is done, which is checked for each
+// row of MCUs. The images not scaled are decoded by image/jpeg, which is
+// stopped only by the errors of r.
+func DecodeScaledContext(ctx context.Context, r io.Reader, denom int) (image.Image, error) {
+	switch denom {
+	case 1:
+		return jpeg.Decode(r)
+	case 2, 4, 8:
+	default:
+		return nil, ErrDenom
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decode(ctx, data, 8/denom)
+}
+
+// GetScaleDenom returns the largest denominator of DecodeScaled with which
+// the image of width and height is scaled to minWidth and minHeight at
+// least, the sizes which are not positive are not limited.
+func GetScaleDenom(width, height, minWidth, minHeight int) int {
+	for _, denom := range []int{8, 4, 2} {
+		if (minWidth <= 0 || scaledSize(width, denom) >= minWidth) &&
+			(minHeight <= 0 || scaledSize(height, denom) >= minHeight) {
+			return denom
+		}
+	}
+	return 1
+}
+
+func scaledSize(size, denom int) int {
+	return (size + denom - 1) / denom
+}
+
+type component struct {
+	id uint8
+	// the sampling factors
+	h, v int
+	// the quantization table
+	tq uint8
+	// the blocks of the component, padded to the MCUs
+	blocksX, blocksY int
+	// the coefficients of the progressive images, 64 per block in the
+	// natural order
+	coefs []int16
+	// the pixels decoded, n*n per block
+	pix    []uint8
+	stride int
+	dcPred int32
+}
+
+type decoder struct {
+	ctx  context.Context
+	data []byte
+	pos  int
+	// the bits read, aligned to the most significant bit
+	bits  uint32
+	nbits uint
+	// the marker which ends the entropy coded data
+	hitMarker bool
+
+	// the pixels of each block side decoded
+	n             int
+	width, height int
+	progressive   bool
+	comps         []component
+	hmax, vmax    int
+	mcusX, mcusY  int
+	// in the natural order
+	quant           [4][64]int32
+	huff            [2][4]*huffman
+	restartInterval int
+	eobrun          int
+
+	jfif           bool
+	adobe          bool
+	adobeTransform uint8
+}
+
+func decode(ctx context.Context, data []byte, n int) (image.Image, error) {
+	d := &decoder{ctx: ctx, data: data, n: n}
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+	if d.progressive {
+		if err := d.reconstructProgressive(); err != nil {
+			return nil, err
+		}
+	}
+	return d.image(), nil
+}
+
+func (d *decoder) parse() error {
+	if len(d.data) < 2 || d.data[0] != 0xff || d.data[1] != markerSOI {
+		return ErrFormat
+	}
+	d.pos = 2
+	scans := 0
+	for {
+		marker, err := d.nextMarker()
+		if err != nil {
+			if err == io.ErrUnexpectedEOF && scans > 0 {
+				// the truncated images are decoded as possible
+				return nil
+			}
+			return err
+		}
+		if marker == markerEOI {
+			if scans == 0 {
+				return ErrFormat
+			}
+			return nil
+		}
+		if marker >= markerRST0 && marker <= markerRST7 {
+			// no length
+			continue
+		}
+		if d.pos+2 > len(d.data) {
+			return io.ErrUnexpectedEOF
+		}
+		length := int(d.data[d.pos])<<8 | int(d.data[d.pos+1]) - 2
+		d.pos += 2
+		if length < 0 || d.pos+length > len(d.data) {
+			return io.ErrUnexpectedEOF
+		}
+		seg := d.data[d.pos : d.pos+length]
+		d.pos += length
+
+		switch {
+		case marker == markerSOF0 || marker == markerSOF1 || marker == markerSOF2:
+			if d.comps != nil {
+				return ErrFormat
+			}
+			d.progressive = marker == markerSOF2
+			err = d.parseSOF(seg)
+		case marker >= 0xc3 && marker <= 0xcf && marker != markerDHT && marker != 0xc8 && marker != 0xcc:
+			// lossless, hierarchical or arithmetic coding
+			return ErrUnsupported
+		case marker == markerDHT:
+			err = d.parseDHT(seg)
+		case marker == markerDQT:
+			err = d.parseDQT(seg)
+		case marker == markerDRI:
+			if len(seg) != 2 {
+				return ErrFormat
+			}
+			d.restartInterval = int(seg[0])<<8 | int(seg[1])
+		case marker == markerAPP0:
+			d.jfif = len(seg) >= 5 && string(seg[:5]) == "JFIF\x00"
+		case marker == markerAPPE:
+			if len(seg) >= 12 && string(seg[:5]) == "Adobe" {
+				d.adobe = true
+				d.adobeTransform = seg[11]
+			}
+		case marker == markerSOS:
+			if d.comps == nil {
+				return ErrFormat
+			}
+			err = d.parseSOS(seg)
+			scans++
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// nextMarker skips to the next marker and returns it.
+func (d *decoder) nextMarker() (byte, error) {
+	for {
+		for d.pos < len(d.data) && d.data[d.pos] != 0xff {
+			d.pos++
+		}
+		// skip the fill bytes
+		for d.pos < len(d.data) && d.data[d.pos] == 0xff {
+			d.pos++
+		}
+		if d.pos >= len(d.data) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		marker := d.data[d.pos]
+		d.pos++
+		if marker != 0 {
+			return marker, nil
+		}
+	}
+}
+
+func (d *decoder) parseSOF(seg []byte) error {
+	if len(seg) < 6 {
+		return ErrFormat
+	}
+	if seg[0] != 8 {
+		return ErrUnsupported
+	}
+	d.height = int(seg[1])<<8 | int(seg[2])
+	d.width = int(seg[3])<<8 | int(seg[4])
+	ncomps := int(seg[5])
+	if d.width == 0 || d.height == 0 {
+		// the height defined by DNL
+		return ErrUnsupported
+	}
+	if ncomps != 1 && ncomps != 3 {
+		return ErrUnsupported
+	}
+	if len(seg) != 6+3*ncomps {
+		return ErrFormat
+	}
+
+	d.comps = make([]component, ncomps)
+	d.hmax, d.vmax = 1, 1
+	for i := range d.comps {
+		c := &d.comps[i]
+		c.id = seg[6+3*i]
+		c.h, c.v = int(seg[7+3*i]>>4), int(seg[7+3*i]&0xf)
+		c.tq = seg[8+3*i]
+		if c.h < 1 || c.h > 4 || c.v < 1 || c.v > 4 || c.tq > 3 {
+			return ErrFormat
+		}
+		if ncomps == 1 {
+			// the MCU is one block
+			c.h, c.v = 1, 1
+		}
+		if c.h > d.hmax {
+			d.hmax = c.h
+		}
+		if c.v > d.vmax {
+			d.vmax = c.v
+		}
+	}
+	d.mcusX = (d.width + 8*d.hmax - 1) / (8 * d.hmax)
+	d.mcusY = (d.height + 8*d.vmax - 1) / (8 * d.vmax)
+	for i := range d.comps {
+		c := &d.comps[i]
+		c.blocksX, c.blocksY = d.mcusX*c.h, d.mcusY*c.v
+		c.stride = c.blocksX * d.n
+		c.pix = make([]uint8, c.stride*c.blocksY*d.n)
+		if d.progressive {
+			c.coefs = make([]int16, c.blocksX*c.blocksY*64)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) parseDQT(seg []byte) error {
+	for len(seg) > 0 {
+		pq, tq := seg[0]>>4, seg[0]&0xf
+		if tq > 3 {
+			return ErrFormat
+		}
+		seg = seg[1:]
+		switch pq {
+		case 0:
+			if len(seg) < 64 {
+				return ErrFormat
+			}
+			for k := 0; k < 64; k++ {
+				d.quant[tq][unzig[k]] = int32(seg[k])
+			}
+			seg = seg[64:]
+		case 1:
+			if len(seg) < 128 {
+				return ErrFormat
+			}
+			for k := 0; k < 64; k++ {
+				d.quant[tq][unzig[k]] = int32(seg[2*k])<<8 | int32(seg[2*k+1])
+			}
+			seg = seg[128:]
+		default:
+			return ErrFormat
+		}
+	}
+	return nil
+}
+
+// image returns the image of the pixels decoded.
+func (d *decoder) image() image.Image {
+	w, h := scaledSize(d.width, 8/d.n), scaledSize(d.height, 8/d.n)
+	if len(d.comps) == 1 {
+		c := &d.comps[0]
+		img := image.NewGray(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			copy(img.Pix[y*img.Stride:y*img.Stride+w], c.pix[y*c.stride:])
+		}
+		return img
+	}
+
+	// the subsampled components are upsampled linearly as the fancy
+	// upsampling of libjpeg
+	rgb := d.isRGB()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var planes [3][]uint8
+	for i := range planes {
+		planes[i] = d.upsample(&d.comps[i], w, h)
+	}
+	for i, j := 0, 0; i < len(img.Pix); i, j = i+4, j+1 {
+		c0, c1, c2 := planes[0][j], planes[1][j], planes[2][j]
+		if !rgb {
+			c0, c1, c2 = color.YCbCrToRGB(c0, c1, c2)
+		}
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c0, c1, c2, 0xff
+	}
+	return img
+}
+
+// upsample returns the pixels of the component in the size of the image.
+func (d *decoder) upsample(c *component, w, h int) []uint8 {
+	pix := make([]uint8, w*h)
+	if c.h == d.hmax && c.v == d.vmax {
+		for y := 0; y < h; y++ {
+			copy(pix[y*w:(y+1)*w], c.pix[y*c.stride:])
+		}
+		return pix
+	}
+
+	// the pixels of the component are at the centers of the areas of the
+	// image pixels they cover
+	cw, ch := (w*c.h+d.hmax-1)/d.hmax, (h*c.v+d.vmax-1)/d.vmax
+	type sample struct {
+		i0, i1 int
+		k      float32
+	}
+	samples := func(n, cn, f, fmax int) []sample {
+		result := make([]sample, n)
+		for x := range result {
+			pos := (float32(x)+0.5)*float32(f)/float32(fmax) - 0.5
+			i0 := int(math.Floor(float64(pos)))
+			k := pos - float32(i0)
+			i1 := i0 + 1
+			if i0 < 0 {
+				i0 = 0
+			}
+			if i1 > cn-1 {
+				i1 = cn - 1
+			}
+			if i0 > i1 {
+				i0 = i1
+			}
+			result[x] = sample{i0, i1, k}
+		}
+		return result
+	}
+	xs := samples(w, cw, c.h, d.hmax)
+	ys := samples(h, ch, c.v, d.vmax)
+	for y, sy := range ys {
+		row0, row1 := c.pix[sy.i0*c.stride:], c.pix[sy.i1*c.stride:]
+		dst := pix[y*w : (y+1)*w]
+		for x, sx := range xs {
+			top := float32(row0[sx.i0]) + (float32(row0[sx.i1])-float32(row0[sx.i0]))*sx.k
+			bottom := float32(row1[sx.i0]) + (float32(row1[sx.i1])-float32(row1[sx.i0]))*sx.k
+			dst[x] = uint8(top + (bottom-top)*sy.k + 0.5)
+		}
+	}
+	return pix
+}
+
+// isRGB reports whether the components are RGB instead of YCbCr, the same
+// as image/jpeg.
+func (d *decoder) isRGB() bool {
+	if d.jfif {
+		return false
+	}
+	if d.adobe && d.adobeTransform == 0 {
+		return true
+	}
+	return d.comps[0].id == 'R' && d.comps[1].id == 'G' && d.comps[2].id == 'B'
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package jpeg
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meanDiff returns the mean difference of the channels between the image
+// and the reference image scaled down by averaging the denom*denom pixels.
+func meanDiff(ref, img image.Image, denom int) float64 {
+	rb, b := ref.Bounds(), img.Bounds()
+	var sum float64
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var avg [3]float64
+			var n float64
+			for ry := y * denom; ry < (y+1)*denom && ry < rb.Dy(); ry++ {
+				for rx := x * denom; rx < (x+1)*denom && rx < rb.Dx(); rx++ {
+					r, g, b, _ := ref.At(rb.Min.X+rx, rb.Min.Y+ry).RGBA()
+					avg[0] += float64(r >> 8)
+					avg[1] += float64(g >> 8)
+					avg[2] += float64(b >> 8)
+					n++
+				}
+			}
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			sum += (math.Abs(avg[0]/n-float64(r>>8)) + math.Abs(avg[1]/n-float64(g>>8)) +
+				math.Abs(avg[2]/n-float64(bl>>8))) / 3
+		}
+	}
+	return sum / float64(b.Dx()*b.Dy())
+}
+
+func testDecodeScaled(t *testing.T, name string, data []byte, gray bool) {
+	ref, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err, name)
+	rb := ref.Bounds()
+
+	// the full size decoded by the DCT scaling is the same as image/jpeg
+	img, err := decode(context.Background(), data, 8)
+	require.NoError(t, err, name)
+	assert.Equal(t, rb.Size(), img.Bounds().Size(), name)
+	assert.True(t, meanDiff(ref, img, 1) < 2, name)
+
+	for _, denom := range []int{1, 2, 4, 8} {
+		img, err := DecodeScaled(bytes.NewReader(data), denom)
+		require.NoError(t, err, name)
+		assert.Equal(t, image.Pt(scaledSize(rb.Dx(), denom), scaledSize(rb.Dy(), denom)),
+			img.Bounds().Size(), name, denom)
+		diff := meanDiff(ref, img, denom)
+		assert.True(t, diff < 8, "%s 1/%d: mean difference %g", name, denom, diff)
+		if denom > 1 {
+			_, isGray := img.(*image.Gray)
+			assert.Equal(t, gray, isGray, name)
+		}
+	}
+}
+
+func TestDecodeScaled(t *testing.T) {
+	for _, name := range []string{
+		"video-001.q50.420.jpeg",
+		"video-001.q50.420.progressive.jpeg",
+		"video-001.restart2.jpeg",
+		"video-005.gray.q50.progressive.jpeg",
+	} {
+		data, err := ioutil.ReadFile("testdata/" + name)
+		require.NoError(t, err)
+		testDecodeScaled(t, name, data, name == "video-005.gray.q50.progressive.jpeg")
+	}
+}
+
+func TestDecodeScaledEncoded(t *testing.T) {
+	// the sizes not aligned to the blocks
+	rgba := image.NewRGBA(image.Rect(0, 0, 61, 45))
+	gray := image.NewGray(image.Rect(0, 0, 33, 17))
+	for y := 0; y < 45; y++ {
+		for x := 0; x < 61; x++ {
+			c := color.RGBA{uint8(x * 4), uint8(y * 5), uint8((x + y) * 2), 0xff}
+			rgba.SetRGBA(x, y, c)
+			gray.SetGray(x, y, color.Gray{uint8(x*7 + y*3)})
+		}
+	}
+	for name, img := range map[string]image.Image{"rgba": rgba, "gray": gray} {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}))
+		testDecodeScaled(t, name, buf.Bytes(), name == "gray")
+	}
+}
+
+func TestDecodeScaledError(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/video-001.q50.420.jpeg")
+	require.NoError(t, err)
+	for _, denom := range []int{0, 3, 16} {
+		_, err = DecodeScaled(bytes.NewReader(data), denom)
+		assert.Equal(t, ErrDenom, err)
+	}
+
+	_, err = DecodeScaled(bytes.NewReader([]byte("not a jpeg")), 2)
+	assert.Equal(t, ErrFormat, err)
+
+	// the lossless image
+	sof3 := []byte{0xff, 0xd8, 0xff, 0xc3, 0, 11, 8, 0, 1, 0, 1, 1, 1, 0x11, 0}
+	_, err = DecodeScaled(bytes.NewReader(sof3), 2)
+	assert.Equal(t, ErrUnsupported, err)
+
+	// the decoding is stopped by the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = DecodeScaledContext(ctx, bytes.NewReader(data), 2)
+	assert.Equal(t, context.Canceled, err)
+	progressive, err := ioutil.ReadFile("testdata/video-001.q50.420.progressive.jpeg")
+	require.NoError(t, err)
+	_, err = DecodeScaledContext(ctx, bytes.NewReader(progressive), 2)
+	assert.Equal(t, context.Canceled, err)
+
+	// the truncated image is decoded as possible
+	img, err := DecodeScaled(bytes.NewReader(data[:len(data)*2/3]), 4)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 38, 26), img.Bounds())
+}
+
+func TestGetScaleDenom(t *testing.T) {
+	assert.Equal(t, 8, GetScaleDenom(4000, 3000, 256, 256))
+	assert.Equal(t, 4, GetScaleDenom(4000, 3000, 1000, 0))
+	assert.Equal(t, 2, GetScaleDenom(4000, 3000, 0, 1000))
+	assert.Equal(t, 1, GetScaleDenom(4000, 3000, 3000, 0))
+	assert.Equal(t, 8, GetScaleDenom(4000, 3000, 0, 0))
+	// rounded up
+	assert.Equal(t, 8, GetScaleDenom(1001, 1001, 126, 126))
+	assert.Equal(t, 4, GetScaleDenom(1001, 1001, 127, 127))
+}
+
+func TestDecodeScaledCorrupted(t *testing.T) {
+	decode := func(data []byte, denom int) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("panic with %d bytes in 1/%d: %v", len(data), denom, r)
+			}
+		}()
+		_, err = DecodeScaled(bytes.NewReader(data), denom)
+		return
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, name := range []string{"video-001.q50.420.jpeg", "video-001.q50.420.progressive.jpeg",
+		"video-001.restart2.jpeg", "video-005.gray.q50.progressive.jpeg"} {
+		data, err := ioutil.ReadFile("testdata/" + name)
+		require.NoError(t, err)
+
+		// the headers are truncated
+		for n := 0; n < 700 && n < len(data); n++ {
+			_ = decode(data[:n], 2)
+		}
+		assert.Error(t, decode(data[:1], 2))
+
+		// the bytes are mutated, mostly in the headers
+		mutated := make([]byte, len(data))
+		for i := 0; i < 1000; i++ {
+			copy(mutated, data)
+			for j := rnd.Intn(4); j >= 0; j-- {
+				pos := rnd.Intn(700)
+				if rnd.Intn(4) == 0 {
+					pos = rnd.Intn(len(data))
+				}
+				mutated[pos] = byte(rnd.Intn(256))
+			}
+			_ = decode(mutated, 2<<uint(rnd.Intn(3)))
+		}
+	}
+
+	// the Huffman table with more codes of length 1 than possible
+	dht := []byte{0xff, 0xd8, 0xff, 0xc4, 0, 21, 0x00, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3}
+	assert.Equal(t, ErrFormat, decode(dht, 2))
+}
@@ -0,0 +1,434 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package jpeg
+
+// unzig maps the zig-zag order to the natural order of the coefficients.
+var unzig = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// huffman is a Huffman table of the canonical codes.
+type huffman struct {
+	values []uint8
+	// the codes not longer than lutBits, length<<8 | value, 0 if longer
+	lut [1 << lutBits]uint16
+	// for each length, the max code and the index of its first value
+	// minus its first code, the max code is -1 if there are no codes
+	maxCode [17]int32
+	valPtr  [17]int32
+}
+
+const lutBits = 8
+
+func (d *decoder) parseDHT(seg []byte) error {
+	for len(seg) > 0 {
+		if len(seg) < 17 {
+			return ErrFormat
+		}
+		tc, th := seg[0]>>4, seg[0]&0xf
+		if tc > 1 || th > 3 {
+			return ErrFormat
+		}
+		var counts [17]int
+		total := 0
+		for l := 1; l <= 16; l++ {
+			counts[l] = int(seg[l])
+			total += counts[l]
+		}
+		seg = seg[17:]
+		if total == 0 || total > 256 || len(seg) < total {
+			return ErrFormat
+		}
+
+		h := &huffman{values: append([]uint8(nil), seg[:total]...)}
+		seg = seg[total:]
+		code, k := int32(0), int32(0)
+		for l := 1; l <= 16; l++ {
+			h.valPtr[l] = k - code
+			// the codes of the length must not be more than the length
+			// can have, which is checked before they are added to lut
+			if code+int32(counts[l]) > 1<<uint(l) {
+				return ErrFormat
+			}
+			for i := 0; i < counts[l]; i++ {
+				if l <= lutBits {
+					// all the entries with the code as the prefix
+					shift := uint(lutBits - l)
+					for j := int32(0); j < 1<<shift; j++ {
+						h.lut[code<<shift|j] = uint16(l)<<8 | uint16(h.values[k])
+					}
+				}
+				code++
+				k++
+			}
+			h.maxCode[l] = code - 1
+			if counts[l] == 0 {
+				h.maxCode[l] = -1
+			}
+			code <<= 1
+		}
+		d.huff[tc][th] = h
+	}
+	return nil
+}
+
+// fill reads the bytes of the entropy coded data until there are 24 bits
+// at least, zeros are read after the marker which ends the data.
+func (d *decoder) fill() {
+	for d.nbits <= 24 {
+		var b byte
+		if !d.hitMarker && d.pos < len(d.data) {
+			b = d.data[d.pos]
+			if b == 0xff {
+				if d.pos+1 < len(d.data) && d.data[d.pos+1] == 0 {
+					// the stuffed zero byte
+					d.pos += 2
+				} else {
+					d.hitMarker = true
+					b = 0
+				}
+			} else {
+				d.pos++
+			}
+		}
+		d.bits |= uint32(b) << (24 - d.nbits)
+		d.nbits += 8
+	}
+}
+
+func (d *decoder) readBits(n uint) int32 {
+	if n == 0 {
+		return 0
+	}
+	d.fill()
+	v := int32(d.bits >> (32 - n))
+	d.bits <<= n
+	d.nbits -= n
+	return v
+}
+
+// receiveExtend reads the value of n bits in the sign and magnitude form.
+func (d *decoder) receiveExtend(n uint8) int32 {
+	if n == 0 {
+		return 0
+	}
+	v := d.readBits(uint(n))
+	if v < 1<<(n-1) {
+		v += -1<<n + 1
+	}
+	return v
+}
+
+func (d *decoder) decodeHuffman(h *huffman) (uint8, error) {
+	if h == nil {
+		return 0, ErrFormat
+	}
+	d.fill()
+	if e := h.lut[d.bits>>(32-lutBits)]; e != 0 {
+		n := uint(e >> 8)
+		d.bits <<= n
+		d.nbits -= n
+		return uint8(e), nil
+	}
+	code := int32(d.bits >> 16)
+	for l := lutBits + 1; l <= 16; l++ {
+		c := code >> uint(16-l)
+		if c <= h.maxCode[l] {
+			d.bits <<= uint(l)
+			d.nbits -= uint(l)
+			return h.values[h.valPtr[l]+c], nil
+		}
+	}
+	return 0, ErrFormat
+}
+
+// restart discards the bits and reads the RST marker, the marker is not
+// required as libjpeg does, the missing data is decoded as zeros.
+func (d *decoder) restart() {
+	d.bits, d.nbits = 0, 0
+	d.hitMarker = false
+	for d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+	}
+	if d.pos < len(d.data) && d.data[d.pos] >= markerRST0 && d.data[d.pos] <= markerRST7 {
+		d.pos++
+	} else if d.pos > 0 && d.pos <= len(d.data) && d.data[d.pos-1] == 0xff {
+		// leave the other marker to be read
+		d.pos--
+	}
+	for i := range d.comps {
+		d.comps[i].dcPred = 0
+	}
+	d.eobrun = 0
+}
+
+type scanComponent struct {
+	c      *component
+	dc, ac *huffman
+}
+
+func (d *decoder) parseSOS(seg []byte) error {
+	if len(seg) < 1 {
+		return ErrFormat
+	}
+	ns := int(seg[0])
+	if ns < 1 || ns > len(d.comps) || len(seg) != 4+2*ns {
+		return ErrFormat
+	}
+	comps := make([]scanComponent, ns)
+	for i := range comps {
+		id, tables := seg[1+2*i], seg[2+2*i]
+		for j := range d.comps {
+			if d.comps[j].id == id {
+				comps[i].c = &d.comps[j]
+			}
+		}
+		if comps[i].c == nil || tables>>4 > 3 || tables&0xf > 3 {
+			return ErrFormat
+		}
+		comps[i].dc = d.huff[0][tables>>4]
+		comps[i].ac = d.huff[1][tables&0xf]
+	}
+	ss, se := int(seg[1+2*ns]), int(seg[2+2*ns])
+	ah, al := uint(seg[3+2*ns]>>4), uint(seg[3+2*ns]&0xf)
+	if d.progressive {
+		if ss > se || se > 63 || (ss == 0) != (se == 0) || (ss > 0 && ns != 1) || al > 13 {
+			return ErrFormat
+		}
+	} else if ss != 0 || se != 63 || ah != 0 || al != 0 {
+		return ErrFormat
+	}
+
+	d.bits, d.nbits = 0, 0
+	d.hitMarker = false
+	d.eobrun = 0
+	for i := range d.comps {
+		d.comps[i].dcPred = 0
+	}
+
+	var block [64]int32
+	decodeBlock := func(sc *scanComponent, bx, by int) error {
+		if !d.progressive {
+			if err := d.decodeBaseline(sc, &block); err != nil {
+				return err
+			}
+			d.reconstruct(sc.c, bx, by, &block)
+			return nil
+		}
+		coefs := sc.c.coefs[(by*sc.c.blocksX+bx)*64:][:64]
+		switch {
+		case ss == 0 && ah == 0:
+			return d.decodeDCFirst(sc, coefs, al)
+		case ss == 0:
+			d.decodeDCRefine(coefs, al)
+			return nil
+		case ah == 0:
+			return d.decodeACFirst(sc, coefs, ss, se, al)
+		}
+		return d.decodeACRefine(sc, coefs, ss, se, al)
+	}
+
+	var mcus, mcusX int
+	if ns == 1 {
+		// the blocks of the component in the image, one block per MCU
+		c := comps[0].c
+		mcusX = ((d.width*c.h+d.hmax-1)/d.hmax + 7) / 8
+		mcusY := ((d.height*c.v+d.vmax-1)/d.vmax + 7) / 8
+		mcus = mcusX * mcusY
+	} else {
+		mcusX = d.mcusX
+		mcus = d.mcusX * d.mcusY
+	}
+	for mcu := 0; mcu < mcus; mcu++ {
+		if d.restartInterval > 0 && mcu > 0 && mcu%d.restartInterval == 0 {
+			d.restart()
+		}
+		mx, my := mcu%mcusX, mcu/mcusX
+		if mx == 0 {
+			if err := d.ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if ns == 1 {
+			if err := decodeBlock(&comps[0], mx, my); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := range comps {
+			sc := &comps[i]
+			for v := 0; v < sc.c.v; v++ {
+				for h := 0; h < sc.c.h; h++ {
+					if err := decodeBlock(sc, mx*sc.c.h+h, my*sc.c.v+v); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeBaseline(sc *scanComponent, block *[64]int32) error {
+	*block = [64]int32{}
+	t, err := d.decodeHuffman(sc.dc)
+	if err != nil {
+		return err
+	}
+	if t > 16 {
+		return ErrFormat
+	}
+	sc.c.dcPred += d.receiveExtend(t)
+	block[0] = sc.c.dcPred
+	for k := 1; k < 64; k++ {
+		rs, err := d.decodeHuffman(sc.ac)
+		if err != nil {
+			return err
+		}
+		r, s := int(rs>>4), rs&0xf
+		if s == 0 {
+			if r != 15 {
+				break
+			}
+			k += 15
+			continue
+		}
+		k += r
+		if k > 63 {
+			return ErrFormat
+		}
+		block[unzig[k]] = d.receiveExtend(s)
+	}
+	return nil
+}
+
+func (d *decoder) decodeDCFirst(sc *scanComponent, coefs []int16, al uint) error {
+	t, err := d.decodeHuffman(sc.dc)
+	if err != nil {
+		return err
+	}
+	if t > 16 {
+		return ErrFormat
+	}
+	sc.c.dcPred += d.receiveExtend(t)
+	coefs[0] = int16(sc.c.dcPred << al)
+	return nil
+}
+
+func (d *decoder) decodeDCRefine(coefs []int16, al uint) {
+	if d.readBits(1) != 0 {
+		coefs[0] |= 1 << al
+	}
+}
+
+func (d *decoder) decodeACFirst(sc *scanComponent, coefs []int16, ss, se int, al uint) error {
+	if d.eobrun > 0 {
+		d.eobrun--
+		return nil
+	}
+	for k := ss; k <= se; k++ {
+		rs, err := d.decodeHuffman(sc.ac)
+		if err != nil {
+			return err
+		}
+		r, s := int(rs>>4), rs&0xf
+		if s == 0 {
+			if r != 15 {
+				d.eobrun = 1<<uint(r) - 1
+				if r > 0 {
+					d.eobrun += int(d.readBits(uint(r)))
+				}
+				break
+			}
+			k += 15
+			continue
+		}
+		k += r
+		if k > se {
+			return ErrFormat
+		}
+		coefs[unzig[k]] = int16(d.receiveExtend(s) << al)
+	}
+	return nil
+}
+
+func (d *decoder) decodeACRefine(sc *scanComponent, coefs []int16, ss, se int, al uint) error {
+	delta := int16(1 << al)
+	k := ss
+	if d.eobrun == 0 {
+		for ; k <= se; k++ {
+			var z int16
+			rs, err := d.decodeHuffman(sc.ac)
+			if err != nil {
+				return err
+			}
+			r, s := int(rs>>4), rs&0xf
+			switch s {
+			case 0:
+				if r != 15 {
+					d.eobrun = 1 << uint(r)
+					if r > 0 {
+						d.eobrun += int(d.readBits(uint(r)))
+					}
+				}
+			case 1:
+				z = delta
+				if d.readBits(1) == 0 {
+					z = -delta
+				}
+			default:
+				return ErrFormat
+			}
+			if d.eobrun > 0 {
+				break
+			}
+			// skip r zero coefficients, and refine the nonzero ones
+			k = d.refineNonZeroes(coefs, k, se, r, delta)
+			if k > se {
+				return ErrFormat
+			}
+			if z != 0 {
+				coefs[unzig[k]] = z
+			}
+		}
+	}
+	if d.eobrun > 0 {
+		d.eobrun--
+		d.refineNonZeroes(coefs, k, se, -1, delta)
+	}
+	return nil
+}
+
+// refineNonZeroes refines the nonzero coefficients from k and skips nz
+// zero coefficients, it returns the index of the zero coefficient after
+// them.
+func (d *decoder) refineNonZeroes(coefs []int16, k, se, nz int, delta int16) int {
+	for ; k <= se; k++ {
+		u := unzig[k]
+		if coefs[u] == 0 {
+			if nz == 0 {
+				break
+			}
+			nz--
+			continue
+		}
+		if d.readBits(1) == 0 {
+			continue
+		}
+		if coefs[u] >= 0 {
+			coefs[u] += delta
+		} else {
+			coefs[u] -= delta
+		}
+	}
+	return k
+}
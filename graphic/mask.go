@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// RoundCorners returns the image whose corners are clipped to the quarter
+// circles of the radius, the edges of the circles are antialiased.
+func RoundCorners(srcimg image.Image, radius float64) (dstimg *image.RGBA) {
+	dstimg = convertToRGBA(srcimg)
+	roundCorners(dstimg, radius)
+	return
+}
+
+// DropShadow returns the image drawn over its shadow, which is in the
+// color c, offset by dx, dy and blurred by sigma, see GaussianBlur. The
+// result is larger than the image to contain the shadow.
+func DropShadow(srcimg image.Image, dx, dy int, sigma float64, c color.Color) (dstimg *image.RGBA) {
+	w, h := GetSize(srcimg)
+	margin := 0
+	if sigma > 0 {
+		margin = int(math.Ceil(sigma * 3))
+	}
+	imgRect := image.Rect(0, 0, w, h)
+	shadowRect := imgRect.Add(image.Pt(dx, dy)).Inset(-margin)
+	bounds := imgRect.Union(shadowRect)
+	// move the result to the origin
+	imgRect = imgRect.Sub(bounds.Min)
+	shadowRect = shadowRect.Sub(bounds.Min)
+	dstimg = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	// the shadow is the alpha of the image in the color
+	shadow := image.NewRGBA(image.Rect(0, 0, shadowRect.Dx(), shadowRect.Dy()))
+	draw.DrawMask(shadow, image.Rect(margin, margin, margin+w, margin+h),
+		image.NewUniform(c), image.Point{}, srcimg, srcimg.Bounds().Min, draw.Src)
+	gaussianBlur(shadow, sigma)
+	draw.Draw(dstimg, shadowRect, shadow, image.Point{}, draw.Src)
+	draw.Draw(dstimg, imgRect, srcimg, srcimg.Bounds().Min, draw.Over)
+	return
+}
+
+func roundCorners(img *image.RGBA, radius float64) {
+	w, h := GetSize(img)
+	radius = math.Min(radius, math.Min(float64(w), float64(h))/2)
+	if radius <= 0 {
+		return
+	}
+	n := int(math.Ceil(radius))
+	// the coverage of the pixels in the top left corner, the others are
+	// mirrored
+	coverage := make([]float64, n*n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			dx, dy := radius-(float64(x)+0.5), radius-(float64(y)+0.5)
+			if dx <= 0 || dy <= 0 {
+				coverage[y*n+x] = 1
+				continue
+			}
+			v := radius - math.Hypot(dx, dy) + 0.5
+			coverage[y*n+x] = math.Max(0, math.Min(1, v))
+		}
+	}
+
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			a := coverage[y*n+x]
+			if a >= 1 {
+				continue
+			}
+			for _, p := range [4]image.Point{{x, y}, {w - 1 - x, y},
+				{x, h - 1 - y}, {w - 1 - x, h - 1 - y}} {
+				i := img.PixOffset(img.Rect.Min.X+p.X, img.Rect.Min.Y+p.Y)
+				for c := 0; c < 4; c++ {
+					img.Pix[i+c] = uint8(float64(img.Pix[i+c])*a + 0.5)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+const (
+	// the colors are quantized to 5 bits per channel before the median cut
+	quantizeBits  = 5
+	quantizeShift = 8 - quantizeBits
+	quantizeMask  = 1<<quantizeBits - 1
+
+	// the pixels sampled from the image at most
+	maxPaletteSamples = 1 << 16
+	// the pixels more transparent are ignored
+	minPaletteAlpha = 128
+
+	// DefaultPaletteColors is the number of colors of the palette when the
+	// maxColors passed to GetPalette is not positive.
+	DefaultPaletteColors = 16
+)
+
+// Swatch is a color of the palette, with the number of the pixels
+// sampled in the color.
+type Swatch struct {
+	color.RGBA
+	Population int
+}
+
+// Hsv returns the color of the swatch in HSV, see Rgb2Hsv.
+func (s Swatch) Hsv() (h, sat, v float64) {
+	return Rgb2Hsv(s.R, s.G, s.B)
+}
+
+// hsl returns the saturation and the lightness in HSL, in [0..1].
+func (s Swatch) hsl() (sat, l float64) {
+	_, sv, v := s.Hsv()
+	l = v * (1 - sv/2)
+	if l > 0 && l < 1 {
+		sat = (v - l) / math.Min(l, 1-l)
+	}
+	return
+}
+
+// GetPaletteOfImage return the palette of an image, see GetPalette.
+func GetPaletteOfImage(imgfile string, maxColors int) ([]Swatch, error) {
+	img, err := LoadImage(imgfile)
+	if err != nil {
+		return nil, err
+	}
+	return GetPalette(img, maxColors), nil
+}
+
+// GetPalette return the main colors of the image by the median cut, sorted
+// by the population. There are maxColors colors at most, or
+// DefaultPaletteColors if it is not positive. The large images are
+// sampled, and the transparent pixels are ignored.
+func GetPalette(img image.Image, maxColors int) []Swatch {
+	if maxColors <= 0 {
+		maxColors = DefaultPaletteColors
+	}
+	bins := getColorHistogram(img)
+	var boxes []colorBox
+	if len(bins) > 0 {
+		boxes = append(boxes, newColorBox(bins))
+	}
+	for len(boxes) < maxColors {
+		// split the box of the largest volume, to separate the colors far
+		// from each other even if they are not many
+		idx := -1
+		for i, box := range boxes {
+			if len(box.bins) > 1 && (idx < 0 || box.volume() > boxes[idx].volume()) {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			break
+		}
+		a, b := boxes[idx].split()
+		boxes[idx] = a
+		boxes = append(boxes, b)
+	}
+
+	palette := make([]Swatch, len(boxes))
+	for i, box := range boxes {
+		palette[i] = box.swatch()
+	}
+	sort.SliceStable(palette, func(i, j int) bool {
+		return palette[i].Population > palette[j].Population
+	})
+	return palette
+}
+
+// colorBin is the pixels of a quantized color.
+type colorBin struct {
+	// the quantized r, g, b
+	c                [3]uint8
+	count            int
+	sumR, sumG, sumB int
+}
+
+func getColorHistogram(img image.Image) []colorBin {
+	b := img.Bounds()
+	step := 1
+	if n := b.Dx() * b.Dy(); n > maxPaletteSamples {
+		step = int(math.Ceil(math.Sqrt(float64(n) / maxPaletteSamples)))
+	}
+
+	hist := make([]colorBin, 1<<(3*quantizeBits))
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < minPaletteAlpha {
+				continue
+			}
+			qr, qg, qb := c.R>>quantizeShift, c.G>>quantizeShift, c.B>>quantizeShift
+			bin := &hist[int(qr)<<(2*quantizeBits)|int(qg)<<quantizeBits|int(qb)]
+			bin.c = [3]uint8{qr, qg, qb}
+			bin.count++
+			bin.sumR += int(c.R)
+			bin.sumG += int(c.G)
+			bin.sumB += int(c.B)
+		}
+	}
+
+	var bins []colorBin
+	for _, bin := range hist {
+		if bin.count > 0 {
+			bins = append(bins, bin)
+		}
+	}
+	return bins
+}
+
+// colorBox is the bins in the box of the quantized colors.
+type colorBox struct {
+	bins     []colorBin
+	min, max [3]uint8
+}
+
+func newColorBox(bins []colorBin) colorBox {
+	box := colorBox{bins: bins}
+	box.min = [3]uint8{quantizeMask, quantizeMask, quantizeMask}
+	for _, bin := range bins {
+		for i, v := range bin.c {
+			if v < box.min[i] {
+				box.min[i] = v
+			}
+			if v > box.max[i] {
+				box.max[i] = v
+			}
+		}
+	}
+	return box
+}
+
+func (box colorBox) volume() int {
+	v := 1
+	for i := range box.min {
+		v *= int(box.max[i]-box.min[i]) + 1
+	}
+	return v
+}
+
+// split splits the box along its longest side at the median of the pixels.
+func (box colorBox) split() (colorBox, colorBox) {
+	dim := 0
+	for i := range box.min {
+		if box.max[i]-box.min[i] > box.max[dim]-box.min[dim] {
+			dim = i
+		}
+	}
+	sort.Slice(box.bins, func(i, j int) bool {
+		return box.bins[i].c[dim] < box.bins[j].c[dim]
+	})
+
+	total := 0
+	for _, bin := range box.bins {
+		total += bin.count
+	}
+	// both of the boxes have one bin at least
+	n, count := 1, box.bins[0].count
+	for n < len(box.bins)-1 && count < total/2 {
+		count += box.bins[n].count
+		n++
+	}
+	return newColorBox(box.bins[:n]), newColorBox(box.bins[n:])
+}
+
+func (box colorBox) swatch() Swatch {
+	var count, sumR, sumG, sumB int
+	for _, bin := range box.bins {
+		count += bin.count
+		sumR += bin.sumR
+		sumG += bin.sumG
+		sumB += bin.sumB
+	}
+	return Swatch{
+		RGBA: color.RGBA{
+			R: uint8((sumR + count/2) / count),
+			G: uint8((sumG + count/2) / count),
+			B: uint8((sumB + count/2) / count),
+			A: 0xff,
+		},
+		Population: count,
+	}
+}
+
+// Swatches is the swatches of the palette for the themes, picked as the
+// Palette of Material Design does. The swatches are nil if there are no
+// colors matched in the palette.
+type Swatches struct {
+	Vibrant      *Swatch
+	LightVibrant *Swatch
+	DarkVibrant  *Swatch
+	Muted        *Swatch
+	LightMuted   *Swatch
+	DarkMuted    *Swatch
+}
+
+type swatchTarget struct {
+	swatch **Swatch
+	// the min, target and max of the saturation and the lightness in HSL
+	sat, light [3]float64
+}
+
+// the weights of the saturation, the lightness and the population
+const (
+	swatchSatWeight        = 0.24
+	swatchLightWeight      = 0.52
+	swatchPopulationWeight = 0.24
+)
+
+var (
+	lightLightness  = [3]float64{0.55, 0.74, 1}
+	normalLightness = [3]float64{0.3, 0.5, 0.7}
+	darkLightness   = [3]float64{0, 0.26, 0.45}
+
+	vibrantSaturation = [3]float64{0.35, 1, 1}
+	mutedSaturation   = [3]float64{0, 0.3, 0.4}
+)
+
+// GetSwatches picks the vibrant and the muted swatches from the palette,
+// each of the colors is picked once at most. The colors close to black or
+// white are not picked.
+func GetSwatches(palette []Swatch) Swatches {
+	var result Swatches
+	targets := []swatchTarget{
+		{&result.LightVibrant, vibrantSaturation, lightLightness},
+		{&result.Vibrant, vibrantSaturation, normalLightness},
+		{&result.DarkVibrant, vibrantSaturation, darkLightness},
+		{&result.LightMuted, mutedSaturation, lightLightness},
+		{&result.Muted, mutedSaturation, normalLightness},
+		{&result.DarkMuted, mutedSaturation, darkLightness},
+	}
+
+	maxPopulation := 0
+	for _, s := range palette {
+		if s.Population > maxPopulation {
+			maxPopulation = s.Population
+		}
+	}
+	used := make([]bool, len(palette))
+	for _, target := range targets {
+		best, bestScore := -1, 0.0
+		for i, s := range palette {
+			if used[i] {
+				continue
+			}
+			sat, l := s.hsl()
+			if l <= 0.05 || l >= 0.95 ||
+				sat < target.sat[0] || sat > target.sat[2] ||
+				l < target.light[0] || l > target.light[2] {
+				continue
+			}
+			score := swatchSatWeight*(1-math.Abs(sat-target.sat[1])) +
+				swatchLightWeight*(1-math.Abs(l-target.light[1])) +
+				swatchPopulationWeight*float64(s.Population)/float64(maxPopulation)
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best >= 0 {
+			used[best] = true
+			s := palette[best]
+			*target.swatch = &s
+		}
+	}
+	return result
+}
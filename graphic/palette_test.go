@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPalette(t *testing.T) {
+	red := color.RGBA{0xe0, 0x20, 0x20, 0xff}
+	blue := color.RGBA{0x20, 0x40, 0xc0, 0xff}
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	// 50% red, 30% blue, 20% gray and the transparent column
+	img := newTestImage(11, 10, func(x, y int) color.Color {
+		switch {
+		case x < 5:
+			return red
+		case x < 8:
+			return blue
+		case x < 10:
+			return gray
+		}
+		return color.Transparent
+	})
+	palette := GetPalette(img, 0)
+	require.Len(t, palette, 3)
+	assert.Equal(t, Swatch{red, 50}, palette[0])
+	assert.Equal(t, Swatch{blue, 30}, palette[1])
+	assert.Equal(t, Swatch{gray, 20}, palette[2])
+
+	// the colors are merged into maxColors
+	gradient := newTestImage(256, 16, func(x, y int) color.Color {
+		return color.RGBA{uint8(x), uint8(y * 16), 0x80, 0xff}
+	})
+	palette = GetPalette(gradient, 8)
+	require.Len(t, palette, 8)
+	total := 0
+	for i, s := range palette {
+		total += s.Population
+		if i > 0 {
+			assert.True(t, s.Population <= palette[i-1].Population)
+		}
+	}
+	assert.Equal(t, 256*16, total)
+
+	assert.Empty(t, GetPalette(newTestImage(4, 4, func(x, y int) color.Color {
+		return color.Transparent
+	}), 0))
+
+	palette, err := GetPaletteOfImage(originImg, 0)
+	require.NoError(t, err)
+	assert.Len(t, palette, DefaultPaletteColors)
+	_, err = GetPaletteOfImage(originImgNotImage, 0)
+	assert.Error(t, err)
+}
+
+func TestGetSwatches(t *testing.T) {
+	vibrant := Swatch{color.RGBA{0xf0, 0x20, 0x30, 0xff}, 10}
+	lightVibrant := Swatch{color.RGBA{0x80, 0xd0, 0xff, 0xff}, 10}
+	darkVibrant := Swatch{color.RGBA{0x10, 0x50, 0x20, 0xff}, 10}
+	muted := Swatch{color.RGBA{0x70, 0x80, 0x90, 0xff}, 10}
+	lightMuted := Swatch{color.RGBA{0xc8, 0xc0, 0xb0, 0xff}, 10}
+	darkMuted := Swatch{color.RGBA{0x40, 0x38, 0x30, 0xff}, 10}
+	white := Swatch{color.RGBA{0xff, 0xff, 0xff, 0xff}, 100}
+
+	swatches := GetSwatches([]Swatch{white, muted, darkVibrant, lightMuted,
+		vibrant, darkMuted, lightVibrant})
+	assert.Equal(t, Swatches{
+		Vibrant:      &vibrant,
+		LightVibrant: &lightVibrant,
+		DarkVibrant:  &darkVibrant,
+		Muted:        &muted,
+		LightMuted:   &lightMuted,
+		DarkMuted:    &darkMuted,
+	}, swatches)
+
+	// the more populous one of the similar colors
+	vibrant2 := Swatch{color.RGBA{0xe8, 0x28, 0x30, 0xff}, 100}
+	swatches = GetSwatches([]Swatch{vibrant, vibrant2})
+	assert.Equal(t, &vibrant2, swatches.Vibrant)
+	assert.Nil(t, swatches.Muted)
+
+	assert.Equal(t, Swatches{}, GetSwatches(nil))
+}
+
+func TestContrastRatio(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	assert.Equal(t, 0.0, RelativeLuminance(black))
+	assert.Equal(t, 1.0, RelativeLuminance(white))
+	assert.Equal(t, 21.0, ContrastRatio(black, white))
+	assert.Equal(t, 21.0, ContrastRatio(white, black))
+	assert.Equal(t, 1.0, ContrastRatio(white, white))
+	assert.InDelta(t, 4.48, ContrastRatio(color.RGBA{0x77, 0x77, 0x77, 0xff}, white), 0.01)
+	assert.InDelta(t, 4.54, ContrastRatio(color.RGBA{0x76, 0x76, 0x76, 0xff}, white), 0.01)
+}
+
+func TestGetReadableForeground(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	blue := color.RGBA{0x20, 0x40, 0xc0, 0xff}
+	assert.Equal(t, blue, GetReadableForeground(blue, white, ContrastRatioAA))
+
+	// darkened on the light background, and lightened on the dark one
+	lightBlue := color.RGBA{0x80, 0xa0, 0xff, 0xff}
+	for _, bg := range []color.RGBA{white, black, {0x30, 0x30, 0x30, 0xff}} {
+		for _, ratio := range []float64{ContrastRatioAALarge, ContrastRatioAA, ContrastRatioAAA} {
+			fg := GetReadableForeground(lightBlue, bg, ratio)
+			assert.True(t, ContrastRatio(fg, bg) >= ratio)
+			h, _, _ := Rgb2Hsv(fg.R, fg.G, fg.B)
+			assert.True(t, math.Abs(h-225) < 2, "hue %v", h)
+		}
+	}
+	fg := GetReadableForeground(lightBlue, white, ContrastRatioAA)
+	assert.True(t, RelativeLuminance(fg) < RelativeLuminance(lightBlue))
+	// changed as little as possible
+	assert.True(t, ContrastRatio(fg, white) < ContrastRatioAA+0.2)
+	fg = GetReadableForeground(blue, black, ContrastRatioAA)
+	assert.True(t, RelativeLuminance(fg) > RelativeLuminance(blue))
+
+	// not reachable
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	assert.Equal(t, black, GetReadableForeground(blue, white, 22))
+	assert.Equal(t, white, GetReadableForeground(gray, black, 22))
+	assert.Equal(t, black, GetReadableForeground(gray, gray, ContrastRatioAAA))
+}
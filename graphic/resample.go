@@ -0,0 +1,325 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Filter defines the resampling filter to scale images.
+type Filter int
+
+// Supported resampling filters, from the fastest to the sharpest.
+const (
+	// FilterNearest picks the nearest pixel, it is the fastest but the
+	// result is aliased.
+	FilterNearest Filter = iota
+	// FilterBox averages the pixels covered, good for downscaling.
+	FilterBox
+	// FilterBilinear interpolates the pixels linearly.
+	FilterBilinear
+	// FilterBicubic is the Catmull-Rom cubic filter.
+	FilterBicubic
+	// FilterLanczos3 is the Lanczos filter with 3 lobes, it gives the
+	// sharpest result but is the slowest.
+	FilterLanczos3
+)
+
+func (f Filter) String() string {
+	switch f {
+	case FilterNearest:
+		return "nearest"
+	case FilterBox:
+		return "box"
+	case FilterBilinear:
+		return "bilinear"
+	case FilterBicubic:
+		return "bicubic"
+	case FilterLanczos3:
+		return "lanczos3"
+	}
+	return "unknown"
+}
+
+// getFilter returns the filter of the optional argument, FilterNearest by
+// default.
+func getFilter(filter []Filter) Filter {
+	if len(filter) > 0 {
+		return filter[0]
+	}
+	return FilterNearest
+}
+
+type kernel struct {
+	// the kernel is zero out of [-support, support]
+	support float64
+	fn      func(x float64) float64
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+var kernels = map[Filter]kernel{
+	FilterBox: {0.5, func(x float64) float64 {
+		if x >= -0.5 && x < 0.5 {
+			return 1
+		}
+		return 0
+	}},
+	FilterBilinear: {1, func(x float64) float64 {
+		x = math.Abs(x)
+		if x < 1 {
+			return 1 - x
+		}
+		return 0
+	}},
+	FilterBicubic: {2, func(x float64) float64 {
+		x = math.Abs(x)
+		switch {
+		case x < 1:
+			return (1.5*x-2.5)*x*x + 1
+		case x < 2:
+			return ((-0.5*x+2.5)*x-4)*x + 2
+		}
+		return 0
+	}},
+	FilterLanczos3: {3, func(x float64) float64 {
+		if x > -3 && x < 3 {
+			return sinc(x) * sinc(x/3)
+		}
+		return 0
+	}},
+}
+
+// the source pixels and their weights for a destination pixel
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// getContributions returns the contributions of each destination pixel
+// when scaling srcLen pixels to dstLen pixels. The kernel is stretched
+// when downscaling so that all the source pixels are counted.
+func getContributions(srcLen, dstLen int, k kernel) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	filterScale := math.Max(scale, 1)
+	support := k.support * filterScale
+
+	result := make([]contribution, dstLen)
+	for i := range result {
+		center := (float64(i) + 0.5) * scale
+		begin := int(math.Floor(center - support))
+		if begin < 0 {
+			begin = 0
+		}
+		end := int(math.Ceil(center + support))
+		if end > srcLen {
+			end = srcLen
+		}
+
+		weights := make([]float32, 0, end-begin)
+		var sum float64
+		for j := begin; j < end; j++ {
+			w := k.fn((float64(j) + 0.5 - center) / filterScale)
+			weights = append(weights, float32(w))
+			sum += w
+		}
+		if sum == 0 {
+			// no pixel is covered, take the nearest one
+			j := int(center)
+			if j >= srcLen {
+				j = srcLen - 1
+			}
+			result[i] = contribution{start: j, weights: []float32{1}}
+			continue
+		}
+		for j := range weights {
+			weights[j] /= float32(sum)
+		}
+		result[i] = contribution{start: begin, weights: weights}
+	}
+	return result
+}
+
+// the rows are processed by the goroutines in bands of this many rows
+const bandRows = 16
+
+// parallel calls fn with the bands of the rows [0, n) on all the cores.
+func parallel(n int, fn func(start, end int)) {
+	bands := (n + bandRows - 1) / bandRows
+	workers := runtime.GOMAXPROCS(0)
+	if workers > bands {
+		workers = bands
+	}
+	if workers <= 1 {
+		fn(0, n)
+		return
+	}
+
+	var next int32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				band := int(atomic.AddInt32(&next, 1)) - 1
+				if band >= bands {
+					return
+				}
+				end := (band + 1) * bandRows
+				if end > n {
+					end = n
+				}
+				fn(band*bandRows, end)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// clampChannel rounds the accumulated value to a channel not greater than
+// max, the premultiplied colors are never greater than the alpha.
+func clampChannel(v float32, max uint8) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= float32(max) {
+		return max
+	}
+	return uint8(v + 0.5)
+}
+
+// resample returns a new RGBA image with the given width and height
+// created by resampling the given image with the filter, the rows are
+// processed on all the cores.
+func resample(srcimg image.Image, newWidth, newHeight int, filter Filter) *image.RGBA {
+	if newWidth < 0 {
+		newWidth = 0
+	}
+	if newHeight < 0 {
+		newHeight = 0
+	}
+	dstimg := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	w, h := GetSize(srcimg)
+	if w == 0 || h == 0 || newWidth == 0 || newHeight == 0 {
+		return dstimg
+	}
+
+	src, ok := srcimg.(*image.RGBA)
+	if !ok || src.Rect.Min != (image.Point{}) {
+		src = convertToRGBA(srcimg)
+	}
+
+	k, ok := kernels[filter]
+	if !ok {
+		resampleNearest(src, dstimg)
+		return dstimg
+	}
+
+	// scale horizontally to tmp, then vertically to dstimg
+	tmp := src
+	if newWidth != w {
+		tmp = image.NewRGBA(image.Rect(0, 0, newWidth, h))
+		resampleHorizontal(src, tmp, getContributions(w, newWidth, k))
+	}
+	if newHeight != h {
+		resampleVertical(tmp, dstimg, getContributions(h, newHeight, k))
+	} else {
+		for y := 0; y < h; y++ {
+			copy(dstimg.Pix[y*dstimg.Stride:y*dstimg.Stride+newWidth*4], tmp.Pix[y*tmp.Stride:])
+		}
+	}
+	return dstimg
+}
+
+func resampleNearest(src, dst *image.RGBA) {
+	w, h := GetSize(src)
+	dw, dh := GetSize(dst)
+	xs := make([]int, dw)
+	for x := range xs {
+		xs[x] = int((float64(x) + 0.5) * float64(w) / float64(dw))
+		if xs[x] >= w {
+			xs[x] = w - 1
+		}
+	}
+	parallel(dh, func(start, end int) {
+		for y := start; y < end; y++ {
+			sy := int((float64(y) + 0.5) * float64(h) / float64(dh))
+			if sy >= h {
+				sy = h - 1
+			}
+			srcRow := src.Pix[sy*src.Stride:]
+			dstRow := dst.Pix[y*dst.Stride:]
+			for x, sx := range xs {
+				copy(dstRow[x*4:x*4+4], srcRow[sx*4:sx*4+4])
+			}
+		}
+	})
+}
+
+func resampleHorizontal(src, dst *image.RGBA, contribs []contribution) {
+	_, h := GetSize(src)
+	parallel(h, func(start, end int) {
+		for y := start; y < end; y++ {
+			srcRow := src.Pix[y*src.Stride:]
+			dstRow := dst.Pix[y*dst.Stride:]
+			for x, c := range contribs {
+				var r, g, b, a float32
+				i := c.start * 4
+				for _, weight := range c.weights {
+					r += float32(srcRow[i]) * weight
+					g += float32(srcRow[i+1]) * weight
+					b += float32(srcRow[i+2]) * weight
+					a += float32(srcRow[i+3]) * weight
+					i += 4
+				}
+				alpha := clampChannel(a, 0xff)
+				dstRow[x*4] = clampChannel(r, alpha)
+				dstRow[x*4+1] = clampChannel(g, alpha)
+				dstRow[x*4+2] = clampChannel(b, alpha)
+				dstRow[x*4+3] = alpha
+			}
+		}
+	})
+}
+
+func resampleVertical(src, dst *image.RGBA, contribs []contribution) {
+	w, _ := GetSize(dst)
+	parallel(len(contribs), func(start, end int) {
+		// the sums of the channels of a row
+		sums := make([]float32, w*4)
+		for y := start; y < end; y++ {
+			c := contribs[y]
+			for i := range sums {
+				sums[i] = 0
+			}
+			for j, weight := range c.weights {
+				srcRow := src.Pix[(c.start+j)*src.Stride:]
+				for i := range sums {
+					sums[i] += float32(srcRow[i]) * weight
+				}
+			}
+
+			dstRow := dst.Pix[y*dst.Stride:]
+			for i := 0; i < len(sums); i += 4 {
+				alpha := clampChannel(sums[i+3], 0xff)
+				dstRow[i] = clampChannel(sums[i], alpha)
+				dstRow[i+1] = clampChannel(sums[i+1], alpha)
+				dstRow[i+2] = clampChannel(sums[i+2], alpha)
+				dstRow[i+3] = alpha
+			}
+		}
+	})
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package graphic
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var allFilters = []Filter{FilterNearest, FilterBox, FilterBilinear, FilterBicubic, FilterLanczos3}
+
+func newTestImage(w, h int, fn func(x, y int) color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, fn(x, y))
+		}
+	}
+	return img
+}
+
+func TestScaleFilters(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	solid := newTestImage(37, 23, func(x, y int) color.Color { return red })
+	for _, filter := range allFilters {
+		for _, size := range []image.Point{{10, 7}, {37, 23}, {100, 61}, {1, 1}} {
+			dstimg := Scale(solid, size.X, size.Y, filter)
+			require.Equal(t, image.Rect(0, 0, size.X, size.Y), dstimg.Bounds(), filter.String())
+			for y := 0; y < size.Y; y++ {
+				for x := 0; x < size.X; x++ {
+					require.Equal(t, red, dstimg.RGBAAt(x, y), "%s %v %d,%d", filter, size, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestScaleDownAverage(t *testing.T) {
+	// the 1 pixel wide stripes are averaged to gray by the box filter but
+	// aliased by the nearest one
+	stripes := newTestImage(64, 64, func(x, y int) color.Color {
+		if x%2 == 0 {
+			return color.White
+		}
+		return color.Black
+	})
+	dstimg := Scale(stripes, 16, 16, FilterBox)
+	for x := 0; x < 16; x++ {
+		c := dstimg.RGBAAt(x, 8)
+		assert.InDelta(t, 0x80, int(c.R), 1)
+		assert.Equal(t, uint8(0xff), c.A)
+	}
+	dstimg = Scale(stripes, 16, 16)
+	c := dstimg.RGBAAt(8, 8)
+	assert.True(t, c.R == 0 || c.R == 0xff)
+}
+
+func TestScalePremultiplied(t *testing.T) {
+	// the transparent pixels do not darken the opaque ones and the colors
+	// are never greater than the alpha
+	img := newTestImage(8, 8, func(x, y int) color.Color {
+		if x < 4 {
+			return color.RGBA{}
+		}
+		return color.RGBA{0xff, 0xff, 0xff, 0xff}
+	})
+	for _, filter := range allFilters {
+		dstimg := Scale(img, 24, 24, filter)
+		for y := 0; y < 24; y++ {
+			for x := 0; x < 24; x++ {
+				c := dstimg.RGBAAt(x, y)
+				require.True(t, c.R <= c.A && c.G <= c.A && c.B <= c.A, "%s %d,%d %v", filter, x, y, c)
+			}
+		}
+		assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, dstimg.RGBAAt(23, 12), filter.String())
+		assert.Equal(t, color.RGBA{}, dstimg.RGBAAt(0, 12), filter.String())
+	}
+}
+
+func TestScaleSubImage(t *testing.T) {
+	img := newTestImage(20, 20, func(x, y int) color.Color {
+		if x >= 10 {
+			return color.White
+		}
+		return color.Black
+	})
+	sub := img.SubImage(image.Rect(10, 0, 20, 10))
+	for _, filter := range allFilters {
+		dstimg := Scale(sub, 5, 5, filter)
+		assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, dstimg.RGBAAt(0, 0), filter.String())
+	}
+}
+
+func TestScaleEmpty(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	assert.Equal(t, image.Rect(0, 0, 0, 10), Scale(img, 0, 10, FilterBicubic).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 0, 0), Scale(img, -1, -1).Bounds())
+	empty := image.NewRGBA(image.Rect(0, 0, 0, 0))
+	assert.Equal(t, image.Rect(0, 0, 4, 4), Scale(empty, 4, 4, FilterLanczos3).Bounds())
+}
+
+func TestParallel(t *testing.T) {
+	for _, n := range []int{0, 1, bandRows, bandRows*3 + 5} {
+		rows := make([]int, n)
+		parallel(n, func(start, end int) {
+			for i := start; i < end; i++ {
+				rows[i]++
+			}
+		})
+		for i := range rows {
+			assert.Equal(t, 1, rows[i])
+		}
+	}
+}
+
+func TestFillScale(t *testing.T) {
+	img := newTestImage(40, 20, func(x, y int) color.Color { return color.White })
+	dstimg, err := Fill(img, 10, 10, FillScale, FilterBilinear)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), dstimg.Bounds())
+	assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, dstimg.RGBAAt(9, 9))
+}
+
+// doScaleNearestNeighbor is the former implementation of Scale, it is kept
+// for the benchmarks.
+func doScaleNearestNeighbor(img image.Image, newWidth, newHeight int) (newimg *image.RGBA) {
+	w := img.Bounds().Max.X
+	h := img.Bounds().Max.Y
+	newimg = image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+
+	xr := (w<<16)/newWidth + 1
+	yr := (h<<16)/newHeight + 1
+
+	for yo := 0; yo < newHeight; yo++ {
+		y2 := (yo * yr) >> 16
+		for xo := 0; xo < newWidth; xo++ {
+			x2 := (xo * xr) >> 16
+			newimg.Set(xo, yo, img.At(x2, y2))
+		}
+	}
+	return newimg
+}
+
+// a 4K photo to a 1080p wallpaper
+func newBenchmarkImage() image.Image {
+	img := image.NewYCbCr(image.Rect(0, 0, 3840, 2160), image.YCbCrSubsampleRatio420)
+	for i := range img.Y {
+		img.Y[i] = uint8(i)
+	}
+	return img
+}
+
+func BenchmarkScaleFormer(b *testing.B) {
+	img := newBenchmarkImage()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doScaleNearestNeighbor(img, 1920, 1080)
+	}
+}
+
+func benchmarkScale(b *testing.B, filter Filter) {
+	img := newBenchmarkImage()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Scale(img, 1920, 1080, filter)
+	}
+}
+
+func BenchmarkScaleNearest(b *testing.B) {
+	benchmarkScale(b, FilterNearest)
+}
+
+func BenchmarkScaleBox(b *testing.B) {
+	benchmarkScale(b, FilterBox)
+}
+
+func BenchmarkScaleBilinear(b *testing.B) {
+	benchmarkScale(b, FilterBilinear)
+}
+
+func BenchmarkScaleBicubic(b *testing.B) {
+	benchmarkScale(b, FilterBicubic)
+}
+
+func BenchmarkScaleLanczos3(b *testing.B) {
+	benchmarkScale(b, FilterLanczos3)
+}
@@ -5,27 +5,140 @@
 package graphic
 
 import (
+	"bufio"
 	"golang.org/x/image/bmp"
 	"golang.org/x/image/tiff"
 	"image"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
 	"os"
+
+	"github.com/linuxdeepin/go-lib/graphic/exif"
+	"github.com/linuxdeepin/go-lib/graphic/svg"
+	"github.com/linuxdeepin/go-lib/graphic/webp"
 )
 
-// LoadImage load image file and return image.Image object.
+// LoadImage load image file and return image.Image object, the image is
+// rotated by its EXIF orientation to be upright. The SVG images are
+// rendered in their intrinsic sizes, and only the first frames of the GIF
+// animations are loaded, see LoadImageAtSize and LoadAnimation.
 func LoadImage(imgfile string) (img image.Image, err error) {
 	f, err := os.Open(imgfile)
 	if err != nil {
 		return
 	}
 	defer f.Close()
-	img, _, err = image.Decode(f)
+	img, _, err = decodeImage(f)
+	if err != nil {
+		return
+	}
+	if o := getOrientation(f); o != exif.OrientationNormal {
+		img = exif.Apply(img, o)
+	}
 	return
 }
 
-// SaveImage save image.Image object to target file.
+// LoadImageAtSize load image file in the width and height, the aspect
+// ratio is kept if one of them is zero. The SVG images are rendered in the
+// size, and the others are loaded by LoadImage and then scaled by the
+// filter.
+func LoadImageAtSize(imgfile string, width, height int, filter ...Filter) (img image.Image, err error) {
+	f, err := os.Open(imgfile)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if sniff(br).name == string(FormatSvg) {
+		return svg.Render(br, width, height)
+	}
+
+	img, err = LoadImage(imgfile)
+	if err != nil {
+		return
+	}
+	w, h := GetSize(img)
+	if w == 0 || h == 0 {
+		return
+	}
+	switch {
+	case width <= 0 && height <= 0:
+		return
+	case width <= 0:
+		width = int(float64(w)*float64(height)/float64(h) + 0.5)
+	case height <= 0:
+		height = int(float64(h)*float64(width)/float64(w) + 0.5)
+	}
+	if width == w && height == h {
+		return
+	}
+	return Scale(img, width, height, filter...), nil
+}
+
+// decodeImage is the same as image.Decode, but the SVG images which do
+// not start with the XML declaration or the <svg> tag are also decoded.
+func decodeImage(r io.Reader) (image.Image, string, error) {
+	br := bufio.NewReader(r)
+	if sniff(br).name == string(FormatSvg) {
+		img, err := svg.Decode(br)
+		return img, string(FormatSvg), err
+	}
+	return image.Decode(br)
+}
+
+// getOrientation returns the EXIF orientation of the image file.
+func getOrientation(f *os.File) exif.Orientation {
+	_, err := f.Seek(0, io.SeekStart)
+	if err != nil {
+		return exif.OrientationNormal
+	}
+	md, err := exif.Decode(f)
+	if err != nil {
+		return exif.OrientationNormal
+	}
+	return md.Orientation
+}
+
+// StripImageMetadata saves the image file without the metadata such as
+// EXIF. The JPEG images are copied without being encoded again if they
+// are upright already, the others are rotated by the orientation and
+// encoded again in their formats, or png if the formats can not be saved.
+func StripImageMetadata(srcfile, dstfile string) (err error) {
+	f, err := os.Open(srcfile)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, name, err := decodeConfig(f)
+	if err != nil {
+		return
+	}
+	if Format(name) == FormatJpeg && getOrientation(f) == exif.OrientationNormal {
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return
+		}
+		df, err := os.Create(dstfile)
+		if err != nil {
+			return err
+		}
+		defer df.Close()
+		return exif.StripJPEG(df, f)
+	}
+
+	img, err := LoadImage(srcfile)
+	if err != nil {
+		return
+	}
+	// the encoders never write the metadata
+	return SaveImage(dstfile, img, Format(name))
+}
+
+// SaveImage save image.Image object to target file, no metadata is
+// saved. The WebP images are saved losslessly, and the formats which can
+// not be saved such as svg are saved in png.
 func SaveImage(dstfile string, m image.Image, f Format) (err error) {
 	df, err := openFileOrCreate(dstfile)
 	if err != nil {
@@ -45,6 +158,10 @@ func doSaveImage(w io.Writer, m image.Image, f Format) (err error) {
 		err = bmp.Encode(w, m)
 	case FormatTiff:
 		err = tiff.Encode(w, m, nil)
+	case FormatGif:
+		err = gif.Encode(w, m, nil)
+	case FormatWebp:
+		err = webp.Encode(w, m)
 	default:
 		err = png.Encode(w, m)
 	}
@@ -7,16 +7,18 @@ package graphic
 import (
 	"fmt"
 	"image"
+
+	"github.com/linuxdeepin/go-lib/graphic/thumbcache"
 )
 
 // ScaleImage returns a new image file with the given width and
-// height created by resizing the given image.
-func ScaleImage(srcfile, dstfile string, newWidth, newHeight int, f Format) (err error) {
+// height created by resizing the given image, see Scale for the filter.
+func ScaleImage(srcfile, dstfile string, newWidth, newHeight int, f Format, filter ...Filter) (err error) {
 	srcimg, err := LoadImage(srcfile)
 	if err != nil {
 		return
 	}
-	dstimg := Scale(srcimg, newWidth, newHeight)
+	dstimg := Scale(srcimg, newWidth, newHeight, filter...)
 	err = SaveImage(dstfile, dstimg, f)
 	dstimg.Pix = nil
 	return
@@ -24,12 +26,12 @@ func ScaleImage(srcfile, dstfile string, newWidth, newHeight int, f Format) (err
 
 // ScaleImagePrefer resize image file to new width and heigh, and
 // maintain the original proportions unchanged.
-func ScaleImagePrefer(srcfile, dstfile string, newWidth, newHeight int, f Format) (err error) {
+func ScaleImagePrefer(srcfile, dstfile string, newWidth, newHeight int, f Format, filter ...Filter) (err error) {
 	srcimg, err := LoadImage(srcfile)
 	if err != nil {
 		return
 	}
-	dstimg, err := ScalePrefer(srcimg, newWidth, newHeight)
+	dstimg, err := ScalePrefer(srcimg, newWidth, newHeight, filter...)
 	if err != nil {
 		return
 	}
@@ -40,23 +42,20 @@ func ScaleImagePrefer(srcfile, dstfile string, newWidth, newHeight int, f Format
 
 // ScaleImageCache resize any recognized format image file and save to cache
 // directory, if already exists, just return it.
-func ScaleImageCache(srcfile string, newWidth, newHeight int, f Format) (dstfile string, useCache bool, err error) {
-	dstfile = generateCacheFilePath(fmt.Sprintf("ScaleImageCache%s%d%d%s", srcfile, newWidth, newHeight, f))
-	if isFileExists(dstfile) {
-		useCache = true
-		return
-	}
-	err = ScaleImage(srcfile, dstfile, newWidth, newHeight, f)
-	return
+func ScaleImageCache(srcfile string, newWidth, newHeight int, f Format, filter ...Filter) (dstfile string, useCache bool, err error) {
+	params := fmt.Sprintf("ScaleImageCache%d,%d,%s,%s", newWidth, newHeight, f, getFilter(filter))
+	return getCacheFile(srcfile, params, f, func(dstfile string) error {
+		return ScaleImage(srcfile, dstfile, newWidth, newHeight, f, filter...)
+	})
 }
 
 // ThumbnailImage resize target image file with limited maximum width and height.
-func ThumbnailImage(srcfile, dstfile string, maxWidth, maxHeight int, f Format) (err error) {
+func ThumbnailImage(srcfile, dstfile string, maxWidth, maxHeight int, f Format, filter ...Filter) (err error) {
 	srcimg, err := LoadImage(srcfile)
 	if err != nil {
 		return
 	}
-	dstimg := Thumbnail(srcimg, maxWidth, maxHeight)
+	dstimg := Thumbnail(srcimg, maxWidth, maxHeight, filter...)
 	err = SaveImage(dstfile, dstimg, f)
 	dstimg.Pix = nil
 	return
@@ -64,25 +63,51 @@ func ThumbnailImage(srcfile, dstfile string, maxWidth, maxHeight int, f Format)
 
 // ThumbnailImageCache resize target image file with limited maximum width
 // and height, and save to cache directory, if already exists, just
-// return it.
-func ThumbnailImageCache(srcfile string, maxWidth, maxHeight int, f Format) (dstfile string, useCache bool, err error) {
-	dstfile = generateCacheFilePath(fmt.Sprintf("ThumbnailImageCache%s%d%d%s", srcfile, maxWidth, maxHeight, f))
-	if isFileExists(dstfile) {
-		useCache = true
+// return it. The png thumbnails of the standard sizes, 128, 256 and 512,
+// are saved to the thumbnail directory shared with the other
+// applications.
+func ThumbnailImageCache(srcfile string, maxWidth, maxHeight int, f Format, filter ...Filter) (dstfile string, useCache bool, err error) {
+	size := thumbcache.Size(maxWidth)
+	if f == FormatPng && maxWidth == maxHeight && size.Dir() != "" {
+		return thumbnailImageStandardCache(srcfile, size, filter...)
+	}
+	params := fmt.Sprintf("ThumbnailImageCache%d,%d,%s,%s", maxWidth, maxHeight, f, getFilter(filter))
+	return getCacheFile(srcfile, params, f, func(dstfile string) error {
+		return ThumbnailImage(srcfile, dstfile, maxWidth, maxHeight, f, filter...)
+	})
+}
+
+func thumbnailImageStandardCache(srcfile string, size thumbcache.Size, filter ...Filter) (dstfile string, useCache bool, err error) {
+	c := thumbcache.Default()
+	if dstfile, ok := c.Lookup(srcfile, size); ok {
+		return dstfile, true, nil
+	}
+	if c.Failed(srcfile) {
+		return "", false, fmt.Errorf("failed to make the thumbnail of %s before", srcfile)
+	}
+	srcimg, err := LoadImage(srcfile)
+	if err != nil {
+		_ = c.SaveFailed(srcfile)
 		return
 	}
-	err = ThumbnailImage(srcfile, dstfile, maxWidth, maxHeight, f)
+	dstimg := Thumbnail(srcimg, int(size), int(size), filter...)
+	dstfile, err = c.Save(srcfile, size, dstimg, "")
+	dstimg.Pix = nil
 	return
 }
 
-// Scale resize image object to new width and height.
-func Scale(srcimg image.Image, newWidth, newHeight int) (dstimg *image.RGBA) {
-	dstimg = doScaleNearestNeighbor(srcimg, newWidth, newHeight)
+// Scale resize image object to new width and height. The filter is
+// optional, FilterNearest is used if it is not given, the better filters
+// such as FilterLanczos3 are slower but the result is not aliased. The
+// image is scaled on all the cores.
+func Scale(srcimg image.Image, newWidth, newHeight int, filter ...Filter) (dstimg *image.RGBA) {
+	dstimg = resample(srcimg, newWidth, newHeight, getFilter(filter))
 	return
 }
 
-// Thumbnail resize image object with limited maximum width and height.
-func Thumbnail(srcimg image.Image, maxWidth, maxHeight int) (dstimg *image.RGBA) {
+// Thumbnail resize image object with limited maximum width and height,
+// see Scale for the filter.
+func Thumbnail(srcimg image.Image, maxWidth, maxHeight int, filter ...Filter) (dstimg *image.RGBA) {
 	// get new width and heigh
 	var newWidth, newHeight int
 	w, h := GetSize(srcimg)
@@ -93,46 +118,22 @@ func Thumbnail(srcimg image.Image, maxWidth, maxHeight int) (dstimg *image.RGBA)
 		newHeight = maxHeight
 		newWidth = int(float32(newHeight) * scale)
 	}
-	return Scale(srcimg, newWidth, newHeight)
+	return Scale(srcimg, newWidth, newHeight, filter...)
 }
 
 // ScalePrefer resize image object to new width and heigh, and
-// maintain the original proportions unchanged.
-func ScalePrefer(srcimg image.Image, newWidth, newHeight int) (dstimg *image.RGBA, err error) {
+// maintain the original proportions unchanged, see Scale for the filter.
+func ScalePrefer(srcimg image.Image, newWidth, newHeight int, filter ...Filter) (dstimg *image.RGBA, err error) {
 	iw, ih := GetSize(srcimg)
 	x, y, w, h, err := GetPreferScaleClipRect(newWidth, newHeight, iw, ih)
 	if err != nil {
 		return
 	}
 	dstimg = Clip(srcimg, x, y, w, h)
-	dstimg = Scale(dstimg, newWidth, newHeight)
+	dstimg = Scale(dstimg, newWidth, newHeight, filter...)
 	return
 }
 
-// TODO doScaleNearestNeighbor returns a new RGBA image with the given width and
-// height created by resizing the given image using the nearest neighbor
-// algorithm.
-func doScaleNearestNeighbor(img image.Image, newWidth, newHeight int) (newimg *image.RGBA) {
-	w := img.Bounds().Max.X
-	h := img.Bounds().Max.Y
-	newimg = image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
-
-	xr := (w<<16)/newWidth + 1
-	yr := (h<<16)/newHeight + 1
-
-	for yo := 0; yo < newHeight; yo++ {
-		y2 := (yo * yr) >> 16
-		for xo := 0; xo < newWidth; xo++ {
-			x2 := (xo * xr) >> 16
-			newimg.Set(xo, yo, img.At(x2, y2))
-			//Much faster, but requires some image type.
-			//newimg.Pix[offset] = img.Pix[y2*w+x2]
-			//offset++
-		}
-	}
-	return newimg
-}
-
 // GetPreferScaleClipRect get the maximum rectangle in center of
 // image which with the same scale to reference width/heigh.
 func GetPreferScaleClipRect(refWidth, refHeight, imgWidth, imgHeight int) (x, y, w, h int, err error) {
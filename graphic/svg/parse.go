@@ -0,0 +1,371 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package svg
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// matrix is the affine transform, which maps x, y to
+// a*x + c*y + e, b*x + d*y + f.
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+func translateMatrix(tx, ty float64) matrix {
+	return matrix{1, 0, 0, 1, tx, ty}
+}
+
+func scaleMatrix(sx, sy float64) matrix {
+	return matrix{sx, 0, 0, sy, 0, 0}
+}
+
+// mul returns the transform applying n and then m.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m matrix) apply(p point) point {
+	return point{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+func (m matrix) invert() (matrix, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 || math.IsNaN(det) {
+		return matrix{}, false
+	}
+	return matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}, true
+}
+
+// scale returns how much the lengths are scaled in average.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// parseStyle adds the declarations of the style attribute to attrs.
+func parseStyle(style string, attrs map[string]string) {
+	for _, decl := range strings.Split(style, ";") {
+		i := strings.IndexByte(decl, ':')
+		if i < 0 {
+			continue
+		}
+		name := strings.TrimSpace(decl[:i])
+		value := strings.TrimSpace(decl[i+1:])
+		value = strings.TrimSpace(strings.TrimSuffix(value, "!important"))
+		if name != "" {
+			attrs[name] = value
+		}
+	}
+}
+
+// numberScanner reads the numbers separated by the spaces or commas.
+type numberScanner struct {
+	s   string
+	pos int
+}
+
+func (sc *numberScanner) skipSeparators() {
+	for sc.pos < len(sc.s) {
+		switch sc.s[sc.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			sc.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (sc *numberScanner) done() bool {
+	sc.skipSeparators()
+	return sc.pos >= len(sc.s)
+}
+
+// number reads the next number, such as "-1.5e2", the numbers may not be
+// separated, such as "1-2.5.5" which is 1, -2.5 and 0.5.
+func (sc *numberScanner) number() (float64, bool) {
+	sc.skipSeparators()
+	start := sc.pos
+	s := sc.s
+	i := start
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	v, err := strconv.ParseFloat(s[start:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	sc.pos = i
+	return v, true
+}
+
+// flag reads the flag of the arcs, which is "0" or "1" and may not be
+// separated from the next number.
+func (sc *numberScanner) flag() (bool, bool) {
+	sc.skipSeparators()
+	if sc.pos < len(sc.s) {
+		switch sc.s[sc.pos] {
+		case '0':
+			sc.pos++
+			return false, true
+		case '1':
+			sc.pos++
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// parseNumbers parses the list of numbers, such as the view box.
+func parseNumbers(s string) ([]float64, bool) {
+	sc := &numberScanner{s: s}
+	var result []float64
+	for !sc.done() {
+		v, ok := sc.number()
+		if !ok {
+			return nil, false
+		}
+		result = append(result, v)
+	}
+	return result, len(result) > 0
+}
+
+// the pixels of the units
+var units = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 96.0 / 72,
+	"pc": 96.0 / 6,
+	"mm": 96 / 25.4,
+	"cm": 96 / 2.54,
+	"in": 96,
+	// the font size is assumed to be 16 pixels
+	"em": 16,
+	"ex": 8,
+}
+
+// parseLength parses the length such as "10", "2mm" and "50%", the
+// percentage is of ref. It returns def if s is not a valid length.
+func parseLength(s string, ref, def float64) float64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def
+	}
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
+		if err != nil {
+			return def
+		}
+		return v / 100 * ref
+	}
+	i := len(s)
+	for i > 0 && (s[i-1] >= 'a' && s[i-1] <= 'z' || s[i-1] >= 'A' && s[i-1] <= 'Z') {
+		i--
+	}
+	unit, ok := units[strings.ToLower(s[i:])]
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil {
+		return def
+	}
+	return v * unit
+}
+
+// parseAbsoluteLength parses the length which is not a percentage, it
+// returns 0 if s is not such a length.
+func parseAbsoluteLength(s string) float64 {
+	if strings.HasSuffix(strings.TrimSpace(s), "%") {
+		return 0
+	}
+	return parseLength(s, 0, 0)
+}
+
+// parseNumber parses the number or the percentage, which is of 1.
+func parseNumber(s string, def float64) float64 {
+	return parseLength(s, 1, def)
+}
+
+// parseColor parses the color such as "#fff", "#ff8000", "rgb(255, 128,
+// 0)" and "orange". It returns false if s is not a valid color.
+func parseColor(s string) (color.NRGBA, bool) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		switch len(hex) {
+		case 3:
+			return color.NRGBA{uint8(v>>8) * 0x11, uint8(v>>4&0xf) * 0x11, uint8(v&0xf) * 0x11, 0xff}, true
+		case 4:
+			return color.NRGBA{uint8(v>>12) * 0x11, uint8(v>>8&0xf) * 0x11, uint8(v>>4&0xf) * 0x11,
+				uint8(v&0xf) * 0x11}, true
+		case 6:
+			return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, true
+		case 8:
+			return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+		}
+		return color.NRGBA{}, false
+
+	case strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba("):
+		i := strings.IndexByte(s, '(')
+		if !strings.HasSuffix(s, ")") {
+			return color.NRGBA{}, false
+		}
+		args := strings.FieldsFunc(s[i+1:len(s)-1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '/'
+		})
+		if len(args) != 3 && len(args) != 4 {
+			return color.NRGBA{}, false
+		}
+		var c [4]uint8
+		c[3] = 0xff
+		for j, arg := range args {
+			var v float64
+			if j == 3 {
+				v = parseNumber(arg, -1) * 255
+			} else {
+				v = parseLength(arg, 255, -1)
+			}
+			if v < 0 {
+				return color.NRGBA{}, false
+			}
+			c[j] = clampByte(v)
+		}
+		return color.NRGBA{c[0], c[1], c[2], c[3]}, true
+
+	case strings.EqualFold(s, "transparent"):
+		return color.NRGBA{}, true
+	}
+
+	c, ok := colornames.Map[strings.ToLower(s)]
+	if !ok {
+		return color.NRGBA{}, false
+	}
+	// the named colors are opaque
+	return color.NRGBA{c.R, c.G, c.B, c.A}, true
+}
+
+func clampByte(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// parseTransform parses the transform list, such as
+// "translate(10, 20) rotate(45)".
+func parseTransform(s string) (matrix, bool) {
+	m := identity
+	s = strings.TrimSpace(s)
+	for s != "" {
+		open := strings.IndexByte(s, '(')
+		end := strings.IndexByte(s, ')')
+		if open < 0 || end < open {
+			return identity, false
+		}
+		name := strings.TrimSpace(s[:open])
+		args, ok := parseNumbers(s[open+1 : end])
+		if !ok {
+			return identity, false
+		}
+		s = strings.TrimLeft(s[end+1:], " \t\r\n,")
+
+		var t matrix
+		switch {
+		case name == "matrix" && len(args) == 6:
+			copy(t[:], args)
+		case name == "translate" && len(args) == 1:
+			t = translateMatrix(args[0], 0)
+		case name == "translate" && len(args) == 2:
+			t = translateMatrix(args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			t = scaleMatrix(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			t = scaleMatrix(args[0], args[1])
+		case name == "rotate" && (len(args) == 1 || len(args) == 3):
+			sin, cos := math.Sincos(args[0] * math.Pi / 180)
+			t = matrix{cos, sin, -sin, cos, 0, 0}
+			if len(args) == 3 {
+				t = translateMatrix(args[1], args[2]).mul(t).mul(translateMatrix(-args[1], -args[2]))
+			}
+		case name == "skewX" && len(args) == 1:
+			t = matrix{1, 0, math.Tan(args[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(args) == 1:
+			t = matrix{1, math.Tan(args[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return identity, false
+		}
+		m = m.mul(t)
+	}
+	return m, true
+}
+
+// parseURL returns the id referenced by the value such as "url(#id)".
+func parseURL(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "url(") {
+		return "", false
+	}
+	end := strings.IndexByte(s, ')')
+	if end < 0 {
+		return "", false
+	}
+	ref := strings.Trim(strings.TrimSpace(s[4:end]), `"'`)
+	if !strings.HasPrefix(ref, "#") {
+		return "", false
+	}
+	return ref[1:], true
+}
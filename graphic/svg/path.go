@@ -0,0 +1,498 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package svg
+
+import (
+	"math"
+)
+
+type point struct {
+	x, y float64
+}
+
+func (p point) add(q point) point {
+	return point{p.x + q.x, p.y + q.y}
+}
+
+func (p point) sub(q point) point {
+	return point{p.x - q.x, p.y - q.y}
+}
+
+func (p point) mul(k float64) point {
+	return point{p.x * k, p.y * k}
+}
+
+func (p point) length() float64 {
+	return math.Hypot(p.x, p.y)
+}
+
+// subpath is the polyline flattened from the curves.
+type subpath struct {
+	points []point
+	closed bool
+}
+
+// pathBuilder flattens the path in the user space, the curves are split
+// by their lengths in the device space, which m transforms to.
+type pathBuilder struct {
+	m        matrix
+	subpaths []subpath
+	// the current point and the start of the current subpath
+	pos, start point
+	// the last control point for the smooth curves, and whether the last
+	// segment is a cubic or a quadratic curve
+	ctrl        point
+	lastCommand byte
+}
+
+func (b *pathBuilder) current() *subpath {
+	if len(b.subpaths) == 0 {
+		b.moveTo(b.pos)
+	}
+	return &b.subpaths[len(b.subpaths)-1]
+}
+
+func (b *pathBuilder) moveTo(p point) {
+	if n := len(b.subpaths); n > 0 && len(b.subpaths[n-1].points) == 1 && !b.subpaths[n-1].closed {
+		// replace the empty subpath
+		b.subpaths[n-1].points[0] = p
+	} else {
+		b.subpaths = append(b.subpaths, subpath{points: []point{p}})
+	}
+	b.pos, b.start = p, p
+}
+
+func (b *pathBuilder) lineTo(p point) {
+	sp := b.current()
+	if sp.closed {
+		// a new subpath starts at the start of the closed one
+		b.moveTo(b.start)
+		sp = b.current()
+	}
+	sp.points = append(sp.points, p)
+	b.pos = p
+}
+
+func (b *pathBuilder) close() {
+	if len(b.subpaths) == 0 {
+		return
+	}
+	sp := &b.subpaths[len(b.subpaths)-1]
+	sp.closed = true
+	b.pos = b.start
+}
+
+// the max distance in pixels between the curves and the lines flattened
+const tolerance = 0.025
+
+// clampSegments limits the number of the lines a curve is flattened to.
+func clampSegments(n float64) int {
+	if n < 1 || math.IsNaN(n) {
+		return 1
+	}
+	if n > 256 {
+		return 256
+	}
+	return int(math.Ceil(n))
+}
+
+// segments returns how many lines the curve is flattened to, d is the max
+// second difference of its control points in the device space, which is
+// scaled by k for the degree of the curve.
+func (b *pathBuilder) segments(k float64, d ...point) int {
+	max := 0.0
+	for _, p := range d {
+		q := point{b.m[0]*p.x + b.m[2]*p.y, b.m[1]*p.x + b.m[3]*p.y}
+		max = math.Max(max, q.length())
+	}
+	return clampSegments(math.Sqrt(k * max / tolerance))
+}
+
+func (b *pathBuilder) cubicTo(c1, c2, p point) {
+	p0 := b.pos
+	n := b.segments(0.75, p0.sub(c1.mul(2)).add(c2), c1.sub(c2.mul(2)).add(p))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		b.lineTo(point{
+			u*u*u*p0.x + 3*u*u*t*c1.x + 3*u*t*t*c2.x + t*t*t*p.x,
+			u*u*u*p0.y + 3*u*u*t*c1.y + 3*u*t*t*c2.y + t*t*t*p.y,
+		})
+	}
+}
+
+func (b *pathBuilder) quadTo(c, p point) {
+	p0 := b.pos
+	n := b.segments(0.25, p0.sub(c.mul(2)).add(p))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		b.lineTo(point{
+			u*u*p0.x + 2*u*t*c.x + t*t*p.x,
+			u*u*p0.y + 2*u*t*c.y + t*t*p.y,
+		})
+	}
+}
+
+// arcTo adds the elliptical arc of the path data, the angle is in degrees.
+func (b *pathBuilder) arcTo(rx, ry, angle float64, large, sweep bool, p point) {
+	p0 := b.pos
+	if p0 == p {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		b.lineTo(p)
+		return
+	}
+
+	// the conversion from the endpoints to the center, see the
+	// implementation notes of SVG
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	dx, dy := (p0.x-p.x)/2, (p0.y-p.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	// the radii are scaled up if they are too small
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		k := math.Sqrt(lambda)
+		rx, ry = rx*k, ry*k
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := 0.0
+	if num > 0 && den > 0 {
+		k = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		k = -k
+	}
+	cx1 := k * rx * y1 / ry
+	cy1 := -k * ry * x1 / rx
+	cx := cos*cx1 - sin*cy1 + (p0.x+p.x)/2
+	cy := sin*cx1 + cos*cy1 + (p0.y+p.y)/2
+
+	angleOf := func(ux, uy float64) float64 {
+		return math.Atan2(uy, ux)
+	}
+	theta := angleOf((x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angleOf((-x1-cx1)/rx, (-y1-cy1)/ry) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	// the chord of the angle a is at most r*a*a/8 from the arc
+	n := clampSegments(math.Abs(delta) / math.Sqrt(8*tolerance/(math.Max(rx, ry)*b.m.scale())))
+	for i := 1; i < n; i++ {
+		a := theta + delta*float64(i)/float64(n)
+		s, c := math.Sincos(a)
+		b.lineTo(point{
+			cx + cos*rx*c - sin*ry*s,
+			cy + sin*rx*c + cos*ry*s,
+		})
+	}
+	// exactly at the end point
+	b.lineTo(p)
+}
+
+// ellipse adds the closed ellipse.
+func (b *pathBuilder) ellipse(cx, cy, rx, ry float64) {
+	b.moveTo(point{cx + rx, cy})
+	b.arcTo(rx, ry, 0, false, true, point{cx - rx, cy})
+	b.arcTo(rx, ry, 0, false, true, point{cx + rx, cy})
+	b.close()
+}
+
+// parsePathData adds the path of the path data such as "M 0 0 L 10 10 Z",
+// the path is kept until the first error as the specification says.
+func (b *pathBuilder) parsePathData(d string) {
+	sc := &numberScanner{s: d}
+	var command byte
+	for !sc.done() {
+		c := d[sc.pos]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+			command = c
+			sc.pos++
+			if command == 'z' || command == 'Z' {
+				b.close()
+				b.lastCommand = command
+				continue
+			}
+		} else if command == 0 || command == 'z' || command == 'Z' {
+			return
+		}
+		if !b.parseCommand(sc, command) {
+			return
+		}
+		b.lastCommand = command
+		// the numbers after the moveto are the lineto
+		if command == 'M' {
+			command = 'L'
+		} else if command == 'm' {
+			command = 'l'
+		}
+	}
+}
+
+// the numbers of the arguments of the commands
+var commandArgs = map[byte]int{'m': 2, 'l': 2, 'h': 1, 'v': 1, 'c': 6, 's': 4, 'q': 4, 't': 2, 'a': 7}
+
+// parseCommand adds the segment of the command with the arguments read
+// from sc.
+func (b *pathBuilder) parseCommand(sc *numberScanner, command byte) bool {
+	var args [7]float64
+	var flags [2]bool
+	lower := command | 0x20
+	count, ok := commandArgs[lower]
+	if !ok {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		if lower == 'a' && (i == 3 || i == 4) {
+			if flags[i-3], ok = sc.flag(); !ok {
+				return false
+			}
+			continue
+		}
+		if args[i], ok = sc.number(); !ok {
+			return false
+		}
+	}
+
+	relative := command == lower
+	origin := point{}
+	if relative {
+		origin = b.pos
+	}
+	pt := func(i int) point {
+		return point{args[i] + origin.x, args[i+1] + origin.y}
+	}
+	// the reflection of the last control point if the last segment is
+	// the same kind of curve
+	reflect := func(kinds string) point {
+		last := b.lastCommand | 0x20
+		for i := 0; i < len(kinds); i++ {
+			if last == kinds[i] {
+				return b.pos.mul(2).sub(b.ctrl)
+			}
+		}
+		return b.pos
+	}
+
+	switch lower {
+	case 'm':
+		b.moveTo(pt(0))
+	case 'l':
+		b.lineTo(pt(0))
+	case 'h':
+		b.lineTo(point{args[0] + origin.x, b.pos.y})
+	case 'v':
+		b.lineTo(point{b.pos.x, args[0] + origin.y})
+	case 'c':
+		b.ctrl = pt(2)
+		b.cubicTo(pt(0), b.ctrl, pt(4))
+	case 's':
+		c1 := reflect("cs")
+		b.ctrl = pt(0)
+		b.cubicTo(c1, b.ctrl, pt(2))
+	case 'q':
+		b.ctrl = pt(0)
+		b.quadTo(b.ctrl, pt(2))
+	case 't':
+		b.ctrl = reflect("qt")
+		b.quadTo(b.ctrl, pt(0))
+	case 'a':
+		b.arcTo(args[0], args[1], args[2], flags[0], flags[1], pt(5))
+	}
+	return true
+}
+
+// bounds returns the bounding box of the path in the user space.
+func (b *pathBuilder) bounds() (min, max point) {
+	min = point{math.Inf(1), math.Inf(1)}
+	max = point{math.Inf(-1), math.Inf(-1)}
+	for _, sp := range b.subpaths {
+		for _, p := range sp.points {
+			min.x, min.y = math.Min(min.x, p.x), math.Min(min.y, p.y)
+			max.x, max.y = math.Max(max.x, p.x), math.Max(max.y, p.y)
+		}
+	}
+	return
+}
+
+// polygons returns the subpaths transformed to the device space, which
+// are closed for filling.
+func (b *pathBuilder) polygons() [][]point {
+	var result [][]point
+	for _, sp := range b.subpaths {
+		if len(sp.points) < 3 {
+			continue
+		}
+		poly := make([]point, len(sp.points))
+		for i, p := range sp.points {
+			poly[i] = b.m.apply(p)
+		}
+		result = append(result, poly)
+	}
+	return result
+}
+
+// strokeStyle is how the lines are stroked.
+type strokeStyle struct {
+	width      float64
+	lineCap    string
+	lineJoin   string
+	miterLimit float64
+}
+
+// orientedPolygon returns the polygon in the device space, whose vertices
+// are clockwise in the device space, so that the polygons of a stroke
+// are united by the nonzero rule.
+func orientedPolygon(m matrix, points ...point) []point {
+	poly := make([]point, len(points))
+	area := 0.0
+	for i, p := range points {
+		poly[i] = m.apply(p)
+	}
+	for i := range poly {
+		p, q := poly[i], poly[(i+1)%len(poly)]
+		area += p.x*q.y - q.x*p.y
+	}
+	if area < 0 {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+	return poly
+}
+
+// circlePolygon returns the polygon of the circle in the user space.
+func (b *pathBuilder) circlePolygon(c point, r float64) []point {
+	n := clampSegments(2*math.Pi/math.Sqrt(8*tolerance/(r*b.m.scale()))) + 4
+	points := make([]point, n)
+	for i := range points {
+		s, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		points[i] = point{c.x + r*cos, c.y + r*s}
+	}
+	return orientedPolygon(b.m, points...)
+}
+
+// strokePolygons returns the polygons in the device space covering the
+// stroke of the path, they should be filled by the nonzero rule.
+func (b *pathBuilder) strokePolygons(st strokeStyle) [][]point {
+	if st.width <= 0 {
+		return nil
+	}
+	hw := st.width / 2
+	var result [][]point
+	for _, sp := range b.subpaths {
+		// the duplicated points are removed
+		points := make([]point, 0, len(sp.points))
+		for _, p := range sp.points {
+			if len(points) == 0 || p != points[len(points)-1] {
+				points = append(points, p)
+			}
+		}
+		if sp.closed && len(points) > 1 && points[0] == points[len(points)-1] {
+			points = points[:len(points)-1]
+		}
+
+		if len(points) == 1 {
+			// the zero length subpath is drawn by the caps only
+			p := points[0]
+			switch st.lineCap {
+			case "round":
+				result = append(result, b.circlePolygon(p, hw))
+			case "square":
+				result = append(result, orientedPolygon(b.m,
+					point{p.x - hw, p.y - hw}, point{p.x + hw, p.y - hw},
+					point{p.x + hw, p.y + hw}, point{p.x - hw, p.y + hw}))
+			}
+			continue
+		}
+
+		n := len(points)
+		segments := n - 1
+		if sp.closed {
+			segments = n
+		}
+		normal := func(i int) (point, point) {
+			p, q := points[i], points[(i+1)%n]
+			d := q.sub(p)
+			l := d.length()
+			return d.mul(1 / l), point{-d.y / l * hw, d.x / l * hw}
+		}
+		for i := 0; i < segments; i++ {
+			p, q := points[i], points[(i+1)%n]
+			_, nm := normal(i)
+			result = append(result, orientedPolygon(b.m, p.add(nm), q.add(nm), q.sub(nm), p.sub(nm)))
+		}
+
+		// the joins at the vertices between the segments
+		for k := 0; k < n; k++ {
+			if !sp.closed && (k == 0 || k == n-1) {
+				continue
+			}
+			result = append(result, b.joinPolygons(st, points[k], normal, (k+n-1)%n, k)...)
+		}
+
+		if !sp.closed {
+			first, nFirst := normal(0)
+			last, nLast := normal(n - 2)
+			switch st.lineCap {
+			case "round":
+				result = append(result, b.circlePolygon(points[0], hw), b.circlePolygon(points[n-1], hw))
+			case "square":
+				p0 := points[0]
+				e0 := p0.sub(first.mul(hw))
+				result = append(result, orientedPolygon(b.m, p0.add(nFirst), e0.add(nFirst), e0.sub(nFirst), p0.sub(nFirst)))
+				p1 := points[n-1]
+				e1 := p1.add(last.mul(hw))
+				result = append(result, orientedPolygon(b.m, p1.add(nLast), e1.add(nLast), e1.sub(nLast), p1.sub(nLast)))
+			}
+		}
+	}
+	return result
+}
+
+// joinPolygons returns the polygons of the join at p, between the segment i
+// and the segment j.
+func (b *pathBuilder) joinPolygons(st strokeStyle, p point, normal func(int) (point, point), i, j int) [][]point {
+	hw := st.width / 2
+	d0, n0 := normal(i)
+	d1, n1 := normal(j)
+	cross := d0.x*d1.y - d0.y*d1.x
+	if math.Abs(cross) < 1e-9 && d0.x*d1.x+d0.y*d1.y > 0 {
+		// straight
+		return nil
+	}
+	if st.lineJoin == "round" {
+		return [][]point{b.circlePolygon(p, hw)}
+	}
+	// the outer side of the turn
+	if cross > 0 {
+		n0, n1 = n0.mul(-1), n1.mul(-1)
+	}
+	a, c := p.add(n0), p.add(n1)
+	if st.lineJoin == "bevel" || st.lineJoin == "miter" && st.miterLimit < 1 {
+		return [][]point{orientedPolygon(b.m, p, a, c)}
+	}
+	// the miter is at the intersection of the outer edges
+	cosTheta := d0.x*d1.x + d0.y*d1.y
+	// the ratio of the miter length to the stroke width is
+	// 1 / sin(theta / 2), theta is the angle between the segments
+	sinHalf := math.Sqrt((1 + cosTheta) / 2)
+	if sinHalf == 0 || 1/sinHalf > st.miterLimit {
+		return [][]point{orientedPolygon(b.m, p, a, c)}
+	}
+	bisector := n0.add(n1)
+	l := bisector.length()
+	if l == 0 {
+		return [][]point{orientedPolygon(b.m, p, a, c)}
+	}
+	tip := p.add(bisector.mul(hw / sinHalf / l))
+	return [][]point{orientedPolygon(b.m, p, a, tip, c)}
+}
@@ -0,0 +1,295 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package svg
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// the scanlines sampled in each row of pixels, the coverage of the
+// pixels in the scanlines is exact
+const subsamples = 16
+
+type edge struct {
+	x0, y0, x1, y1 float64
+	// 1 if the edge goes down, -1 if up
+	dir int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// mask is the coverage of the pixels in rect, in 0 to 1.
+type mask struct {
+	rect  image.Rectangle
+	alpha []float32
+}
+
+// rasterize returns the coverage of the polygons in the bounds, which are
+// filled by the nonzero or the even-odd rule.
+func rasterize(polys [][]point, evenOdd bool, bounds image.Rectangle) *mask {
+	var edges []edge
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for i, p := range poly {
+			q := poly[(i+1)%len(poly)]
+			if math.IsNaN(p.x) || math.IsNaN(p.y) || math.IsInf(p.x, 0) || math.IsInf(p.y, 0) {
+				return nil
+			}
+			minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+			minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+			switch {
+			case p.y < q.y:
+				edges = append(edges, edge{p.x, p.y, q.x, q.y, 1})
+			case p.y > q.y:
+				edges = append(edges, edge{q.x, q.y, p.x, p.y, -1})
+			}
+		}
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+	rect := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)),
+		int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(bounds)
+	if rect.Empty() {
+		return nil
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].y0 < edges[j].y0
+	})
+
+	m := &mask{rect: rect, alpha: make([]float32, rect.Dx()*rect.Dy())}
+	width := rect.Dx()
+	var active []edge
+	var crossings []crossing
+	next := 0
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		row := m.alpha[(py-rect.Min.Y)*width : (py-rect.Min.Y+1)*width]
+		for s := 0; s < subsamples; s++ {
+			sy := float64(py) + (float64(s)+0.5)/subsamples
+			for next < len(edges) && edges[next].y0 <= sy {
+				active = append(active, edges[next])
+				next++
+			}
+			crossings = crossings[:0]
+			n := 0
+			for _, e := range active {
+				if e.y1 <= sy {
+					continue
+				}
+				active[n] = e
+				n++
+				if e.y0 <= sy {
+					x := e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+					crossings = append(crossings, crossing{x, e.dir})
+				}
+			}
+			active = active[:n]
+			sort.Slice(crossings, func(i, j int) bool {
+				return crossings[i].x < crossings[j].x
+			})
+
+			winding := 0
+			for i, c := range crossings {
+				winding += c.dir
+				inside := winding != 0
+				if evenOdd {
+					inside = winding%2 != 0
+				}
+				if inside && i+1 < len(crossings) {
+					addSpan(row, c.x-float64(rect.Min.X), crossings[i+1].x-float64(rect.Min.X))
+				}
+			}
+		}
+	}
+	return m
+}
+
+// addSpan adds the coverage of the span in a scanline to the row.
+func addSpan(row []float32, x0, x1 float64) {
+	const v = 1.0 / subsamples
+	if x0 < 0 {
+		x0 = 0
+	}
+	if max := float64(len(row)); x1 > max {
+		x1 = max
+	}
+	if x0 >= x1 {
+		return
+	}
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		row[i0] += float32((x1 - x0) * v)
+		return
+	}
+	row[i0] += float32((float64(i0+1) - x0) * v)
+	for i := i0 + 1; i < i1; i++ {
+		row[i] += v
+	}
+	if i1 < len(row) {
+		row[i1] += float32((x1 - float64(i1)) * v)
+	}
+}
+
+// paint is the color of the pixels filled.
+type paint interface {
+	// at returns the premultiplied color of the pixel in 0 to 1.
+	at(x, y int) [4]float32
+}
+
+type solidPaint [4]float32
+
+func (p solidPaint) at(x, y int) [4]float32 {
+	return p
+}
+
+// composite draws the paint over dst through the mask, with the opacity.
+func composite(dst *image.RGBA, m *mask, p paint, opacity float32) {
+	if m == nil {
+		return
+	}
+	width := m.rect.Dx()
+	for y := m.rect.Min.Y; y < m.rect.Max.Y; y++ {
+		row := m.alpha[(y-m.rect.Min.Y)*width:]
+		i := dst.PixOffset(m.rect.Min.X, y)
+		for x := m.rect.Min.X; x < m.rect.Max.X; x, i = x+1, i+4 {
+			coverage := row[x-m.rect.Min.X]
+			if coverage <= 0 {
+				continue
+			}
+			if coverage > 1 {
+				coverage = 1
+			}
+			coverage *= opacity
+			c := p.at(x, y)
+			alpha := c[3] * coverage
+			if alpha <= 0 {
+				continue
+			}
+			pix := dst.Pix[i : i+4 : i+4]
+			for j := 0; j < 4; j++ {
+				v := c[j]*coverage*255 + float32(pix[j])*(1-alpha)
+				if v > 255 {
+					v = 255
+				}
+				pix[j] = uint8(v + 0.5)
+			}
+		}
+	}
+}
+
+// compositeLayer draws the layer over dst with the opacity.
+func compositeLayer(dst, layer *image.RGBA, opacity float32) {
+	for i := 0; i < len(dst.Pix); i += 4 {
+		src := layer.Pix[i : i+4 : i+4]
+		if src[3] == 0 {
+			continue
+		}
+		pix := dst.Pix[i : i+4 : i+4]
+		alpha := float32(src[3]) / 255 * opacity
+		for j := 0; j < 4; j++ {
+			v := float32(src[j])*opacity + float32(pix[j])*(1-alpha)
+			if v > 255 {
+				v = 255
+			}
+			pix[j] = uint8(v + 0.5)
+		}
+	}
+}
+
+type gradientStop struct {
+	offset float64
+	// premultiplied
+	color [4]float32
+}
+
+// gradientPaint is the paint of the linear and radial gradients.
+type gradientPaint struct {
+	// from the device space to the space of the gradient
+	inverse matrix
+	radial  bool
+	// the start and the end of the linear gradient, or the center and the
+	// focal point of the radial one
+	p0, p1 point
+	r      float64
+	spread string
+	colors [256][4]float32
+}
+
+func newGradientColors(stops []gradientStop) (colors [256][4]float32) {
+	for i := range colors {
+		t := float64(i) / 255
+		switch {
+		case t <= stops[0].offset:
+			colors[i] = stops[0].color
+		case t >= stops[len(stops)-1].offset:
+			colors[i] = stops[len(stops)-1].color
+		default:
+			j := 1
+			for stops[j].offset < t {
+				j++
+			}
+			a, b := stops[j-1], stops[j]
+			k := float32(0)
+			if b.offset > a.offset {
+				k = float32((t - a.offset) / (b.offset - a.offset))
+			}
+			for c := 0; c < 4; c++ {
+				colors[i][c] = a.color[c]*(1-k) + b.color[c]*k
+			}
+		}
+	}
+	return
+}
+
+func (g *gradientPaint) at(x, y int) [4]float32 {
+	p := g.inverse.apply(point{float64(x) + 0.5, float64(y) + 0.5})
+	var t float64
+	if g.radial {
+		// t where p is on the circle scaled from the focal point
+		d := p.sub(g.p1)
+		fc := g.p1.sub(g.p0)
+		a := d.x*d.x + d.y*d.y
+		b := 2 * (d.x*fc.x + d.y*fc.y)
+		c := fc.x*fc.x + fc.y*fc.y - g.r*g.r
+		if a > 0 {
+			disc := b*b - 4*a*c
+			if disc < 0 {
+				disc = 0
+			}
+			s := (-b + math.Sqrt(disc)) / (2 * a)
+			if s > 0 {
+				t = 1 / s
+			}
+		}
+	} else {
+		d := g.p1.sub(g.p0)
+		l := d.x*d.x + d.y*d.y
+		if l > 0 {
+			t = ((p.x-g.p0.x)*d.x + (p.y-g.p0.y)*d.y) / l
+		}
+	}
+
+	switch g.spread {
+	case "repeat":
+		t -= math.Floor(t)
+	case "reflect":
+		t = math.Mod(math.Abs(t), 2)
+		if t > 1 {
+			t = 2 - t
+		}
+	}
+	if t < 0 || math.IsNaN(t) {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+	return g.colors[int(t*255+0.5)]
+}
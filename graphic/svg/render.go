@@ -0,0 +1,483 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package svg
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// the max depth of the elements, which stops the loops of <use>
+const maxDepth = 64
+
+// the max number of the elements rendered, which stops the images
+// referencing the elements exponentially by the nested <use>, like the
+// limit of the references of librsvg
+const maxElements = 500000
+
+type paintKind int
+
+const (
+	paintNone paintKind = iota
+	paintColor
+	paintCurrentColor
+	paintURL
+)
+
+type paintValue struct {
+	kind  paintKind
+	color color.NRGBA
+	id    string
+	// the color used if the element referenced is not a gradient
+	fallback *paintValue
+}
+
+// style is the properties inherited by the children.
+type style struct {
+	fill, stroke               paintValue
+	fillOpacity, strokeOpacity float64
+	fillEvenOdd                bool
+	strokeWidth, miterLimit    float64
+	lineCap, lineJoin          string
+	color                      color.NRGBA
+	hidden                     bool
+}
+
+func defaultStyle() style {
+	return style{
+		fill:          paintValue{kind: paintColor, color: color.NRGBA{0, 0, 0, 0xff}},
+		stroke:        paintValue{kind: paintNone},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		miterLimit:    4,
+		lineCap:       "butt",
+		lineJoin:      "miter",
+		color:         color.NRGBA{0, 0, 0, 0xff},
+	}
+}
+
+func parsePaint(s string) (paintValue, bool) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "none":
+		return paintValue{kind: paintNone}, true
+	case "currentColor":
+		return paintValue{kind: paintCurrentColor}, true
+	}
+	if id, ok := parseURL(s); ok {
+		p := paintValue{kind: paintURL, id: id}
+		if end := strings.IndexByte(s, ')'); end+1 < len(s) {
+			if fallback, ok := parsePaint(s[end+1:]); ok {
+				p.fallback = &fallback
+			}
+		}
+		return p, true
+	}
+	if c, ok := parseColor(s); ok {
+		return paintValue{kind: paintColor, color: c}, true
+	}
+	return paintValue{}, false
+}
+
+func parseOpacity(s string, def float64) float64 {
+	v := parseNumber(s, def)
+	return math.Max(0, math.Min(1, v))
+}
+
+// inherit returns the style of the element, whose parent has the style st.
+func (st style) inherit(attrs map[string]string) style {
+	if v, ok := attrs["color"]; ok {
+		if c, ok := parseColor(v); ok {
+			st.color = c
+		}
+	}
+	if v, ok := attrs["fill"]; ok {
+		if p, ok := parsePaint(v); ok {
+			st.fill = p
+		}
+	}
+	if v, ok := attrs["stroke"]; ok {
+		if p, ok := parsePaint(v); ok {
+			st.stroke = p
+		}
+	}
+	st.fillOpacity = parseOpacity(attrs["fill-opacity"], st.fillOpacity)
+	st.strokeOpacity = parseOpacity(attrs["stroke-opacity"], st.strokeOpacity)
+	if v, ok := attrs["fill-rule"]; ok {
+		st.fillEvenOdd = v == "evenodd"
+	}
+	if v := parseLength(attrs["stroke-width"], 1, -1); v >= 0 {
+		st.strokeWidth = v
+	}
+	if v := parseNumber(attrs["stroke-miterlimit"], -1); v >= 1 {
+		st.miterLimit = v
+	}
+	if v, ok := attrs["stroke-linecap"]; ok {
+		st.lineCap = v
+	}
+	if v, ok := attrs["stroke-linejoin"]; ok {
+		st.lineJoin = v
+	}
+	switch attrs["visibility"] {
+	case "hidden", "collapse":
+		st.hidden = true
+	case "visible":
+		st.hidden = false
+	}
+	return st
+}
+
+type renderer struct {
+	ctx context.Context
+	doc *document
+	// the size of the view box, for the lengths in percentages
+	viewport [2]float64
+	// the number of the elements rendered, and the error which stops
+	// the rendering
+	elements int
+	err      error
+}
+
+func (rd *renderer) lengthX(attrs map[string]string, name string) float64 {
+	return parseLength(attrs[name], rd.viewport[0], 0)
+}
+
+func (rd *renderer) lengthY(attrs map[string]string, name string) float64 {
+	return parseLength(attrs[name], rd.viewport[1], 0)
+}
+
+// length returns the length neither horizontal nor vertical, such as the
+// radius of a circle.
+func (rd *renderer) length(attrs map[string]string, name string) float64 {
+	diagonal := math.Hypot(rd.viewport[0], rd.viewport[1]) / math.Sqrt2
+	return parseLength(attrs[name], diagonal, 0)
+}
+
+func (rd *renderer) renderChildren(dst *image.RGBA, n *node, m matrix, st style, depth int) {
+	for _, child := range n.children {
+		rd.render(dst, child, m, st, depth+1)
+	}
+}
+
+// render renders the element n, m is the transform from the user space of
+// its parent to the device space.
+func (rd *renderer) render(dst *image.RGBA, n *node, m matrix, st style, depth int) {
+	if rd.err != nil || depth > maxDepth || n.attrs["display"] == "none" {
+		return
+	}
+	rd.elements++
+	if rd.elements > maxElements {
+		rd.err = ErrTooComplex
+		return
+	}
+	if err := rd.ctx.Err(); err != nil {
+		rd.err = err
+		return
+	}
+	switch n.name {
+	case "svg", "g", "a", "switch", "use",
+		"path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+	default:
+		// the definitions and the unsupported elements
+		return
+	}
+
+	st = st.inherit(n.attrs)
+	if v, ok := n.attrs["transform"]; ok {
+		t, ok := parseTransform(v)
+		if !ok {
+			return
+		}
+		m = m.mul(t)
+	}
+	opacity := parseOpacity(n.attrs["opacity"], 1)
+	if opacity <= 0 {
+		return
+	}
+
+	switch n.name {
+	case "svg", "g", "a", "switch", "use":
+		target := n
+		if n.name == "use" {
+			var ok bool
+			target, ok = rd.doc.ids[strings.TrimPrefix(n.attrs["href"], "#")]
+			if !ok || !strings.HasPrefix(n.attrs["href"], "#") {
+				return
+			}
+			m = m.mul(translateMatrix(rd.lengthX(n.attrs, "x"), rd.lengthY(n.attrs, "y")))
+		}
+		layer := dst
+		if opacity < 1 {
+			layer = image.NewRGBA(dst.Rect)
+		}
+		switch {
+		case n.name != "use":
+			rd.renderChildren(layer, n, m, st, depth)
+		case target.name == "symbol":
+			rd.renderChildren(layer, target, m, st.inherit(target.attrs), depth+1)
+		default:
+			rd.render(layer, target, m, st, depth+1)
+		}
+		if opacity < 1 {
+			compositeLayer(dst, layer, float32(opacity))
+		}
+		return
+	}
+
+	if st.hidden {
+		return
+	}
+	b := &pathBuilder{m: m}
+	if !rd.buildShape(b, n) {
+		return
+	}
+
+	if st.fill.kind != paintNone {
+		if p := rd.newPaint(st.fill, st, st.fillOpacity, b); p != nil {
+			composite(dst, rasterize(b.polygons(), st.fillEvenOdd, dst.Rect), p, float32(opacity))
+		}
+	}
+	if st.stroke.kind != paintNone && st.strokeWidth > 0 {
+		if p := rd.newPaint(st.stroke, st, st.strokeOpacity, b); p != nil {
+			polys := b.strokePolygons(strokeStyle{
+				width:      st.strokeWidth,
+				lineCap:    st.lineCap,
+				lineJoin:   st.lineJoin,
+				miterLimit: st.miterLimit,
+			})
+			composite(dst, rasterize(polys, false, dst.Rect), p, float32(opacity))
+		}
+	}
+}
+
+// buildShape adds the path of the shape element to b, it returns false if
+// the shape is not rendered.
+func (rd *renderer) buildShape(b *pathBuilder, n *node) bool {
+	attrs := n.attrs
+	switch n.name {
+	case "path":
+		b.parsePathData(attrs["d"])
+
+	case "rect":
+		x, y := rd.lengthX(attrs, "x"), rd.lengthY(attrs, "y")
+		w, h := rd.lengthX(attrs, "width"), rd.lengthY(attrs, "height")
+		if w <= 0 || h <= 0 {
+			return false
+		}
+		rx, hasRx := attrs["rx"]
+		ry, hasRy := attrs["ry"]
+		rxv := parseLength(rx, rd.viewport[0], 0)
+		ryv := parseLength(ry, rd.viewport[1], 0)
+		// one of the radii is the other if it is missing
+		if !hasRx {
+			rxv = ryv
+		} else if !hasRy {
+			ryv = rxv
+		}
+		rxv = math.Max(0, math.Min(rxv, w/2))
+		ryv = math.Max(0, math.Min(ryv, h/2))
+		if rxv == 0 || ryv == 0 {
+			b.moveTo(point{x, y})
+			b.lineTo(point{x + w, y})
+			b.lineTo(point{x + w, y + h})
+			b.lineTo(point{x, y + h})
+		} else {
+			b.moveTo(point{x + rxv, y})
+			b.lineTo(point{x + w - rxv, y})
+			b.arcTo(rxv, ryv, 0, false, true, point{x + w, y + ryv})
+			b.lineTo(point{x + w, y + h - ryv})
+			b.arcTo(rxv, ryv, 0, false, true, point{x + w - rxv, y + h})
+			b.lineTo(point{x + rxv, y + h})
+			b.arcTo(rxv, ryv, 0, false, true, point{x, y + h - ryv})
+			b.lineTo(point{x, y + ryv})
+			b.arcTo(rxv, ryv, 0, false, true, point{x + rxv, y})
+		}
+		b.close()
+
+	case "circle":
+		r := rd.length(attrs, "r")
+		if r <= 0 {
+			return false
+		}
+		b.ellipse(rd.lengthX(attrs, "cx"), rd.lengthY(attrs, "cy"), r, r)
+
+	case "ellipse":
+		rx, ry := rd.lengthX(attrs, "rx"), rd.lengthY(attrs, "ry")
+		if rx <= 0 || ry <= 0 {
+			return false
+		}
+		b.ellipse(rd.lengthX(attrs, "cx"), rd.lengthY(attrs, "cy"), rx, ry)
+
+	case "line":
+		b.moveTo(point{rd.lengthX(attrs, "x1"), rd.lengthY(attrs, "y1")})
+		b.lineTo(point{rd.lengthX(attrs, "x2"), rd.lengthY(attrs, "y2")})
+
+	case "polyline", "polygon":
+		v, _ := parseNumbers(attrs["points"])
+		if len(v) < 4 {
+			return false
+		}
+		b.moveTo(point{v[0], v[1]})
+		for i := 2; i+1 < len(v); i += 2 {
+			b.lineTo(point{v[i], v[i+1]})
+		}
+		if n.name == "polygon" {
+			b.close()
+		}
+	}
+	return len(b.subpaths) > 0
+}
+
+func premultiply(c color.NRGBA, opacity float64) [4]float32 {
+	a := float32(c.A) / 255 * float32(opacity)
+	return [4]float32{
+		float32(c.R) / 255 * a,
+		float32(c.G) / 255 * a,
+		float32(c.B) / 255 * a,
+		a,
+	}
+}
+
+// newPaint returns the paint of the fill or the stroke, b is the path
+// painted, whose bounding box is used by the gradients.
+func (rd *renderer) newPaint(v paintValue, st style, opacity float64, b *pathBuilder) paint {
+	switch v.kind {
+	case paintColor:
+		return solidPaint(premultiply(v.color, opacity))
+	case paintCurrentColor:
+		return solidPaint(premultiply(st.color, opacity))
+	case paintURL:
+		n, ok := rd.doc.ids[v.id]
+		if ok && (n.name == "linearGradient" || n.name == "radialGradient") {
+			return rd.newGradient(n, opacity, b)
+		}
+		if v.fallback != nil {
+			return rd.newPaint(*v.fallback, st, opacity, b)
+		}
+	}
+	return nil
+}
+
+// gradientAttr returns the attribute of the gradient, which may be
+// inherited from the gradient referenced by href.
+func (rd *renderer) gradientAttr(n *node, name string) (string, bool) {
+	for i := 0; i < maxDepth && n != nil; i++ {
+		if v, ok := n.attrs[name]; ok {
+			return v, true
+		}
+		n = rd.gradientHref(n)
+	}
+	return "", false
+}
+
+func (rd *renderer) gradientHref(n *node) *node {
+	href := n.attrs["href"]
+	if !strings.HasPrefix(href, "#") {
+		return nil
+	}
+	ref, ok := rd.doc.ids[href[1:]]
+	if !ok || (ref.name != "linearGradient" && ref.name != "radialGradient") {
+		return nil
+	}
+	return ref
+}
+
+// gradientStops returns the stops of the gradient, or of the gradient
+// referenced if it has none.
+func (rd *renderer) gradientStops(n *node, opacity float64) []gradientStop {
+	for i := 0; i < maxDepth && n != nil; i++ {
+		var stops []gradientStop
+		last := 0.0
+		for _, child := range n.children {
+			if child.name != "stop" {
+				continue
+			}
+			offset := math.Max(0, math.Min(1, parseNumber(child.attrs["offset"], 0)))
+			// the offsets never decrease
+			offset = math.Max(offset, last)
+			last = offset
+			c, ok := parseColor(child.attrs["stop-color"])
+			if !ok {
+				c = color.NRGBA{0, 0, 0, 0xff}
+			}
+			stopOpacity := parseOpacity(child.attrs["stop-opacity"], 1)
+			stops = append(stops, gradientStop{offset, premultiply(c, stopOpacity*opacity)})
+		}
+		if len(stops) > 0 {
+			return stops
+		}
+		n = rd.gradientHref(n)
+	}
+	return nil
+}
+
+func (rd *renderer) newGradient(n *node, opacity float64, b *pathBuilder) paint {
+	stops := rd.gradientStops(n, opacity)
+	if len(stops) == 0 {
+		// nothing is painted without the stops
+		return nil
+	}
+	if len(stops) == 1 {
+		return solidPaint(stops[0].color)
+	}
+
+	attr := func(name string) string {
+		v, _ := rd.gradientAttr(n, name)
+		return v
+	}
+	userSpace := attr("gradientUnits") == "userSpaceOnUse"
+	// the percentages are of the view box in the user space, or of the
+	// bounding box
+	refX, refY, ref := 1.0, 1.0, 1.0
+	m := b.m
+	if userSpace {
+		refX, refY = rd.viewport[0], rd.viewport[1]
+		ref = math.Hypot(refX, refY) / math.Sqrt2
+	} else {
+		min, max := b.bounds()
+		w, h := max.x-min.x, max.y-min.y
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		m = m.mul(matrix{w, 0, 0, h, min.x, min.y})
+	}
+	if v, ok := rd.gradientAttr(n, "gradientTransform"); ok {
+		if t, ok := parseTransform(v); ok {
+			m = m.mul(t)
+		}
+	}
+	inverse, ok := m.invert()
+	if !ok {
+		return nil
+	}
+
+	g := &gradientPaint{
+		inverse: inverse,
+		spread:  attr("spreadMethod"),
+		colors:  newGradientColors(stops),
+	}
+	if n.name == "linearGradient" {
+		g.p0 = point{parseLength(attr("x1"), refX, 0), parseLength(attr("y1"), refY, 0)}
+		g.p1 = point{parseLength(attr("x2"), refX, refX), parseLength(attr("y2"), refY, 0)}
+		return g
+	}
+
+	g.radial = true
+	g.p0 = point{parseLength(attr("cx"), refX, refX/2), parseLength(attr("cy"), refY, refY/2)}
+	g.r = parseLength(attr("r"), ref, ref/2)
+	if g.r <= 0 {
+		return solidPaint(stops[len(stops)-1].color)
+	}
+	g.p1 = point{parseLength(attr("fx"), refX, g.p0.x), parseLength(attr("fy"), refY, g.p0.y)}
+	// the focal point is moved into the circle
+	if d := g.p1.sub(g.p0); d.length() > g.r*0.99 {
+		g.p1 = g.p0.add(d.mul(g.r * 0.99 / d.length()))
+	}
+	return g
+}
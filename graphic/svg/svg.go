@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package svg rasterizes the SVG images, such as the icons of the icon
+// themes. The subset supported is the shapes and paths, the solid colors
+// and the gradients, the strokes, the transforms, the opacity and the
+// elements reused by <use>. The text, the embedded images, the clip paths,
+// the masks, the filters and the CSS style sheets are ignored.
+//
+// The package is registered to the image package as "svg", the images
+// decoded by image.Decode are in their intrinsic sizes.
+package svg
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrFormat is returned when the data is not a valid SVG image.
+	ErrFormat = errors.New("svg: invalid format")
+	// ErrSize is returned when the size of the image is unknown or
+	// invalid.
+	ErrSize = errors.New("svg: invalid size")
+	// ErrTooComplex is returned when the image has too many elements to
+	// render, such as the elements referenced by the nested <use>.
+	ErrTooComplex = errors.New("svg: too many elements")
+)
+
+// the images larger are not rendered
+const maxSize = 1 << 14
+
+func init() {
+	image.RegisterFormat("svg", "<?xml", Decode, DecodeConfig)
+	image.RegisterFormat("svg", "<svg", Decode, DecodeConfig)
+}
+
+// node is an element of the document.
+type node struct {
+	name string
+	// the attributes and the declarations of the style attribute, which
+	// override the attributes
+	attrs    map[string]string
+	children []*node
+}
+
+type document struct {
+	root *node
+	ids  map[string]*node
+	// the intrinsic size
+	width, height float64
+	viewBox       [4]float64
+	hasViewBox    bool
+}
+
+func parse(r io.Reader) (*document, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	// the encodings are not converted, the attributes used are ASCII
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	doc := &document{ids: make(map[string]*node)}
+	var stack []*node
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if doc.root != nil && len(stack) == 0 {
+				// the trailing garbage
+				break
+			}
+			return nil, ErrFormat
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				n.attrs[attr.Name.Local] = strings.TrimSpace(attr.Value)
+			}
+			if style, ok := n.attrs["style"]; ok {
+				parseStyle(style, n.attrs)
+			}
+			if id := n.attrs["id"]; id != "" {
+				doc.ids[id] = n
+			}
+			if len(stack) == 0 {
+				if doc.root != nil || n.name != "svg" {
+					return nil, ErrFormat
+				}
+				doc.root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if doc.root == nil {
+		return nil, ErrFormat
+	}
+
+	if v, ok := parseNumbers(doc.root.attrs["viewBox"]); ok && len(v) == 4 && v[2] > 0 && v[3] > 0 {
+		copy(doc.viewBox[:], v)
+		doc.hasViewBox = true
+	}
+	doc.width = parseAbsoluteLength(doc.root.attrs["width"])
+	doc.height = parseAbsoluteLength(doc.root.attrs["height"])
+	if doc.hasViewBox {
+		// the missing size is in the aspect ratio of the view box
+		switch {
+		case doc.width <= 0 && doc.height <= 0:
+			doc.width, doc.height = doc.viewBox[2], doc.viewBox[3]
+		case doc.width <= 0:
+			doc.width = doc.height * doc.viewBox[2] / doc.viewBox[3]
+		case doc.height <= 0:
+			doc.height = doc.width * doc.viewBox[3] / doc.viewBox[2]
+		}
+	}
+	return doc, nil
+}
+
+// size returns the size in pixels of the image rendered in the width and
+// height requested, the intrinsic size is used if both are zero, and the
+// aspect ratio is kept if one is zero.
+func (doc *document) size(width, height int) (int, int, error) {
+	if doc.width <= 0 || doc.height <= 0 {
+		return 0, 0, ErrSize
+	}
+	switch {
+	case width <= 0 && height <= 0:
+		width, height = int(doc.width+0.5), int(doc.height+0.5)
+	case width <= 0:
+		width = int(float64(height)*doc.width/doc.height + 0.5)
+	case height <= 0:
+		height = int(float64(width)*doc.height/doc.width + 0.5)
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if width > maxSize || height > maxSize {
+		return 0, 0, ErrSize
+	}
+	return width, height, nil
+}
+
+// DecodeConfig returns the color model and the intrinsic size of the SVG
+// image.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	doc, err := parse(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	w, h, err := doc.size(0, 0)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.RGBAModel, Width: w, Height: h}, nil
+}
+
+// Decode renders the SVG image in its intrinsic size.
+func Decode(r io.Reader) (image.Image, error) {
+	return Render(r, 0, 0)
+}
+
+// Render renders the SVG image in the width and height, the intrinsic
+// size is used if both are zero, and the aspect ratio is kept if one of
+// them is zero. The image is scaled by its view box, the aspect ratio is
+// kept as preserveAspectRatio of the image.
+func Render(r io.Reader, width, height int) (*image.RGBA, error) {
+	return RenderContext(context.Background(), r, width, height)
+}
+
+// RenderContext renders the image as Render, the rendering is stopped with
+// the error of ctx once ctx is done, which is checked for each element.
+func RenderContext(ctx context.Context, r io.Reader, width, height int) (*image.RGBA, error) {
+	doc, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	width, height, err = doc.size(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	var m matrix
+	viewport := [2]float64{doc.width, doc.height}
+	if doc.hasViewBox {
+		m = viewBoxMatrix(doc.viewBox, float64(width), float64(height),
+			doc.root.attrs["preserveAspectRatio"])
+		viewport = [2]float64{doc.viewBox[2], doc.viewBox[3]}
+	} else {
+		m = scaleMatrix(float64(width)/doc.width, float64(height)/doc.height)
+	}
+	rd := &renderer{ctx: ctx, doc: doc, viewport: viewport}
+	rd.renderChildren(dst, doc.root, m, defaultStyle(), 0)
+	if rd.err != nil {
+		return nil, rd.err
+	}
+	return dst, nil
+}
+
+// viewBoxMatrix returns the transform from the view box to the viewport of
+// the size.
+func viewBoxMatrix(viewBox [4]float64, width, height float64, preserveAspectRatio string) matrix {
+	sx, sy := width/viewBox[2], height/viewBox[3]
+	fields := strings.Fields(preserveAspectRatio)
+	align := "xMidYMid"
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if align == "none" {
+		return scaleMatrix(sx, sy).mul(translateMatrix(-viewBox[0], -viewBox[1]))
+	}
+
+	scale := sx
+	if len(fields) > 1 && fields[1] == "slice" {
+		if sy > scale {
+			scale = sy
+		}
+	} else if sy < scale {
+		scale = sy
+	}
+	tx := -viewBox[0] * scale
+	ty := -viewBox[1] * scale
+	extraX := width - viewBox[2]*scale
+	extraY := height - viewBox[3]*scale
+	switch {
+	case strings.HasPrefix(align, "xMid"):
+		tx += extraX / 2
+	case strings.HasPrefix(align, "xMax"):
+		tx += extraX
+	}
+	switch {
+	case strings.HasSuffix(align, "YMid"):
+		ty += extraY / 2
+	case strings.HasSuffix(align, "YMax"):
+		ty += extraY
+	}
+	return matrix{scale, 0, 0, scale, tx, ty}
+}
@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package svg
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, data string, width, height int) *image.RGBA {
+	img, err := Render(strings.NewReader(data), width, height)
+	require.NoError(t, err)
+	return img
+}
+
+var (
+	transparent = color.RGBA{}
+	red         = color.RGBA{0xff, 0, 0, 0xff}
+)
+
+func TestRenderRect(t *testing.T) {
+	const data = `<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16">
+		<rect x="4" y="4" width="8" height="8" fill="#f00"/></svg>`
+	img := render(t, data, 0, 0)
+	assert.Equal(t, image.Rect(0, 0, 16, 16), img.Bounds())
+	assert.Equal(t, red, img.RGBAAt(4, 4))
+	assert.Equal(t, red, img.RGBAAt(11, 11))
+	assert.Equal(t, transparent, img.RGBAAt(3, 8))
+	assert.Equal(t, transparent, img.RGBAAt(12, 8))
+
+	// scaled without the view box, and the aspect ratio is kept
+	img = render(t, data, 32, 0)
+	assert.Equal(t, image.Rect(0, 0, 32, 32), img.Bounds())
+	assert.Equal(t, red, img.RGBAAt(8, 8))
+	assert.Equal(t, transparent, img.RGBAAt(7, 8))
+}
+
+func TestRenderViewBox(t *testing.T) {
+	const data = `<svg viewBox="10 10 20 10"><rect x="10" y="10" width="10" height="10"/></svg>`
+	config, err := DecodeConfig(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 20, config.Width)
+	assert.Equal(t, 10, config.Height)
+
+	// centered in the square
+	img := render(t, data, 40, 40)
+	black := color.RGBA{0, 0, 0, 0xff}
+	assert.Equal(t, transparent, img.RGBAAt(10, 9))
+	assert.Equal(t, black, img.RGBAAt(0, 10))
+	assert.Equal(t, black, img.RGBAAt(19, 29))
+	assert.Equal(t, transparent, img.RGBAAt(20, 20))
+
+	img = render(t, strings.Replace(data, "<svg", `<svg preserveAspectRatio="none"`, 1), 40, 40)
+	assert.Equal(t, black, img.RGBAAt(0, 0))
+	assert.Equal(t, black, img.RGBAAt(19, 39))
+	assert.Equal(t, transparent, img.RGBAAt(20, 0))
+}
+
+func TestRenderAntialiasing(t *testing.T) {
+	// the area of the circle is covered
+	img := render(t, `<svg width="40" height="40"><circle cx="20" cy="20" r="15"/></svg>`, 0, 0)
+	area := 0.0
+	partial := 0
+	for i := 3; i < len(img.Pix); i += 4 {
+		area += float64(img.Pix[i]) / 255
+		if img.Pix[i] != 0 && img.Pix[i] != 0xff {
+			partial++
+		}
+	}
+	assert.InDelta(t, math.Pi*15*15, area, 2)
+	assert.True(t, partial > 0)
+
+	// the half covered pixels
+	img = render(t, `<svg width="4" height="4"><rect x="0.5" y="0" width="3" height="4" fill="red"/></svg>`, 0, 0)
+	assert.InDelta(t, 0x80, int(img.RGBAAt(0, 1).A), 2)
+	assert.Equal(t, red, img.RGBAAt(1, 1))
+}
+
+func TestRenderFillRule(t *testing.T) {
+	// the inner square is in the same direction as the outer one
+	const path = `M0 0H10V10H0Z M3 3H7V7H3Z`
+	img := render(t, `<svg width="10" height="10"><path d="`+path+`" fill-rule="evenodd"/></svg>`, 0, 0)
+	assert.Equal(t, uint8(0xff), img.RGBAAt(1, 1).A)
+	assert.Equal(t, transparent, img.RGBAAt(5, 5))
+
+	img = render(t, `<svg width="10" height="10"><path d="`+path+`"/></svg>`, 0, 0)
+	assert.Equal(t, uint8(0xff), img.RGBAAt(5, 5).A)
+}
+
+func TestRenderStroke(t *testing.T) {
+	img := render(t, `<svg width="20" height="20"><g stroke="red" stroke-width="2">
+		<line x1="2" y1="5" x2="18" y2="5"/>
+		<polyline points="2 10 10 10 10 18" fill="none" stroke-linecap="square"/></g></svg>`, 0, 0)
+	// butt caps
+	assert.Equal(t, red, img.RGBAAt(2, 4))
+	assert.Equal(t, red, img.RGBAAt(17, 5))
+	assert.Equal(t, transparent, img.RGBAAt(1, 5))
+	assert.Equal(t, transparent, img.RGBAAt(10, 3))
+	// square caps and the miter join
+	assert.Equal(t, red, img.RGBAAt(1, 10))
+	assert.Equal(t, red, img.RGBAAt(10, 9))
+	assert.Equal(t, red, img.RGBAAt(10, 18))
+	assert.Equal(t, transparent, img.RGBAAt(12, 12))
+}
+
+func TestRenderPathData(t *testing.T) {
+	// the relative and smooth commands, the arcs with the flags not
+	// separated and the numbers not separated
+	b := &pathBuilder{m: identity}
+	b.parsePathData("m1 1h8v4l-2-2s1 1 2 2q1 1 2 2t1 1a1 1 0 00 2 0zM-1.5.5 10 10")
+	require.Len(t, b.subpaths, 2)
+	assert.True(t, b.subpaths[0].closed)
+	assert.Equal(t, point{1, 1}, b.subpaths[0].points[0])
+	assert.Equal(t, point{9, 1}, b.subpaths[0].points[1])
+	assert.Equal(t, point{9, 5}, b.subpaths[0].points[2])
+	assert.Equal(t, point{7, 3}, b.subpaths[0].points[3])
+	assert.Equal(t, []point{{-1.5, 0.5}, {10, 10}}, b.subpaths[1].points)
+
+	min, max := b.bounds()
+	assert.Equal(t, point{-1.5, 0.5}, min)
+	assert.Equal(t, 14.0, max.x)
+
+	// the path is kept until the error
+	b = &pathBuilder{m: identity}
+	b.parsePathData("M0 0L1 1L2 x3 3")
+	require.Len(t, b.subpaths, 1)
+	assert.Equal(t, []point{{0, 0}, {1, 1}}, b.subpaths[0].points)
+}
+
+func TestRenderArc(t *testing.T) {
+	b := &pathBuilder{m: identity}
+	b.parsePathData("M0 5 A5 5 0 0 1 10 5")
+	min, max := b.bounds()
+	assert.InDelta(t, 0, min.y, 1e-9)
+	assert.InDelta(t, 5, max.y, 1e-9)
+	// the radii too small are scaled up
+	b = &pathBuilder{m: identity}
+	b.parsePathData("M0 0 A1 1 0 0 0 10 0")
+	min, max = b.bounds()
+	assert.InDelta(t, 5, max.y, 1e-9)
+	for _, p := range b.subpaths[0].points {
+		assert.InDelta(t, 5, math.Hypot(p.x-5, p.y), 1e-9)
+	}
+}
+
+func TestRenderGradient(t *testing.T) {
+	img := render(t, `<svg width="100" height="10">
+		<linearGradient id="g"><stop offset="0" stop-color="black"/><stop offset="1" stop-color="white"/></linearGradient>
+		<rect width="100" height="10" fill="url(#g)"/></svg>`, 0, 0)
+	assert.True(t, img.RGBAAt(0, 5).R < 5)
+	assert.True(t, img.RGBAAt(99, 5).R > 250)
+	assert.InDelta(t, 0x80, int(img.RGBAAt(50, 5).R), 3)
+	for x := 1; x < 100; x++ {
+		require.True(t, img.RGBAAt(x, 5).R >= img.RGBAAt(x-1, 5).R)
+	}
+
+	img = render(t, `<svg width="20" height="20">
+		<radialGradient id="g" spreadMethod="pad"><stop offset="0" stop-color="red"/>
+		<stop offset="100%" stop-color="red" stop-opacity="0"/></radialGradient>
+		<rect width="20" height="20" fill="url(#g)"/></svg>`, 0, 0)
+	assert.True(t, img.RGBAAt(10, 10).A > 230)
+	assert.True(t, img.RGBAAt(10, 2).A < img.RGBAAt(10, 6).A)
+	assert.Equal(t, transparent, img.RGBAAt(0, 0))
+}
+
+func TestRenderUseAndOpacity(t *testing.T) {
+	img := render(t, `<svg width="20" height="10">
+		<defs><rect id="r" width="10" height="10"/></defs>
+		<use href="#r" fill="red"/>
+		<g opacity="0.5"><use xlink:href="#r" x="10" fill="red"/></g>
+		<use href="#loop" id="loop"/>
+		<rect width="10" height="10" fill="blue" display="none"/></svg>`, 0, 0)
+	assert.Equal(t, red, img.RGBAAt(5, 5))
+	assert.Equal(t, color.RGBA{0x80, 0, 0, 0x80}, img.RGBAAt(15, 5))
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]color.NRGBA{
+		"#f80":                {0xff, 0x88, 0, 0xff},
+		"#FF8000":             {0xff, 0x80, 0, 0xff},
+		"#ff800080":           {0xff, 0x80, 0, 0x80},
+		"rgb(255, 128, 0)":    {0xff, 0x80, 0, 0xff},
+		"rgb(100%, 50%, 0%)":  {0xff, 0x80, 0, 0xff},
+		"rgba(255,128,0,0.5)": {0xff, 0x80, 0, 0x80},
+		"Orange":              {0xff, 0xa5, 0, 0xff},
+		" transparent ":       {},
+	}
+	for s, expected := range tests {
+		c, ok := parseColor(s)
+		assert.True(t, ok, s)
+		assert.Equal(t, expected, c, s)
+	}
+	for _, s := range []string{"", "#12", "#ggg", "rgb(1,2)", "nocolor"} {
+		_, ok := parseColor(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	m, ok := parseTransform("translate(10,20) scale(2) rotate(90)")
+	require.True(t, ok)
+	p := m.apply(point{1, 0})
+	assert.InDelta(t, 10, p.x, 1e-9)
+	assert.InDelta(t, 22, p.y, 1e-9)
+
+	m, ok = parseTransform("rotate(180 5 5)")
+	require.True(t, ok)
+	p = m.apply(point{0, 0})
+	assert.InDelta(t, 10, p.x, 1e-9)
+	assert.InDelta(t, 10, p.y, 1e-9)
+
+	inverse, ok := m.invert()
+	require.True(t, ok)
+	p = inverse.apply(p)
+	assert.InDelta(t, 0, p.x, 1e-9)
+
+	_, ok = parseTransform("translate(10")
+	assert.False(t, ok)
+	_, ok = parseTransform("scale(1,2,3)")
+	assert.False(t, ok)
+}
+
+func TestParseLength(t *testing.T) {
+	assert.Equal(t, 10.0, parseLength("10", 0, -1))
+	assert.Equal(t, 10.0, parseLength("10px", 0, -1))
+	assert.Equal(t, 96.0, parseLength("1in", 0, -1))
+	assert.Equal(t, 16.0, parseLength("12pt", 0, -1))
+	assert.Equal(t, 25.0, parseLength("50%", 50, -1))
+	assert.Equal(t, -1.0, parseLength("10furlongs", 0, -1))
+	assert.Equal(t, -1.0, parseLength("", 0, -1))
+	assert.Equal(t, 0.0, parseAbsoluteLength("100%"))
+}
+
+func TestDecodeFile(t *testing.T) {
+	f, err := os.Open("testdata/icon.svg")
+	require.NoError(t, err)
+	defer f.Close()
+	img, name, err := image.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, "svg", name)
+	assert.Equal(t, image.Rect(0, 0, 48, 48), img.Bounds())
+
+	// the tab, the body and the transparent corner
+	rgba := img.(*image.RGBA)
+	assert.Equal(t, color.RGBA{0x2a, 0x6f, 0xc9, 0xff}, rgba.RGBAAt(10, 7))
+	assert.Equal(t, uint8(0xff), rgba.RGBAAt(10, 40).A)
+	assert.Equal(t, transparent, rgba.RGBAAt(0, 47))
+	// the gradient from the top to the bottom
+	assert.True(t, rgba.RGBAAt(6, 17).G < rgba.RGBAAt(6, 40).G)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Render(strings.NewReader(`<html><svg width="10" height="10"/></html>`), 0, 0)
+	assert.Equal(t, ErrFormat, err)
+	_, err = Render(strings.NewReader(`not xml`), 0, 0)
+	assert.Equal(t, ErrFormat, err)
+	_, err = Render(strings.NewReader(`<svg width="100%"/>`), 0, 0)
+	assert.Equal(t, ErrSize, err)
+	_, err = Render(strings.NewReader(`<svg width="10" height="10"/>`), maxSize+1, 0)
+	assert.Equal(t, ErrSize, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = RenderContext(ctx, strings.NewReader(`<svg width="10" height="10"><rect width="5" height="5"/></svg>`), 0, 0)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestRenderNestedUse(t *testing.T) {
+	// each level references the previous level twice, which renders 2^29
+	// rectangles
+	var buf strings.Builder
+	buf.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="10" height="10"><defs>`)
+	buf.WriteString(`<g id="a0"><rect width="1" height="1"/></g>`)
+	for i := 1; i < 30; i++ {
+		fmt.Fprintf(&buf, `<g id="a%d"><use href="#a%d"/><use xlink:href="#a%d"/></g>`, i, i-1, i-1)
+	}
+	buf.WriteString(`</defs><use href="#a29"/></svg>`)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Render(strings.NewReader(buf.String()), 0, 0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.Equal(t, ErrTooComplex, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the rendering is not stopped")
+	}
+
+	// the small number of the references is rendered
+	img, err := Render(strings.NewReader(strings.Replace(buf.String(), `href="#a29"/></svg>`, `href="#a5"/></svg>`, 1)), 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0xff), img.RGBAAt(0, 0).A)
+}
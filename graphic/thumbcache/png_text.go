@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package thumbcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+var (
+	errNotPNG         = errors.New("not a png file")
+	errPNGChunkLength = errors.New("invalid png chunk length")
+)
+
+const (
+	// the signature and the IHDR chunk, which is always the first chunk
+	pngHeaderLen = 8 + 4 + 4 + 13 + 4
+	// the lengths of the chunks are at most 2^31-1 by the specification
+	maxPNGChunkLen = 1<<31 - 1
+	// the larger tEXt chunks are skipped, the texts of the thumbnails are
+	// short, such as the URI and the modification time
+	maxPNGTextLen = 64 << 10
+)
+
+// encodePNG encodes the image to png with the tEXt chunks of the texts.
+func encodePNG(w io.Writer, img image.Image, texts map[string]string) error {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return err
+	}
+	data := buf.Bytes()
+
+	// the tEXt chunks are put after IHDR, in the order of the keys
+	keys := make([]string, 0, len(texts))
+	for key := range texts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	_, err = w.Write(data[:pngHeaderLen])
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err = writePNGChunk(w, "tEXt", []byte(key+"\x00"+texts[key]))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(data[pngHeaderLen:])
+	return err
+}
+
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], typ)
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(header[4:])
+	_, _ = crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	for _, b := range [][]byte{header[:], data, footer[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPNGTexts returns the texts of the tEXt chunks of the png before the
+// image data, which are where the thumbnails keep them.
+func readPNGTexts(r io.Reader) (map[string]string, error) {
+	br := bufio.NewReader(r)
+	signature := make([]byte, len(pngSignature))
+	_, err := io.ReadFull(br, signature)
+	if err != nil || !bytes.Equal(signature, pngSignature) {
+		return nil, errNotPNG
+	}
+
+	texts := make(map[string]string)
+	var header [8]byte
+	for {
+		_, err = io.ReadFull(br, header[:])
+		if err != nil {
+			return nil, err
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		if length > maxPNGChunkLen {
+			return nil, errPNGChunkLength
+		}
+		typ := string(header[4:])
+		if typ == "IDAT" || typ == "IEND" {
+			return texts, nil
+		}
+		if typ != "tEXt" || length > maxPNGTextLen {
+			// the data and the crc
+			_, err = br.Discard(int(length) + 4)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		data := make([]byte, length+4)
+		_, err = io.ReadFull(br, data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[:length]
+		if i := bytes.IndexByte(data, 0); i > 0 {
+			texts[string(data[:i])] = string(data[i+1:])
+		}
+	}
+}
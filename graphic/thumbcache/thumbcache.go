@@ -0,0 +1,422 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package thumbcache is the cache of the thumbnails and the other images
+// generated from the image files, following the freedesktop Thumbnail
+// Managing Standard. The entries are validated against the modification
+// time and the size of the source files, and the least recently used ones
+// are removed when a private cache is over its budget of bytes.
+package thumbcache
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"image"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
+)
+
+// Size is the size of the thumbnails, the thumbnails are at most Size
+// pixels wide and high.
+type Size int
+
+const (
+	SizeNormal Size = 128
+	SizeLarge  Size = 256
+	SizeXLarge Size = 512
+)
+
+var sizes = []Size{SizeNormal, SizeLarge, SizeXLarge}
+
+// Dir returns the name of the directory of the thumbnails of the size.
+func (s Size) Dir() string {
+	switch s {
+	case SizeNormal:
+		return "normal"
+	case SizeLarge:
+		return "large"
+	case SizeXLarge:
+		return "x-large"
+	}
+	return ""
+}
+
+// the keys of the png tEXt chunks
+const (
+	keyURI   = "Thumb::URI"
+	keyMTime = "Thumb::MTime"
+	keySize  = "Thumb::Size"
+	keyMime  = "Thumb::Mime"
+)
+
+// DefaultMaxBytes is the budget suggested for the private caches of the
+// applications.
+const DefaultMaxBytes = 256 << 20
+
+// DefaultDir returns the directory of the thumbnails shared by all the
+// applications, $XDG_CACHE_HOME/thumbnails.
+func DefaultDir() string {
+	return filepath.Join(basedir.GetUserCacheDir(), "thumbnails")
+}
+
+var (
+	defaultCache     *Cache
+	defaultCacheOnce sync.Once
+)
+
+// Default returns the cache of DefaultDir. It has no budget, as the
+// thumbnails in the directory are written by all the applications, and
+// they are removed by the tools managing the directory.
+func Default() *Cache {
+	defaultCacheOnce.Do(func() {
+		defaultCache = New(DefaultDir(), "deepin-go-lib", 0)
+	})
+	return defaultCache
+}
+
+// Cache is a directory of the cached images. The thumbnails are saved in
+// the directories of their sizes and the failures in fail/<app>, the
+// other images are saved in the directory itself.
+type Cache struct {
+	dir      string
+	app      string
+	maxBytes int64
+
+	mu sync.Mutex
+	// the bytes used by the entries, -1 before the directory is scanned
+	used int64
+}
+
+// New returns the cache in the directory dir, app is the name of the
+// directory of the failures. The least recently used entries are removed
+// when the entries are more than maxBytes, there is no limit if maxBytes
+// is 0.
+func New(dir, app string, maxBytes int64) *Cache {
+	return &Cache{
+		dir:      dir,
+		app:      app,
+		maxBytes: maxBytes,
+		used:     -1,
+	}
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// URI returns the canonical URI of the file, such as
+// "file:///home/user/a%20b.png".
+func URI(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Scheme: "file", Path: abs}
+	return u.String(), nil
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Path returns the path of the thumbnail of the URI, the file may not
+// exist.
+func (c *Cache) Path(uri string, size Size) string {
+	return filepath.Join(c.dir, size.Dir(), md5Hex(uri)+".png")
+}
+
+func (c *Cache) failPath(uri string) string {
+	return filepath.Join(c.dir, "fail", c.app, md5Hex(uri)+".png")
+}
+
+// source is the file that the entries are generated from
+type source struct {
+	uri   string
+	mtime int64
+	size  int64
+}
+
+func statSource(file string) (*source, error) {
+	uri, err := URI(file)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	return &source{
+		uri:   uri,
+		mtime: fi.ModTime().Unix(),
+		size:  fi.Size(),
+	}, nil
+}
+
+func (src *source) texts() map[string]string {
+	return map[string]string{
+		keyURI:   src.uri,
+		keyMTime: strconv.FormatInt(src.mtime, 10),
+		keySize:  strconv.FormatInt(src.size, 10),
+	}
+}
+
+// isValid reports whether the png is generated from the current source.
+func (src *source) isValid(pngFile string) bool {
+	f, err := os.Open(pngFile)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	texts, err := readPNGTexts(f)
+	if err != nil {
+		return false
+	}
+	if texts[keyURI] != src.uri || texts[keyMTime] != strconv.FormatInt(src.mtime, 10) {
+		return false
+	}
+	// Thumb::Size is optional
+	if size, ok := texts[keySize]; ok && size != strconv.FormatInt(src.size, 10) {
+		return false
+	}
+	return true
+}
+
+// Lookup returns the thumbnail of the file, ok is false if there is no
+// thumbnail or the file is changed after the thumbnail is made.
+func (c *Cache) Lookup(file string, size Size) (thumbFile string, ok bool) {
+	src, err := statSource(file)
+	if err != nil {
+		return "", false
+	}
+	thumbFile = c.Path(src.uri, size)
+	if !src.isValid(thumbFile) {
+		return "", false
+	}
+	touch(thumbFile)
+	return thumbFile, true
+}
+
+// Save saves the thumbnail of the file, mime is the mime type of the file
+// and may be empty. The image should be scaled to the size already.
+func (c *Cache) Save(file string, size Size, img image.Image, mime string) (thumbFile string, err error) {
+	src, err := statSource(file)
+	if err != nil {
+		return "", err
+	}
+	texts := src.texts()
+	if mime != "" {
+		texts[keyMime] = mime
+	}
+	thumbFile = c.Path(src.uri, size)
+	err = c.writePNG(thumbFile, img, texts)
+	if err != nil {
+		return "", err
+	}
+	return thumbFile, nil
+}
+
+// Failed reports whether making the thumbnail of the file is failed
+// before, and the file is not changed since then.
+func (c *Cache) Failed(file string) bool {
+	src, err := statSource(file)
+	if err != nil {
+		return false
+	}
+	return src.isValid(c.failPath(src.uri))
+}
+
+// SaveFailed records that making the thumbnail of the file is failed, so
+// that it is not tried again until the file is changed.
+func (c *Cache) SaveFailed(file string) error {
+	src, err := statSource(file)
+	if err != nil {
+		return err
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	return c.writePNG(c.failPath(src.uri), img, src.texts())
+}
+
+func (c *Cache) writePNG(file string, img image.Image, texts map[string]string) error {
+	return c.writeFile(file, func(tmpFile string) error {
+		f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		err = encodePNG(f, img, texts)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+}
+
+// Derived returns the image generated from the file by an operation, such
+// as scaling. params tells the operation and its arguments, and ext is the
+// extension of the image. If the image is not cached, create is called to
+// write it to the file dst. The images are addressed by the source and its
+// modification time and size, so that they are generated again once the
+// source is changed.
+func (c *Cache) Derived(file, params, ext string, create func(dst string) error) (dstFile string, useCache bool, err error) {
+	src, err := statSource(file)
+	if err != nil {
+		return "", false, err
+	}
+	key := strings.Join([]string{src.uri, strconv.FormatInt(src.mtime, 10),
+		strconv.FormatInt(src.size, 10), params}, "\x00")
+	dstFile = filepath.Join(c.dir, md5Hex(key))
+	if ext != "" {
+		dstFile += "." + ext
+	}
+
+	if _, err := os.Stat(dstFile); err == nil {
+		touch(dstFile)
+		return dstFile, true, nil
+	}
+	err = c.writeFile(dstFile, create)
+	if err != nil {
+		return "", false, err
+	}
+	return dstFile, false, nil
+}
+
+// writeFile calls write with a temporary file which is renamed to file
+// after written, so that the readers never see the incomplete files.
+func (c *Cache) writeFile(file string, write func(tmpFile string) error) error {
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(file)+"-*")
+	if err != nil {
+		return err
+	}
+	tmpFile := tmp.Name()
+	_ = tmp.Close()
+
+	err = write(tmpFile)
+	if err == nil {
+		err = os.Rename(tmpFile, file)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile)
+		return err
+	}
+
+	if fi, err := os.Stat(file); err == nil {
+		c.added(fi.Size())
+	}
+	return nil
+}
+
+// touch marks the entry as used recently.
+func touch(file string) {
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+}
+
+type cacheEntry struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+// entries returns the entries of the cache, the failures are not
+// included since they are tiny.
+func (c *Cache) entries() []cacheEntry {
+	var result []cacheEntry
+	dirs := []string{c.dir}
+	for _, size := range sizes {
+		dirs = append(dirs, filepath.Join(c.dir, size.Dir()))
+	}
+	for _, dir := range dirs {
+		fileInfos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range fileInfos {
+			// the directories and the temporary files
+			if !fi.Mode().IsRegular() || strings.HasPrefix(fi.Name(), ".") {
+				continue
+			}
+			result = append(result, cacheEntry{
+				path:  filepath.Join(dir, fi.Name()),
+				size:  fi.Size(),
+				mtime: fi.ModTime(),
+			})
+		}
+	}
+	return result
+}
+
+// added counts the bytes of the new entry, and removes the least recently
+// used entries if the cache is over the budget.
+func (c *Cache) added(size int64) {
+	if c.maxBytes <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.used < 0 {
+		// the new entry is counted by the scan
+		c.used = 0
+		for _, e := range c.entries() {
+			c.used += e.size
+		}
+	} else {
+		c.used += size
+	}
+	if c.used > c.maxBytes {
+		c.evict()
+	}
+}
+
+// evict removes the least recently used entries until the cache is below
+// 90% of the budget if it is over the budget, so that it is not done
+// again at once.
+func (c *Cache) evict() {
+	// scan again since the other processes may share the directory
+	entries := c.entries()
+	c.used = 0
+	for _, e := range entries {
+		c.used += e.size
+	}
+	if c.used <= c.maxBytes {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].mtime.Before(entries[j].mtime)
+	})
+	target := c.maxBytes / 10 * 9
+	for _, e := range entries {
+		if c.used <= target {
+			break
+		}
+		if err := os.Remove(e.path); err == nil || os.IsNotExist(err) {
+			c.used -= e.size
+		}
+	}
+}
+
+// Evict removes the least recently used entries if the cache is over the
+// budget.
+func (c *Cache) Evict() {
+	if c.maxBytes <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+}
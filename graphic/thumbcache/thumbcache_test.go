@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package thumbcache
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSource(t *testing.T, file, content string, mtime time.Time) {
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(file, mtime, mtime))
+}
+
+func TestURI(t *testing.T) {
+	uri, err := URI("/home/user/a b#1.png")
+	require.NoError(t, err)
+	assert.Equal(t, "file:///home/user/a%20b%231.png", uri)
+}
+
+func TestDefault(t *testing.T) {
+	c := Default()
+	assert.Equal(t, DefaultDir(), c.Dir())
+	// the thumbnails of the other applications are never evicted
+	assert.Equal(t, int64(0), c.maxBytes)
+}
+
+func TestPNGTexts(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	texts := map[string]string{keyURI: "file:///a.png", keyMTime: "1"}
+	var buf bytes.Buffer
+	require.NoError(t, encodePNG(&buf, img, texts))
+
+	// still a valid png
+	decoded, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds(), decoded.Bounds())
+
+	result, err := readPNGTexts(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, texts, result)
+
+	_, err = readPNGTexts(bytes.NewReader([]byte("not a png")))
+	assert.Equal(t, errNotPNG, err)
+
+	// the chunk claims to be 4GB
+	data := append([]byte(nil), buf.Bytes()[:pngHeaderLen]...)
+	data = append(data, 0xff, 0xff, 0xff, 0xff, 't', 'E', 'X', 't')
+	_, err = readPNGTexts(bytes.NewReader(data))
+	assert.Equal(t, errPNGChunkLength, err)
+
+	// the large tEXt chunks and the ones after the image data are skipped
+	var large bytes.Buffer
+	large.Write(buf.Bytes()[:pngHeaderLen])
+	require.NoError(t, writePNGChunk(&large, "tEXt", []byte("Large\x00"+strings.Repeat("a", maxPNGTextLen))))
+	require.NoError(t, writePNGChunk(&large, "tEXt", []byte("Small\x00b")))
+	require.NoError(t, writePNGChunk(&large, "IDAT", nil))
+	require.NoError(t, writePNGChunk(&large, "tEXt", []byte("After\x00c")))
+	// the chunk after the image data is truncated, which is not read
+	large.Write([]byte{0, 0, 0, 100, 't', 'E', 'X', 't'})
+	result, err = readPNGTexts(&large)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Small": "b"}, result)
+}
+
+func TestCacheThumbnail(t *testing.T) {
+	dir := t.TempDir()
+	c := New(filepath.Join(dir, "thumbnails"), "test", 0)
+	srcFile := filepath.Join(dir, "src.png")
+	mtime := time.Unix(1600000000, 0)
+	writeSource(t, srcFile, "source", mtime)
+
+	_, ok := c.Lookup(srcFile, SizeNormal)
+	assert.False(t, ok)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 128, 64))
+	thumbFile, err := c.Save(srcFile, SizeNormal, img, "image/png")
+	require.NoError(t, err)
+	uri, _ := URI(srcFile)
+	assert.Equal(t, filepath.Join(dir, "thumbnails/normal", md5Hex(uri)+".png"), thumbFile)
+	fi, err := os.Stat(thumbFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	f, err := os.Open(thumbFile)
+	require.NoError(t, err)
+	texts, err := readPNGTexts(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Thumb::URI":   uri,
+		"Thumb::MTime": "1600000000",
+		"Thumb::Size":  "6",
+		"Thumb::Mime":  "image/png",
+	}, texts)
+
+	result, ok := c.Lookup(srcFile, SizeNormal)
+	assert.True(t, ok)
+	assert.Equal(t, thumbFile, result)
+	_, ok = c.Lookup(srcFile, SizeLarge)
+	assert.False(t, ok)
+
+	// the source is changed
+	writeSource(t, srcFile, "source", mtime.Add(time.Second))
+	_, ok = c.Lookup(srcFile, SizeNormal)
+	assert.False(t, ok)
+	_, err = c.Save(srcFile, SizeNormal, img, "")
+	require.NoError(t, err)
+	writeSource(t, srcFile, "changed", mtime.Add(time.Second))
+	_, ok = c.Lookup(srcFile, SizeNormal)
+	assert.False(t, ok)
+}
+
+func TestCacheFailed(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, "test", 0)
+	srcFile := filepath.Join(dir, "src.png")
+	mtime := time.Unix(1600000000, 0)
+	writeSource(t, srcFile, "broken", mtime)
+
+	assert.False(t, c.Failed(srcFile))
+	require.NoError(t, c.SaveFailed(srcFile))
+	assert.True(t, c.Failed(srcFile))
+	uri, _ := URI(srcFile)
+	assert.FileExists(t, filepath.Join(dir, "fail/test", md5Hex(uri)+".png"))
+
+	writeSource(t, srcFile, "fixed", mtime.Add(time.Second))
+	assert.False(t, c.Failed(srcFile))
+
+	assert.Error(t, c.SaveFailed(filepath.Join(dir, "not-exist")))
+}
+
+func TestCacheDerived(t *testing.T) {
+	dir := t.TempDir()
+	c := New(filepath.Join(dir, "cache"), "test", 0)
+	srcFile := filepath.Join(dir, "src.png")
+	mtime := time.Unix(1600000000, 0)
+	writeSource(t, srcFile, "source", mtime)
+
+	count := 0
+	create := func(dst string) error {
+		count++
+		return ioutil.WriteFile(dst, []byte("derived"), 0644)
+	}
+	dstFile, useCache, err := c.Derived(srcFile, "scale 10x10", "png", create)
+	require.NoError(t, err)
+	assert.False(t, useCache)
+	assert.Equal(t, ".png", filepath.Ext(dstFile))
+	content, err := ioutil.ReadFile(dstFile)
+	require.NoError(t, err)
+	assert.Equal(t, "derived", string(content))
+
+	result, useCache, err := c.Derived(srcFile, "scale 10x10", "png", create)
+	require.NoError(t, err)
+	assert.True(t, useCache)
+	assert.Equal(t, dstFile, result)
+	assert.Equal(t, 1, count)
+
+	// other params
+	result, useCache, err = c.Derived(srcFile, "scale 20x20", "png", create)
+	require.NoError(t, err)
+	assert.False(t, useCache)
+	assert.NotEqual(t, dstFile, result)
+
+	// the source is changed
+	writeSource(t, srcFile, "source", mtime.Add(time.Second))
+	result, useCache, err = c.Derived(srcFile, "scale 10x10", "png", create)
+	require.NoError(t, err)
+	assert.False(t, useCache)
+	assert.NotEqual(t, dstFile, result)
+	assert.Equal(t, 3, count)
+
+	// the failed ones leave nothing
+	_, _, err = c.Derived(srcFile, "fail", "png", func(dst string) error {
+		return os.ErrInvalid
+	})
+	assert.Equal(t, os.ErrInvalid, err)
+	fileInfos, err := ioutil.ReadDir(c.Dir())
+	require.NoError(t, err)
+	assert.Len(t, fileInfos, 3)
+
+	_, _, err = c.Derived(filepath.Join(dir, "not-exist"), "", "png", create)
+	assert.Error(t, err)
+}
+
+func TestCacheEvict(t *testing.T) {
+	dir := t.TempDir()
+	c := New(filepath.Join(dir, "cache"), "test", 1000)
+	create := func(dst string) error {
+		return ioutil.WriteFile(dst, make([]byte, 300), 0644)
+	}
+
+	var files []string
+	for i, name := range []string{"a", "b", "c"} {
+		srcFile := filepath.Join(dir, name+".src")
+		writeSource(t, srcFile, name, time.Unix(1600000000, 0))
+		dstFile, _, err := c.Derived(srcFile, "", "", create)
+		require.NoError(t, err)
+		// the older ones are used less recently
+		used := time.Now().Add(time.Duration(i-10) * time.Second)
+		require.NoError(t, os.Chtimes(dstFile, used, used))
+		files = append(files, dstFile)
+	}
+	// use a again
+	_, useCache, err := c.Derived(filepath.Join(dir, "a.src"), "", "", create)
+	require.NoError(t, err)
+	assert.True(t, useCache)
+
+	// the budget is exceeded, b is the least recently used
+	srcFile := filepath.Join(dir, "d.src")
+	writeSource(t, srcFile, "d", time.Unix(1600000000, 0))
+	dstFile, _, err := c.Derived(srcFile, "", "", create)
+	require.NoError(t, err)
+	assert.FileExists(t, files[0])
+	assert.NoFileExists(t, files[1])
+	assert.FileExists(t, files[2])
+	assert.FileExists(t, dstFile)
+}
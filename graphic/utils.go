@@ -8,25 +8,29 @@ import (
 	"image"
 	"image/draw"
 	"os"
+	"path/filepath"
+
+	"github.com/linuxdeepin/go-lib/graphic/thumbcache"
 	"github.com/linuxdeepin/go-lib/utils"
 )
 
-func generateCacheFilePath(keyword string) (dstfile string) {
-	return utils.GenerateCacheFilePathWithPrefix("graphic", keyword)
+// ImageCache keeps the images generated by the XXXCache functions, it is
+// shared by the packages generating them, such as gdkpixbuf, so that the
+// cache directory is managed by a single instance.
+var ImageCache = thumbcache.New(filepath.Join(utils.DefaultCachePrefix, "graphic"),
+	"deepin-graphic", thumbcache.DefaultMaxBytes)
+
+// getCacheFile returns the cached image generated from srcfile by the
+// operation with the params, create is called to generate it if it is
+// not cached or srcfile is changed.
+func getCacheFile(srcfile, params string, f Format, create func(dstfile string) error) (dstfile string, useCache bool, err error) {
+	return ImageCache.Derived(srcfile, params, string(f), create)
 }
 
 func openFileOrCreate(file string) (*os.File, error) {
 	return os.OpenFile(file, os.O_WRONLY|os.O_CREATE, 0644)
 }
 
-func isFileExists(file string) bool {
-	if _, err := os.Stat(file); err == nil {
-		return true
-	} else {
-		return false
-	}
-}
-
 // convert image.Image to *image.RGBA
 func convertToRGBA(img image.Image) (rgba *image.RGBA) {
 	b := img.Bounds()
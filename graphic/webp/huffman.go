@@ -0,0 +1,284 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package webp
+
+import (
+	"sort"
+)
+
+// bitWriter writes the bits from the least significant one, which is the
+// order of VP8L.
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nbits uint
+}
+
+// write writes the low n bits of v, n is at most 32.
+func (w *bitWriter) write(v uint32, n uint) {
+	w.bits |= uint64(v) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nbits -= 8
+	}
+}
+
+// bytes returns the bits written, the last byte is padded with zeros.
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// huffmanCode is the canonical Huffman code of an alphabet.
+type huffmanCode struct {
+	// the bits of the codes, reversed to be written from the first bit
+	codes []uint16
+	// the lengths of the codes written, which are zero if the alphabet
+	// has only one symbol used
+	lengths []uint8
+}
+
+func (c *huffmanCode) writeSymbol(w *bitWriter, symbol int) {
+	w.write(uint32(c.codes[symbol]), uint(c.lengths[symbol]))
+}
+
+type huffmanNode struct {
+	freq int
+	// the symbols of the leaves under the node
+	symbols []int
+}
+
+// buildLengths returns the lengths of the Huffman codes of the
+// frequencies, which are at most maxLength. At least 2 frequencies should
+// be non-zero.
+func buildLengths(freqs []int, maxLength int) []uint8 {
+	freqs = append([]int(nil), freqs...)
+	for {
+		lengths := make([]uint8, len(freqs))
+		var nodes []huffmanNode
+		for symbol, freq := range freqs {
+			if freq > 0 {
+				nodes = append(nodes, huffmanNode{freq, []int{symbol}})
+			}
+		}
+		// merge the 2 least frequent nodes until a tree is left, the
+		// symbols under the merged nodes get 1 bit longer
+		for len(nodes) > 1 {
+			sort.SliceStable(nodes, func(i, j int) bool {
+				return nodes[i].freq < nodes[j].freq
+			})
+			a, b := nodes[0], nodes[1]
+			symbols := append(a.symbols, b.symbols...)
+			for _, symbol := range symbols {
+				lengths[symbol]++
+			}
+			nodes = append(nodes[2:], huffmanNode{a.freq + b.freq, symbols})
+		}
+
+		ok := true
+		for _, l := range lengths {
+			if int(l) > maxLength {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return lengths
+		}
+		// flatten the frequencies until the codes are short enough
+		for i, freq := range freqs {
+			if freq > 0 {
+				freqs[i] = (freq + 1) / 2
+			}
+		}
+	}
+}
+
+// canonicalCode returns the canonical Huffman code of the lengths.
+func canonicalCode(lengths []uint8) *huffmanCode {
+	var count [16]int
+	used := 0
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+			used++
+		}
+	}
+	var next [16]int
+	code := 0
+	for l := 1; l < len(next); l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+
+	c := &huffmanCode{
+		codes:   make([]uint16, len(lengths)),
+		lengths: make([]uint8, len(lengths)),
+	}
+	if used == 1 {
+		// no bits are needed for the only symbol
+		return c
+	}
+	for symbol, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		v := next[l]
+		next[l]++
+		// reverse the bits since the code is read from the first bit
+		var reversed uint16
+		for i := uint8(0); i < l; i++ {
+			reversed = reversed<<1 | uint16(v>>i&1)
+		}
+		c.codes[symbol] = reversed
+		c.lengths[symbol] = l
+	}
+	return c
+}
+
+// the code length code is used to write the code lengths of the other
+// codes, the symbols 16, 17 and 18 repeat the lengths
+var codeLengthCodeOrder = [19]int{
+	17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+}
+
+type codeLengthToken struct {
+	symbol int
+	extra  uint32
+}
+
+// repeatLengths returns the tokens writing the code lengths.
+func repeatLengths(lengths []uint8) []codeLengthToken {
+	var tokens []codeLengthToken
+	// the length repeated by 16 if no non-zero length is written
+	prev := uint8(8)
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == l {
+			run++
+		}
+		i += run
+
+		if l == 0 {
+			for run >= 11 {
+				n := run
+				if n > 138 {
+					n = 138
+				}
+				tokens = append(tokens, codeLengthToken{18, uint32(n - 11)})
+				run -= n
+			}
+			if run >= 3 {
+				tokens = append(tokens, codeLengthToken{17, uint32(run - 3)})
+				run = 0
+			}
+		} else {
+			if l != prev {
+				tokens = append(tokens, codeLengthToken{int(l), 0})
+				prev = l
+				run--
+			}
+			for run >= 3 {
+				n := run
+				if n > 6 {
+					n = 6
+				}
+				tokens = append(tokens, codeLengthToken{16, uint32(n - 3)})
+				run -= n
+			}
+		}
+		for ; run > 0; run-- {
+			tokens = append(tokens, codeLengthToken{int(l), 0})
+		}
+	}
+	return tokens
+}
+
+var repeatExtraBits = [3]uint{2, 3, 7}
+
+// writeHuffmanCode writes the Huffman code of the frequencies and returns
+// it.
+func writeHuffmanCode(w *bitWriter, freqs []int) *huffmanCode {
+	var symbols []int
+	for symbol, freq := range freqs {
+		if freq > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	if len(symbols) == 0 {
+		// the alphabet is not used, a simple code of the symbol 0
+		symbols = []int{0}
+	}
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		// the simple code of 1 or 2 symbols of 8 bits
+		w.write(1, 1)
+		w.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			w.write(0, 1)
+			w.write(uint32(symbols[0]), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(symbols[0]), 8)
+		}
+		c := &huffmanCode{
+			codes:   make([]uint16, len(freqs)),
+			lengths: make([]uint8, len(freqs)),
+		}
+		if len(symbols) == 2 {
+			w.write(uint32(symbols[1]), 8)
+			c.codes[symbols[1]] = 1
+			c.lengths[symbols[0]] = 1
+			c.lengths[symbols[1]] = 1
+		}
+		return c
+	}
+
+	var lengths []uint8
+	if len(symbols) == 1 {
+		lengths = make([]uint8, len(freqs))
+		lengths[symbols[0]] = 1
+	} else {
+		lengths = buildLengths(freqs, 15)
+	}
+	tokens := repeatLengths(lengths)
+	clFreqs := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		clFreqs[t.symbol]++
+	}
+	var clLengths []uint8
+	if len(tokens) > 0 && clFreqs[tokens[0].symbol] == len(tokens) {
+		clLengths = make([]uint8, len(clFreqs))
+		clLengths[tokens[0].symbol] = 1
+	} else {
+		clLengths = buildLengths(clFreqs, 7)
+	}
+	clCode := canonicalCode(clLengths)
+
+	w.write(0, 1)
+	n := len(codeLengthCodeOrder)
+	for n > 4 && clLengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+	w.write(uint32(n-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:n] {
+		w.write(uint32(clLengths[symbol]), 3)
+	}
+	// all the lengths are written
+	w.write(0, 1)
+	for _, t := range tokens {
+		clCode.writeSymbol(w, t.symbol)
+		if t.symbol >= 16 {
+			w.write(t.extra, repeatExtraBits[t.symbol-16])
+		}
+	}
+	return canonicalCode(lengths)
+}
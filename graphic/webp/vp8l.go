@@ -0,0 +1,349 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package webp
+
+// The lossless bitstream is described in RFC 9649. The encoder uses the
+// subtract green and the predictor transforms, the LZ77 backward
+// references and one group of Huffman codes, without the color cache.
+
+const (
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	// the tiles of the predictor transform are 16x16
+	predictorBits = 4
+
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+
+	// the distances 1 to 120 are the codes of the pixels near by
+	distanceCodeOffset = 120
+	maxDistance        = 1<<20 - distanceCodeOffset
+	maxLength          = 4096
+	minLength          = 3
+
+	hashBits       = 16
+	maxChainLength = 16
+)
+
+// the predictor modes tried for the tiles
+const (
+	predictLeft   = 1
+	predictTop    = 2
+	predictSelect = 11
+	predictClamp  = 12
+)
+
+var predictorModes = []int{predictLeft, predictTop, predictSelect, predictClamp}
+
+// encodeVP8L returns the data of the VP8L chunk.
+func encodeVP8L(argb []uint32, width, height int, hasAlpha bool) []byte {
+	w := &bitWriter{}
+	w.write(0x2f, 8)
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	if hasAlpha {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+	// the version
+	w.write(0, 3)
+
+	subtractGreen(argb)
+	w.write(1, 1)
+	w.write(transformSubtractGreen, 2)
+
+	residuals, modes, tilesX := predict(argb, width, height)
+	w.write(1, 1)
+	w.write(transformPredictor, 2)
+	w.write(predictorBits-2, 3)
+	writeImage(w, modes, tilesX, false)
+
+	// no more transforms
+	w.write(0, 1)
+	writeImage(w, residuals, width, true)
+	return w.bytes()
+}
+
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		green := p >> 8 & 0xff
+		argb[i] = p&0xff00ff00 | ((p>>16&0xff-green)&0xff)<<16 | (p-green)&0xff
+	}
+}
+
+// subPixels subtracts each channel of b from a.
+func subPixels(a, b uint32) uint32 {
+	alphaGreen := (a | 0x00ff00ff) - (b & 0xff00ff00)
+	redBlue := (a | 0xff00ff00) - (b & 0x00ff00ff)
+	return alphaGreen&0xff00ff00 | redBlue&0x00ff00ff
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func predictPixel(mode int, left, top, topLeft uint32) uint32 {
+	switch mode {
+	case predictLeft:
+		return left
+	case predictTop:
+		return top
+	case predictSelect:
+		var distLeft, distTop int
+		for shift := uint(0); shift < 32; shift += 8 {
+			l := int(left >> shift & 0xff)
+			t := int(top >> shift & 0xff)
+			tl := int(topLeft >> shift & 0xff)
+			distLeft += abs(t - tl)
+			distTop += abs(l - tl)
+		}
+		if distLeft < distTop {
+			return left
+		}
+		return top
+	case predictClamp:
+		var result uint32
+		for shift := uint(0); shift < 32; shift += 8 {
+			v := int(left>>shift&0xff) + int(top>>shift&0xff) - int(topLeft>>shift&0xff)
+			if v < 0 {
+				v = 0
+			} else if v > 0xff {
+				v = 0xff
+			}
+			result |= uint32(v) << shift
+		}
+		return result
+	}
+	return 0xff000000
+}
+
+// residualCost estimates how many bits the residual takes.
+func residualCost(r uint32) int {
+	cost := 0
+	for shift := uint(0); shift < 32; shift += 8 {
+		cost += abs(int(int8(r >> shift)))
+	}
+	return cost
+}
+
+// predict returns the residuals of the predictor transform, and the
+// image of the modes of the tiles.
+func predict(argb []uint32, width, height int) (residuals, modes []uint32, tilesX int) {
+	const tileSize = 1 << predictorBits
+	tilesX = (width + tileSize - 1) >> predictorBits
+	tilesY := (height + tileSize - 1) >> predictorBits
+	residuals = make([]uint32, len(argb))
+	modes = make([]uint32, tilesX*tilesY)
+
+	// the first row is predicted by the left pixels, and the first column
+	// by the top ones
+	residuals[0] = subPixels(argb[0], 0xff000000)
+	for x := 1; x < width; x++ {
+		residuals[x] = subPixels(argb[x], argb[x-1])
+	}
+	for y := 1; y < height; y++ {
+		residuals[y*width] = subPixels(argb[y*width], argb[(y-1)*width])
+	}
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			x0, y0 := tx*tileSize, ty*tileSize
+			x1, y1 := x0+tileSize, y0+tileSize
+			if x0 == 0 {
+				x0 = 1
+			}
+			if y0 == 0 {
+				y0 = 1
+			}
+			if x1 > width {
+				x1 = width
+			}
+			if y1 > height {
+				y1 = height
+			}
+
+			bestMode, bestCost := predictLeft, -1
+			for _, mode := range predictorModes {
+				cost := 0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						i := y*width + x
+						p := predictPixel(mode, argb[i-1], argb[i-width], argb[i-width-1])
+						cost += residualCost(subPixels(argb[i], p))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+
+			modes[ty*tilesX+tx] = 0xff000000 | uint32(bestMode)<<8
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					i := y*width + x
+					p := predictPixel(bestMode, argb[i-1], argb[i-width], argb[i-width-1])
+					residuals[i] = subPixels(argb[i], p)
+				}
+			}
+		}
+	}
+	return
+}
+
+// token is a literal pixel, or a backward reference if length > 0.
+type token struct {
+	argb     uint32
+	length   int
+	distance int
+}
+
+func hashPixels(a, b uint32) uint32 {
+	return (a*0x1e35a7bd ^ b*0x9e3779b1) >> (32 - hashBits)
+}
+
+// findReferences returns the tokens of the pixels with the backward
+// references found by the hash chains.
+func findReferences(argb []uint32) []token {
+	tokens := make([]token, 0, len(argb))
+	head := make([]int32, 1<<hashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, len(argb))
+	insert := func(i int) {
+		if i+1 < len(argb) {
+			h := hashPixels(argb[i], argb[i+1])
+			prev[i] = head[h]
+			head[h] = int32(i)
+		}
+	}
+
+	for i := 0; i < len(argb); {
+		bestLength, bestDistance := 0, 0
+		if i+1 < len(argb) {
+			h := hashPixels(argb[i], argb[i+1])
+			limit := len(argb) - i
+			if limit > maxLength {
+				limit = maxLength
+			}
+			for j, n := head[h], 0; j >= 0 && n < maxChainLength; j, n = prev[j], n+1 {
+				distance := i - int(j)
+				if distance > maxDistance {
+					break
+				}
+				length := 0
+				for length < limit && argb[int(j)+length] == argb[i+length] {
+					length++
+				}
+				if length > bestLength {
+					bestLength, bestDistance = length, distance
+					if length == limit {
+						break
+					}
+				}
+			}
+		}
+
+		if bestLength >= minLength {
+			tokens = append(tokens, token{length: bestLength, distance: bestDistance})
+			for n := 0; n < bestLength; n++ {
+				insert(i + n)
+			}
+			i += bestLength
+		} else {
+			tokens = append(tokens, token{argb: argb[i]})
+			insert(i)
+			i++
+		}
+	}
+	return tokens
+}
+
+// prefixEncode returns the prefix code, the extra bits and their count
+// of the LZ77 length or distance.
+func prefixEncode(v int) (code int, extra uint32, extraBits uint) {
+	v--
+	if v < 4 {
+		return v, 0, 0
+	}
+	highest := uint(0)
+	for v>>(highest+1) != 0 {
+		highest++
+	}
+	second := v >> (highest - 1) & 1
+	extraBits = highest - 1
+	return int(2*highest) + second, uint32(v) & (1<<extraBits - 1), extraBits
+}
+
+// distanceCode returns the distance written, the pixel above and the left
+// one have the short codes.
+func distanceCode(distance, width int) int {
+	switch distance {
+	case width:
+		return 1
+	case 1:
+		return 2
+	}
+	return distance + distanceCodeOffset
+}
+
+// writeImage writes the entropy coded image, the main image is top level
+// and the others are the images of the transforms.
+func writeImage(w *bitWriter, argb []uint32, width int, topLevel bool) {
+	tokens := findReferences(argb)
+
+	green := make([]int, numLiteralCodes+numLengthCodes)
+	red := make([]int, numLiteralCodes)
+	blue := make([]int, numLiteralCodes)
+	alpha := make([]int, numLiteralCodes)
+	distance := make([]int, numDistanceCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			alpha[t.argb>>24]++
+			red[t.argb>>16&0xff]++
+			green[t.argb>>8&0xff]++
+			blue[t.argb&0xff]++
+			continue
+		}
+		code, _, _ := prefixEncode(t.length)
+		green[numLiteralCodes+code]++
+		code, _, _ = prefixEncode(distanceCode(t.distance, width))
+		distance[code]++
+	}
+
+	// no color cache
+	w.write(0, 1)
+	if topLevel {
+		// one group of Huffman codes for the whole image
+		w.write(0, 1)
+	}
+	greenCode := writeHuffmanCode(w, green)
+	redCode := writeHuffmanCode(w, red)
+	blueCode := writeHuffmanCode(w, blue)
+	alphaCode := writeHuffmanCode(w, alpha)
+	distanceCodes := writeHuffmanCode(w, distance)
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			greenCode.writeSymbol(w, int(t.argb>>8&0xff))
+			redCode.writeSymbol(w, int(t.argb>>16&0xff))
+			blueCode.writeSymbol(w, int(t.argb&0xff))
+			alphaCode.writeSymbol(w, int(t.argb>>24))
+			continue
+		}
+		code, extra, extraBits := prefixEncode(t.length)
+		greenCode.writeSymbol(w, numLiteralCodes+code)
+		w.write(extra, extraBits)
+		code, extra, extraBits = prefixEncode(distanceCode(t.distance, width))
+		distanceCodes.writeSymbol(w, code)
+		w.write(extra, extraBits)
+	}
+}
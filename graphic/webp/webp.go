@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package webp encodes the images in the lossless WebP format, the WebP
+// images are decoded by golang.org/x/image/webp, which is registered to
+// the image package when this package is imported.
+package webp
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+
+	"golang.org/x/image/webp"
+)
+
+// the max width and height of the WebP images
+const maxSize = 1 << 14
+
+// ErrSize is returned when the image is empty or too large for WebP.
+var ErrSize = errors.New("webp: invalid image size")
+
+// Decode reads a WebP image from r.
+func Decode(r io.Reader) (image.Image, error) {
+	return webp.Decode(r)
+}
+
+// DecodeConfig returns the color model and dimensions of a WebP image
+// without decoding the entire image.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	return webp.DecodeConfig(r)
+}
+
+// Encode writes the image m to w in the lossless WebP format.
+func Encode(w io.Writer, m image.Image) error {
+	b := m.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 || width > maxSize || height > maxSize {
+		return ErrSize
+	}
+
+	nrgba, ok := m.(*image.NRGBA)
+	if !ok {
+		nrgba = image.NewNRGBA(b)
+		draw.Draw(nrgba, b, m, b.Min, draw.Src)
+	}
+	argb := make([]uint32, 0, width*height)
+	hasAlpha := false
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := nrgba.PixOffset(b.Min.X, y)
+		for x := 0; x < width; x++ {
+			p := nrgba.Pix[i : i+4 : i+4]
+			if p[3] != 0xff {
+				hasAlpha = true
+			}
+			argb = append(argb, uint32(p[3])<<24|uint32(p[0])<<16|uint32(p[1])<<8|uint32(p[2]))
+			i += 4
+		}
+	}
+
+	data := encodeVP8L(argb, width, height, hasAlpha)
+	padding := len(data) & 1
+	var header [20]byte
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+len(data)+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if padding != 0 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
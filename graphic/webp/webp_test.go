@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestImage(w, h int, fn func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, fn(x, y))
+		}
+	}
+	return img
+}
+
+func testRoundTrip(t *testing.T, name string, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, img), name)
+
+	config, err := DecodeConfig(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err, name)
+	b := img.Bounds()
+	assert.Equal(t, b.Dx(), config.Width, name)
+	assert.Equal(t, b.Dy(), config.Height, name)
+
+	result, err := Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err, name)
+	expected := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(expected, expected.Bounds(), img, b.Min, draw.Src)
+	require.Equal(t, expected.Rect, result.Bounds(), name)
+	assert.Equal(t, expected.Pix, result.(*image.NRGBA).Pix, name)
+	return buf.Bytes()
+}
+
+func TestEncode(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	images := map[string]image.Image{
+		"solid": newTestImage(40, 30, func(x, y int) color.NRGBA {
+			return color.NRGBA{0x12, 0x34, 0x56, 0xff}
+		}),
+		"one pixel": newTestImage(1, 1, func(x, y int) color.NRGBA {
+			return color.NRGBA{0xff, 0, 0x80, 0x40}
+		}),
+		"one column": newTestImage(1, 50, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(y * 5), 0, 0, 0xff}
+		}),
+		"gradient": newTestImage(67, 45, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 3), uint8(y * 5), uint8(x + y), uint8(255 - x)}
+		}),
+		"stripes": newTestImage(100, 80, func(x, y int) color.NRGBA {
+			if (x/7+y/5)%2 == 0 {
+				return color.NRGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			return color.NRGBA{0x20, 0x40, 0x60, 0x80}
+		}),
+		"noise": newTestImage(33, 17, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(rnd.Intn(256)), uint8(rnd.Intn(256)),
+				uint8(rnd.Intn(256)), uint8(rnd.Intn(256))}
+		}),
+	}
+	for name, img := range images {
+		testRoundTrip(t, name, img)
+	}
+
+	// the other color models and the sub images
+	rgba := image.NewRGBA(image.Rect(-5, -5, 20, 20))
+	draw.Draw(rgba, rgba.Rect, images["gradient"], image.Point{}, draw.Src)
+	testRoundTrip(t, "rgba", rgba)
+	testRoundTrip(t, "sub image", images["gradient"].(*image.NRGBA).SubImage(image.Rect(10, 10, 30, 25)))
+	gray := image.NewGray(image.Rect(0, 0, 20, 10))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i)
+	}
+	testRoundTrip(t, "gray", gray)
+}
+
+func TestEncodeCompression(t *testing.T) {
+	// the flat images are much smaller than the raw pixels
+	img := newTestImage(256, 256, func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(x), uint8(y), 0x80, 0xff}
+	})
+	data := testRoundTrip(t, "large gradient", img)
+	assert.True(t, len(data) < 256*256/10, len(data))
+}
+
+func TestEncodeSize(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Equal(t, ErrSize, Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 0, 10))))
+	assert.Equal(t, ErrSize, Encode(&buf, image.NewNRGBA(image.Rect(0, 0, maxSize+1, 1))))
+	assert.Zero(t, buf.Len())
+}
+
+func TestBuildLengths(t *testing.T) {
+	// the Fibonacci frequencies give the longest codes
+	freqs := make([]int, 30)
+	freqs[0], freqs[1] = 1, 1
+	for i := 2; i < len(freqs); i++ {
+		freqs[i] = freqs[i-1] + freqs[i-2]
+	}
+	lengths := buildLengths(freqs, 7)
+	kraft := 0.0
+	for _, l := range lengths {
+		require.True(t, l >= 1 && l <= 7, l)
+		kraft += 1 / float64(int(1)<<l)
+	}
+	assert.Equal(t, 1.0, kraft)
+}
@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package imgutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	"github.com/linuxdeepin/go-lib/graphic"
+	"github.com/linuxdeepin/go-lib/graphic/exif"
+	"github.com/linuxdeepin/go-lib/graphic/jpeg"
+	"github.com/linuxdeepin/go-lib/graphic/svg"
+	"github.com/linuxdeepin/go-lib/strv"
+)
+
+var (
+	ErrFileTooLarge     = errors.New("imgutil: file too large")
+	ErrImageTooLarge    = errors.New("imgutil: image too large")
+	ErrFormatNotAllowed = errors.New("imgutil: format not allowed")
+	ErrTimeout          = errors.New("imgutil: decoding timed out")
+)
+
+// DecodeOptions limits the decoding of the untrusted images, the zero
+// values are not limited.
+type DecodeOptions struct {
+	// the max width*height of the image
+	MaxPixels int64
+	// the max size of the file in bytes
+	MaxFileSize int64
+	// the formats allowed, such as FormatPNG
+	Formats []string
+	// the max duration of the decoding, the decoding of JPEG and SVG is
+	// stopped in the timeout, the other formats are stopped at the next
+	// read of the file
+	Timeout time.Duration
+
+	// the image decoded is scaled down to fit in the size keeping the
+	// aspect ratio, the JPEG images are scaled while decoding, so that
+	// the thumbnails of the large photos are decoded fast
+	MaxWidth, MaxHeight int
+}
+
+// Config is the format and the size of the image, the size is of the
+// image rotated by its EXIF orientation.
+type Config struct {
+	Format        string
+	Width, Height int
+}
+
+type probeResult struct {
+	format      string
+	config      image.Config
+	orientation exif.Orientation
+}
+
+// Probe reads the format and the size of the image file without decoding
+// it, and checks them by the options, opts may be nil.
+func Probe(filename string, opts *DecodeOptions) (Config, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return Config{}, err
+	}
+	defer fh.Close()
+
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+	res, err := probe(fh, opts)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{Format: res.format, Width: res.config.Width, Height: res.config.Height}
+	if res.orientation.SwapsSize() {
+		cfg.Width, cfg.Height = cfg.Height, cfg.Width
+	}
+	return cfg, nil
+}
+
+func probe(fh *os.File, opts *DecodeOptions) (*probeResult, error) {
+	if opts.MaxFileSize > 0 {
+		info, err := fh.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > opts.MaxFileSize {
+			return nil, ErrFileTooLarge
+		}
+	}
+
+	format, config, err := decodeConfig(bufio.NewReader(fh))
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Formats) > 0 && !strv.Strv(opts.Formats).Contains(format) {
+		return nil, ErrFormatNotAllowed
+	}
+	if opts.MaxPixels > 0 && int64(config.Width)*int64(config.Height) > opts.MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	res := &probeResult{format: format, config: config}
+	if format == FormatJPEG || format == FormatTIFF {
+		if _, err := fh.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if md, err := exif.Decode(fh); err == nil {
+			res.orientation = md.Orientation
+		}
+	}
+	return res, nil
+}
+
+func decodeConfig(br *bufio.Reader) (string, image.Config, error) {
+	format := sniff(br).name
+	if format == FormatSVG {
+		config, err := svg.DecodeConfig(br)
+		return format, config, err
+	}
+	config, name, err := image.DecodeConfig(br)
+	if format == "" {
+		format = name
+	}
+	return format, config, err
+}
+
+// LoadWithOptions loads the image file as Load, with the limits of the
+// options for the untrusted images, such as the thumbnails in the file
+// manager. The image is decoded without gdkpixbuf, the header is checked
+// before decoding, so that the huge images are rejected before they are
+// allocated. opts may be nil.
+func LoadWithOptions(filename string, opts *DecodeOptions) (image.Image, error) {
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+	if opts.Timeout <= 0 {
+		return loadWithOptions(context.Background(), filename, opts)
+	}
+
+	type result struct {
+		img image.Image
+		err error
+	}
+	// the decoding is abandoned after the timeout, and stopped by the
+	// decoders or the reader once the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	ch := make(chan result, 1)
+	go func() {
+		img, err := loadWithOptions(ctx, filename, opts)
+		ch <- result{img, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.img, r.err
+	case <-ctx.Done():
+		return nil, ErrTimeout
+	}
+}
+
+// contextReader fails the reads once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func loadWithOptions(ctx context.Context, filename string, opts *DecodeOptions) (image.Image, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	res, err := probe(fh, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fh.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(&contextReader{ctx: ctx, r: fh})
+
+	// the size to fit in, before the orientation is applied
+	maxWidth, maxHeight := opts.MaxWidth, opts.MaxHeight
+	if res.orientation.SwapsSize() {
+		maxWidth, maxHeight = maxHeight, maxWidth
+	}
+	width, height := fitSize(res.config.Width, res.config.Height, maxWidth, maxHeight)
+
+	var img image.Image
+	switch res.format {
+	case FormatSVG:
+		img, err = svg.RenderContext(ctx, br, width, height)
+	case FormatJPEG:
+		img, err = decodeJPEG(ctx, br, res.config, width, height)
+	default:
+		img, _, err = image.Decode(br)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	img = exif.Apply(img, res.orientation)
+	if res.orientation.SwapsSize() {
+		width, height = height, width
+	}
+	if b := img.Bounds(); b.Dx() > width || b.Dy() > height {
+		img = graphic.Scale(img, width, height, graphic.FilterBox)
+	}
+	return img, nil
+}
+
+// decodeJPEG decodes the JPEG image scaled down to the width and height at
+// least.
+func decodeJPEG(ctx context.Context, r io.Reader, config image.Config, width, height int) (image.Image, error) {
+	denom := jpeg.GetScaleDenom(config.Width, config.Height, width, height)
+	if denom == 1 {
+		img, _, err := image.Decode(r)
+		return img, err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	img, err := jpeg.DecodeScaledContext(ctx, bytes.NewReader(data), denom)
+	if err == jpeg.ErrUnsupported {
+		// such as the CMYK images
+		img, _, err = image.Decode(bytes.NewReader(data))
+	}
+	return img, err
+}
+
+// fitSize returns the size of the image scaled down to fit in maxWidth and
+// maxHeight keeping the aspect ratio, the sizes which are not positive
+// are not limited.
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = math.Min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale == 1 {
+		return width, height
+	}
+	w := int(math.Max(1, math.Round(float64(width)*scale)))
+	h := int(math.Max(1, math.Round(float64(height)*scale)))
+	return w, h
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package imgutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHugePNG writes a PNG image which claims to be width*height in its
+// header, only a few pixels are in the data.
+func writeHugePNG(t *testing.T, filename string, width, height uint32) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))))
+	data := buf.Bytes()
+	// the IHDR chunk follows the signature, its data after the length
+	// and the type, and then the CRC of the type and the data
+	ihdr := data[16:29]
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	require.NoError(t, ioutil.WriteFile(filename, data, 0644))
+}
+
+func writeJPEG(t *testing.T, filename string, width, height int) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0x80, 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	require.NoError(t, ioutil.WriteFile(filename, buf.Bytes(), 0644))
+}
+
+func TestProbe(t *testing.T) {
+	for _, ext := range []string{"bmp", "gif", "jpg", "png", "tiff", "webp", "svg"} {
+		cfg, err := Probe("testdata/deepin-music."+ext, nil)
+		if assert.NoError(t, err, ext) {
+			assert.Equal(t, 48, cfg.Width, ext)
+			assert.Equal(t, 48, cfg.Height, ext)
+			if ext != "jpg" {
+				assert.Equal(t, ext, cfg.Format)
+			} else {
+				assert.Equal(t, FormatJPEG, cfg.Format)
+			}
+		}
+	}
+
+	// the size is upright
+	cfg, err := Probe("testdata/exif-rotate90.jpg", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Config{Format: FormatJPEG, Width: 20, Height: 40}, cfg)
+
+	// only the header is read
+	huge := filepath.Join(t.TempDir(), "huge.png")
+	writeHugePNG(t, huge, 60000, 60000)
+	cfg, err = Probe(huge, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Config{Format: FormatPNG, Width: 60000, Height: 60000}, cfg)
+
+	_, err = Probe("testdata/not-exist.png", nil)
+	assert.Error(t, err)
+}
+
+func TestProbeLimits(t *testing.T) {
+	filename := "testdata/deepin-music.png"
+	_, err := Probe(filename, &DecodeOptions{MaxFileSize: 100})
+	assert.Equal(t, ErrFileTooLarge, err)
+	_, err = Probe(filename, &DecodeOptions{MaxPixels: 48*48 - 1})
+	assert.Equal(t, ErrImageTooLarge, err)
+	_, err = Probe(filename, &DecodeOptions{Formats: []string{FormatJPEG, FormatWEBP}})
+	assert.Equal(t, ErrFormatNotAllowed, err)
+
+	_, err = Probe(filename, &DecodeOptions{
+		MaxFileSize: 1 << 20,
+		MaxPixels:   48 * 48,
+		Formats:     []string{FormatPNG},
+	})
+	assert.NoError(t, err)
+}
+
+func TestLoadWithOptions(t *testing.T) {
+	for _, ext := range []string{"bmp", "gif", "jpg", "png", "tiff", "webp", "svg"} {
+		filename := "testdata/deepin-music." + ext
+		img, err := LoadWithOptions(filename, nil)
+		if assert.NoError(t, err, ext) {
+			assert.Equal(t, image.Rect(0, 0, 48, 48), img.Bounds(), ext)
+		}
+
+		img, err = LoadWithOptions(filename, &DecodeOptions{MaxWidth: 16, MaxHeight: 32})
+		if assert.NoError(t, err, ext) {
+			assert.Equal(t, image.Rect(0, 0, 16, 16), img.Bounds(), ext)
+		}
+	}
+
+	// rotated by the EXIF orientation
+	img, err := LoadWithOptions("testdata/exif-rotate90.jpg", &DecodeOptions{MaxHeight: 20})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 10, 20), img.Bounds())
+
+	huge := filepath.Join(t.TempDir(), "huge.png")
+	writeHugePNG(t, huge, 60000, 60000)
+	_, err = LoadWithOptions(huge, &DecodeOptions{MaxPixels: 4096 * 4096})
+	assert.Equal(t, ErrImageTooLarge, err)
+
+	_, err = LoadWithOptions("testdata/deepin-music.svg", &DecodeOptions{Formats: []string{FormatPNG}})
+	assert.Equal(t, ErrFormatNotAllowed, err)
+}
+
+func TestLoadWithOptionsJPEG(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "photo.jpg")
+	writeJPEG(t, filename, 400, 300)
+
+	// decoded in 1/2 by the DCT scaling, and then scaled to fit
+	img, err := LoadWithOptions(filename, &DecodeOptions{MaxWidth: 160, MaxHeight: 160})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 160, 120), img.Bounds())
+	r, g, b, _ := img.At(80, 60).RGBA()
+	assert.InDelta(t, 200, r>>8, 8)
+	assert.InDelta(t, 150, g>>8, 8)
+	assert.InDelta(t, 0x80, b>>8, 8)
+
+	// decoded in 1/8 exactly
+	img, err = LoadWithOptions(filename, &DecodeOptions{MaxWidth: 50})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 50, 38), img.Bounds())
+}
+
+func TestLoadWithOptionsTimeout(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "photo.jpg")
+	writeJPEG(t, filename, 2000, 2000)
+	_, err := LoadWithOptions(filename, &DecodeOptions{Timeout: time.Nanosecond})
+	assert.Equal(t, ErrTimeout, err)
+
+	img, err := LoadWithOptions(filename, &DecodeOptions{Timeout: time.Minute, MaxWidth: 100})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 100), img.Bounds())
+}
+
+func TestLoadWithOptionsTimeoutStops(t *testing.T) {
+	// the SVG image which takes minutes to render, 2^18 rectangles in the
+	// full size referenced by the nested <use>
+	var buf strings.Builder
+	buf.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="1000" height="1000"><defs>`)
+	buf.WriteString(`<g id="a0"><rect width="1000" height="1000" fill-opacity="0.5"/></g>`)
+	for i := 1; i <= 18; i++ {
+		fmt.Fprintf(&buf, `<g id="a%d"><use href="#a%d"/><use href="#a%d"/></g>`, i, i-1, i-1)
+	}
+	buf.WriteString(`</defs><use href="#a18"/></svg>`)
+	filename := filepath.Join(t.TempDir(), "slow.svg")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(buf.String()), 0644))
+
+	goroutines := runtime.NumGoroutine()
+	_, err := LoadWithOptions(filename, &DecodeOptions{Timeout: 50 * time.Millisecond})
+	assert.Equal(t, ErrTimeout, err)
+
+	// the goroutine decoding is stopped soon after the timeout
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+func TestFitSize(t *testing.T) {
+	w, h := fitSize(400, 300, 0, 0)
+	assert.Equal(t, []int{400, 300}, []int{w, h})
+	w, h = fitSize(400, 300, 800, 600)
+	assert.Equal(t, []int{400, 300}, []int{w, h})
+	w, h = fitSize(400, 300, 100, 0)
+	assert.Equal(t, []int{100, 75}, []int{w, h})
+	w, h = fitSize(400, 300, 100, 30)
+	assert.Equal(t, []int{40, 30}, []int{w, h})
+	w, h = fitSize(10000, 1, 100, 100)
+	assert.Equal(t, []int{100, 1}, []int{w, h})
+}
@@ -15,6 +15,9 @@ import (
 	_ "golang.org/x/image/bmp"
 	_ "golang.org/x/image/tiff"
 	"github.com/linuxdeepin/go-lib/gdkpixbuf"
+	"github.com/linuxdeepin/go-lib/graphic/exif"
+	"github.com/linuxdeepin/go-lib/graphic/svg"
+	_ "github.com/linuxdeepin/go-lib/graphic/webp"
 	"github.com/linuxdeepin/go-lib/strv"
 )
 
@@ -24,21 +27,31 @@ const (
 	FormatPNG  = "png"
 	FormatBMP  = "bmp"
 	FormatTIFF = "tiff"
+	FormatWEBP = "webp"
+	FormatSVG  = "svg"
 )
 
 var supportedFormats = strv.Strv([]string{FormatGIF, FormatJPEG, FormatPNG,
-	FormatBMP, FormatTIFF})
+	FormatBMP, FormatTIFF, FormatWEBP, FormatSVG})
 
 func GetSupportedFormats() strv.Strv {
 	return supportedFormats
 }
 
+// Load loads the image file, the image is rotated by its EXIF orientation
+// to be upright.
 func Load(filename string) (image.Image, error) {
 	img, err := loadViaGdkPixbuf(filename)
-	if err == nil {
-		return img, nil
+	if err != nil {
+		img, err = loadCommon(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if md, err := exif.DecodeFile(filename); err == nil {
+		img = exif.Apply(img, md.Orientation)
 	}
-	return loadCommon(filename)
+	return img, nil
 }
 
 func loadCommon(filename string) (image.Image, error) {
@@ -49,6 +62,10 @@ func loadCommon(filename string) (image.Image, error) {
 	defer fh.Close()
 
 	br := bufio.NewReader(fh)
+	// the SVG images may not start with the <svg> tag
+	if sniff(br).name == FormatSVG {
+		return svg.Decode(br)
+	}
 	img, _, err := image.Decode(br)
 	return img, err
 }
@@ -103,6 +120,6 @@ func CanDecodeConfig(filename string) bool {
 
 	defer fh.Close()
 	reader := bufio.NewReader(fh)
-	_, _, err = image.DecodeConfig(reader)
+	_, _, err = decodeConfig(reader)
 	return err == nil
 }
@@ -16,7 +16,8 @@ import (
 
 func TestLoad(t *testing.T) {
 	for _, name := range []string{"deepin-music.bmp", "deepin-music.gif",
-		"deepin-music.jpg", "deepin-music.png", "deepin-music.tiff"} {
+		"deepin-music.jpg", "deepin-music.png", "deepin-music.tiff",
+		"deepin-music.webp", "deepin-music.svg"} {
 		filename := filepath.Join("testdata", name)
 		img, err := Load(filename)
 		if assert.Nil(t, err) {
@@ -27,7 +28,8 @@ func TestLoad(t *testing.T) {
 
 func TestIsSupported(t *testing.T) {
 	for _, name := range []string{"deepin-music.bmp", "deepin-music.gif",
-		"deepin-music.jpg", "deepin-music.png", "deepin-music.tiff"} {
+		"deepin-music.jpg", "deepin-music.png", "deepin-music.tiff",
+		"deepin-music.webp", "deepin-music.svg"} {
 
 		filename := filepath.Join("testdata", name)
 		assert.True(t, IsSupported(filename), "should support "+name)
@@ -36,7 +38,8 @@ func TestIsSupported(t *testing.T) {
 
 func TestCanDecodeConfig(t *testing.T) {
 	for _, name := range []string{"deepin-music.bmp", "deepin-music.gif",
-		"deepin-music.jpg", "deepin-music.png", "deepin-music.tiff"} {
+		"deepin-music.jpg", "deepin-music.png", "deepin-music.tiff",
+		"deepin-music.webp", "deepin-music.svg"} {
 
 		filename := filepath.Join("testdata", name)
 		assert.True(t, CanDecodeConfig(filename), "should decode config ok "+name)
@@ -44,7 +47,7 @@ func TestCanDecodeConfig(t *testing.T) {
 }
 
 func TestSniffFormat(t *testing.T) {
-	for _, ext := range []string{"bmp", "gif", "png", "tiff"} {
+	for _, ext := range []string{"bmp", "gif", "png", "tiff", "webp", "svg"} {
 		filename := "testdata/deepin-music." + ext
 		format, err := SniffFormat(filename)
 		if assert.Nil(t, err) {
@@ -29,6 +29,12 @@ type Country struct {
 	OfficialName string `xml:"official_name,attr"`
 }
 
+// LocalizedName returns the name translated into lang, such as "zh_CN",
+// with the iso-codes translations. An empty lang means the English name.
+func (c *Country) LocalizedName(lang string) string {
+	return NewTranslator("iso_3166", lang).Tr(c.Name)
+}
+
 var countryDatabase *CountryDatabase
 var countryDatabaseLock sync.Mutex
 
@@ -130,6 +136,19 @@ func GetCountryNameForCode(code string) (name string, err error) {
 	return
 }
 
+func getCountryForCode(code string) (*Country, bool) {
+	database, err := GetCountryDatabase()
+	if err != nil {
+		return nil, false
+	}
+	for i := range database.Countries {
+		if strings.EqualFold(code, database.Countries[i].Alpha2Code) {
+			return &database.Countries[i], true
+		}
+	}
+	return nil, false
+}
+
 // GetAllCountryCode return all country code.
 func GetAllCountryCode() (codeList []string, err error) {
 	database, err := GetCountryDatabase()
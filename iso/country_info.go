@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"sort"
+	"strings"
+)
+
+// countryInfo is the currency and the official languages of a country,
+// which iso-codes does not provide.
+type countryInfo struct {
+	currency string
+	// the ISO 639-1 codes, or the ISO 639-3 codes of the languages
+	// without ISO 639-1 codes, the most used one first
+	languages []string
+}
+
+// countryInfoTable is derived from the CLDR territory information.
+var countryInfoTable = map[string]countryInfo{
+	"AD": {"EUR", []string{"ca"}},
+	"AE": {"AED", []string{"ar"}},
+	"AF": {"AFN", []string{"ps", "fa"}},
+	"AG": {"XCD", []string{"en"}},
+	"AI": {"XCD", []string{"en"}},
+	"AL": {"ALL", []string{"sq"}},
+	"AM": {"AMD", []string{"hy"}},
+	"AO": {"AOA", []string{"pt"}},
+	"AQ": {"", nil},
+	"AR": {"ARS", []string{"es"}},
+	"AS": {"USD", []string{"en", "sm"}},
+	"AT": {"EUR", []string{"de"}},
+	"AU": {"AUD", []string{"en"}},
+	"AW": {"AWG", []string{"nl", "pap"}},
+	"AX": {"EUR", []string{"sv"}},
+	"AZ": {"AZN", []string{"az"}},
+	"BA": {"BAM", []string{"bs", "hr", "sr"}},
+	"BB": {"BBD", []string{"en"}},
+	"BD": {"BDT", []string{"bn"}},
+	"BE": {"EUR", []string{"nl", "fr", "de"}},
+	"BF": {"XOF", []string{"fr"}},
+	"BG": {"EUR", []string{"bg"}},
+	"BH": {"BHD", []string{"ar"}},
+	"BI": {"BIF", []string{"rn", "fr", "en"}},
+	"BJ": {"XOF", []string{"fr"}},
+	"BL": {"EUR", []string{"fr"}},
+	"BM": {"BMD", []string{"en"}},
+	"BN": {"BND", []string{"ms"}},
+	"BO": {"BOB", []string{"es", "qu", "ay"}},
+	"BQ": {"USD", []string{"nl"}},
+	"BR": {"BRL", []string{"pt"}},
+	"BS": {"BSD", []string{"en"}},
+	"BT": {"BTN", []string{"dz"}},
+	"BV": {"NOK", nil},
+	"BW": {"BWP", []string{"en", "tn"}},
+	"BY": {"BYN", []string{"be", "ru"}},
+	"BZ": {"BZD", []string{"en"}},
+	"CA": {"CAD", []string{"en", "fr"}},
+	"CC": {"AUD", []string{"en"}},
+	"CD": {"CDF", []string{"fr"}},
+	"CF": {"XAF", []string{"fr", "sg"}},
+	"CG": {"XAF", []string{"fr"}},
+	"CH": {"CHF", []string{"de", "fr", "it", "rm"}},
+	"CI": {"XOF", []string{"fr"}},
+	"CK": {"NZD", []string{"en"}},
+	"CL": {"CLP", []string{"es"}},
+	"CM": {"XAF", []string{"fr", "en"}},
+	"CN": {"CNY", []string{"zh"}},
+	"CO": {"COP", []string{"es"}},
+	"CR": {"CRC", []string{"es"}},
+	"CU": {"CUP", []string{"es"}},
+	"CV": {"CVE", []string{"pt"}},
+	"CW": {"XCG", []string{"pap", "nl", "en"}},
+	"CX": {"AUD", []string{"en"}},
+	"CY": {"EUR", []string{"el", "tr"}},
+	"CZ": {"CZK", []string{"cs"}},
+	"DE": {"EUR", []string{"de"}},
+	"DJ": {"DJF", []string{"ar", "fr"}},
+	"DK": {"DKK", []string{"da"}},
+	"DM": {"XCD", []string{"en"}},
+	"DO": {"DOP", []string{"es"}},
+	"DZ": {"DZD", []string{"ar"}},
+	"EC": {"USD", []string{"es"}},
+	"EE": {"EUR", []string{"et"}},
+	"EG": {"EGP", []string{"ar"}},
+	"EH": {"MAD", []string{"ar"}},
+	"ER": {"ERN", []string{"ti", "ar", "en"}},
+	"ES": {"EUR", []string{"es"}},
+	"ET": {"ETB", []string{"am"}},
+	"FI": {"EUR", []string{"fi", "sv"}},
+	"FJ": {"FJD", []string{"en", "fj", "hif"}},
+	"FK": {"FKP", []string{"en"}},
+	"FM": {"USD", []string{"en"}},
+	"FO": {"DKK", []string{"fo", "da"}},
+	"FR": {"EUR", []string{"fr"}},
+	"GA": {"XAF", []string{"fr"}},
+	"GB": {"GBP", []string{"en"}},
+	"GD": {"XCD", []string{"en"}},
+	"GE": {"GEL", []string{"ka"}},
+	"GF": {"EUR", []string{"fr"}},
+	"GG": {"GBP", []string{"en"}},
+	"GH": {"GHS", []string{"en"}},
+	"GI": {"GIP", []string{"en"}},
+	"GL": {"DKK", []string{"kl"}},
+	"GM": {"GMD", []string{"en"}},
+	"GN": {"GNF", []string{"fr"}},
+	"GP": {"EUR", []string{"fr"}},
+	"GQ": {"XAF", []string{"es", "fr", "pt"}},
+	"GR": {"EUR", []string{"el"}},
+	"GS": {"GBP", []string{"en"}},
+	"GT": {"GTQ", []string{"es"}},
+	"GU": {"USD", []string{"en", "ch"}},
+	"GW": {"XOF", []string{"pt"}},
+	"GY": {"GYD", []string{"en"}},
+	"HK": {"HKD", []string{"zh", "en"}},
+	"HM": {"AUD", nil},
+	"HN": {"HNL", []string{"es"}},
+	"HR": {"EUR", []string{"hr"}},
+	"HT": {"HTG", []string{"fr", "ht"}},
+	"HU": {"HUF", []string{"hu"}},
+	"ID": {"IDR", []string{"id"}},
+	"IE": {"EUR", []string{"en", "ga"}},
+	"IL": {"ILS", []string{"he"}},
+	"IM": {"GBP", []string{"en", "gv"}},
+	"IN": {"INR", []string{"hi", "en"}},
+	"IO": {"USD", []string{"en"}},
+	"IQ": {"IQD", []string{"ar", "ku"}},
+	"IR": {"IRR", []string{"fa"}},
+	"IS": {"ISK", []string{"is"}},
+	"IT": {"EUR", []string{"it"}},
+	"JE": {"GBP", []string{"en"}},
+	"JM": {"JMD", []string{"en"}},
+	"JO": {"JOD", []string{"ar"}},
+	"JP": {"JPY", []string{"ja"}},
+	"KE": {"KES", []string{"sw", "en"}},
+	"KG": {"KGS", []string{"ky", "ru"}},
+	"KH": {"KHR", []string{"km"}},
+	"KI": {"AUD", []string{"en"}},
+	"KM": {"KMF", []string{"ar", "fr"}},
+	"KN": {"XCD", []string{"en"}},
+	"KP": {"KPW", []string{"ko"}},
+	"KR": {"KRW", []string{"ko"}},
+	"KW": {"KWD", []string{"ar"}},
+	"KY": {"KYD", []string{"en"}},
+	"KZ": {"KZT", []string{"kk", "ru"}},
+	"LA": {"LAK", []string{"lo"}},
+	"LB": {"LBP", []string{"ar"}},
+	"LC": {"XCD", []string{"en"}},
+	"LI": {"CHF", []string{"de"}},
+	"LK": {"LKR", []string{"si", "ta"}},
+	"LR": {"LRD", []string{"en"}},
+	"LS": {"LSL", []string{"st", "en"}},
+	"LT": {"EUR", []string{"lt"}},
+	"LU": {"EUR", []string{"lb", "fr", "de"}},
+	"LV": {"EUR", []string{"lv"}},
+	"LY": {"LYD", []string{"ar"}},
+	"MA": {"MAD", []string{"ar", "zgh"}},
+	"MC": {"EUR", []string{"fr"}},
+	"MD": {"MDL", []string{"ro"}},
+	"ME": {"EUR", []string{"sr"}},
+	"MF": {"EUR", []string{"fr"}},
+	"MG": {"MGA", []string{"mg", "fr"}},
+	"MH": {"USD", []string{"mh", "en"}},
+	"MK": {"MKD", []string{"mk"}},
+	"ML": {"XOF", []string{"fr"}},
+	"MM": {"MMK", []string{"my"}},
+	"MN": {"MNT", []string{"mn"}},
+	"MO": {"MOP", []string{"zh", "pt"}},
+	"MP": {"USD", []string{"en", "ch"}},
+	"MQ": {"EUR", []string{"fr"}},
+	"MR": {"MRU", []string{"ar"}},
+	"MS": {"XCD", []string{"en"}},
+	"MT": {"EUR", []string{"mt", "en"}},
+	"MU": {"MUR", []string{"en", "fr"}},
+	"MV": {"MVR", []string{"dv"}},
+	"MW": {"MWK", []string{"en", "ny"}},
+	"MX": {"MXN", []string{"es"}},
+	"MY": {"MYR", []string{"ms"}},
+	"MZ": {"MZN", []string{"pt"}},
+	"NA": {"NAD", []string{"en"}},
+	"NC": {"XPF", []string{"fr"}},
+	"NE": {"XOF", []string{"fr"}},
+	"NF": {"AUD", []string{"en"}},
+	"NG": {"NGN", []string{"en"}},
+	"NI": {"NIO", []string{"es"}},
+	"NL": {"EUR", []string{"nl"}},
+	"NO": {"NOK", []string{"nb", "nn"}},
+	"NP": {"NPR", []string{"ne"}},
+	"NR": {"AUD", []string{"na", "en"}},
+	"NU": {"NZD", []string{"en", "niu"}},
+	"NZ": {"NZD", []string{"en", "mi"}},
+	"OM": {"OMR", []string{"ar"}},
+	"PA": {"PAB", []string{"es"}},
+	"PE": {"PEN", []string{"es", "qu", "ay"}},
+	"PF": {"XPF", []string{"fr"}},
+	"PG": {"PGK", []string{"tpi", "en", "ho"}},
+	"PH": {"PHP", []string{"fil", "en"}},
+	"PK": {"PKR", []string{"ur", "en"}},
+	"PL": {"PLN", []string{"pl"}},
+	"PM": {"EUR", []string{"fr"}},
+	"PN": {"NZD", []string{"en"}},
+	"PR": {"USD", []string{"es", "en"}},
+	"PS": {"ILS", []string{"ar"}},
+	"PT": {"EUR", []string{"pt"}},
+	"PW": {"USD", []string{"pau", "en"}},
+	"PY": {"PYG", []string{"es", "gn"}},
+	"QA": {"QAR", []string{"ar"}},
+	"RE": {"EUR", []string{"fr"}},
+	"RO": {"RON", []string{"ro"}},
+	"RS": {"RSD", []string{"sr"}},
+	"RU": {"RUB", []string{"ru"}},
+	"RW": {"RWF", []string{"rw", "en", "fr", "sw"}},
+	"SA": {"SAR", []string{"ar"}},
+	"SB": {"SBD", []string{"en"}},
+	"SC": {"SCR", []string{"crs", "en", "fr"}},
+	"SD": {"SDG", []string{"ar", "en"}},
+	"SE": {"SEK", []string{"sv"}},
+	"SG": {"SGD", []string{"en", "zh", "ms", "ta"}},
+	"SH": {"SHP", []string{"en"}},
+	"SI": {"EUR", []string{"sl"}},
+	"SJ": {"NOK", []string{"nb"}},
+	"SK": {"EUR", []string{"sk"}},
+	"SL": {"SLE", []string{"en"}},
+	"SM": {"EUR", []string{"it"}},
+	"SN": {"XOF", []string{"fr"}},
+	"SO": {"SOS", []string{"so", "ar"}},
+	"SR": {"SRD", []string{"nl"}},
+	"SS": {"SSP", []string{"en"}},
+	"ST": {"STN", []string{"pt"}},
+	"SV": {"USD", []string{"es"}},
+	"SX": {"XCG", []string{"en", "nl"}},
+	"SY": {"SYP", []string{"ar"}},
+	"SZ": {"SZL", []string{"en", "ss"}},
+	"TC": {"USD", []string{"en"}},
+	"TD": {"XAF", []string{"fr", "ar"}},
+	"TF": {"EUR", []string{"fr"}},
+	"TG": {"XOF", []string{"fr"}},
+	"TH": {"THB", []string{"th"}},
+	"TJ": {"TJS", []string{"tg"}},
+	"TK": {"NZD", []string{"tkl", "en"}},
+	"TL": {"USD", []string{"pt", "tet"}},
+	"TM": {"TMT", []string{"tk"}},
+	"TN": {"TND", []string{"ar"}},
+	"TO": {"TOP", []string{"to", "en"}},
+	"TR": {"TRY", []string{"tr"}},
+	"TT": {"TTD", []string{"en"}},
+	"TV": {"AUD", []string{"tvl", "en"}},
+	"TW": {"TWD", []string{"zh"}},
+	"TZ": {"TZS", []string{"sw", "en"}},
+	"UA": {"UAH", []string{"uk"}},
+	"UG": {"UGX", []string{"en", "sw"}},
+	"UM": {"USD", []string{"en"}},
+	"US": {"USD", []string{"en"}},
+	"UY": {"UYU", []string{"es"}},
+	"UZ": {"UZS", []string{"uz"}},
+	"VA": {"EUR", []string{"it", "la"}},
+	"VC": {"XCD", []string{"en"}},
+	"VE": {"VES", []string{"es"}},
+	"VG": {"USD", []string{"en"}},
+	"VI": {"USD", []string{"en"}},
+	"VN": {"VND", []string{"vi"}},
+	"VU": {"VUV", []string{"bi", "en", "fr"}},
+	"WF": {"XPF", []string{"fr"}},
+	"WS": {"WST", []string{"sm", "en"}},
+	"YE": {"YER", []string{"ar"}},
+	"YT": {"EUR", []string{"fr"}},
+	"ZA": {"ZAR", []string{"en", "zu", "xh", "af", "nso", "tn", "st", "ts", "ss", "ve", "nr"}},
+	"ZM": {"ZMW", []string{"en"}},
+	"ZW": {"ZWG", []string{"en", "sn", "nd"}},
+}
+
+func getCountryInfo(countryCode string) (countryInfo, error) {
+	info, ok := countryInfoTable[strings.ToUpper(countryCode)]
+	if !ok {
+		return countryInfo{}, errCountryCodeInvalid
+	}
+	return info, nil
+}
+
+// GetCountryCurrencyCode return the ISO 4217 code of the currency used in
+// the country, e.g. "CNY" for "CN". It is empty for the territory without
+// a currency, such as Antarctica.
+func GetCountryCurrencyCode(countryCode string) (code string, err error) {
+	info, err := getCountryInfo(countryCode)
+	if err != nil {
+		return
+	}
+	return info.currency, nil
+}
+
+// GetCountryLanguageCodes return the codes of the official languages of the
+// country, see Language.Code. The most used language is the first.
+func GetCountryLanguageCodes(countryCode string) (codeList []string, err error) {
+	info, err := getCountryInfo(countryCode)
+	if err != nil {
+		return
+	}
+	return append([]string(nil), info.languages...), nil
+}
+
+// GetCountryLanguages return the official languages of the country.
+func GetCountryLanguages(countryCode string) (list []*Language, err error) {
+	codeList, err := GetCountryLanguageCodes(countryCode)
+	if err != nil {
+		return
+	}
+	for _, code := range codeList {
+		entry, err := GetLanguageForCode(code)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entry)
+	}
+	return
+}
+
+// GetLanguageCountryCodes return the codes of the countries where the
+// language is official, sorted by code. The language code may be any code
+// accepted by GetLanguageForCode.
+func GetLanguageCountryCodes(languageCode string) (codeList []string, err error) {
+	language, err := GetLanguageForCode(languageCode)
+	if err != nil {
+		return
+	}
+	for countryCode, info := range countryInfoTable {
+		for _, code := range info.languages {
+			if code == language.Code() {
+				codeList = append(codeList, countryCode)
+				break
+			}
+		}
+	}
+	sort.Strings(codeList)
+	return
+}
+
+// GetCurrencyCountryCodes return the codes of the countries using the
+// currency, sorted by code.
+func GetCurrencyCountryCodes(currencyCode string) (codeList []string) {
+	currencyCode = strings.ToUpper(currencyCode)
+	for countryCode, info := range countryInfoTable {
+		if info.currency == currencyCode {
+			codeList = append(codeList, countryCode)
+		}
+	}
+	sort.Strings(codeList)
+	return
+}
+
+// GetLocaleCurrencyCode return the currency code of the locale country, see
+// GetLocaleCountryCode.
+func GetLocaleCurrencyCode() (code string, err error) {
+	countryCode, err := GetLocaleCountryCode()
+	if err != nil {
+		return
+	}
+	return GetCountryCurrencyCode(countryCode)
+}
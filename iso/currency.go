@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/linuxdeepin/go-lib/gettext"
+)
+
+const (
+	iso4217JSONFile = "/usr/share/iso-codes/json/iso_4217.json"
+	iso4217Domain   = "iso_4217"
+)
+
+// CurrencyDatabase is the ISO 4217 database of iso-codes.
+type CurrencyDatabase struct {
+	Currencies []Currency `json:"4217"`
+}
+
+// Currency is an entry of ISO 4217.
+type Currency struct {
+	Alpha3Code  string `json:"alpha_3"`
+	NumericCode string `json:"numeric"`
+	Name        string `json:"name"`
+}
+
+// LocalizedName returns the name translated into lang, such as "zh_CN",
+// with the iso-codes translations. An empty lang means the English name.
+func (c *Currency) LocalizedName(lang string) string {
+	return gettext.NewTranslator(iso4217Domain, lang).Tr(c.Name)
+}
+
+var currencyDatabase *CurrencyDatabase
+var currencyDatabaseLock sync.Mutex
+
+var errCurrencyCodeInvalid = fmt.Errorf("invalid currency code")
+
+// GetCurrencyDatabase return currency database that unmarshaled from ISO
+// 4217 json file.
+func GetCurrencyDatabase() (*CurrencyDatabase, error) {
+	currencyDatabaseLock.Lock()
+	defer currencyDatabaseLock.Unlock()
+
+	if currencyDatabase != nil {
+		return currencyDatabase, nil
+	}
+
+	content, err := ioutil.ReadFile(iso4217JSONFile)
+	if err != nil {
+		return nil, err
+	}
+	database := &CurrencyDatabase{}
+	err = json.Unmarshal(content, database)
+	if err != nil {
+		return nil, err
+	}
+	currencyDatabase = database
+	return currencyDatabase, nil
+}
+
+// GetCurrencyForCode return the currency of the alphabetic or numeric
+// code, e.g. "CNY" or "156".
+func GetCurrencyForCode(code string) (*Currency, error) {
+	database, err := GetCurrencyDatabase()
+	if err != nil {
+		return nil, err
+	}
+	for i := range database.Currencies {
+		entry := &database.Currencies[i]
+		if strings.EqualFold(code, entry.Alpha3Code) || code == entry.NumericCode {
+			return entry, nil
+		}
+	}
+	return nil, errCurrencyCodeInvalid
+}
+
+// GetCurrencyNameForCode return currency name that corresponding to the
+// currency code.
+func GetCurrencyNameForCode(code string) (name string, err error) {
+	entry, err := GetCurrencyForCode(code)
+	if err != nil {
+		return
+	}
+	return gettext.DGettext(iso4217Domain, entry.Name), nil
+}
+
+// GetAllCurrencyCode return all currency code.
+func GetAllCurrencyCode() (codeList []string, err error) {
+	database, err := GetCurrencyDatabase()
+	if err != nil {
+		return
+	}
+	for _, entry := range database.Currencies {
+		codeList = append(codeList, entry.Alpha3Code)
+	}
+	return
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/linuxdeepin/go-lib/gettext"
+)
+
+const (
+	iso639JSONFile = "/usr/share/iso-codes/json/iso_639-3.json"
+	iso639Domain   = "iso_639-3"
+)
+
+// LanguageDatabase is the ISO 639-3 database of iso-codes.
+type LanguageDatabase struct {
+	Languages []Language `json:"639-3"`
+
+	// the languages by the lower case codes
+	index map[string]*Language
+}
+
+// Language is an entry of ISO 639-3, the ISO 639-1 code is set if the
+// language has one.
+type Language struct {
+	Alpha2Code        string `json:"alpha_2"`
+	Alpha3Code        string `json:"alpha_3"`
+	BibliographicCode string `json:"bibliographic"`
+	Name              string `json:"name"`
+	CommonName        string `json:"common_name"`
+	InvertedName      string `json:"inverted_name"`
+	// Scope is "I" for an individual language, "M" for a macrolanguage
+	// and "S" for special.
+	Scope string `json:"scope"`
+	// Type is "L" for a living language, "E" for extinct, "A" for
+	// ancient, "H" for historical, "C" for constructed and "S" for special.
+	Type string `json:"type"`
+}
+
+// Code returns the ISO 639-1 code of the language if it has one, otherwise
+// the ISO 639-3 code, as the language part of the locale names.
+func (l *Language) Code() string {
+	if l.Alpha2Code != "" {
+		return l.Alpha2Code
+	}
+	return l.Alpha3Code
+}
+
+// LocalizedName returns the name translated into lang, such as "zh_CN",
+// with the iso-codes translations. An empty lang means the English name.
+func (l *Language) LocalizedName(lang string) string {
+	return gettext.NewTranslator(iso639Domain, lang).Tr(l.Name)
+}
+
+var languageDatabase *LanguageDatabase
+var languageDatabaseLock sync.Mutex
+
+var errLanguageCodeInvalid = fmt.Errorf("invalid language code")
+
+// GetLanguageDatabase return language database that unmarshaled from ISO
+// 639-3 json file.
+func GetLanguageDatabase() (*LanguageDatabase, error) {
+	languageDatabaseLock.Lock()
+	defer languageDatabaseLock.Unlock()
+
+	if languageDatabase != nil {
+		return languageDatabase, nil
+	}
+
+	content, err := ioutil.ReadFile(iso639JSONFile)
+	if err != nil {
+		return nil, err
+	}
+	database := &LanguageDatabase{}
+	err = json.Unmarshal(content, database)
+	if err != nil {
+		return nil, err
+	}
+	database.index = make(map[string]*Language, len(database.Languages))
+	for i := range database.Languages {
+		entry := &database.Languages[i]
+		for _, code := range []string{entry.Alpha3Code, entry.BibliographicCode, entry.Alpha2Code} {
+			if code != "" {
+				database.index[strings.ToLower(code)] = entry
+			}
+		}
+	}
+	languageDatabase = database
+	return languageDatabase, nil
+}
+
+// GetLanguageForCode return the language of the ISO 639-1, ISO 639-3 or
+// ISO 639-2/B code, e.g. "zh", "zho" and "chi" are all Chinese.
+func GetLanguageForCode(code string) (*Language, error) {
+	database, err := GetLanguageDatabase()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := database.index[strings.ToLower(code)]
+	if !ok {
+		return nil, errLanguageCodeInvalid
+	}
+	return entry, nil
+}
+
+// GetLanguageNameForCode return language name that corresponding to the
+// language code.
+func GetLanguageNameForCode(code string) (name string, err error) {
+	entry, err := GetLanguageForCode(code)
+	if err != nil {
+		return
+	}
+	return gettext.DGettext(iso639Domain, entry.Name), nil
+}
+
+// GetAllLanguageCode return all language code, see Language.Code.
+func GetAllLanguageCode() (codeList []string, err error) {
+	database, err := GetLanguageDatabase()
+	if err != nil {
+		return
+	}
+	for i := range database.Languages {
+		codeList = append(codeList, database.Languages[i].Code())
+	}
+	return
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLanguageForCode(t *testing.T) {
+	for _, code := range []string{"zh", "zho", "chi", "ZH"} {
+		language, err := GetLanguageForCode(code)
+		require.NoError(t, err)
+		assert.Equal(t, "Chinese", language.Name)
+		assert.Equal(t, "zh", language.Code())
+		assert.Equal(t, "M", language.Scope)
+	}
+
+	language, err := GetLanguageForCode("yue")
+	require.NoError(t, err)
+	assert.Equal(t, "yue", language.Code())
+	assert.Equal(t, "德语", mustGetLanguage(t, "de").LocalizedName("zh_CN.UTF-8"))
+	assert.Equal(t, "German", mustGetLanguage(t, "de").LocalizedName(""))
+
+	_, err = GetLanguageForCode("xx")
+	assert.Equal(t, errLanguageCodeInvalid, err)
+
+	name, err := GetLanguageNameForCode("fr")
+	require.NoError(t, err)
+	assert.NotEmpty(t, name)
+
+	codeList, err := GetAllLanguageCode()
+	require.NoError(t, err)
+	assert.Contains(t, codeList, "en")
+	assert.Contains(t, codeList, "yue")
+}
+
+func mustGetLanguage(t *testing.T, code string) *Language {
+	language, err := GetLanguageForCode(code)
+	require.NoError(t, err)
+	return language
+}
+
+func TestSubdivision(t *testing.T) {
+	subdivision, err := GetSubdivisionForCode("cn-bj")
+	require.NoError(t, err)
+	assert.Equal(t, "CN-BJ", subdivision.Code)
+	assert.Equal(t, "Municipality", subdivision.Type)
+	assert.Equal(t, "CN", subdivision.CountryCode())
+	assert.Equal(t, "", subdivision.ParentCode())
+	assert.Equal(t, "Beijing Shi", subdivision.LocalizedName(""))
+
+	subdivision, err = GetSubdivisionForCode("AZ-BAB")
+	require.NoError(t, err)
+	assert.Equal(t, "AZ-NX", subdivision.ParentCode())
+
+	_, err = GetSubdivisionForCode("CN-XX")
+	assert.Equal(t, errSubdivisionCodeInvalid, err)
+
+	list, err := GetSubdivisionsForCountry("CN")
+	require.NoError(t, err)
+	assert.True(t, len(list) >= 34)
+	for _, s := range list {
+		assert.Equal(t, "CN", s.CountryCode())
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	for _, code := range []string{"CNY", "cny", "156"} {
+		currency, err := GetCurrencyForCode(code)
+		require.NoError(t, err)
+		assert.Equal(t, "CNY", currency.Alpha3Code)
+		assert.Equal(t, "Yuan Renminbi", currency.LocalizedName(""))
+	}
+	_, err := GetCurrencyForCode("XYZ")
+	assert.Equal(t, errCurrencyCodeInvalid, err)
+
+	codeList, err := GetAllCurrencyCode()
+	require.NoError(t, err)
+	assert.Contains(t, codeList, "EUR")
+}
+
+func TestCountryInfo(t *testing.T) {
+	code, err := GetCountryCurrencyCode("cn")
+	require.NoError(t, err)
+	assert.Equal(t, "CNY", code)
+
+	codeList, err := GetCountryLanguageCodes("CH")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"de", "fr", "it", "rm"}, codeList)
+
+	languages, err := GetCountryLanguages("PH")
+	require.NoError(t, err)
+	require.Len(t, languages, 2)
+	assert.Equal(t, "Filipino", languages[0].Name)
+
+	_, err = GetCountryCurrencyCode("XX")
+	assert.Equal(t, errCountryCodeInvalid, err)
+
+	codeList, err = GetLanguageCountryCodes("deu")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AT", "BE", "CH", "DE", "LI", "LU"}, codeList)
+
+	assert.Equal(t, []string{"CH", "LI"}, GetCurrencyCountryCodes("chf"))
+
+	// every country has the info, and the codes are known
+	countryCodes, err := GetAllCountryCode()
+	require.NoError(t, err)
+	for _, countryCode := range countryCodes {
+		info, ok := countryInfoTable[countryCode]
+		if !assert.True(t, ok, countryCode) {
+			continue
+		}
+		for _, languageCode := range info.languages {
+			language, err := GetLanguageForCode(languageCode)
+			if assert.NoError(t, err, languageCode) {
+				assert.Equal(t, languageCode, language.Code())
+			}
+		}
+	}
+	for countryCode, info := range countryInfoTable {
+		if info.currency == "" {
+			continue
+		}
+		assert.Len(t, info.currency, 3, countryCode)
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"strings"
+
+	"github.com/linuxdeepin/go-lib/locale"
+)
+
+// GetLocaleDisplayName returns the name of the locale translated into lang
+// with the iso-codes translations, such as "German (Germany)" for
+// "de_DE.UTF-8" and "德语 (德国)" if lang is "zh_CN". The codeset is not
+// shown, the modifier is shown as is, such as "Catalan (Spain, valencia)".
+// An empty lang means English.
+func GetLocaleDisplayName(localeName, lang string) (string, error) {
+	cs := locale.ExplodeLocale(localeName)
+	language, err := GetLanguageForCode(cs.Language)
+	if err != nil {
+		return "", err
+	}
+	name := language.LocalizedName(lang)
+
+	var details []string
+	if cs.Territory != "" {
+		country, ok := getCountryForCode(cs.Territory)
+		if ok {
+			details = append(details, country.LocalizedName(lang))
+		} else {
+			details = append(details, cs.Territory)
+		}
+	}
+	if cs.Modifier != "" {
+		details = append(details, cs.Modifier)
+	}
+	if len(details) > 0 {
+		name += " (" + strings.Join(details, ", ") + ")"
+	}
+	return name, nil
+}
+
+// GetLocaleNativeName returns the name of the locale in its own language,
+// such as "Deutsch (Deutschland)" for "de_DE.UTF-8".
+func GetLocaleNativeName(localeName string) (string, error) {
+	return GetLocaleDisplayName(localeName, localeName)
+}
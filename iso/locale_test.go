@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLocaleDisplayName(t *testing.T) {
+	testData := []struct {
+		locale, lang, name string
+	}{
+		{"de_DE.UTF-8", "", "German (Germany)"},
+		{"ca_ES.UTF-8@valencia", "", "Catalan (Spain, valencia)"},
+		{"eo", "", "Esperanto"},
+		{"ast_ES.UTF-8", "C", "Asturian (Spain)"},
+		{"de_DE.UTF-8", "zh_CN.UTF-8", "德语 (德国)"},
+	}
+	for _, d := range testData {
+		name, err := GetLocaleDisplayName(d.locale, d.lang)
+		require.NoError(t, err)
+		assert.Equal(t, d.name, name)
+	}
+
+	name, err := GetLocaleNativeName("zh_CN.UTF-8")
+	require.NoError(t, err)
+	assert.Equal(t, "中文 (中国)", name)
+
+	_, err = GetLocaleDisplayName("xx_XX", "")
+	assert.Equal(t, errLanguageCodeInvalid, err)
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iso
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/linuxdeepin/go-lib/gettext"
+)
+
+const (
+	iso3166_2JSONFile = "/usr/share/iso-codes/json/iso_3166-2.json"
+	iso3166_2Domain   = "iso_3166-2"
+)
+
+// SubdivisionDatabase is the ISO 3166-2 database of iso-codes.
+type SubdivisionDatabase struct {
+	Subdivisions []Subdivision `json:"3166-2"`
+}
+
+// Subdivision is a subdivision of a country, such as a province.
+type Subdivision struct {
+	// Code is the country code and the subdivision code, such as "CN-BJ".
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Type is the kind of the subdivision, such as "Province".
+	Type string `json:"type"`
+	// Parent is the subdivision code of the parent without the country
+	// code, it is empty for a top level subdivision.
+	Parent string `json:"parent"`
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 code of the country.
+func (s *Subdivision) CountryCode() string {
+	idx := strings.IndexByte(s.Code, '-')
+	if idx == -1 {
+		return s.Code
+	}
+	return s.Code[:idx]
+}
+
+// ParentCode returns the full code of the parent, such as "GB-ENG", it is
+// empty for a top level subdivision.
+func (s *Subdivision) ParentCode() string {
+	if s.Parent == "" {
+		return ""
+	}
+	return s.CountryCode() + "-" + s.Parent
+}
+
+// LocalizedName returns the name translated into lang, such as "zh_CN",
+// with the iso-codes translations. An empty lang means the English name.
+func (s *Subdivision) LocalizedName(lang string) string {
+	return gettext.NewTranslator(iso3166_2Domain, lang).Tr(s.Name)
+}
+
+var subdivisionDatabase *SubdivisionDatabase
+var subdivisionDatabaseLock sync.Mutex
+
+var errSubdivisionCodeInvalid = fmt.Errorf("invalid subdivision code")
+
+// GetSubdivisionDatabase return subdivision database that unmarshaled from
+// ISO 3166-2 json file.
+func GetSubdivisionDatabase() (*SubdivisionDatabase, error) {
+	subdivisionDatabaseLock.Lock()
+	defer subdivisionDatabaseLock.Unlock()
+
+	if subdivisionDatabase != nil {
+		return subdivisionDatabase, nil
+	}
+
+	content, err := ioutil.ReadFile(iso3166_2JSONFile)
+	if err != nil {
+		return nil, err
+	}
+	database := &SubdivisionDatabase{}
+	err = json.Unmarshal(content, database)
+	if err != nil {
+		return nil, err
+	}
+	subdivisionDatabase = database
+	return subdivisionDatabase, nil
+}
+
+// GetSubdivisionForCode return the subdivision of the code, e.g. "CN-BJ".
+func GetSubdivisionForCode(code string) (*Subdivision, error) {
+	database, err := GetSubdivisionDatabase()
+	if err != nil {
+		return nil, err
+	}
+	for i := range database.Subdivisions {
+		if strings.EqualFold(code, database.Subdivisions[i].Code) {
+			return &database.Subdivisions[i], nil
+		}
+	}
+	return nil, errSubdivisionCodeInvalid
+}
+
+// GetSubdivisionNameForCode return subdivision name that corresponding to
+// the subdivision code.
+func GetSubdivisionNameForCode(code string) (name string, err error) {
+	entry, err := GetSubdivisionForCode(code)
+	if err != nil {
+		return
+	}
+	return gettext.DGettext(iso3166_2Domain, entry.Name), nil
+}
+
+// GetSubdivisionsForCountry return all subdivisions of the country, in
+// the order of the database.
+func GetSubdivisionsForCountry(countryCode string) (list []Subdivision, err error) {
+	database, err := GetSubdivisionDatabase()
+	if err != nil {
+		return
+	}
+	for _, entry := range database.Subdivisions {
+		if strings.EqualFold(countryCode, entry.CountryCode()) {
+			list = append(list, entry)
+		}
+	}
+	return
+}
@@ -0,0 +1,447 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// SupportedLocalesFile lists the locales which can be generated.
+	SupportedLocalesFile = "/usr/share/i18n/SUPPORTED"
+	// LocaleGenFile lists the locales generated by locale-gen.
+	LocaleGenFile = "/etc/locale.gen"
+	// LocaleArchiveFile holds the compiled locales.
+	LocaleArchiveFile = "/usr/lib/locale/locale-archive"
+	// CompiledLocaleDir holds the compiled locales not in the archive.
+	CompiledLocaleDir = "/usr/lib/locale"
+)
+
+var (
+	localeNameRegexp    = regexp.MustCompile(`^[a-zA-Z]{2,3}(_[a-zA-Z0-9]+)?(\.[a-zA-Z0-9_-]+)?(@[a-zA-Z0-9_-]+)?$`)
+	localeCharsetRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// LocaleEntry is a locale and its charset, such as "zh_CN.UTF-8 UTF-8".
+type LocaleEntry struct {
+	Name    string
+	Charset string
+}
+
+// InvalidLocaleEntryError is returned when a locale or a charset is not
+// valid in the locale lists.
+type InvalidLocaleEntryError struct {
+	Name    string
+	Charset string
+}
+
+func (err InvalidLocaleEntryError) Error() string {
+	return fmt.Sprintf("invalid locale entry %q %q", err.Name, err.Charset)
+}
+
+func isValidLocaleEntry(name, charset string) bool {
+	return localeNameRegexp.MatchString(name) && localeCharsetRegexp.MatchString(charset)
+}
+
+// parseLocaleEntry parses a line of SUPPORTED or locale.gen, commented
+// reports whether the entry is commented out. The comment lines which are
+// not entries are not ok.
+func parseLocaleEntry(line string) (entry LocaleEntry, commented, ok bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "#") {
+		commented = true
+		line = strings.TrimLeft(line, "#")
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 2 || !isValidLocaleEntry(fields[0], fields[1]) {
+		return LocaleEntry{}, false, false
+	}
+	return LocaleEntry{Name: fields[0], Charset: fields[1]}, commented, true
+}
+
+// ReadSupportedLocales reads the locale list in the format of
+// /usr/share/i18n/SUPPORTED.
+func ReadSupportedLocales(file string) ([]LocaleEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []LocaleEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry, commented, ok := parseLocaleEntry(scanner.Text())
+		if ok && !commented {
+			result = append(result, entry)
+		}
+	}
+	return result, scanner.Err()
+}
+
+// GetSupportedLocales returns the locales which can be generated on the
+// system.
+func GetSupportedLocales() ([]LocaleEntry, error) {
+	return ReadSupportedLocales(SupportedLocalesFile)
+}
+
+// normalizeCodeset normalizes the codeset as glibc does, "UTF-8" becomes
+// "utf8" and "8859-1" becomes "iso88591".
+func normalizeCodeset(codeset string) string {
+	var sb strings.Builder
+	onlyDigits := true
+	for _, r := range codeset {
+		if unicode.IsLetter(r) {
+			onlyDigits = false
+			sb.WriteRune(unicode.ToLower(r))
+		} else if unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	if onlyDigits {
+		return "iso" + sb.String()
+	}
+	return sb.String()
+}
+
+// NormalizeLocale returns the locale with the normalized codeset, such as
+// "zh_CN.utf8" for "zh_CN.UTF-8". The compiled locales are named so.
+func NormalizeLocale(locale string) string {
+	cs := ExplodeLocale(locale)
+	if cs.Codeset == "" {
+		return locale
+	}
+	name := cs.Language
+	if cs.Territory != "" {
+		name += "_" + cs.Territory
+	}
+	name += "." + normalizeCodeset(cs.Codeset)
+	if cs.Modifier != "" {
+		name += "@" + cs.Modifier
+	}
+	return name
+}
+
+var ErrInvalidLocaleArchive = errors.New("invalid locale archive")
+
+const localeArchiveMagic = 0xde020109
+
+// ReadLocaleArchive returns the names of the locales in the archive created
+// by localedef, such as /usr/lib/locale/locale-archive. The names have the
+// normalized codesets, such as "zh_CN.utf8".
+func ReadLocaleArchive(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// struct locarhead of glibc
+	var header [8]uint32
+	var order binary.ByteOrder = binary.LittleEndian
+	var buf [4]byte
+	if _, err = io.ReadFull(f, buf[:]); err != nil {
+		return nil, ErrInvalidLocaleArchive
+	}
+	switch {
+	case binary.LittleEndian.Uint32(buf[:]) == localeArchiveMagic:
+	case binary.BigEndian.Uint32(buf[:]) == localeArchiveMagic:
+		order = binary.BigEndian
+	default:
+		return nil, ErrInvalidLocaleArchive
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err = binary.Read(f, order, &header); err != nil {
+		return nil, ErrInvalidLocaleArchive
+	}
+	namehashOffset := int64(header[2])
+	namehashSize := int64(header[4])
+	stringOffset := int64(header[5])
+	stringUsed := int64(header[6])
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if namehashOffset+namehashSize*12 > fi.Size() || stringOffset+stringUsed > fi.Size() {
+		return nil, ErrInvalidLocaleArchive
+	}
+	namehash := make([]byte, namehashSize*12)
+	if _, err = f.ReadAt(namehash, namehashOffset); err != nil {
+		return nil, err
+	}
+	strs := make([]byte, stringUsed)
+	if _, err = f.ReadAt(strs, stringOffset); err != nil {
+		return nil, err
+	}
+
+	var result []string
+	// struct namehashent {hashval, name_offset, locrec_offset}
+	for i := int64(0); i < namehashSize; i++ {
+		ent := namehash[i*12:]
+		nameOffset := int64(order.Uint32(ent[4:]))
+		locrecOffset := order.Uint32(ent[8:])
+		if nameOffset == 0 || locrecOffset == 0 {
+			continue
+		}
+		start := nameOffset - stringOffset
+		if start < 0 || start >= stringUsed {
+			return nil, ErrInvalidLocaleArchive
+		}
+		name := strs[start:]
+		if idx := bytes.IndexByte(name, 0); idx != -1 {
+			name = name[:idx]
+		}
+		result = append(result, string(name))
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// ReadCompiledLocaleDir returns the names of the locales compiled into the
+// sub directories of dir, such as /usr/lib/locale/C.utf8.
+func ReadCompiledLocaleDir(dir string) ([]string, error) {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, fi := range fileInfos {
+		if !fi.IsDir() {
+			continue
+		}
+		_, err := os.Stat(filepath.Join(dir, fi.Name(), "LC_CTYPE"))
+		if err == nil {
+			result = append(result, fi.Name())
+		}
+	}
+	return result, nil
+}
+
+func getCompiledLocales(archiveFile, dir string) ([]string, error) {
+	var result []string
+	for _, read := range []func() ([]string, error){
+		func() ([]string, error) { return ReadLocaleArchive(archiveFile) },
+		func() ([]string, error) { return ReadCompiledLocaleDir(dir) },
+	} {
+		names, err := read()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		result = append(result, names...)
+	}
+	sort.Strings(result)
+	return uniqueStrings(result), nil
+}
+
+func uniqueStrings(sorted []string) []string {
+	var result []string
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// GetCompiledLocales returns the names of the locales which can be used on
+// the system, they are in LocaleArchiveFile or CompiledLocaleDir. The C and
+// POSIX locales are not included.
+func GetCompiledLocales() ([]string, error) {
+	return getCompiledLocales(LocaleArchiveFile, CompiledLocaleDir)
+}
+
+func isLocaleIn(locale string, names []string) bool {
+	if locale == "C" || locale == "POSIX" {
+		return true
+	}
+	locale = NormalizeLocale(locale)
+	for _, name := range names {
+		if NormalizeLocale(name) == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocaleCompiled reports whether the locale, such as "zh_CN.UTF-8", can
+// be used on the system.
+func IsLocaleCompiled(locale string) bool {
+	names, err := GetCompiledLocales()
+	if err != nil {
+		return false
+	}
+	return isLocaleIn(locale, names)
+}
+
+// LocaleGenEntry is a locale entry of locale.gen.
+type LocaleGenEntry struct {
+	Name    string
+	Charset string
+	// Enabled reports whether the entry is not commented out.
+	Enabled bool
+}
+
+// LocaleGen is the content of locale.gen, the locales are enabled or
+// disabled by uncommenting or commenting out their lines, the other lines
+// are kept as is.
+type LocaleGen struct {
+	file  string
+	lines []string
+}
+
+// LoadLocaleGen loads the locale.gen file, such as /etc/locale.gen.
+func LoadLocaleGen(file string) (*LocaleGen, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	g := &LocaleGen{file: file}
+	content := strings.TrimSuffix(string(data), "\n")
+	if content != "" {
+		g.lines = strings.Split(content, "\n")
+	}
+	return g, nil
+}
+
+// Entries returns the locale entries, including the commented ones.
+func (g *LocaleGen) Entries() []LocaleGenEntry {
+	var result []LocaleGenEntry
+	for _, line := range g.lines {
+		entry, commented, ok := parseLocaleEntry(line)
+		if ok {
+			result = append(result, LocaleGenEntry{
+				Name:    entry.Name,
+				Charset: entry.Charset,
+				Enabled: !commented,
+			})
+		}
+	}
+	return result
+}
+
+// EnabledLocales returns the names of the enabled locales.
+func (g *LocaleGen) EnabledLocales() []string {
+	var result []string
+	for _, entry := range g.Entries() {
+		if entry.Enabled {
+			result = append(result, entry.Name)
+		}
+	}
+	return result
+}
+
+// IsEnabled reports whether the locale is enabled, the codesets are
+// compared after normalization.
+func (g *LocaleGen) IsEnabled(locale string) bool {
+	locale = NormalizeLocale(locale)
+	for _, entry := range g.Entries() {
+		if entry.Enabled && NormalizeLocale(entry.Name) == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Enable enables the locale. The commented line of the locale is
+// uncommented if there is one, otherwise a line is appended. An empty
+// charset means the charset of the commented line or the codeset of the
+// locale.
+func (g *LocaleGen) Enable(locale, charset string) error {
+	if g.IsEnabled(locale) {
+		return nil
+	}
+	normLocale := NormalizeLocale(locale)
+	for i, line := range g.lines {
+		entry, commented, ok := parseLocaleEntry(line)
+		if !ok || !commented || NormalizeLocale(entry.Name) != normLocale {
+			continue
+		}
+		if charset != "" && normalizeCodeset(charset) != normalizeCodeset(entry.Charset) {
+			continue
+		}
+		g.lines[i] = entry.Name + " " + entry.Charset
+		return nil
+	}
+
+	if charset == "" {
+		charset = ExplodeLocale(locale).Codeset
+	}
+	if !isValidLocaleEntry(locale, charset) {
+		return InvalidLocaleEntryError{Name: locale, Charset: charset}
+	}
+	g.lines = append(g.lines, locale+" "+charset)
+	return nil
+}
+
+// Disable comments out the lines of the locale, it reports whether any
+// line is changed.
+func (g *LocaleGen) Disable(locale string) bool {
+	locale = NormalizeLocale(locale)
+	changed := false
+	for i, line := range g.lines {
+		entry, commented, ok := parseLocaleEntry(line)
+		if ok && !commented && NormalizeLocale(entry.Name) == locale {
+			g.lines[i] = "# " + strings.TrimSpace(line)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Bytes returns the content of the file.
+func (g *LocaleGen) Bytes() []byte {
+	if len(g.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(g.lines, "\n") + "\n")
+}
+
+// Save writes the content to the file it is loaded from. The content is
+// written to a temporary file which then replaces the file, so the file
+// is never left partly written. The file mode is kept.
+func (g *LocaleGen) Save() error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(g.file); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	f, err := ioutil.TempFile(filepath.Dir(g.file), "."+filepath.Base(g.file)+".")
+	if err != nil {
+		return err
+	}
+	tmpFile := f.Name()
+	_, err = f.Write(g.Bytes())
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile, g.file)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile)
+	}
+	return err
+}
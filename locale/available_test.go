@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSupportedLocales(t *testing.T) {
+	entries, err := ReadSupportedLocales("testdata/SUPPORTED")
+	require.NoError(t, err)
+	assert.Len(t, entries, 14)
+	assert.Equal(t, LocaleEntry{Name: "aa_DJ.UTF-8", Charset: "UTF-8"}, entries[0])
+	assert.Contains(t, entries, LocaleEntry{Name: "ca_ES.UTF-8@valencia", Charset: "UTF-8"})
+	assert.Contains(t, entries, LocaleEntry{Name: "eo", Charset: "UTF-8"})
+
+	_, err = ReadSupportedLocales("testdata/none")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "zh_CN.utf8", NormalizeLocale("zh_CN.UTF-8"))
+	assert.Equal(t, "de_DE.iso885915@euro", NormalizeLocale("de_DE.ISO-8859-15@euro"))
+	assert.Equal(t, "ja_JP.iso8859", NormalizeLocale("ja_JP.8859"))
+	assert.Equal(t, "en_US", NormalizeLocale("en_US"))
+}
+
+// writeLocaleArchive writes an archive with the layout of glibc, only the
+// header, the name hash table and the strings are filled.
+func writeLocaleArchive(t *testing.T, file string, order binary.ByteOrder, names []string) {
+	const headerSize = 4 * 14
+	namehashSize := len(names) + 3
+	namehashOffset := headerSize
+	stringOffset := namehashOffset + namehashSize*12
+
+	var strs []byte
+	namehash := make([]uint32, namehashSize*3)
+	for i, name := range names {
+		// leave an empty slot before each entry
+		slot := i + 1
+		namehash[slot*3] = uint32(i + 100)
+		namehash[slot*3+1] = uint32(stringOffset + len(strs))
+		namehash[slot*3+2] = 4096
+		strs = append(strs, name...)
+		strs = append(strs, 0)
+	}
+	header := make([]uint32, 14)
+	header[0] = localeArchiveMagic
+	header[2] = uint32(namehashOffset)
+	header[3] = uint32(len(names))
+	header[4] = uint32(namehashSize)
+	header[5] = uint32(stringOffset)
+	header[6] = uint32(len(strs))
+	header[7] = uint32(len(strs))
+
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, binary.Write(f, order, header))
+	require.NoError(t, binary.Write(f, order, namehash))
+	_, err = f.Write(strs)
+	require.NoError(t, err)
+}
+
+func TestReadLocaleArchive(t *testing.T) {
+	dir := t.TempDir()
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		file := filepath.Join(dir, "locale-archive")
+		writeLocaleArchive(t, file, order, []string{"zh_CN.utf8", "en_US.utf8", "de_DE@euro"})
+		names, err := ReadLocaleArchive(file)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"de_DE@euro", "en_US.utf8", "zh_CN.utf8"}, names)
+	}
+
+	file := filepath.Join(dir, "invalid")
+	require.NoError(t, ioutil.WriteFile(file, []byte("invalid locale archive"), 0644))
+	_, err := ReadLocaleArchive(file)
+	assert.Equal(t, ErrInvalidLocaleArchive, err)
+}
+
+func TestGetCompiledLocales(t *testing.T) {
+	dir := t.TempDir()
+	writeLocaleArchive(t, filepath.Join(dir, "locale-archive"), binary.LittleEndian,
+		[]string{"zh_CN.utf8", "en_US.utf8"})
+	for _, name := range []string{"C.utf8", "zh_CN.utf8", "broken"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0755))
+		if name != "broken" {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name, "LC_CTYPE"), nil, 0644))
+		}
+	}
+
+	names, err := getCompiledLocales(filepath.Join(dir, "locale-archive"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C.utf8", "en_US.utf8", "zh_CN.utf8"}, names)
+	assert.True(t, isLocaleIn("zh_CN.UTF-8", names))
+	assert.True(t, isLocaleIn("C", names))
+	assert.False(t, isLocaleIn("zh_CN", names))
+	assert.False(t, isLocaleIn("de_DE.UTF-8", names))
+
+	// the archive is optional
+	names, err = getCompiledLocales(filepath.Join(dir, "none"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C.utf8", "zh_CN.utf8"}, names)
+}
+
+func TestLocaleGen(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "locale.gen")
+	data, err := ioutil.ReadFile("testdata/locale.gen")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(file, data, 0600))
+
+	g, err := LoadLocaleGen(file)
+	require.NoError(t, err)
+	assert.Len(t, g.Entries(), 9)
+	assert.Equal(t, []string{"en_US.UTF-8", "zh_CN.UTF-8"}, g.EnabledLocales())
+	assert.True(t, g.IsEnabled("zh_CN.utf8"))
+	assert.False(t, g.IsEnabled("zh_CN"))
+
+	// uncomment the line
+	require.NoError(t, g.Enable("de_DE.UTF-8", ""))
+	require.NoError(t, g.Enable("zh_CN.GB18030", "GB18030"))
+	// append a line
+	require.NoError(t, g.Enable("fr_FR.UTF-8", ""))
+	require.NoError(t, g.Enable("ja_JP.EUC-JP", "EUC-JP"))
+	// enabled already
+	require.NoError(t, g.Enable("en_US.UTF-8", "UTF-8"))
+
+	assert.Equal(t, InvalidLocaleEntryError{Name: "fr_FR", Charset: ""}, g.Enable("fr_FR", ""))
+	assert.Error(t, g.Enable("fr_FR.UTF-8\nzz", "UTF-8"))
+	assert.Error(t, g.Enable("fr_FR", "ISO 8859-1"))
+
+	assert.True(t, g.Disable("en_US.UTF-8"))
+	assert.False(t, g.Disable("en_US.UTF-8"))
+	assert.Equal(t, []string{"de_DE.UTF-8", "zh_CN.GB18030", "zh_CN.UTF-8", "fr_FR.UTF-8", "ja_JP.EUC-JP"},
+		g.EnabledLocales())
+
+	require.NoError(t, g.Save())
+	content, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `# This file lists locales that you wish to have built. You can find a list
+# of valid supported locales at /usr/share/i18n/SUPPORTED, and you can add
+# user defined locales to /usr/local/share/i18n/SUPPORTED. If you change
+# this file, you need to rerun locale-gen.
+
+
+# aa_DJ ISO-8859-1
+# aa_DJ.UTF-8 UTF-8
+# de_DE ISO-8859-1
+de_DE.UTF-8 UTF-8
+# de_DE@euro ISO-8859-15
+# en_US.UTF-8 UTF-8
+# zh_CN GB2312
+zh_CN.GB18030 GB18030
+zh_CN.UTF-8 UTF-8
+fr_FR.UTF-8 UTF-8
+ja_JP.EUC-JP EUC-JP
+`, string(content))
+
+	fi, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	// no temporary file is left
+	files, err := ioutil.ReadDir(filepath.Dir(file))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
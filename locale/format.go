@@ -0,0 +1,611 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatter formats numbers, sizes, dates and relative times with the
+// conventions of a locale. It does not depend on the process-global locale
+// and is safe for concurrent use.
+type Formatter struct {
+	data *Data
+	// the variants of the locale name used to look up the per-language
+	// tables, from the most specific one
+	variants []string
+}
+
+// NewFormatter returns a Formatter for the locale, such as "zh_CN.UTF-8",
+// the data are loaded by LoadData.
+func NewFormatter(locale string) *Formatter {
+	return NewFormatterFromData(LoadData(locale))
+}
+
+// NewFormatterFromData returns a Formatter which uses d.
+func NewFormatterFromData(d *Data) *Formatter {
+	return &Formatter{
+		data:     d,
+		variants: GetLocaleVariants(d.Name),
+	}
+}
+
+// Data returns the formatting data of f.
+func (f *Formatter) Data() *Data {
+	return f.data
+}
+
+// lookupString returns the value of the most specific variant in table.
+func (f *Formatter) lookupString(table map[string]string) (string, bool) {
+	for _, variant := range f.variants {
+		if v, ok := table[variant]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// groupDigits inserts sep into the digits according to grouping, as the
+// grouping keyword of LC_NUMERIC.
+func groupDigits(digits, sep string, grouping []int) string {
+	if sep == "" || len(grouping) == 0 {
+		return digits
+	}
+	var groups []string
+	i := 0
+	for len(digits) > 0 {
+		size := grouping[i]
+		if i < len(grouping)-1 {
+			i++
+		}
+		if size <= 0 || size >= len(digits) {
+			break
+		}
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+	groups = append(groups, digits)
+	for l, r := 0, len(groups)-1; l < r; l, r = l+1, r-1 {
+		groups[l], groups[r] = groups[r], groups[l]
+	}
+	return strings.Join(groups, sep)
+}
+
+func formatNumber(v float64, precision int, decimalPoint, thousandsSep string, grouping []int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', precision, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', precision, 64)
+	intPart, fracPart := s, ""
+	if idx := strings.IndexByte(s, '.'); idx != -1 {
+		intPart, fracPart = s[:idx], s[idx+1:]
+	}
+	if decimalPoint == "" {
+		decimalPoint = "."
+	}
+	result := groupDigits(intPart, thousandsSep, grouping)
+	if fracPart != "" {
+		result += decimalPoint + fracPart
+	}
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		result = "-" + result
+	}
+	return result
+}
+
+// FormatInt formats n with the digit grouping of the locale, such as
+// "1,234,567" for en_US and "1.234.567" for de_DE.
+func (f *Formatter) FormatInt(n int64) string {
+	ni := &f.data.Numeric
+	s := strconv.FormatInt(n, 10)
+	if n < 0 {
+		return "-" + groupDigits(s[1:], ni.ThousandsSep, ni.Grouping)
+	}
+	return groupDigits(s, ni.ThousandsSep, ni.Grouping)
+}
+
+// FormatFloat formats v with precision digits after the decimal point, -1
+// means the smallest number of digits necessary, as strconv.FormatFloat.
+func (f *Formatter) FormatFloat(v float64, precision int) string {
+	ni := &f.data.Numeric
+	return formatNumber(v, precision, ni.DecimalPoint, ni.ThousandsSep, ni.Grouping)
+}
+
+// the separators between the number and the percent sign, by CLDR
+var percentSeparators = map[string]string{
+	"de": "\u00a0",
+	"es": "\u00a0",
+	"fr": "\u202f",
+	"ru": "\u00a0",
+}
+
+// FormatPercent formats ratio as a percentage, 0.5 is "50%" for en_US.
+func (f *Formatter) FormatPercent(ratio float64, precision int) string {
+	sep, _ := f.lookupString(percentSeparators)
+	return f.FormatFloat(ratio*100, precision) + sep + "%"
+}
+
+// FormatCurrency formats the amount of money in the local currency with the
+// LC_MONETARY conventions, such as "$1,234.50" for en_US and "1.234,50 €"
+// for de_DE. The C locale has no currency symbol.
+func (f *Formatter) FormatCurrency(v float64) string {
+	mi := &f.data.Monetary
+	ni := &f.data.Numeric
+	fracDigits := mi.FracDigits
+	if fracDigits < 0 {
+		fracDigits = 2
+	}
+	decimalPoint := mi.MonDecimalPoint
+	if decimalPoint == "" {
+		decimalPoint = ni.DecimalPoint
+	}
+	s := formatNumber(math.Abs(v), fracDigits, decimalPoint, mi.MonThousandsSep, mi.MonGrouping)
+	if mi.CurrencySymbol != "" {
+		sep := ""
+		if mi.SepBySpace {
+			sep = " "
+		}
+		if mi.CsPrecedes {
+			s = mi.CurrencySymbol + sep + s
+		} else {
+			s = s + sep + mi.CurrencySymbol
+		}
+	}
+	if v < 0 && math.Round(math.Abs(v)*math.Pow10(fracDigits)) != 0 {
+		sign := mi.NegativeSign
+		if sign == "" {
+			sign = "-"
+		}
+		s = sign + s
+	}
+	return s
+}
+
+var (
+	siSizeUnits  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	iecSizeUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+func (f *Formatter) formatSize(size uint64, base float64, units []string) string {
+	if float64(size) < base {
+		return f.FormatInt(int64(size)) + " " + units[0]
+	}
+	v := float64(size)
+	i := 0
+	for v >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	return f.FormatFloat(v, 1) + " " + units[i]
+}
+
+// FormatSize formats the byte size with the SI units as GLib does, such as
+// "1.5 MB" for 1500000 bytes.
+func (f *Formatter) FormatSize(size uint64) string {
+	return f.formatSize(size, 1000, siSizeUnits)
+}
+
+// FormatSizeIEC formats the byte size with the IEC units, such as
+// "1.5 MiB" for 1572864 bytes.
+func (f *Formatter) FormatSizeIEC(size uint64) string {
+	return f.formatSize(size, 1024, iecSizeUnits)
+}
+
+type timeUnit int
+
+const (
+	unitMinute timeUnit = iota
+	unitHour
+	unitDay
+	unitMonth
+	unitYear
+	numTimeUnits
+)
+
+// relativeTimeNames are the names of a language used to format the
+// relative times, by CLDR.
+type relativeTimeNames struct {
+	now    string
+	past   string // the pattern of the past time, %s is the duration
+	future string
+	// the plural forms of the units, %d is the count
+	units  [numTimeUnits][]string
+	plural func(n int64) int
+}
+
+func oneOtherPlural(n int64) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+func frenchPlural(n int64) int {
+	if n <= 1 {
+		return 0
+	}
+	return 1
+}
+
+func singlePlural(n int64) int {
+	return 0
+}
+
+func russianPlural(n int64) int {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+		return 1
+	default:
+		return 2
+	}
+}
+
+var relativeTimeNamesMap = map[string]*relativeTimeNames{
+	"en": {
+		now:    "now",
+		past:   "%s ago",
+		future: "in %s",
+		units: [numTimeUnits][]string{
+			{"%d minute", "%d minutes"},
+			{"%d hour", "%d hours"},
+			{"%d day", "%d days"},
+			{"%d month", "%d months"},
+			{"%d year", "%d years"},
+		},
+		plural: oneOtherPlural,
+	},
+	"zh": {
+		now:    "现在",
+		past:   "%s前",
+		future: "%s后",
+		units: [numTimeUnits][]string{
+			{"%d分钟"}, {"%d小时"}, {"%d天"}, {"%d个月"}, {"%d年"},
+		},
+		plural: singlePlural,
+	},
+	"zh_TW": {
+		now:    "現在",
+		past:   "%s前",
+		future: "%s後",
+		units: [numTimeUnits][]string{
+			{"%d分鐘"}, {"%d小時"}, {"%d天"}, {"%d個月"}, {"%d年"},
+		},
+		plural: singlePlural,
+	},
+	"ja": {
+		now:    "今",
+		past:   "%s前",
+		future: "%s後",
+		units: [numTimeUnits][]string{
+			{"%d分"}, {"%d時間"}, {"%d日"}, {"%dか月"}, {"%d年"},
+		},
+		plural: singlePlural,
+	},
+	"de": {
+		now:    "jetzt",
+		past:   "vor %s",
+		future: "in %s",
+		// in the dative case after vor and in
+		units: [numTimeUnits][]string{
+			{"%d Minute", "%d Minuten"},
+			{"%d Stunde", "%d Stunden"},
+			{"%d Tag", "%d Tagen"},
+			{"%d Monat", "%d Monaten"},
+			{"%d Jahr", "%d Jahren"},
+		},
+		plural: oneOtherPlural,
+	},
+	"fr": {
+		now:    "maintenant",
+		past:   "il y a %s",
+		future: "dans %s",
+		units: [numTimeUnits][]string{
+			{"%d minute", "%d minutes"},
+			{"%d heure", "%d heures"},
+			{"%d jour", "%d jours"},
+			{"%d mois", "%d mois"},
+			{"%d an", "%d ans"},
+		},
+		plural: frenchPlural,
+	},
+	"es": {
+		now:    "ahora",
+		past:   "hace %s",
+		future: "dentro de %s",
+		units: [numTimeUnits][]string{
+			{"%d minuto", "%d minutos"},
+			{"%d hora", "%d horas"},
+			{"%d día", "%d días"},
+			{"%d mes", "%d meses"},
+			{"%d año", "%d años"},
+		},
+		plural: oneOtherPlural,
+	},
+	"ru": {
+		now:    "сейчас",
+		past:   "%s назад",
+		future: "через %s",
+		// in the accusative case after назад and через
+		units: [numTimeUnits][]string{
+			{"%d минуту", "%d минуты", "%d минут"},
+			{"%d час", "%d часа", "%d часов"},
+			{"%d день", "%d дня", "%d дней"},
+			{"%d месяц", "%d месяца", "%d месяцев"},
+			{"%d год", "%d года", "%d лет"},
+		},
+		plural: russianPlural,
+	},
+}
+
+func (f *Formatter) relativeTimeNames() *relativeTimeNames {
+	for _, variant := range f.variants {
+		if names, ok := relativeTimeNamesMap[variant]; ok {
+			return names
+		}
+	}
+	return relativeTimeNamesMap["en"]
+}
+
+// FormatRelativeTime formats t relative to now, such as "3 minutes ago" or
+// "in 2 days". The differences less than a minute are formatted as "now",
+// the count of the largest unit is rounded down.
+func (f *Formatter) FormatRelativeTime(t, now time.Time) string {
+	return f.FormatRelativeDuration(t.Sub(now))
+}
+
+// FormatRelativeDuration formats d as a relative time, a negative d is in
+// the past.
+func (f *Formatter) FormatRelativeDuration(d time.Duration) string {
+	names := f.relativeTimeNames()
+	past := d < 0
+	if past {
+		d = -d
+	}
+	const day = 24 * time.Hour
+	var unit timeUnit
+	var n int64
+	switch {
+	case d < time.Minute:
+		return names.now
+	case d < time.Hour:
+		unit, n = unitMinute, int64(d/time.Minute)
+	case d < day:
+		unit, n = unitHour, int64(d/time.Hour)
+	case d < 30*day:
+		unit, n = unitDay, int64(d/day)
+	case d < 365*day:
+		unit, n = unitMonth, int64(d/(30*day))
+	default:
+		unit, n = unitYear, int64(d/(365*day))
+	}
+
+	forms := names.units[unit]
+	idx := names.plural(n)
+	if idx >= len(forms) {
+		idx = len(forms) - 1
+	}
+	s := strings.Replace(forms[idx], "%d", strconv.FormatInt(n, 10), 1)
+	pattern := names.future
+	if past {
+		pattern = names.past
+	}
+	return strings.Replace(pattern, "%s", s, 1)
+}
+
+// DateStyle is the length of a formatted date.
+type DateStyle int
+
+const (
+	// DateShort is numeric, such as "1/2/06".
+	DateShort DateStyle = iota
+	// DateMedium has the abbreviated month name, such as "Jan 2, 2006".
+	DateMedium
+	// DateLong has the full month name, such as "January 2, 2006".
+	DateLong
+	// DateFull has the weekday, such as "Monday, January 2, 2006".
+	DateFull
+)
+
+// the date patterns of the languages in the syntax of Strftime, by CLDR
+var datePatterns = map[string][4]string{
+	"en":    {"%-m/%-d/%y", "%b %-d, %Y", "%B %-d, %Y", "%A, %B %-d, %Y"},
+	"en_GB": {"%d/%m/%Y", "%-d %b %Y", "%-d %B %Y", "%A, %-d %B %Y"},
+	"zh":    {"%Y/%-m/%-d", "%Y年%-m月%-d日", "%Y年%-m月%-d日", "%Y年%-m月%-d日%A"},
+	"zh_TW": {"%Y/%-m/%-d", "%Y年%-m月%-d日", "%Y年%-m月%-d日", "%Y年%-m月%-d日 %A"},
+	"ja":    {"%Y/%m/%d", "%Y/%m/%d", "%Y年%-m月%-d日", "%Y年%-m月%-d日%A"},
+	"de":    {"%d.%m.%y", "%d.%m.%Y", "%-d. %B %Y", "%A, %-d. %B %Y"},
+	"fr":    {"%d/%m/%Y", "%-d %b %Y", "%-d %B %Y", "%A %-d %B %Y"},
+	"es":    {"%-d/%-m/%y", "%-d %b %Y", "%-d de %B de %Y", "%A, %-d de %B de %Y"},
+	"ru":    {"%d.%m.%Y", "%-d %b %Y г.", "%-d %B %Y г.", "%A, %-d %B %Y г."},
+}
+
+// FormatDate formats the date of t in the style. The month and weekday
+// names come from the LC_TIME data, the date format of LC_TIME is used
+// for the languages without patterns.
+func (f *Formatter) FormatDate(t time.Time, style DateStyle) string {
+	for _, variant := range f.variants {
+		if patterns, ok := datePatterns[variant]; ok && style >= DateShort && style <= DateFull {
+			return f.Strftime(patterns[style], t)
+		}
+	}
+	return f.Strftime("%x", t)
+}
+
+// FormatTime formats the time of t with the time format of LC_TIME.
+func (f *Formatter) FormatTime(t time.Time) string {
+	return f.Strftime("%X", t)
+}
+
+// FormatDateTime formats t with the date and time format of LC_TIME.
+func (f *Formatter) FormatDateTime(t time.Time) string {
+	return f.Strftime("%c", t)
+}
+
+// the maximum depth of the nested formats, such as %c in d_t_fmt
+const maxStrftimeDepth = 4
+
+// Strftime formats t as strftime(3) with the names and formats of the
+// LC_TIME data. The flags "-" (no padding), "_" (pad with spaces) and "0"
+// (pad with zeros) are supported, the E and O modifiers are ignored.
+func (f *Formatter) Strftime(format string, t time.Time) string {
+	var sb strings.Builder
+	f.strftime(&sb, format, t, 0)
+	return sb.String()
+}
+
+func padNumber(n, width int, pad byte) string {
+	s := strconv.Itoa(n)
+	if pad == '-' {
+		return s
+	}
+	for len(s) < width {
+		s = string(pad) + s
+	}
+	return s
+}
+
+func (f *Formatter) strftime(sb *strings.Builder, format string, t time.Time, depth int) {
+	ti := &f.data.Time
+	nested := func(layout, fallback string) {
+		if layout == "" {
+			layout = fallback
+		}
+		if depth < maxStrftimeDepth {
+			f.strftime(sb, layout, t, depth+1)
+		}
+	}
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 >= len(format) {
+			sb.WriteByte(c)
+			continue
+		}
+		start := i
+		i++
+		var flag byte
+		switch format[i] {
+		case '-', '_', '0':
+			flag = format[i]
+			i++
+		}
+		if i < len(format) && (format[i] == 'E' || format[i] == 'O') {
+			i++
+		}
+		if i >= len(format) {
+			sb.WriteString(format[start:])
+			break
+		}
+		num := func(n, width int, pad byte) {
+			if flag == '_' {
+				pad = ' '
+			} else if flag != 0 {
+				pad = flag
+			}
+			sb.WriteString(padNumber(n, width, pad))
+		}
+
+		switch format[i] {
+		case 'a':
+			sb.WriteString(ti.AbDays[t.Weekday()])
+		case 'A':
+			sb.WriteString(ti.Days[t.Weekday()])
+		case 'b', 'h':
+			sb.WriteString(ti.AbMonths[t.Month()-1])
+		case 'B':
+			sb.WriteString(ti.Months[t.Month()-1])
+		case 'c':
+			nested(ti.DateTimeFormat, "%a %b %e %H:%M:%S %Y")
+		case 'C':
+			num(t.Year()/100, 2, '0')
+		case 'd':
+			num(t.Day(), 2, '0')
+		case 'D':
+			nested("%m/%d/%y", "")
+		case 'e':
+			num(t.Day(), 2, ' ')
+		case 'F':
+			nested("%Y-%m-%d", "")
+		case 'G':
+			year, _ := t.ISOWeek()
+			num(year, 0, '0')
+		case 'g':
+			year, _ := t.ISOWeek()
+			num(year%100, 2, '0')
+		case 'H':
+			num(t.Hour(), 2, '0')
+		case 'I':
+			num(hour12, 2, '0')
+		case 'j':
+			num(t.YearDay(), 3, '0')
+		case 'k':
+			num(t.Hour(), 2, ' ')
+		case 'l':
+			num(hour12, 2, ' ')
+		case 'm':
+			num(int(t.Month()), 2, '0')
+		case 'M':
+			num(t.Minute(), 2, '0')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'p':
+			sb.WriteString(ti.AmPm[t.Hour()/12])
+		case 'P':
+			sb.WriteString(strings.ToLower(ti.AmPm[t.Hour()/12]))
+		case 'r':
+			nested(ti.TimeFormatAmPm, "%I:%M:%S %p")
+		case 'R':
+			nested("%H:%M", "")
+		case 's':
+			sb.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'S':
+			num(t.Second(), 2, '0')
+		case 't':
+			sb.WriteByte('\t')
+		case 'T':
+			nested("%H:%M:%S", "")
+		case 'u':
+			wd := int(t.Weekday())
+			if wd == 0 {
+				wd = 7
+			}
+			num(wd, 1, '0')
+		case 'U':
+			num((t.YearDay()+6-int(t.Weekday()))/7, 2, '0')
+		case 'V':
+			_, week := t.ISOWeek()
+			num(week, 2, '0')
+		case 'w':
+			num(int(t.Weekday()), 1, '0')
+		case 'W':
+			num((t.YearDay()+6-(int(t.Weekday())+6)%7)/7, 2, '0')
+		case 'x':
+			nested(ti.DateFormat, "%m/%d/%y")
+		case 'X':
+			nested(ti.TimeFormat, "%H:%M:%S")
+		case 'y':
+			num(t.Year()%100, 2, '0')
+		case 'Y':
+			num(t.Year(), 0, '0')
+		case 'z':
+			sb.WriteString(t.Format("-0700"))
+		case 'Z':
+			sb.WriteString(t.Format("MST"))
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteString(format[start : i+1])
+		}
+	}
+}
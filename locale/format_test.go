@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFormatter(t *testing.T, locale string) *Formatter {
+	useLocaleSourceDir(t, "testdata/i18n/locales")
+	return NewFormatter(locale)
+}
+
+func TestFormatter_FormatNumber(t *testing.T) {
+	f := newTestFormatter(t, "en_US.UTF-8")
+	assert.Equal(t, "0", f.FormatInt(0))
+	assert.Equal(t, "999", f.FormatInt(999))
+	assert.Equal(t, "1,234,567", f.FormatInt(1234567))
+	assert.Equal(t, "-1,234", f.FormatInt(-1234))
+	assert.Equal(t, "1,234.57", f.FormatFloat(1234.567, 2))
+	assert.Equal(t, "0.5", f.FormatFloat(0.5, -1))
+	assert.Equal(t, "0", f.FormatFloat(-0.001, 0))
+	assert.Equal(t, "45%", f.FormatPercent(0.45, 0))
+	assert.Equal(t, "$1,234.50", f.FormatCurrency(1234.5))
+	assert.Equal(t, "-$0.99", f.FormatCurrency(-0.99))
+
+	f = newTestFormatter(t, "de_DE.UTF-8")
+	assert.Equal(t, "1.234.567,89", f.FormatFloat(1234567.891, 2))
+	assert.Equal(t, "45,5\u00a0%", f.FormatPercent(0.455, 1))
+	assert.Equal(t, "1.234,50 €", f.FormatCurrency(1234.5))
+
+	f = newTestFormatter(t, "fr_FR.UTF-8")
+	assert.Equal(t, "1\u202f234,5", f.FormatFloat(1234.5, 1))
+	assert.Equal(t, "50\u202f%", f.FormatPercent(0.5, 0))
+
+	f = newTestFormatter(t, "ja_JP.UTF-8")
+	assert.Equal(t, "￥1,235", f.FormatCurrency(1234.6))
+
+	f = newTestFormatter(t, "C")
+	assert.Equal(t, "1234567", f.FormatInt(1234567))
+	assert.Equal(t, "1234.50", f.FormatCurrency(1234.5))
+}
+
+func TestGroupDigits(t *testing.T) {
+	assert.Equal(t, "12,34,56,789", groupDigits("123456789", ",", []int{3, 2}))
+	assert.Equal(t, "123456,789", groupDigits("123456789", ",", []int{3, -1}))
+	assert.Equal(t, "123", groupDigits("123", ",", []int{3}))
+	assert.Equal(t, "1234", groupDigits("1234", ",", nil))
+}
+
+func TestFormatter_FormatSize(t *testing.T) {
+	f := newTestFormatter(t, "en_US.UTF-8")
+	assert.Equal(t, "0 B", f.FormatSize(0))
+	assert.Equal(t, "999 B", f.FormatSize(999))
+	assert.Equal(t, "1.0 kB", f.FormatSize(1000))
+	assert.Equal(t, "1.5 MB", f.FormatSize(1500000))
+	assert.Equal(t, "2.0 TB", f.FormatSize(2e12))
+	assert.Equal(t, "1,023 B", f.FormatSizeIEC(1023))
+	assert.Equal(t, "1.5 MiB", f.FormatSizeIEC(1572864))
+
+	f = newTestFormatter(t, "de_DE.UTF-8")
+	assert.Equal(t, "1,5 GB", f.FormatSize(1500000000))
+}
+
+func TestFormatter_FormatRelativeTime(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	f := newTestFormatter(t, "en_US.UTF-8")
+	assert.Equal(t, "now", f.FormatRelativeTime(now.Add(-30*time.Second), now))
+	assert.Equal(t, "1 minute ago", f.FormatRelativeTime(now.Add(-time.Minute), now))
+	assert.Equal(t, "3 minutes ago", f.FormatRelativeTime(now.Add(-3*time.Minute-10*time.Second), now))
+	assert.Equal(t, "in 2 hours", f.FormatRelativeTime(now.Add(2*time.Hour), now))
+	assert.Equal(t, "5 days ago", f.FormatRelativeTime(now.AddDate(0, 0, -5), now))
+	assert.Equal(t, "2 months ago", f.FormatRelativeTime(now.AddDate(0, -2, 0), now))
+	assert.Equal(t, "in 1 year", f.FormatRelativeTime(now.AddDate(1, 0, 0), now))
+
+	f = newTestFormatter(t, "zh_CN.UTF-8")
+	assert.Equal(t, "3分钟前", f.FormatRelativeDuration(-3*time.Minute))
+	assert.Equal(t, "2天后", f.FormatRelativeDuration(48*time.Hour))
+
+	f = newTestFormatter(t, "zh_TW.UTF-8")
+	assert.Equal(t, "3分鐘前", f.FormatRelativeDuration(-3*time.Minute))
+
+	f = newTestFormatter(t, "de_AT.UTF-8")
+	assert.Equal(t, "vor 3 Tagen", f.FormatRelativeDuration(-72*time.Hour))
+
+	f = newTestFormatter(t, "ru_RU.UTF-8")
+	assert.Equal(t, "1 минуту назад", f.FormatRelativeDuration(-time.Minute))
+	assert.Equal(t, "3 минуты назад", f.FormatRelativeDuration(-3*time.Minute))
+	assert.Equal(t, "через 11 часов", f.FormatRelativeDuration(11*time.Hour))
+	assert.Equal(t, "21 день назад", f.FormatRelativeDuration(-21*24*time.Hour))
+}
+
+func TestFormatter_FormatDate(t *testing.T) {
+	tm := time.Date(2023, 3, 5, 14, 7, 9, 0, time.UTC)
+
+	f := newTestFormatter(t, "en_US.UTF-8")
+	assert.Equal(t, "3/5/23", f.FormatDate(tm, DateShort))
+	assert.Equal(t, "Mar 5, 2023", f.FormatDate(tm, DateMedium))
+	assert.Equal(t, "March 5, 2023", f.FormatDate(tm, DateLong))
+	assert.Equal(t, "Sunday, March 5, 2023", f.FormatDate(tm, DateFull))
+	assert.Equal(t, "02:07:09 PM", f.FormatTime(tm))
+
+	f = newTestFormatter(t, "en_GB.UTF-8")
+	assert.Equal(t, "05/03/2023", f.FormatDate(tm, DateShort))
+	assert.Equal(t, "5 March 2023", f.FormatDate(tm, DateLong))
+	assert.Equal(t, "14:07:09", f.FormatTime(tm))
+
+	f = newTestFormatter(t, "zh_CN.UTF-8")
+	assert.Equal(t, "2023/3/5", f.FormatDate(tm, DateShort))
+	assert.Equal(t, "2023年3月5日", f.FormatDate(tm, DateLong))
+	assert.Equal(t, "2023年3月5日星期日", f.FormatDate(tm, DateFull))
+	assert.Equal(t, "14时07分09秒", f.FormatTime(tm))
+
+	// the names come from the locale source
+	f = newTestFormatter(t, "de_AT.UTF-8")
+	assert.Equal(t, "05.03.2023", f.FormatDate(tm, DateMedium))
+	assert.Equal(t, "Sonntag, 5. März 2023", f.FormatDate(tm, DateFull))
+	assert.Equal(t, "So 05 Mär 2023 14:07:09", f.FormatDateTime(tm))
+
+	f = newTestFormatter(t, "ru_RU.UTF-8")
+	assert.Equal(t, "5 марта 2023 г.", f.FormatDate(tm, DateLong))
+
+	// the date format of LC_TIME
+	f = newTestFormatter(t, "C")
+	assert.Equal(t, "03/05/23", f.FormatDate(tm, DateLong))
+}
+
+func TestFormatter_Strftime(t *testing.T) {
+	f := newTestFormatter(t, "en_US.UTF-8")
+	tm := time.Date(2021, 1, 3, 9, 5, 7, 0, time.FixedZone("CST", 8*3600))
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2021-01-03 09:05:07"},
+		{"%a %A %b %B %h", "Sun Sunday Jan January Jan"},
+		{"%C %y %D %F", "20 21 01/03/21 2021-01-03"},
+		{"[%e] [%-d] [%_m] [%k] [%l] [%I]", "[ 3] [3] [ 1] [ 9] [ 9] [09]"},
+		{"%j %u %w %U %W", "003 7 0 01 00"},
+		{"%G %g %V", "2020 20 53"},
+		{"%p %P %r %R %T", "AM am 09:05:07 AM 09:05 09:05:07"},
+		{"%z %Z %s", "+0800 CST 1609635907"},
+		{"%x %X", "01/03/2021 09:05:07 AM"},
+		{"%Ey %Od %% %q %", "21 03 % %q %"},
+		{"%n%t", "\n\t"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, f.Strftime(test.format, tm), test.format)
+	}
+}
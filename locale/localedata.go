@@ -0,0 +1,426 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NumericInfo is the LC_NUMERIC category of a locale.
+type NumericInfo struct {
+	DecimalPoint string
+	ThousandsSep string
+	// Grouping are the sizes of the digit groups from the right, the last
+	// size is repeated unless it is -1, which stops the grouping. It is
+	// empty if the digits are not grouped.
+	Grouping []int
+}
+
+// MonetaryInfo is the LC_MONETARY category of a locale.
+type MonetaryInfo struct {
+	IntCurrSymbol   string
+	CurrencySymbol  string
+	MonDecimalPoint string
+	MonThousandsSep string
+	MonGrouping     []int
+	PositiveSign    string
+	NegativeSign    string
+	FracDigits      int
+	// CsPrecedes reports whether the currency symbol precedes the value.
+	CsPrecedes bool
+	// SepBySpace reports whether a space separates the currency symbol
+	// and the value.
+	SepBySpace bool
+}
+
+// TimeInfo is the LC_TIME category of a locale, the formats are in the
+// syntax of strftime.
+type TimeInfo struct {
+	AbDays   [7]string // from Sunday
+	Days     [7]string
+	AbMonths [12]string
+	Months   [12]string
+	AmPm     [2]string
+
+	DateTimeFormat string // d_t_fmt, %c
+	DateFormat     string // d_fmt, %x
+	TimeFormat     string // t_fmt, %X
+	TimeFormatAmPm string // t_fmt_ampm, %r
+}
+
+// Data is the formatting data of a locale.
+type Data struct {
+	// Name is the locale name, such as "zh_CN".
+	Name     string
+	Numeric  NumericInfo
+	Monetary MonetaryInfo
+	Time     TimeInfo
+}
+
+// LocaleSourceDir is the directory of the glibc locale sources.
+var LocaleSourceDir = "/usr/share/i18n/locales"
+
+var (
+	localeDataCacheMu sync.Mutex
+	localeDataCache   = make(map[string]*Data)
+)
+
+// LoadData returns the formatting data of the locale, such as "zh_CN.UTF-8".
+// The glibc locale source in LocaleSourceDir is preferred, the bundled
+// tables are used if there is no source for any variant of the locale.
+// The data of the C locale is returned for an unknown locale.
+func LoadData(locale string) *Data {
+	localeDataCacheMu.Lock()
+	defer localeDataCacheMu.Unlock()
+	if d, ok := localeDataCache[locale]; ok {
+		return d
+	}
+	d := loadData(locale)
+	localeDataCache[locale] = d
+	return d
+}
+
+func loadData(locale string) *Data {
+	cs := ExplodeLocale(locale)
+	// the codeset does not matter
+	name := cs.Language
+	if cs.Territory != "" {
+		name += "_" + cs.Territory
+	}
+	if cs.Modifier != "" {
+		name += "@" + cs.Modifier
+	}
+	variants := GetLocaleVariants(name)
+
+	for _, variant := range variants {
+		d, err := LoadDataFromSource(filepath.Join(LocaleSourceDir, variant))
+		if err == nil {
+			d.Name = variant
+			return d
+		}
+	}
+	for _, variant := range variants {
+		if d, ok := builtinData[variant]; ok {
+			return d
+		}
+	}
+	// the language without a territory, such as "de", uses the first
+	// bundled territory
+	for _, d := range builtinDataList {
+		if strings.HasPrefix(d.Name, cs.Language+"_") {
+			return d
+		}
+	}
+	return builtinData["C"]
+}
+
+// LocaleSourceError is returned when a glibc locale source can not be parsed.
+type LocaleSourceError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (err LocaleSourceError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", err.File, err.Line, err.Msg)
+}
+
+// the maximum depth of the copy directives
+const maxCopyDepth = 8
+
+// LoadDataFromSource parses the glibc locale source file, such as
+// /usr/share/i18n/locales/zh_CN. The copy directives are resolved in the
+// directory of file. Only LC_NUMERIC, LC_MONETARY and LC_TIME are read.
+func LoadDataFromSource(file string) (*Data, error) {
+	d := &Data{Name: filepath.Base(file)}
+	for _, category := range []string{"LC_NUMERIC", "LC_MONETARY", "LC_TIME"} {
+		values, err := readSourceCategory(file, category, 0)
+		if err != nil {
+			return nil, err
+		}
+		switch category {
+		case "LC_NUMERIC":
+			d.Numeric = numericFromValues(values)
+		case "LC_MONETARY":
+			d.Monetary = monetaryFromValues(values)
+		case "LC_TIME":
+			d.Time = timeFromValues(values)
+		}
+	}
+	return d, nil
+}
+
+// sourceValues are the values of the keywords of a category.
+type sourceValues map[string][]string
+
+func (v sourceValues) str(key string) string {
+	if list := v[key]; len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+func (v sourceValues) num(key string) int {
+	n, _ := strconv.Atoi(v.str(key))
+	return n
+}
+
+func (v sourceValues) grouping(key string) []int {
+	var result []int
+	for _, s := range v[key] {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			break
+		}
+		if n <= 0 {
+			if len(result) > 0 {
+				result = append(result, -1)
+			}
+			break
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+func (v sourceValues) strs(key string, dst []string) {
+	copy(dst, v[key])
+}
+
+func numericFromValues(v sourceValues) NumericInfo {
+	return NumericInfo{
+		DecimalPoint: v.str("decimal_point"),
+		ThousandsSep: v.str("thousands_sep"),
+		Grouping:     v.grouping("grouping"),
+	}
+}
+
+func monetaryFromValues(v sourceValues) MonetaryInfo {
+	return MonetaryInfo{
+		IntCurrSymbol:   v.str("int_curr_symbol"),
+		CurrencySymbol:  v.str("currency_symbol"),
+		MonDecimalPoint: v.str("mon_decimal_point"),
+		MonThousandsSep: v.str("mon_thousands_sep"),
+		MonGrouping:     v.grouping("mon_grouping"),
+		PositiveSign:    v.str("positive_sign"),
+		NegativeSign:    v.str("negative_sign"),
+		FracDigits:      v.num("frac_digits"),
+		CsPrecedes:      v.num("p_cs_precedes") == 1,
+		SepBySpace:      v.num("p_sep_by_space") == 1,
+	}
+}
+
+func timeFromValues(v sourceValues) TimeInfo {
+	var ti TimeInfo
+	v.strs("abday", ti.AbDays[:])
+	v.strs("day", ti.Days[:])
+	v.strs("abmon", ti.AbMonths[:])
+	v.strs("mon", ti.Months[:])
+	v.strs("am_pm", ti.AmPm[:])
+	ti.DateTimeFormat = v.str("d_t_fmt")
+	ti.DateFormat = v.str("d_fmt")
+	ti.TimeFormat = v.str("t_fmt")
+	ti.TimeFormatAmPm = v.str("t_fmt_ampm")
+	return ti
+}
+
+// readSourceCategory reads the keywords of the category in file, a copy
+// directive is resolved by reading the same category of the copied locale.
+func readSourceCategory(file, category string, depth int) (sourceValues, error) {
+	if depth > maxCopyDepth {
+		return nil, LocaleSourceError{File: file, Msg: "too many levels of copy"}
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := sourceParser{
+		file:        file,
+		commentChar: '#',
+		escapeChar:  '\\',
+	}
+	values := make(sourceValues)
+	inCategory := false
+	found := false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	var logical string
+	for scanner.Scan() {
+		p.line++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if logical == "" && strings.HasPrefix(trimmed, string(p.commentChar)) {
+			continue
+		}
+		// a line ending with the escape character continues
+		if strings.HasSuffix(trimmed, string(p.escapeChar)) &&
+			!strings.HasSuffix(trimmed, string([]rune{p.escapeChar, p.escapeChar})) {
+			logical += strings.TrimSuffix(trimmed, string(p.escapeChar))
+			continue
+		}
+		logical += trimmed
+		line, logical = logical, ""
+		if line == "" {
+			continue
+		}
+
+		keyword, rest := splitKeyword(line)
+		switch {
+		case keyword == "comment_char" && rest != "":
+			p.commentChar = []rune(rest)[0]
+			continue
+		case keyword == "escape_char" && rest != "":
+			p.escapeChar = []rune(rest)[0]
+			continue
+		case keyword == category:
+			inCategory = true
+			found = true
+			continue
+		case keyword == "END" && rest == category:
+			inCategory = false
+			continue
+		}
+		if !inCategory {
+			continue
+		}
+
+		list, err := p.parseValues(rest)
+		if err != nil {
+			return nil, err
+		}
+		if keyword == "copy" {
+			if len(list) == 0 {
+				return nil, p.error("copy without a locale")
+			}
+			copied, err := readSourceCategory(filepath.Join(filepath.Dir(file), list[0]), category, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range copied {
+				values[k] = v
+			}
+			continue
+		}
+		values[keyword] = list
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, LocaleSourceError{File: file, Line: p.line, Msg: "no category " + category}
+	}
+	return values, nil
+}
+
+func splitKeyword(line string) (keyword, rest string) {
+	idx := strings.IndexAny(line, " \t")
+	if idx == -1 {
+		return line, ""
+	}
+	return line[:idx], strings.TrimSpace(line[idx+1:])
+}
+
+type sourceParser struct {
+	file        string
+	line        int
+	commentChar rune
+	escapeChar  rune
+}
+
+func (p *sourceParser) error(msg string) error {
+	return LocaleSourceError{File: p.file, Line: p.line, Msg: msg}
+}
+
+// parseValues parses the values separated by ";", such as
+// "<U0053><U0075><U006E>";"Mon" or 3;3.
+func (p *sourceParser) parseValues(s string) ([]string, error) {
+	var result []string
+	rs := []rune(s)
+	i := 0
+	for i < len(rs) {
+		for i < len(rs) && (rs[i] == ' ' || rs[i] == '\t') {
+			i++
+		}
+		if i >= len(rs) {
+			break
+		}
+		var value string
+		var err error
+		if rs[i] == '"' {
+			value, i, err = p.parseString(rs, i+1, true)
+		} else {
+			value, i, err = p.parseString(rs, i, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		for i < len(rs) && (rs[i] == ' ' || rs[i] == '\t') {
+			i++
+		}
+		if i < len(rs) {
+			if rs[i] != ';' {
+				return nil, p.error("expected ;")
+			}
+			i++
+		}
+	}
+	return result, nil
+}
+
+// parseString decodes the symbols <Uxxxx> and the escaped characters
+// from rs[i:]. A quoted string ends with ", a bare value ends with ; or a
+// space.
+func (p *sourceParser) parseString(rs []rune, i int, quoted bool) (string, int, error) {
+	var sb strings.Builder
+	for i < len(rs) {
+		c := rs[i]
+		switch {
+		case quoted && c == '"':
+			return sb.String(), i + 1, nil
+		case !quoted && (c == ';' || c == ' ' || c == '\t'):
+			return sb.String(), i, nil
+		case c == p.escapeChar:
+			if i+1 >= len(rs) {
+				return "", i, p.error("incomplete escape")
+			}
+			sb.WriteRune(rs[i+1])
+			i += 2
+		case c == '<':
+			end := i + 1
+			for end < len(rs) && rs[end] != '>' {
+				end++
+			}
+			if end >= len(rs) {
+				return "", i, p.error("incomplete symbol")
+			}
+			sym := string(rs[i+1 : end])
+			if !strings.HasPrefix(sym, "U") {
+				return "", i, p.error("unknown symbol <" + sym + ">")
+			}
+			code, err := strconv.ParseUint(sym[1:], 16, 32)
+			if err != nil {
+				return "", i, p.error("invalid symbol <" + sym + ">")
+			}
+			sb.WriteRune(rune(code))
+			i = end + 1
+		default:
+			sb.WriteRune(c)
+			i++
+		}
+	}
+	if quoted {
+		return "", i, p.error("unterminated string")
+	}
+	return sb.String(), i, nil
+}
@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+// The bundled formatting data, derived from the glibc locales and CLDR.
+// They are used when the glibc locale sources are not installed.
+
+var enTimeNames = TimeInfo{
+	AbDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	Days:     [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	AbMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	Months: [12]string{"January", "February", "March", "April", "May", "June", "July",
+		"August", "September", "October", "November", "December"},
+	AmPm: [2]string{"AM", "PM"},
+}
+
+var cData = &Data{
+	Name: "C",
+	Numeric: NumericInfo{
+		DecimalPoint: ".",
+	},
+	Monetary: MonetaryInfo{
+		FracDigits: -1,
+	},
+	Time: withTimeFormats(enTimeNames, "%a %b %e %H:%M:%S %Y", "%m/%d/%y", "%H:%M:%S", "%I:%M:%S %p"),
+}
+
+func withTimeFormats(ti TimeInfo, dateTime, date, time, timeAmPm string) TimeInfo {
+	ti.DateTimeFormat = dateTime
+	ti.DateFormat = date
+	ti.TimeFormat = time
+	ti.TimeFormatAmPm = timeAmPm
+	return ti
+}
+
+var (
+	zhHansTimeNames = TimeInfo{
+		AbDays: [7]string{"日", "一", "二", "三", "四", "五", "六"},
+		Days:   [7]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		AbMonths: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月",
+			"10月", "11月", "12月"},
+		Months: [12]string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月",
+			"十月", "十一月", "十二月"},
+		AmPm: [2]string{"上午", "下午"},
+	}
+	zhHantTimeNames = TimeInfo{
+		AbDays:   zhHansTimeNames.AbDays,
+		Days:     [7]string{"週日", "週一", "週二", "週三", "週四", "週五", "週六"},
+		AbMonths: zhHansTimeNames.AbMonths,
+		Months:   zhHansTimeNames.Months,
+		AmPm:     zhHansTimeNames.AmPm,
+	}
+)
+
+var builtinDataList = []*Data{
+	{
+		Name: "en_US",
+		Numeric: NumericInfo{
+			DecimalPoint: ".",
+			ThousandsSep: ",",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "USD ",
+			CurrencySymbol:  "$",
+			MonDecimalPoint: ".",
+			MonThousandsSep: ",",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			CsPrecedes:      true,
+		},
+		Time: withTimeFormats(enTimeNames, "%a %d %b %Y %r %Z", "%m/%d/%Y", "%r", "%I:%M:%S %p"),
+	},
+	{
+		Name: "en_GB",
+		Numeric: NumericInfo{
+			DecimalPoint: ".",
+			ThousandsSep: ",",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "GBP ",
+			CurrencySymbol:  "£",
+			MonDecimalPoint: ".",
+			MonThousandsSep: ",",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			CsPrecedes:      true,
+		},
+		Time: func() TimeInfo {
+			ti := withTimeFormats(enTimeNames, "%a %d %b %Y %T %Z", "%d/%m/%y", "%T", "%l:%M:%S %P %Z")
+			ti.AmPm = [2]string{"am", "pm"}
+			return ti
+		}(),
+	},
+	{
+		Name: "zh_CN",
+		Numeric: NumericInfo{
+			DecimalPoint: ".",
+			ThousandsSep: ",",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "CNY ",
+			CurrencySymbol:  "￥",
+			MonDecimalPoint: ".",
+			MonThousandsSep: ",",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			CsPrecedes:      true,
+		},
+		Time: withTimeFormats(zhHansTimeNames, "%Y年%m月%d日 %A %H时%M分%S秒", "%Y年%m月%d日",
+			"%H时%M分%S秒", "%p %I时%M分%S秒"),
+	},
+	{
+		Name: "zh_TW",
+		Numeric: NumericInfo{
+			DecimalPoint: ".",
+			ThousandsSep: ",",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "TWD ",
+			CurrencySymbol:  "NT$",
+			MonDecimalPoint: ".",
+			MonThousandsSep: ",",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			CsPrecedes:      true,
+		},
+		Time: withTimeFormats(zhHantTimeNames, "%Y年%m月%d日 (%A) %H時%M分%S秒", "%Y年%m月%d日",
+			"%H時%M分%S秒", "%p %I時%M分%S秒"),
+	},
+	{
+		Name: "de_DE",
+		Numeric: NumericInfo{
+			DecimalPoint: ",",
+			ThousandsSep: ".",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "EUR ",
+			CurrencySymbol:  "€",
+			MonDecimalPoint: ",",
+			MonThousandsSep: ".",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			SepBySpace:      true,
+		},
+		Time: withTimeFormats(TimeInfo{
+			AbDays:   [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+			Days:     [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+			AbMonths: [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+			Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli",
+				"August", "September", "Oktober", "November", "Dezember"},
+		}, "%a %d %b %Y %T %Z", "%d.%m.%Y", "%T", ""),
+	},
+	{
+		Name: "fr_FR",
+		Numeric: NumericInfo{
+			DecimalPoint: ",",
+			ThousandsSep: " ",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "EUR ",
+			CurrencySymbol:  "€",
+			MonDecimalPoint: ",",
+			MonThousandsSep: " ",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			SepBySpace:      true,
+		},
+		Time: withTimeFormats(TimeInfo{
+			AbDays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+			Days:   [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+			AbMonths: [12]string{"janv.", "févr.", "mars", "avril", "mai", "juin", "juil.", "août",
+				"sept.", "oct.", "nov.", "déc."},
+			Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet",
+				"août", "septembre", "octobre", "novembre", "décembre"},
+		}, "%a %d %b %Y %T %Z", "%d/%m/%Y", "%T", ""),
+	},
+	{
+		Name: "es_ES",
+		Numeric: NumericInfo{
+			DecimalPoint: ",",
+			ThousandsSep: ".",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "EUR ",
+			CurrencySymbol:  "€",
+			MonDecimalPoint: ",",
+			MonThousandsSep: ".",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			SepBySpace:      true,
+		},
+		Time: withTimeFormats(TimeInfo{
+			AbDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+			Days:     [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+			AbMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+			Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio",
+				"agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		}, "%a %d %b %Y %T %Z", "%d/%m/%y", "%T", ""),
+	},
+	{
+		Name: "ja_JP",
+		Numeric: NumericInfo{
+			DecimalPoint: ".",
+			ThousandsSep: ",",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "JPY ",
+			CurrencySymbol:  "￥",
+			MonDecimalPoint: ".",
+			MonThousandsSep: ",",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      0,
+			CsPrecedes:      true,
+		},
+		Time: withTimeFormats(TimeInfo{
+			AbDays: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+			Days:   [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+			AbMonths: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月",
+				"10月", "11月", "12月"},
+			Months: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月",
+				"10月", "11月", "12月"},
+			AmPm: [2]string{"午前", "午後"},
+		}, "%Y年%m月%d日 %H時%M分%S秒", "%Y年%m月%d日", "%H時%M分%S秒", "%p%I時%M分%S秒"),
+	},
+	{
+		Name: "ru_RU",
+		Numeric: NumericInfo{
+			DecimalPoint: ",",
+			ThousandsSep: " ",
+			Grouping:     []int{3},
+		},
+		Monetary: MonetaryInfo{
+			IntCurrSymbol:   "RUB ",
+			CurrencySymbol:  "₽",
+			MonDecimalPoint: ",",
+			MonThousandsSep: " ",
+			MonGrouping:     []int{3},
+			NegativeSign:    "-",
+			FracDigits:      2,
+			SepBySpace:      true,
+		},
+		// the months are in the genitive case as glibc 2.27 and later
+		Time: withTimeFormats(TimeInfo{
+			AbDays: [7]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+			Days: [7]string{"Воскресенье", "Понедельник", "Вторник", "Среда", "Четверг",
+				"Пятница", "Суббота"},
+			AbMonths: [12]string{"янв", "фев", "мар", "апр", "мая", "июн", "июл", "авг", "сен",
+				"окт", "ноя", "дек"},
+			Months: [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля",
+				"августа", "сентября", "октября", "ноября", "декабря"},
+		}, "%a %d %b %Y %T", "%d.%m.%Y", "%T", ""),
+	},
+}
+
+var builtinData = func() map[string]*Data {
+	m := map[string]*Data{
+		"C":     cData,
+		"POSIX": cData,
+	}
+	for _, d := range builtinDataList {
+		m[d.Name] = d
+	}
+	return m
+}()
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package locale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useLocaleSourceDir(t *testing.T, dir string) {
+	oldDir := LocaleSourceDir
+	LocaleSourceDir = dir
+	localeDataCache = make(map[string]*Data)
+	t.Cleanup(func() {
+		LocaleSourceDir = oldDir
+		localeDataCache = make(map[string]*Data)
+	})
+}
+
+func TestLoadDataFromSource(t *testing.T) {
+	d, err := LoadDataFromSource("testdata/i18n/locales/de_DE")
+	require.NoError(t, err)
+	assert.Equal(t, "de_DE", d.Name)
+	assert.Equal(t, NumericInfo{
+		DecimalPoint: ",",
+		ThousandsSep: ".",
+		Grouping:     []int{3, 3},
+	}, d.Numeric)
+	assert.Equal(t, MonetaryInfo{
+		IntCurrSymbol:   "EUR ",
+		CurrencySymbol:  "€",
+		MonDecimalPoint: ",",
+		MonThousandsSep: ".",
+		MonGrouping:     []int{3, 3},
+		NegativeSign:    "-",
+		FracDigits:      2,
+		SepBySpace:      true,
+	}, d.Monetary)
+	assert.Equal(t, "Sonntag", d.Time.Days[0])
+	assert.Equal(t, "Sa", d.Time.AbDays[6])
+	assert.Equal(t, "Mär", d.Time.AbMonths[2])
+	assert.Equal(t, "Dezember", d.Time.Months[11])
+	assert.Equal(t, "%a %d %b %Y %T", d.Time.DateTimeFormat)
+	assert.Equal(t, "%d.%m.%Y", d.Time.DateFormat)
+
+	at, err := LoadDataFromSource("testdata/i18n/locales/de_AT")
+	require.NoError(t, err)
+	assert.Equal(t, "de_AT", at.Name)
+	assert.Equal(t, d.Numeric, at.Numeric)
+	assert.Equal(t, d.Monetary, at.Monetary)
+	assert.Equal(t, d.Time, at.Time)
+
+	_, err = LoadDataFromSource("testdata/i18n/locales/broken")
+	assert.Equal(t, LocaleSourceError{
+		File: "testdata/i18n/locales/broken",
+		Line: 5,
+		Msg:  "unterminated string",
+	}, err)
+
+	_, err = LoadDataFromSource("testdata/i18n/locales/loop")
+	assert.IsType(t, LocaleSourceError{}, err)
+
+	_, err = LoadDataFromSource("testdata/i18n/locales/none")
+	assert.Error(t, err)
+}
+
+func TestLoadData(t *testing.T) {
+	useLocaleSourceDir(t, "testdata/i18n/locales")
+
+	// from the source
+	d := LoadData("de_AT.UTF-8")
+	assert.Equal(t, "de_AT", d.Name)
+	assert.Equal(t, ",", d.Numeric.DecimalPoint)
+	assert.Same(t, d, LoadData("de_AT.UTF-8"))
+
+	// the bundled tables
+	assert.Same(t, builtinData["zh_CN"], LoadData("zh_CN.UTF-8"))
+	assert.Same(t, builtinData["en_GB"], LoadData("en_GB"))
+	assert.Same(t, builtinData["fr_FR"], LoadData("fr_CA.UTF-8"))
+	assert.Same(t, builtinData["ru_RU"], LoadData("ru"))
+	assert.Same(t, builtinData["C"], LoadData("C.UTF-8"))
+	assert.Same(t, builtinData["C"], LoadData("xx_YY"))
+}
@@ -6,11 +6,14 @@ package mime
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"strings"
+	"sync"
 
-	dutils "github.com/linuxdeepin/go-lib/utils"
 	gio "github.com/linuxdeepin/go-gir/gio-2.0"
+	"github.com/linuxdeepin/go-lib/keyfile"
+	"github.com/linuxdeepin/go-lib/mime/mimeapps"
+	"github.com/linuxdeepin/go-lib/mime/mimedb"
+	dutils "github.com/linuxdeepin/go-lib/utils"
 )
 
 const (
@@ -18,9 +21,33 @@ const (
 	MimeTypeIcon   = "application/x-icon-theme"
 	MimeTypeCursor = "application/x-cursor-theme"
 
+	MimeTypeSound    = "application/x-sound-theme"
+	MimeTypePlymouth = "application/x-plymouth-theme"
+	MimeTypeGrub     = "application/x-grub-theme"
+	MimeTypeFont     = "application/x-font-package"
+
 	MimeUserConfig = ".config/mimeapps.list"
 )
 
+var (
+	_db   *mimedb.Database
+	_dbMu sync.Mutex
+)
+
+// SetDatabase makes Query detect the content type with the pure Go
+// shared-mime-info database db instead of GIO, nil restores GIO.
+func SetDatabase(db *mimedb.Database) {
+	_dbMu.Lock()
+	_db = db
+	_dbMu.Unlock()
+}
+
+func getDatabase() *mimedb.Database {
+	_dbMu.Lock()
+	defer _dbMu.Unlock()
+	return _db
+}
+
 // Query query file mime type
 func Query(uri string) (string, error) {
 	file := dutils.DecodeURI(uri)
@@ -34,72 +61,68 @@ func Query(uri string) (string, error) {
 	return doQueryFile(file)
 }
 
+func newMimeApps() *mimeapps.MimeApps {
+	apps := mimeapps.New()
+	apps.SetDatabase(getDatabase())
+	return apps
+}
+
 // Set 'mime' default app to 'desktopId'
 //
 // desktopId: the basename of the desktop file
 func SetDefaultApp(mime, desktopId string) error {
+	apps := newMimeApps()
 	// If the default app is in /usr/share/applications/, still go to set
-	cur := GetUserDefaultApp(mime)
+	cur := apps.GetUserDefaultApp(mime)
 	if cur == desktopId {
 		return nil
 	}
 
-	app := gio.NewDesktopAppInfo(desktopId)
-	if app == nil {
+	if apps.GetDesktopFile(desktopId) == "" {
 		return fmt.Errorf("Invalid id '%v'", desktopId)
 	}
-	defer app.Unref()
-
-	_, err := app.SetAsDefaultForType(mime)
-	return err
+	return apps.SetDefaultApp(mime, desktopId)
 }
 
 // get default from ~/.config/mimeapps.list
 func GetUserDefaultApp(ty string) string {
-	v, exist := dutils.ReadKeyFromKeyFile(filepath.Join(os.Getenv("HOME"), MimeUserConfig),
-		"Default Applications", ty, "")
-	if !exist {
-		return ""
-	}
-
-	ret, ok := v.(string)
-	if !ok {
-		return ""
-	}
-
-	return ret
+	return newMimeApps().GetUserDefaultApp(ty)
 }
 
 // Get default app for 'mime'
-// gio.AppInfoGetDefaultForType can get default from ~/.config/mimeapps.list ， /usr/share/applications/mimeinfo.cache , /usr/share/applications/mimeapps.list
+// the default is searched in the mimeapps.list files of the config and data dirs, and mimeinfo.cache
 // ret0: desktopId
 func GetDefaultApp(mime string, mustSupportURIs bool) (string, error) {
-	app := gio.AppInfoGetDefaultForType(mime, false)
-	if app == nil {
+	apps := newMimeApps()
+	desktopId := apps.GetDefaultApp(mime)
+	if desktopId == "" {
 		return "", fmt.Errorf("Invalid mime '%v'", mime)
 	}
-	defer app.Unref()
 
 	if mustSupportURIs {
-		if !app.SupportsUris() {
+		if !supportsURIs(apps.GetDesktopFile(desktopId)) {
 			return "", fmt.Errorf("Not found app supported '%s' and uris", mime)
 		}
 	}
 
-	return app.GetId(), nil
+	return desktopId, nil
 }
 
 // Get app list of supported the 'mime'
 // ret0: desktopId list
 func GetAppList(mime string) []string {
-	apps := gio.AppInfoGetAllForType(mime)
+	return newMimeApps().GetAppList(mime)
+}
 
-	var list []string
-	for _, app := range apps {
-		list = append(list, app.GetId())
-		app.Unref()
+// supportsURIs reports whether the Exec key of the desktop file has the
+// field code %u or %U.
+func supportsURIs(desktopFile string) bool {
+	kf := keyfile.NewKeyFile()
+	if kf.LoadFromFile(desktopFile) != nil {
+		return false
 	}
-	return list
+	exec, _ := kf.GetString("Desktop Entry", "Exec")
+	return strings.Contains(exec, "%u") || strings.Contains(exec, "%U")
 }
 
 func doQueryFile(file string) (string, error) {
@@ -107,6 +130,10 @@ func doQueryFile(file string) (string, error) {
 		return "", fmt.Errorf("Not found the file '%s'", file)
 	}
 
+	if db := getDatabase(); db != nil {
+		return db.QueryFile(file)
+	}
+
 	gf := gio.FileNewForPath(file)
 	defer gf.Unref()
 
@@ -7,6 +7,7 @@ package mime
 import (
 	"testing"
 
+	"github.com/linuxdeepin/go-lib/mime/mimedb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,3 +33,18 @@ func TestQueryURI(t *testing.T) {
 		assert.Equal(t, m, info.mime)
 	}
 }
+
+func TestQueryWithDatabase(t *testing.T) {
+	db, err := mimedb.LoadDirs([]string{"mimedb/testdata/xml/mime"})
+	require.NoError(t, err)
+	SetDatabase(db)
+	defer SetDatabase(nil)
+
+	m, err := Query("testdata/data.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", m)
+
+	m, err = Query("testdata/Deepin/index.theme")
+	require.NoError(t, err)
+	assert.Equal(t, MimeTypeGtk, m)
+}
@@ -0,0 +1,347 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package mimeapps implements the freedesktop Association between MIME types
+// and applications specification, it reads and writes mimeapps.list files and
+// reads mimeinfo.cache files.
+package mimeapps
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/linuxdeepin/go-lib/keyfile"
+	"github.com/linuxdeepin/go-lib/mime/mimedb"
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
+)
+
+const (
+	SectionDefaultApplications = "Default Applications"
+	SectionAddedAssociations   = "Added Associations"
+	SectionRemovedAssociations = "Removed Associations"
+	SectionMimeCache           = "MIME Cache"
+
+	listFileName  = "mimeapps.list"
+	cacheFileName = "mimeinfo.cache"
+	appsDirName   = "applications"
+)
+
+type listFile struct {
+	path     string
+	defaults map[string][]string
+	added    map[string][]string
+	removed  map[string][]string
+}
+
+type MimeApps struct {
+	configDirs []string
+	dataDirs   []string
+	desktops   []string
+	db         *mimedb.Database
+
+	lists  []*listFile
+	caches []map[string][]string
+}
+
+// New returns a MimeApps using the XDG base directories and the desktops
+// in $XDG_CURRENT_DESKTOP.
+func New() *MimeApps {
+	return NewWithDirs(basedir.GetConfigDirs(), basedir.GetDataDirs(), GetCurrentDesktops())
+}
+
+// NewWithDirs returns a MimeApps using the given directories, which are
+// ordered from the highest priority to the lowest. The first config dir is
+// the one written by the Set methods.
+func NewWithDirs(configDirs, dataDirs, desktops []string) *MimeApps {
+	m := &MimeApps{
+		configDirs: configDirs,
+		dataDirs:   dataDirs,
+		desktops:   desktops,
+	}
+	m.Reload()
+	return m
+}
+
+// GetCurrentDesktops returns the lowercase names in $XDG_CURRENT_DESKTOP.
+func GetCurrentDesktops() []string {
+	var result []string
+	for _, desktop := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if desktop != "" {
+			result = append(result, strings.ToLower(desktop))
+		}
+	}
+	return result
+}
+
+// SetDatabase sets the shared mime info database used to resolve aliases
+// and parent types, nil disables the subclass fallback.
+func (m *MimeApps) SetDatabase(db *mimedb.Database) {
+	m.db = db
+}
+
+// GetListFiles returns the paths of the mimeapps.list files in precedence
+// order, no matter whether they exist.
+func (m *MimeApps) GetListFiles() []string {
+	var result []string
+	addDir := func(dir string) {
+		for _, desktop := range m.desktops {
+			result = append(result, filepath.Join(dir, desktop+"-"+listFileName))
+		}
+		result = append(result, filepath.Join(dir, listFileName))
+	}
+	for _, dir := range m.configDirs {
+		addDir(dir)
+	}
+	for _, dir := range m.dataDirs {
+		addDir(filepath.Join(dir, appsDirName))
+	}
+	return result
+}
+
+// GetUserListFile returns the mimeapps.list file modified by the Set
+// methods, ex. ~/.config/mimeapps.list.
+func (m *MimeApps) GetUserListFile() string {
+	if len(m.configDirs) == 0 {
+		return ""
+	}
+	return filepath.Join(m.configDirs[0], listFileName)
+}
+
+// Reload reads all mimeapps.list and mimeinfo.cache files again.
+func (m *MimeApps) Reload() {
+	m.lists = nil
+	for _, file := range m.GetListFiles() {
+		kf := keyfile.NewKeyFile()
+		if kf.LoadFromFile(file) != nil {
+			continue
+		}
+		m.lists = append(m.lists, &listFile{
+			path:     file,
+			defaults: readSection(kf, SectionDefaultApplications),
+			added:    readSection(kf, SectionAddedAssociations),
+			removed:  readSection(kf, SectionRemovedAssociations),
+		})
+	}
+
+	m.caches = nil
+	for _, dir := range m.dataDirs {
+		kf := keyfile.NewKeyFile()
+		if kf.LoadFromFile(filepath.Join(dir, appsDirName, cacheFileName)) != nil {
+			continue
+		}
+		m.caches = append(m.caches, readSection(kf, SectionMimeCache))
+	}
+}
+
+func readSection(kf *keyfile.KeyFile, section string) map[string][]string {
+	result := make(map[string][]string)
+	for _, key := range kf.GetKeys(section) {
+		list, err := kf.GetStringList(section, key)
+		if err != nil {
+			continue
+		}
+		var ids []string
+		for _, id := range list {
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+		result[key] = ids
+	}
+	return result
+}
+
+// GetDesktopFile returns the path of the desktop file with the desktop id,
+// or an empty string if it is not installed.
+func (m *MimeApps) GetDesktopFile(desktopId string) string {
+	if !strings.HasSuffix(desktopId, ".desktop") || strings.Contains(desktopId, "/") {
+		return ""
+	}
+	for _, dir := range m.dataDirs {
+		file := findDesktopFile(filepath.Join(dir, appsDirName), desktopId)
+		if file != "" {
+			return file
+		}
+	}
+	return ""
+}
+
+// ex. the desktop id "kde4-kate.desktop" may be the file kde4/kate.desktop
+func findDesktopFile(dir, desktopId string) string {
+	file := filepath.Join(dir, desktopId)
+	fi, err := os.Stat(file)
+	if err == nil && fi.Mode().IsRegular() {
+		return file
+	}
+	for i := 0; i < len(desktopId); i++ {
+		if desktopId[i] != '-' {
+			continue
+		}
+		subDir := filepath.Join(dir, desktopId[:i])
+		fi, err := os.Stat(subDir)
+		if err != nil || !fi.IsDir() {
+			continue
+		}
+		file = findDesktopFile(subDir, desktopId[i+1:])
+		if file != "" {
+			return file
+		}
+	}
+	return ""
+}
+
+func (m *MimeApps) isInstalled(desktopId string) bool {
+	return m.GetDesktopFile(desktopId) != ""
+}
+
+func (m *MimeApps) unalias(mimeType string) string {
+	if m.db == nil {
+		return mimeType
+	}
+	return m.db.Unalias(mimeType)
+}
+
+// getFallbackTypes returns mimeType and its ancestors, the implicit
+// application/octet-stream is not included.
+func (m *MimeApps) getFallbackTypes(mimeType string) []string {
+	mimeType = m.unalias(mimeType)
+	result := []string{mimeType}
+	if m.db == nil {
+		return result
+	}
+	visited := map[string]bool{mimeType: true}
+	for i := 0; i < len(result); i++ {
+		for _, parent := range m.db.GetParents(result[i]) {
+			parent = m.unalias(parent)
+			if visited[parent] || parent == mimedb.TypeOctetStream {
+				continue
+			}
+			visited[parent] = true
+			result = append(result, parent)
+		}
+	}
+	return result
+}
+
+// getTypeKeys returns the keys to look up for mimeType, which include its aliases.
+func (m *MimeApps) getTypeKeys(mimeType string) []string {
+	keys := []string{mimeType}
+	if m.db != nil {
+		keys = append(keys, m.db.GetAliases(mimeType)...)
+	}
+	return keys
+}
+
+func lookup(section map[string][]string, keys []string) []string {
+	var result []string
+	for _, key := range keys {
+		result = append(result, section[key]...)
+	}
+	return result
+}
+
+// getDefaultApp returns the default app of mimeType without the subclass fallback.
+func (m *MimeApps) getDefaultApp(mimeType string) string {
+	keys := m.getTypeKeys(mimeType)
+	removed := make(map[string]bool)
+	for _, list := range m.lists {
+		for _, id := range lookup(list.defaults, keys) {
+			if !removed[id] && m.isInstalled(id) {
+				return id
+			}
+		}
+		for _, id := range lookup(list.removed, keys) {
+			removed[id] = true
+		}
+	}
+
+	apps := m.getAssociations(mimeType)
+	if len(apps) > 0 {
+		return apps[0]
+	}
+	return ""
+}
+
+// getAssociations returns the apps associated with mimeType by Added
+// Associations and mimeinfo.cache, without the subclass fallback.
+func (m *MimeApps) getAssociations(mimeType string) []string {
+	keys := m.getTypeKeys(mimeType)
+	var result []string
+	seen := make(map[string]bool)
+	add := func(ids []string) {
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if m.isInstalled(id) {
+				result = append(result, id)
+			}
+		}
+	}
+
+	// removed associations only affect lower precedence files
+	for _, list := range m.lists {
+		add(lookup(list.added, keys))
+		for _, id := range lookup(list.removed, keys) {
+			seen[id] = true
+		}
+	}
+	for _, cache := range m.caches {
+		add(lookup(cache, keys))
+	}
+	return result
+}
+
+// GetDefaultApp returns the desktop id of the default app of mimeType, the
+// default apps of the parent types are used if mimeType has none.
+func (m *MimeApps) GetDefaultApp(mimeType string) string {
+	for _, t := range m.getFallbackTypes(mimeType) {
+		if id := m.getDefaultApp(t); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// GetAppList returns the desktop ids of the apps which can open mimeType,
+// the default app goes first, followed by the apps associated with mimeType
+// and the apps of the parent types.
+func (m *MimeApps) GetAppList(mimeType string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	add(m.GetDefaultApp(mimeType))
+	for _, t := range m.getFallbackTypes(mimeType) {
+		for _, id := range m.getAssociations(t) {
+			add(id)
+		}
+	}
+	return result
+}
+
+// GetUserDefaultApp returns the default app of mimeType set in the user
+// config dir, no matter whether it is installed.
+func (m *MimeApps) GetUserDefaultApp(mimeType string) string {
+	if len(m.configDirs) == 0 {
+		return ""
+	}
+	keys := m.getTypeKeys(m.unalias(mimeType))
+	for _, list := range m.lists {
+		if filepath.Dir(list.path) != m.configDirs[0] {
+			continue
+		}
+		if ids := lookup(list.defaults, keys); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+	return ""
+}
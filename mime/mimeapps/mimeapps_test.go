@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimeapps
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linuxdeepin/go-lib/mime/mimedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMimeApps(configDir string, desktops []string) *MimeApps {
+	return NewWithDirs([]string{configDir, "testdata/sysconfig"},
+		[]string{"testdata/data", "testdata/sysdata"}, desktops)
+}
+
+func TestGetListFiles(t *testing.T) {
+	m := newTestMimeApps("testdata/config", []string{"deepin"})
+	assert.Equal(t, []string{
+		"testdata/config/deepin-mimeapps.list",
+		"testdata/config/mimeapps.list",
+		"testdata/sysconfig/deepin-mimeapps.list",
+		"testdata/sysconfig/mimeapps.list",
+		"testdata/data/applications/deepin-mimeapps.list",
+		"testdata/data/applications/mimeapps.list",
+		"testdata/sysdata/applications/deepin-mimeapps.list",
+		"testdata/sysdata/applications/mimeapps.list",
+	}, m.GetListFiles())
+	assert.Equal(t, "testdata/config/mimeapps.list", m.GetUserListFile())
+}
+
+func TestGetCurrentDesktops(t *testing.T) {
+	old := os.Getenv("XDG_CURRENT_DESKTOP")
+	defer os.Setenv("XDG_CURRENT_DESKTOP", old)
+
+	os.Setenv("XDG_CURRENT_DESKTOP", "Deepin:GNOME")
+	assert.Equal(t, []string{"deepin", "gnome"}, GetCurrentDesktops())
+	os.Setenv("XDG_CURRENT_DESKTOP", "")
+	assert.Nil(t, GetCurrentDesktops())
+}
+
+func TestGetDesktopFile(t *testing.T) {
+	m := newTestMimeApps("testdata/config", nil)
+	assert.Equal(t, "testdata/sysdata/applications/vim.desktop", m.GetDesktopFile("vim.desktop"))
+	assert.Equal(t, "testdata/data/applications/kde4/kate.desktop", m.GetDesktopFile("kde4-kate.desktop"))
+	assert.Equal(t, "", m.GetDesktopFile("uninstalled.desktop"))
+	assert.Equal(t, "", m.GetDesktopFile("kde4/kate.desktop"))
+}
+
+func TestGetDefaultApp(t *testing.T) {
+	m := newTestMimeApps("testdata/config", []string{"deepin"})
+	assert.Equal(t, "firefox.desktop", m.GetDefaultApp("text/html"))
+	assert.Equal(t, "firefox.desktop", m.GetDefaultApp("image/png"))
+	assert.Equal(t, "vim.desktop", m.GetDefaultApp("text/plain"))
+	assert.Equal(t, "kde4-kate.desktop", m.GetDefaultApp("text/x-readme"))
+	assert.Equal(t, "", m.GetDefaultApp("text/x-csrc"))
+
+	assert.Equal(t, "firefox.desktop", m.GetUserDefaultApp("text/html"))
+	assert.Equal(t, "", m.GetUserDefaultApp("image/png"))
+
+	// without the desktop specific file
+	m = newTestMimeApps("testdata/config", nil)
+	assert.Equal(t, "", m.GetDefaultApp("text/x-readme"))
+}
+
+func TestSubclassFallback(t *testing.T) {
+	db, err := mimedb.LoadDirs([]string{"../mimedb/testdata/xml/mime"})
+	require.NoError(t, err)
+
+	m := newTestMimeApps("testdata/config", nil)
+	m.SetDatabase(db)
+	assert.Equal(t, "vim.desktop", m.GetDefaultApp("text/x-readme"))
+	assert.Equal(t, "vim.desktop", m.GetDefaultApp("text/x-c"))
+	assert.Equal(t, []string{"vim.desktop", "kde4-kate.desktop"}, m.GetAppList("text/x-c++src"))
+	assert.Equal(t, "", m.GetDefaultApp("application/gzip"))
+}
+
+func TestGetAppList(t *testing.T) {
+	m := newTestMimeApps("testdata/config", nil)
+	// gedit.desktop is removed by testdata/sysconfig/mimeapps.list
+	assert.Equal(t, []string{"vim.desktop", "kde4-kate.desktop"}, m.GetAppList("text/plain"))
+	assert.Equal(t, []string{"firefox.desktop", "chromium.desktop"}, m.GetAppList("text/html"))
+	assert.Equal(t, []string{"firefox.desktop"}, m.GetAppList("image/png"))
+	assert.Nil(t, m.GetAppList("application/x-unknown"))
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimeapps")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configDir := filepath.Join(dir, "config")
+	m := newTestMimeApps(configDir, nil)
+
+	err = m.SetDefaultApp("text/html", "not-exist.desktop")
+	assert.Error(t, err)
+
+	err = m.SetDefaultApp("text/plain", "gedit.desktop")
+	require.NoError(t, err)
+	assert.Equal(t, "gedit.desktop", m.GetDefaultApp("text/plain"))
+	assert.Equal(t, "gedit.desktop", m.GetUserDefaultApp("text/plain"))
+	assert.Equal(t, []string{"gedit.desktop", "vim.desktop"}, m.GetAppList("text/plain"))
+
+	err = m.AddAssociation("text/plain", "kde4-kate.desktop")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gedit.desktop", "kde4-kate.desktop", "vim.desktop"}, m.GetAppList("text/plain"))
+
+	err = m.RemoveAssociation("text/plain", "gedit.desktop")
+	require.NoError(t, err)
+	assert.Equal(t, "vim.desktop", m.GetDefaultApp("text/plain"))
+	assert.Equal(t, []string{"vim.desktop", "kde4-kate.desktop"}, m.GetAppList("text/plain"))
+
+	err = m.RemoveAssociation("text/html", "chromium.desktop")
+	require.NoError(t, err)
+	assert.Equal(t, "firefox.desktop", m.GetDefaultApp("text/html"))
+	assert.Equal(t, []string{"firefox.desktop"}, m.GetAppList("text/html"))
+
+	content, err := ioutil.ReadFile(m.GetUserListFile())
+	require.NoError(t, err)
+	assert.Equal(t, `[Added Associations]
+text/plain=kde4-kate.desktop;
+
+[Removed Associations]
+text/plain=gedit.desktop;
+text/html=chromium.desktop;
+
+`, string(content))
+
+	err = m.ResetAssociations("text/plain")
+	require.NoError(t, err)
+	err = m.ResetAssociations("text/html")
+	require.NoError(t, err)
+	assert.Equal(t, "chromium.desktop", m.GetDefaultApp("text/html"))
+	assert.Equal(t, []string{"chromium.desktop", "firefox.desktop"}, m.GetAppList("text/html"))
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimeapps
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/linuxdeepin/go-lib/keyfile"
+)
+
+var ErrNoUserConfigDir = errors.New("no user config dir")
+
+// modifyUserListFile loads the user mimeapps.list, calls fn and saves it.
+func (m *MimeApps) modifyUserListFile(fn func(kf *keyfile.KeyFile)) error {
+	file := m.GetUserListFile()
+	if file == "" {
+		return ErrNoUserConfigDir
+	}
+
+	kf := keyfile.NewKeyFile()
+	err := kf.LoadFromFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	fn(kf)
+
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	err = kf.SaveToFile(file)
+	if err != nil {
+		return err
+	}
+	m.Reload()
+	return nil
+}
+
+func getList(kf *keyfile.KeyFile, section, mimeType string) []string {
+	list, _ := kf.GetStringList(section, mimeType)
+	var result []string
+	for _, id := range list {
+		if id != "" {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func setList(kf *keyfile.KeyFile, section, mimeType string, list []string) {
+	if len(list) == 0 {
+		kf.DeleteKey(section, mimeType)
+		return
+	}
+	kf.SetStringList(section, mimeType, list)
+}
+
+func removeItem(list []string, item string) []string {
+	result := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != item {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (m *MimeApps) checkDesktopId(desktopId string) error {
+	if !m.isInstalled(desktopId) {
+		return fmt.Errorf("desktop file %q is not installed", desktopId)
+	}
+	return nil
+}
+
+// SetDefaultApp makes desktopId the default app of mimeType and adds the
+// association between them in the user mimeapps.list.
+func (m *MimeApps) SetDefaultApp(mimeType, desktopId string) error {
+	err := m.checkDesktopId(desktopId)
+	if err != nil {
+		return err
+	}
+	return m.modifyUserListFile(func(kf *keyfile.KeyFile) {
+		kf.SetStringList(SectionDefaultApplications, mimeType, []string{desktopId})
+
+		added := getList(kf, SectionAddedAssociations, mimeType)
+		added = append([]string{desktopId}, removeItem(added, desktopId)...)
+		setList(kf, SectionAddedAssociations, mimeType, added)
+
+		removed := getList(kf, SectionRemovedAssociations, mimeType)
+		setList(kf, SectionRemovedAssociations, mimeType, removeItem(removed, desktopId))
+	})
+}
+
+// AddAssociation associates desktopId with mimeType in the user mimeapps.list.
+func (m *MimeApps) AddAssociation(mimeType, desktopId string) error {
+	err := m.checkDesktopId(desktopId)
+	if err != nil {
+		return err
+	}
+	return m.modifyUserListFile(func(kf *keyfile.KeyFile) {
+		added := getList(kf, SectionAddedAssociations, mimeType)
+		added = append(removeItem(added, desktopId), desktopId)
+		setList(kf, SectionAddedAssociations, mimeType, added)
+
+		removed := getList(kf, SectionRemovedAssociations, mimeType)
+		setList(kf, SectionRemovedAssociations, mimeType, removeItem(removed, desktopId))
+	})
+}
+
+// RemoveAssociation removes the association between desktopId and mimeType
+// in the user mimeapps.list, it also hides the associations in lower
+// precedence files and mimeinfo.cache.
+func (m *MimeApps) RemoveAssociation(mimeType, desktopId string) error {
+	return m.modifyUserListFile(func(kf *keyfile.KeyFile) {
+		defaults := getList(kf, SectionDefaultApplications, mimeType)
+		setList(kf, SectionDefaultApplications, mimeType, removeItem(defaults, desktopId))
+
+		added := getList(kf, SectionAddedAssociations, mimeType)
+		setList(kf, SectionAddedAssociations, mimeType, removeItem(added, desktopId))
+
+		removed := getList(kf, SectionRemovedAssociations, mimeType)
+		removed = append(removeItem(removed, desktopId), desktopId)
+		setList(kf, SectionRemovedAssociations, mimeType, removed)
+	})
+}
+
+// ResetAssociations deletes all entries of mimeType in the user mimeapps.list.
+func (m *MimeApps) ResetAssociations(mimeType string) error {
+	return m.modifyUserListFile(func(kf *keyfile.KeyFile) {
+		kf.DeleteKey(SectionDefaultApplications, mimeType)
+		kf.DeleteKey(SectionAddedAssociations, mimeType)
+		kf.DeleteKey(SectionRemovedAssociations, mimeType)
+	})
+}
@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+)
+
+// mime.cache format, all numbers are big endian uint32 except the version.
+//
+//	Header:
+//	2   CARD16   MAJOR_VERSION  1
+//	2   CARD16   MINOR_VERSION  2
+//	4   CARD32   ALIAS_LIST_OFFSET
+//	4   CARD32   PARENT_LIST_OFFSET
+//	4   CARD32   LITERAL_LIST_OFFSET
+//	4   CARD32   REVERSE_SUFFIX_TREE_OFFSET
+//	4   CARD32   GLOB_LIST_OFFSET
+//	4   CARD32   MAGIC_LIST_OFFSET
+//	4   CARD32   NAMESPACE_LIST_OFFSET
+//	4   CARD32   ICONS_LIST_OFFSET
+//	4   CARD32   GENERIC_ICONS_LIST_OFFSET
+const (
+	cacheMajorVersion = 1
+
+	cacheOffsetAliasList         = 4
+	cacheOffsetParentList        = 8
+	cacheOffsetLiteralList       = 12
+	cacheOffsetReverseSuffixTree = 16
+	cacheOffsetGlobList          = 20
+	cacheOffsetMagicList         = 24
+	cacheOffsetIconsList         = 32
+	cacheOffsetGenericIconsList  = 36
+	cacheHeaderSize              = 40
+
+	cacheWeightMask          = 0xff
+	cacheFlagCaseSensitive   = 0x100
+	cacheSuffixNodeSize      = 12
+	cacheMagicMatchSize      = 16
+	cacheMagicMatchletSize   = 32
+	cacheMaxSuffixTreeLength = 256
+)
+
+var errCacheCorrupted = errors.New("mime.cache is corrupted")
+
+type cacheReader struct {
+	data []byte
+	err  error
+}
+
+func (r *cacheReader) u32(offset uint32) uint32 {
+	if r.err != nil {
+		return 0
+	}
+	if uint64(offset)+4 > uint64(len(r.data)) {
+		r.err = errCacheCorrupted
+		return 0
+	}
+	return binary.BigEndian.Uint32(r.data[offset:])
+}
+
+func (r *cacheReader) str(offset uint32) string {
+	if r.err != nil {
+		return ""
+	}
+	if uint64(offset) >= uint64(len(r.data)) {
+		r.err = errCacheCorrupted
+		return ""
+	}
+	end := bytes.IndexByte(r.data[offset:], 0)
+	if end == -1 {
+		r.err = errCacheCorrupted
+		return ""
+	}
+	return string(r.data[offset : offset+uint32(end)])
+}
+
+func (r *cacheReader) bytes(offset, length uint32) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(offset)+uint64(length) > uint64(len(r.data)) {
+		r.err = errCacheCorrupted
+		return nil
+	}
+	return r.data[offset : offset+length]
+}
+
+// count reads the number of entries at offset, and checks that they fit in
+// the data.
+func (r *cacheReader) count(offset uint32, entrySize uint32) uint32 {
+	n := r.u32(offset)
+	if r.err == nil && uint64(n)*uint64(entrySize) > uint64(len(r.data)) {
+		r.err = errCacheCorrupted
+		return 0
+	}
+	return n
+}
+
+// LoadCache loads a mime.cache file generated by update-mime-database.
+func LoadCache(file string) (*Database, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	db, err := ParseCache(data)
+	if err != nil {
+		return nil, ParseError{File: file, Msg: err.Error()}
+	}
+	return db, nil
+}
+
+// ParseCache parses the content of a mime.cache file.
+func ParseCache(data []byte) (*Database, error) {
+	if len(data) < cacheHeaderSize {
+		return nil, errCacheCorrupted
+	}
+	major := binary.BigEndian.Uint16(data)
+	if major != cacheMajorVersion {
+		return nil, errors.New("unsupported mime.cache version")
+	}
+
+	r := &cacheReader{data: data}
+	db := newDatabase()
+
+	// alias list
+	offset := r.u32(cacheOffsetAliasList)
+	n := r.count(offset, 8)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		entry := offset + 4 + i*8
+		alias := r.str(r.u32(entry))
+		db.aliases[alias] = r.str(r.u32(entry + 4))
+	}
+
+	// parent list
+	offset = r.u32(cacheOffsetParentList)
+	n = r.count(offset, 8)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		entry := offset + 4 + i*8
+		mimeType := r.str(r.u32(entry))
+		parentsOffset := r.u32(entry + 4)
+		nParents := r.count(parentsOffset, 4)
+		parents := make([]string, 0, nParents)
+		for j := uint32(0); j < nParents && r.err == nil; j++ {
+			parents = append(parents, r.str(r.u32(parentsOffset+4+j*4)))
+		}
+		db.parents[mimeType] = parents
+	}
+
+	// literal list and glob list
+	for _, listOffset := range []uint32{cacheOffsetLiteralList, cacheOffsetGlobList} {
+		offset = r.u32(listOffset)
+		n = r.count(offset, 12)
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			entry := offset + 4 + i*12
+			pattern := r.str(r.u32(entry))
+			mimeType := r.str(r.u32(entry + 4))
+			weight := r.u32(entry + 8)
+			db.addGlob(&glob{
+				pattern:       pattern,
+				mimeType:      mimeType,
+				weight:        int(weight & cacheWeightMask),
+				caseSensitive: weight&cacheFlagCaseSensitive != 0,
+			})
+		}
+	}
+
+	// reverse suffix tree
+	offset = r.u32(cacheOffsetReverseSuffixTree)
+	n = r.count(offset, cacheSuffixNodeSize)
+	r.readSuffixNodes(db, r.u32(offset+4), n, nil)
+
+	// magic list
+	offset = r.u32(cacheOffsetMagicList)
+	n = r.count(offset, cacheMagicMatchSize)
+	db.maxExtent = int(r.u32(offset + 4))
+	matchesOffset := r.u32(offset + 8)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		entry := matchesOffset + i*cacheMagicMatchSize
+		mg := &magic{
+			priority: int(r.u32(entry)),
+			mimeType: r.str(r.u32(entry + 4)),
+		}
+		nMatchlets := r.count(entry+8, cacheMagicMatchletSize)
+		mg.matchlets = r.readMatchlets(r.u32(entry+12), nMatchlets, 0)
+		db.magics = append(db.magics, mg)
+	}
+
+	// icons list and generic icons list
+	for _, item := range []struct {
+		offset uint32
+		icons  map[string]string
+	}{
+		{cacheOffsetIconsList, db.icons},
+		{cacheOffsetGenericIconsList, db.genericIcons},
+	} {
+		offset = r.u32(item.offset)
+		n = r.count(offset, 8)
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			entry := offset + 4 + i*8
+			mimeType := r.str(r.u32(entry))
+			item.icons[mimeType] = r.str(r.u32(entry + 4))
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	db.sortMagics()
+	return db, nil
+}
+
+// readSuffixNodes walks the reverse suffix tree, suffix holds the characters
+// from the end of the file name to the current node.
+func (r *cacheReader) readSuffixNodes(db *Database, offset, n uint32, suffix []rune) {
+	if len(suffix) > cacheMaxSuffixTreeLength {
+		r.err = errCacheCorrupted
+		return
+	}
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		node := offset + i*cacheSuffixNodeSize
+		char := r.u32(node)
+		if char == 0 {
+			// leaf node
+			mimeType := r.str(r.u32(node + 4))
+			weight := r.u32(node + 8)
+			pattern := make([]rune, 0, len(suffix)+1)
+			pattern = append(pattern, '*')
+			for j := len(suffix) - 1; j >= 0; j-- {
+				pattern = append(pattern, suffix[j])
+			}
+			db.addGlob(&glob{
+				pattern:       string(pattern),
+				mimeType:      mimeType,
+				weight:        int(weight & cacheWeightMask),
+				caseSensitive: weight&cacheFlagCaseSensitive != 0,
+			})
+			continue
+		}
+		nChildren := r.count(node+4, cacheSuffixNodeSize)
+		r.readSuffixNodes(db, r.u32(node+8), nChildren, append(suffix, rune(char)))
+	}
+}
+
+func (r *cacheReader) readMatchlets(offset, n uint32, depth int) []*matchlet {
+	if depth > cacheMaxSuffixTreeLength {
+		r.err = errCacheCorrupted
+		return nil
+	}
+	result := make([]*matchlet, 0, n)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		entry := offset + i*cacheMagicMatchletSize
+		m := &matchlet{
+			rangeStart:  int(r.u32(entry)),
+			rangeLength: int(r.u32(entry + 4)),
+		}
+		wordSize := int(r.u32(entry + 8))
+		valueLength := r.u32(entry + 12)
+		m.value = r.bytes(r.u32(entry+16), valueLength)
+		if maskOffset := r.u32(entry + 20); maskOffset != 0 {
+			m.mask = r.bytes(maskOffset, valueLength)
+		}
+		// host endian values are stored in big endian
+		if isLittleEndian && wordSize > 1 {
+			m.value = swapWords(m.value, wordSize)
+			m.mask = swapWords(m.mask, wordSize)
+		}
+		nChildren := r.count(entry+24, cacheMagicMatchletSize)
+		if nChildren > 0 {
+			m.children = r.readMatchlets(r.u32(entry+28), nChildren, depth+1)
+		}
+		result = append(result, m)
+	}
+	return result
+}
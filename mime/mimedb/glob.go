@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimedb
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+type glob struct {
+	pattern       string
+	mimeType      string
+	weight        int
+	caseSensitive bool
+}
+
+func isLiteralPattern(pattern string) bool {
+	return !strings.ContainsAny(pattern, "*?[")
+}
+
+// ex. "*.tar.gz" has the suffix ".tar.gz"
+func getPatternSuffix(pattern string) (string, bool) {
+	if len(pattern) < 2 || pattern[0] != '*' {
+		return "", false
+	}
+	suffix := pattern[1:]
+	if !isLiteralPattern(suffix) {
+		return "", false
+	}
+	return suffix, true
+}
+
+func globKey(s string, caseSensitive bool) string {
+	if caseSensitive {
+		return s
+	}
+	return strings.ToLower(s)
+}
+
+func (db *Database) addGlob(g *glob) {
+	if isLiteralPattern(g.pattern) {
+		key := globKey(g.pattern, g.caseSensitive)
+		db.literals[key] = append(db.literals[key], g)
+	} else if suffix, ok := getPatternSuffix(g.pattern); ok {
+		key := globKey(suffix, g.caseSensitive)
+		db.suffixes[key] = append(db.suffixes[key], g)
+	} else {
+		db.globs = append(db.globs, g)
+	}
+}
+
+func (db *Database) allGlobs() []*glob {
+	var result []*glob
+	for _, m := range []map[string][]*glob{db.literals, db.suffixes} {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, m[key]...)
+		}
+	}
+	return append(result, db.globs...)
+}
+
+type globMatches struct {
+	types         []string
+	weight        int
+	patternLength int
+}
+
+func (gm *globMatches) add(g *glob) {
+	switch {
+	case len(gm.types) == 0,
+		g.weight > gm.weight,
+		g.weight == gm.weight && len(g.pattern) > gm.patternLength:
+		gm.types = []string{g.mimeType}
+		gm.weight = g.weight
+		gm.patternLength = len(g.pattern)
+
+	case g.weight == gm.weight && len(g.pattern) == gm.patternLength:
+		for _, t := range gm.types {
+			if t == g.mimeType {
+				return
+			}
+		}
+		gm.types = append(gm.types, g.mimeType)
+	}
+}
+
+func lookupGlobMap(m map[string][]*glob, key string) []*glob {
+	var result []*glob
+	for _, g := range m[key] {
+		if g.caseSensitive {
+			result = append(result, g)
+		}
+	}
+	for _, g := range m[strings.ToLower(key)] {
+		if !g.caseSensitive {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+func (db *Database) matchGlobs(name string) []string {
+	// literal patterns are preferred over any other patterns
+	var gm globMatches
+	for _, g := range lookupGlobMap(db.literals, name) {
+		gm.add(g)
+	}
+	if len(gm.types) > 0 {
+		return gm.types
+	}
+
+	for i := range name {
+		for _, g := range lookupGlobMap(db.suffixes, name[i:]) {
+			gm.add(g)
+		}
+	}
+
+	lowerName := strings.ToLower(name)
+	for _, g := range db.globs {
+		var ok bool
+		if g.caseSensitive {
+			ok, _ = path.Match(g.pattern, name)
+		} else {
+			ok, _ = path.Match(strings.ToLower(g.pattern), lowerName)
+		}
+		if ok {
+			gm.add(g)
+		}
+	}
+	return gm.types
+}
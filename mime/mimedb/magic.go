@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimedb
+
+import (
+	"unsafe"
+)
+
+type magic struct {
+	priority  int
+	mimeType  string
+	matchlets []*matchlet
+}
+
+type matchlet struct {
+	rangeStart  int
+	rangeLength int
+	value       []byte
+	mask        []byte // nil or the same length as value
+	children    []*matchlet
+}
+
+var isLittleEndian = func() bool {
+	v := uint16(1)
+	return (*[2]byte)(unsafe.Pointer(&v))[0] == 1
+}()
+
+// the end of the data needed to check m and its children
+func (m *matchlet) extent() int {
+	n := m.rangeStart + m.rangeLength - 1 + len(m.value)
+	for _, child := range m.children {
+		if e := child.extent(); e > n {
+			n = e
+		}
+	}
+	return n
+}
+
+func (m *matchlet) matchAt(data []byte, offset int) bool {
+	if offset+len(m.value) > len(data) {
+		return false
+	}
+	for i, b := range m.value {
+		d := data[offset+i]
+		if m.mask != nil {
+			d &= m.mask[i]
+			b &= m.mask[i]
+		}
+		if d != b {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *matchlet) match(data []byte) bool {
+	rangeLength := m.rangeLength
+	if rangeLength < 1 {
+		rangeLength = 1
+	}
+	found := false
+	for offset := m.rangeStart; offset < m.rangeStart+rangeLength; offset++ {
+		if m.matchAt(data, offset) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if len(m.children) == 0 {
+		return true
+	}
+	for _, child := range m.children {
+		if child.match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mg *magic) match(data []byte) bool {
+	for _, m := range mg.matchlets {
+		if m.match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *Database) matchMagic(data []byte) *magic {
+	for _, mg := range db.magics {
+		if mg.match(data) {
+			return mg
+		}
+	}
+	return nil
+}
+
+func (db *Database) addMagic(mg *magic) {
+	db.magics = append(db.magics, mg)
+	for _, m := range mg.matchlets {
+		if e := m.extent(); e > db.maxExtent {
+			db.maxExtent = e
+		}
+	}
+}
+
+// swapWords converts the words of size wordSize in data between big endian
+// and little endian.
+func swapWords(data []byte, wordSize int) []byte {
+	if data == nil || wordSize <= 1 || len(data)%wordSize != 0 {
+		return data
+	}
+	result := make([]byte, len(data))
+	for i := 0; i < len(data); i += wordSize {
+		for j := 0; j < wordSize; j++ {
+			result[i+j] = data[i+wordSize-1-j]
+		}
+	}
+	return result
+}
@@ -0,0 +1,402 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package mimedb is a pure Go reader of the freedesktop shared-mime-info
+// database. It loads mime.cache or the packages/*.xml sources and detects
+// mime types by file name globs and magic rules.
+package mimedb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
+)
+
+const (
+	TypeTextPlain   = "text/plain"
+	TypeOctetStream = "application/octet-stream"
+	TypeZeroSize    = "application/x-zerosize"
+	TypeDirectory   = "inode/directory"
+
+	defaultGlobWeight    = 50
+	defaultMagicPriority = 50
+	defaultMaxExtent     = 4096
+)
+
+var ErrNoDatabase = errors.New("no shared mime info database found")
+
+type Database struct {
+	literals map[string][]*glob
+	suffixes map[string][]*glob
+	globs    []*glob
+	magics   []*magic
+
+	aliases      map[string]string
+	parents      map[string][]string
+	icons        map[string]string
+	genericIcons map[string]string
+	maxExtent    int
+
+	globDeleted  map[string]bool
+	magicDeleted map[string]bool
+}
+
+func newDatabase() *Database {
+	return &Database{
+		literals:     make(map[string][]*glob),
+		suffixes:     make(map[string][]*glob),
+		aliases:      make(map[string]string),
+		parents:      make(map[string][]string),
+		icons:        make(map[string]string),
+		genericIcons: make(map[string]string),
+		globDeleted:  make(map[string]bool),
+		magicDeleted: make(map[string]bool),
+	}
+}
+
+// GetMimeDirs returns the mime directories of the XDG data dirs, from the
+// highest priority to the lowest.
+func GetMimeDirs() []string {
+	var dirs []string
+	for _, dir := range basedir.GetDataDirs() {
+		dirs = append(dirs, filepath.Join(dir, "mime"))
+	}
+	return dirs
+}
+
+// Load loads the database from the mime directories of the XDG data dirs.
+func Load() (*Database, error) {
+	return LoadDirs(GetMimeDirs())
+}
+
+// LoadDirs loads the database from dirs, which are ordered from the highest
+// priority to the lowest. In each dir mime.cache is preferred, the
+// packages/*.xml sources are used if there is no usable cache.
+func LoadDirs(dirs []string) (*Database, error) {
+	db := newDatabase()
+	loaded := false
+	for _, dir := range dirs {
+		part, err := loadDir(dir)
+		if err != nil {
+			continue
+		}
+		db.merge(part)
+		loaded = true
+	}
+	if !loaded {
+		return nil, ErrNoDatabase
+	}
+	db.sortMagics()
+	return db, nil
+}
+
+func loadDir(dir string) (*Database, error) {
+	part, err := LoadCache(filepath.Join(dir, "mime.cache"))
+	if err == nil {
+		return part, nil
+	}
+	return LoadPackages(filepath.Join(dir, "packages"))
+}
+
+// LoadPackages loads all *.xml files in dir, such as /usr/share/mime/packages.
+func LoadPackages(dir string) (*Database, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, ErrNoDatabase
+	}
+	sort.Strings(files)
+
+	db := newDatabase()
+	for _, file := range files {
+		err = db.loadXMLFile(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	db.sortMagics()
+	return db, nil
+}
+
+// merge adds the entries of part, which has a lower priority than db.
+func (db *Database) merge(part *Database) {
+	for _, g := range part.allGlobs() {
+		if !db.globDeleted[g.mimeType] {
+			db.addGlob(g)
+		}
+	}
+	for _, m := range part.magics {
+		if !db.magicDeleted[m.mimeType] {
+			db.magics = append(db.magics, m)
+		}
+	}
+	if part.maxExtent > db.maxExtent {
+		db.maxExtent = part.maxExtent
+	}
+	for k, v := range part.aliases {
+		if _, ok := db.aliases[k]; !ok {
+			db.aliases[k] = v
+		}
+	}
+	for k, v := range part.parents {
+		if _, ok := db.parents[k]; !ok {
+			db.parents[k] = v
+		}
+	}
+	for k, v := range part.icons {
+		if _, ok := db.icons[k]; !ok {
+			db.icons[k] = v
+		}
+	}
+	for k, v := range part.genericIcons {
+		if _, ok := db.genericIcons[k]; !ok {
+			db.genericIcons[k] = v
+		}
+	}
+	for k := range part.globDeleted {
+		db.globDeleted[k] = true
+	}
+	for k := range part.magicDeleted {
+		db.magicDeleted[k] = true
+	}
+}
+
+func (db *Database) sortMagics() {
+	sort.SliceStable(db.magics, func(i, j int) bool {
+		return db.magics[i].priority > db.magics[j].priority
+	})
+}
+
+// Unalias returns the canonical name of mimeType.
+func (db *Database) Unalias(mimeType string) string {
+	if v, ok := db.aliases[mimeType]; ok {
+		return v
+	}
+	return mimeType
+}
+
+// GetAliases returns all aliases of mimeType.
+func (db *Database) GetAliases(mimeType string) []string {
+	mimeType = db.Unalias(mimeType)
+	var result []string
+	for alias, v := range db.aliases {
+		if v == mimeType {
+			result = append(result, alias)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// GetParents returns the direct parents of mimeType, including the implicit
+// text/plain and application/octet-stream parents.
+func (db *Database) GetParents(mimeType string) []string {
+	mimeType = db.Unalias(mimeType)
+	parents := append([]string(nil), db.parents[mimeType]...)
+	if len(parents) > 0 {
+		return parents
+	}
+	if strings.HasPrefix(mimeType, "text/") && mimeType != TypeTextPlain {
+		return []string{TypeTextPlain}
+	}
+	if !strings.HasPrefix(mimeType, "inode/") && mimeType != TypeOctetStream {
+		return []string{TypeOctetStream}
+	}
+	return nil
+}
+
+// IsSubclassOf reports whether mimeType equals base or is a descendant of it.
+// base may be a media wildcard like "image/*".
+func (db *Database) IsSubclassOf(mimeType, base string) bool {
+	return db.isSubclassOf(db.Unalias(mimeType), db.Unalias(base), make(map[string]bool))
+}
+
+func (db *Database) isSubclassOf(mimeType, base string, visited map[string]bool) bool {
+	if mimeType == base {
+		return true
+	}
+	if strings.HasSuffix(base, "/*") &&
+		strings.HasPrefix(mimeType, strings.TrimSuffix(base, "*")) {
+		return true
+	}
+	if visited[mimeType] {
+		return false
+	}
+	visited[mimeType] = true
+	for _, parent := range db.GetParents(mimeType) {
+		if db.isSubclassOf(db.Unalias(parent), base, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIcon returns the icon name of mimeType, ex. "text-x-go".
+func (db *Database) GetIcon(mimeType string) string {
+	mimeType = db.Unalias(mimeType)
+	if icon, ok := db.icons[mimeType]; ok {
+		return icon
+	}
+	return strings.Replace(mimeType, "/", "-", 1)
+}
+
+// GetGenericIcon returns the generic icon name of mimeType, ex. "text-x-generic".
+func (db *Database) GetGenericIcon(mimeType string) string {
+	mimeType = db.Unalias(mimeType)
+	if icon, ok := db.genericIcons[mimeType]; ok {
+		return icon
+	}
+	media := mimeType
+	if idx := strings.IndexByte(mimeType, '/'); idx != -1 {
+		media = mimeType[:idx]
+	}
+	return media + "-x-generic"
+}
+
+// GetMaxExtent returns how many bytes at the head of a file are needed to
+// check all magic rules.
+func (db *Database) GetMaxExtent() int {
+	if db.maxExtent <= 0 {
+		return defaultMaxExtent
+	}
+	return db.maxExtent
+}
+
+// QueryFileName returns the mime types matched by the globs of the
+// database, ordered by weight and pattern length.
+func (db *Database) QueryFileName(name string) []string {
+	return db.matchGlobs(filepath.Base(name))
+}
+
+// QueryData returns the mime type matched by the magic rules of the
+// database, or an empty string.
+func (db *Database) QueryData(data []byte) string {
+	m := db.matchMagic(data)
+	if m == nil {
+		return ""
+	}
+	return m.mimeType
+}
+
+// Query detects the mime type from both the file name and the file content.
+func (db *Database) Query(name string, data []byte) string {
+	globTypes := db.QueryFileName(name)
+	if len(globTypes) == 1 {
+		return globTypes[0]
+	}
+	return db.resolve(globTypes, data)
+}
+
+func (db *Database) resolve(globTypes []string, data []byte) string {
+	magicType := db.QueryData(data)
+	if len(globTypes) > 0 {
+		if magicType != "" {
+			for _, t := range globTypes {
+				if db.IsSubclassOf(magicType, t) {
+					return magicType
+				}
+			}
+		}
+		return globTypes[0]
+	}
+	if magicType != "" {
+		return magicType
+	}
+	if len(data) == 0 {
+		return TypeZeroSize
+	}
+	if looksLikeText(data) {
+		return TypeTextPlain
+	}
+	return TypeOctetStream
+}
+
+// QueryFile detects the mime type of file, the content is read only if the
+// file name is not enough.
+func (db *Database) QueryFile(file string) (string, error) {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	if t := inodeType(fi.Mode()); t != "" {
+		return t, nil
+	}
+
+	globTypes := db.QueryFileName(file)
+	if len(globTypes) == 1 {
+		return globTypes[0], nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(f, int64(db.GetMaxExtent())))
+	if err != nil {
+		return "", err
+	}
+	return db.resolve(globTypes, data), nil
+}
+
+func inodeType(mode os.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return TypeDirectory
+	case mode&os.ModeCharDevice != 0:
+		return "inode/chardevice"
+	case mode&os.ModeDevice != 0:
+		return "inode/blockdevice"
+	case mode&os.ModeNamedPipe != 0:
+		return "inode/fifo"
+	case mode&os.ModeSocket != 0:
+		return "inode/socket"
+	}
+	return ""
+}
+
+func looksLikeText(data []byte) bool {
+	// the last rune may be cut off
+	if len(data) > utf8.UTFMax {
+		for i := 0; i < utf8.UTFMax; i++ {
+			if utf8.Valid(data[:len(data)-i]) {
+				data = data[:len(data)-i]
+				break
+			}
+		}
+	}
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, b := range data {
+		if b < 0x20 {
+			switch b {
+			case '\t', '\n', '\r', '\f', '\b', 0x1b:
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type ParseError struct {
+	File string
+	Msg  string
+}
+
+func (err ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %q: %s", err.File, err.Msg)
+}
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimedb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestDatabases(t *testing.T) map[string]*Database {
+	cacheDb, err := LoadCache("testdata/cache/mime/mime.cache")
+	require.NoError(t, err)
+	xmlDb, err := LoadPackages("testdata/xml/mime/packages")
+	require.NoError(t, err)
+	return map[string]*Database{
+		"cache": cacheDb,
+		"xml":   xmlDb,
+	}
+}
+
+func TestQueryFileName(t *testing.T) {
+	var tests = []struct {
+		name   string
+		result []string
+	}{
+		{"a.txt", []string{"text/plain"}},
+		{"A.TXT", []string{"text/plain"}},
+		{"main.go", []string{"text/x-go"}},
+		{"main.c", []string{"text/x-csrc"}},
+		{"main.C", []string{"text/x-c++src"}},
+		{"main.CPP", []string{"text/x-c++src"}},
+		{"/usr/src/Makefile", []string{"text/x-makefile"}},
+		{"GNUmakefile", []string{"text/x-makefile"}},
+		{"gnumakefile", nil},
+		{"README.md", []string{"text/x-readme"}},
+		{"a.gz", []string{"application/gzip"}},
+		{"a.tar.gz", []string{"application/x-compressed-tar"}},
+		{"a.amb", []string{"application/x-ambiguous-one", "application/x-ambiguous-two"}},
+		{"unknown", nil},
+	}
+
+	for kind, db := range loadTestDatabases(t) {
+		for _, test := range tests {
+			result := db.QueryFileName(test.name)
+			assert.ElementsMatch(t, test.result, result, "%s: %s", kind, test.name)
+		}
+	}
+}
+
+func TestQueryData(t *testing.T) {
+	nested := []byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 'o', 'k', 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0}
+	nestedLittle := []byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0x02, 0x01}
+	nestedFail := []byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 0, 0,
+		0, 0, 'O', 'K', 0, 0, 0, 0, 0}
+
+	host := make([]byte, 2)
+	if isLittleEndian {
+		host[0], host[1] = 0x34, 0x12
+	} else {
+		host[0], host[1] = 0x12, 0x34
+	}
+
+	var tests = []struct {
+		data   []byte
+		result string
+	}{
+		{[]byte("\x89PNG\r\n"), "image/png"},
+		{[]byte{037, 0213, 8, 0}, "application/gzip"},
+		{nested, "application/x-test-nested"},
+		{nestedLittle, "application/x-test-nested"},
+		{nestedFail, ""},
+		{host, "application/x-test-host"},
+		{[]byte("hello"), ""},
+		{nil, ""},
+	}
+
+	for kind, db := range loadTestDatabases(t) {
+		for _, test := range tests {
+			assert.Equal(t, test.result, db.QueryData(test.data), "%s: %q", kind, test.data)
+		}
+		assert.True(t, db.GetMaxExtent() >= 18, kind)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var tests = []struct {
+		name   string
+		data   []byte
+		result string
+	}{
+		{"a.txt", []byte{0, 1, 2}, "text/plain"},
+		{"a.amb", []byte("AMB2"), "application/x-ambiguous-two"},
+		{"a.amb", []byte("AMB1"), "application/x-ambiguous-one"},
+		{"a.tar.gz", []byte{037, 0213}, "application/x-compressed-tar"},
+		{"image", []byte("\x89PNG"), "image/png"},
+		{"unknown", []byte("hello world\n"), TypeTextPlain},
+		{"unknown", []byte("你好"), TypeTextPlain},
+		{"unknown", []byte{0, 1, 2, 3}, TypeOctetStream},
+		{"unknown", nil, TypeZeroSize},
+	}
+
+	for kind, db := range loadTestDatabases(t) {
+		for _, test := range tests {
+			assert.Equal(t, test.result, db.Query(test.name, test.data), "%s: %s", kind, test.name)
+		}
+	}
+}
+
+func TestQueryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimedb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pngFile := filepath.Join(dir, "image")
+	err = ioutil.WriteFile(pngFile, []byte("\x89PNG\r\n\x1a\n"), 0644)
+	require.NoError(t, err)
+
+	for kind, db := range loadTestDatabases(t) {
+		result, err := db.QueryFile(pngFile)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", result, kind)
+
+		result, err = db.QueryFile(dir)
+		require.NoError(t, err)
+		assert.Equal(t, TypeDirectory, result, kind)
+
+		_, err = db.QueryFile(filepath.Join(dir, "not-exist"))
+		assert.Error(t, err)
+	}
+}
+
+func TestAliasAndSubclass(t *testing.T) {
+	for kind, db := range loadTestDatabases(t) {
+		assert.Equal(t, "application/gzip", db.Unalias("application/x-gzip"), kind)
+		assert.Equal(t, "text/x-csrc", db.Unalias("text/x-c"), kind)
+		assert.Equal(t, []string{"application/x-gzip"}, db.GetAliases("application/gzip"), kind)
+
+		assert.Equal(t, []string{"text/x-csrc"}, db.GetParents("text/x-c++src"), kind)
+		assert.Equal(t, []string{TypeTextPlain}, db.GetParents("text/x-unknown"), kind)
+		assert.Equal(t, []string{TypeOctetStream}, db.GetParents("image/png"), kind)
+		assert.Nil(t, db.GetParents(TypeDirectory), kind)
+
+		assert.True(t, db.IsSubclassOf("text/x-c++src", "text/x-c"), kind)
+		assert.True(t, db.IsSubclassOf("text/x-c++src", "text/plain"), kind)
+		assert.True(t, db.IsSubclassOf("application/x-compressed-tar", "application/x-gzip"), kind)
+		assert.True(t, db.IsSubclassOf("application/x-compressed-tar", TypeOctetStream), kind)
+		assert.True(t, db.IsSubclassOf("image/png", "image/*"), kind)
+		assert.False(t, db.IsSubclassOf("image/png", "text/plain"), kind)
+		assert.False(t, db.IsSubclassOf(TypeDirectory, TypeOctetStream), kind)
+	}
+}
+
+func TestIcons(t *testing.T) {
+	for kind, db := range loadTestDatabases(t) {
+		assert.Equal(t, "text-x-go", db.GetIcon("text/x-go"), kind)
+		assert.Equal(t, "text-x-readme-custom", db.GetIcon("text/x-readme"), kind)
+		assert.Equal(t, "text-x-generic", db.GetGenericIcon("text/x-go"), kind)
+		assert.Equal(t, "package-x-generic", db.GetGenericIcon("application/x-gzip"), kind)
+	}
+}
+
+func TestLoadDirs(t *testing.T) {
+	db, err := LoadDirs([]string{"testdata/not-exist", "testdata/cache/mime", "testdata/xml/mime"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"text/x-go"}, db.QueryFileName("a.go"))
+	assert.Equal(t, "image/png", db.QueryData([]byte("\x89PNG")))
+
+	_, err = LoadDirs([]string{"testdata/not-exist"})
+	assert.Equal(t, ErrNoDatabase, err)
+}
+
+func TestGlobDeleteAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimedb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	packagesDir := filepath.Join(dir, "packages")
+	require.NoError(t, os.Mkdir(packagesDir, 0755))
+	err = ioutil.WriteFile(filepath.Join(packagesDir, "override.xml"), []byte(`<?xml version="1.0"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+  <mime-type type="text/x-go">
+    <glob-deleteall/>
+    <glob pattern="*.golang"/>
+  </mime-type>
+</mime-info>`), 0644)
+	require.NoError(t, err)
+
+	db, err := LoadDirs([]string{dir, "testdata/xml/mime"})
+	require.NoError(t, err)
+	assert.Nil(t, db.QueryFileName("a.go"))
+	assert.Equal(t, []string{"text/x-go"}, db.QueryFileName("a.golang"))
+}
+
+func TestParseCacheCorrupted(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/cache/mime/mime.cache")
+	require.NoError(t, err)
+
+	for _, n := range []int{0, 10, cacheHeaderSize, len(data) / 2} {
+		_, err = ParseCache(data[:n])
+		assert.Error(t, err, n)
+	}
+}
+
+func TestUnescapeMatchString(t *testing.T) {
+	assert.Equal(t, []byte{037, 0213}, unescapeMatchString(`\037\213`))
+	assert.Equal(t, []byte("\x89PNG"), unescapeMatchString(`\x89PNG`))
+	assert.Equal(t, []byte("a\nb\\"), unescapeMatchString(`a\nb\\`))
+	assert.Equal(t, []byte(" "), unescapeMatchString(`\ `))
+}
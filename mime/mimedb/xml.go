@@ -0,0 +1,342 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mimedb
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type xmlMimeInfo struct {
+	MimeTypes []xmlMimeType `xml:"mime-type"`
+}
+
+type xmlMimeType struct {
+	Type           string      `xml:"type,attr"`
+	Globs          []xmlGlob   `xml:"glob"`
+	GlobDeleteAll  *struct{}   `xml:"glob-deleteall"`
+	Magics         []xmlMagic  `xml:"magic"`
+	MagicDeleteAll *struct{}   `xml:"magic-deleteall"`
+	Aliases        []xmlType   `xml:"alias"`
+	SubClassOf     []xmlType   `xml:"sub-class-of"`
+	Icon           *xmlIconRef `xml:"icon"`
+	GenericIcon    *xmlIconRef `xml:"generic-icon"`
+}
+
+type xmlGlob struct {
+	Pattern       string `xml:"pattern,attr"`
+	Weight        string `xml:"weight,attr"`
+	CaseSensitive string `xml:"case-sensitive,attr"`
+}
+
+type xmlMagic struct {
+	Priority string     `xml:"priority,attr"`
+	Matches  []xmlMatch `xml:"match"`
+}
+
+type xmlMatch struct {
+	Type    string     `xml:"type,attr"`
+	Offset  string     `xml:"offset,attr"`
+	Value   string     `xml:"value,attr"`
+	Mask    string     `xml:"mask,attr"`
+	Matches []xmlMatch `xml:"match"`
+}
+
+type xmlType struct {
+	Type string `xml:"type,attr"`
+}
+
+type xmlIconRef struct {
+	Name string `xml:"name,attr"`
+}
+
+func (db *Database) loadXMLFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = db.loadXML(f)
+	if err != nil {
+		return ParseError{File: file, Msg: err.Error()}
+	}
+	return nil
+}
+
+// ParsePackage parses a shared-mime-info XML source, such as
+// /usr/share/mime/packages/freedesktop.org.xml.
+func ParsePackage(r io.Reader) (*Database, error) {
+	db := newDatabase()
+	err := db.loadXML(r)
+	if err != nil {
+		return nil, err
+	}
+	db.sortMagics()
+	return db, nil
+}
+
+func (db *Database) loadXML(r io.Reader) error {
+	var info xmlMimeInfo
+	err := xml.NewDecoder(r).Decode(&info)
+	if err != nil {
+		return err
+	}
+
+	for _, mt := range info.MimeTypes {
+		if mt.Type == "" {
+			return fmt.Errorf("mime-type without type attribute")
+		}
+
+		if mt.GlobDeleteAll != nil {
+			db.deleteGlobs(mt.Type)
+			db.globDeleted[mt.Type] = true
+		}
+		for _, g := range mt.Globs {
+			weight := defaultGlobWeight
+			if g.Weight != "" {
+				weight, err = strconv.Atoi(g.Weight)
+				if err != nil {
+					return fmt.Errorf("invalid glob weight %q", g.Weight)
+				}
+			}
+			db.addGlob(&glob{
+				pattern:       g.Pattern,
+				mimeType:      mt.Type,
+				weight:        weight,
+				caseSensitive: g.CaseSensitive == "true",
+			})
+		}
+
+		if mt.MagicDeleteAll != nil {
+			db.deleteMagics(mt.Type)
+			db.magicDeleted[mt.Type] = true
+		}
+		for _, xm := range mt.Magics {
+			priority := defaultMagicPriority
+			if xm.Priority != "" {
+				priority, err = strconv.Atoi(xm.Priority)
+				if err != nil {
+					return fmt.Errorf("invalid magic priority %q", xm.Priority)
+				}
+			}
+			matchlets, err := parseXMLMatches(xm.Matches)
+			if err != nil {
+				return fmt.Errorf("invalid magic of %s: %v", mt.Type, err)
+			}
+			db.addMagic(&magic{
+				priority:  priority,
+				mimeType:  mt.Type,
+				matchlets: matchlets,
+			})
+		}
+
+		for _, alias := range mt.Aliases {
+			db.aliases[alias.Type] = mt.Type
+		}
+		for _, parent := range mt.SubClassOf {
+			db.parents[mt.Type] = append(db.parents[mt.Type], parent.Type)
+		}
+		if mt.Icon != nil {
+			db.icons[mt.Type] = mt.Icon.Name
+		}
+		if mt.GenericIcon != nil {
+			db.genericIcons[mt.Type] = mt.GenericIcon.Name
+		}
+	}
+	return nil
+}
+
+func (db *Database) deleteGlobs(mimeType string) {
+	filter := func(globs []*glob) []*glob {
+		result := globs[:0]
+		for _, g := range globs {
+			if g.mimeType != mimeType {
+				result = append(result, g)
+			}
+		}
+		return result
+	}
+	for k, v := range db.literals {
+		db.literals[k] = filter(v)
+	}
+	for k, v := range db.suffixes {
+		db.suffixes[k] = filter(v)
+	}
+	db.globs = filter(db.globs)
+}
+
+func (db *Database) deleteMagics(mimeType string) {
+	result := db.magics[:0]
+	for _, m := range db.magics {
+		if m.mimeType != mimeType {
+			result = append(result, m)
+		}
+	}
+	db.magics = result
+}
+
+func parseXMLMatches(matches []xmlMatch) ([]*matchlet, error) {
+	result := make([]*matchlet, 0, len(matches))
+	for _, xm := range matches {
+		m, err := parseXMLMatch(xm)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+func parseXMLMatch(xm xmlMatch) (*matchlet, error) {
+	m := &matchlet{}
+	var err error
+	m.rangeStart, m.rangeLength, err = parseMatchOffset(xm.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	switch xm.Type {
+	case "string":
+		m.value = unescapeMatchString(xm.Value)
+		if xm.Mask != "" {
+			m.mask, err = parseHexMask(xm.Mask, len(m.value))
+		}
+	case "byte", "big16", "big32", "little16", "little32", "host16", "host32":
+		m.value, err = parseMatchNumber(xm.Type, xm.Value)
+		if err == nil && xm.Mask != "" {
+			m.mask, err = parseMatchNumber(xm.Type, xm.Mask)
+		}
+	default:
+		err = fmt.Errorf("unsupported match type %q", xm.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.children, err = parseXMLMatches(xm.Matches)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ex. "4" or "0:64"
+func parseMatchOffset(offset string) (start, length int, err error) {
+	parts := strings.SplitN(offset, ":", 2)
+	start, err = strconv.Atoi(parts[0])
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid match offset %q", offset)
+	}
+	length = 1
+	if len(parts) == 2 {
+		end, err := strconv.Atoi(parts[1])
+		if err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid match offset %q", offset)
+		}
+		length = end - start + 1
+	}
+	return start, length, nil
+}
+
+func parseMatchNumber(typ, s string) ([]byte, error) {
+	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match value %q", s)
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if strings.HasPrefix(typ, "little") ||
+		(strings.HasPrefix(typ, "host") && isLittleEndian) {
+		order = binary.LittleEndian
+	}
+
+	switch typ {
+	case "byte":
+		if v > 0xff {
+			return nil, fmt.Errorf("match value %q out of range", s)
+		}
+		return []byte{byte(v)}, nil
+	case "big16", "little16", "host16":
+		if v > 0xffff {
+			return nil, fmt.Errorf("match value %q out of range", s)
+		}
+		buf := make([]byte, 2)
+		order.PutUint16(buf, uint16(v))
+		return buf, nil
+	default:
+		buf := make([]byte, 4)
+		order.PutUint32(buf, uint32(v))
+		return buf, nil
+	}
+}
+
+// ex. "0xffff00ff"
+func parseHexMask(s string, length int) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("invalid match mask %q", s)
+	}
+	mask, err := hex.DecodeString(s[2:])
+	if err != nil || len(mask) != length {
+		return nil, fmt.Errorf("invalid match mask %q", s)
+	}
+	return mask, nil
+}
+
+// unescapeMatchString handles the C style escape sequences in the value of
+// string matches.
+func unescapeMatchString(s string) []byte {
+	result := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			result = append(result, c)
+			continue
+		}
+		i++
+		c = s[i]
+		switch {
+		case c == 'n':
+			result = append(result, '\n')
+		case c == 'r':
+			result = append(result, '\r')
+		case c == 't':
+			result = append(result, '\t')
+		case c == 'x':
+			j := i + 1
+			for j < len(s) && j < i+3 && isHexDigit(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				result = append(result, 'x')
+				continue
+			}
+			v, _ := strconv.ParseUint(s[i+1:j], 16, 8)
+			result = append(result, byte(v))
+			i = j - 1
+		case c >= '0' && c <= '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			v, _ := strconv.ParseUint(s[i:j], 8, 16)
+			result = append(result, byte(v))
+			i = j - 1
+		default:
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
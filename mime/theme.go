@@ -5,86 +5,281 @@
 package mime
 
 import (
+	"bytes"
 	"fmt"
 	"path"
-	dutils "github.com/linuxdeepin/go-lib/utils"
-)
+	"strings"
+	"sync"
 
-const (
-	mimeTypeXCursor = "image/x-xcursor"
+	"github.com/linuxdeepin/go-lib/keyfile"
 )
 
-func queryThemeMime(file string) (string, error) {
-	gtk, _ := isGtkTheme(file)
-	if gtk {
-		return MimeTypeGtk, nil
-	}
+// ThemeDetector reports whether files is a theme of some kind.
+type ThemeDetector func(files ThemeFiles) bool
+
+// ThemeEntryMatcher reports whether the file name in the root directory of
+// a theme stands for the theme, so that Query detects the theme of the
+// directory for the file.
+type ThemeEntryMatcher func(name string) bool
+
+type themeDetectorInfo struct {
+	mimeType string
+	detect   ThemeDetector
+	// nil matches all the files
+	isEntry ThemeEntryMatcher
+}
 
-	icon, _ := isIconTheme(file)
-	if icon {
-		return MimeTypeIcon, nil
+var (
+	_themeDetectors = []themeDetectorInfo{
+		{MimeTypeGtk, detectGtkTheme, nil},
+		{MimeTypeIcon, detectIconTheme, isIndexTheme},
+		{MimeTypeCursor, detectCursorTheme, nil},
+		{MimeTypeSound, detectSoundTheme, isIndexTheme},
+		{MimeTypePlymouth, detectPlymouthTheme, isPlymouthFile},
+		{MimeTypeGrub, detectGrubTheme, isGrubThemeFile},
+		{MimeTypeFont, detectFontPackage, isFontFile},
 	}
+	_themeDetectorsMu sync.Mutex
+)
+
+// RegisterThemeDetector adds a detector for the theme type mimeType, the
+// detectors are tried in the order of registration after the builtin ones.
+// Query detects the theme with the detector for the files index.theme,
+// theme.txt and *.plymouth in the root directory of the theme.
+func RegisterThemeDetector(mimeType string, detector ThemeDetector) {
+	RegisterThemeDetectorWithEntries(mimeType, detector, isThemeDescriptor)
+}
+
+// RegisterThemeDetectorWithEntries adds a detector as RegisterThemeDetector,
+// Query detects the theme with the detector for the files matched by
+// isEntry in the root directory of the theme, nil matches all the files.
+func RegisterThemeDetectorWithEntries(mimeType string, detector ThemeDetector, isEntry ThemeEntryMatcher) {
+	_themeDetectorsMu.Lock()
+	_themeDetectors = append(_themeDetectors, themeDetectorInfo{mimeType, detector, isEntry})
+	_themeDetectorsMu.Unlock()
+}
 
-	cursor, _ := isCursorTheme(file)
-	if cursor {
-		return MimeTypeCursor, nil
+func getThemeDetectors() []themeDetectorInfo {
+	_themeDetectorsMu.Lock()
+	defer _themeDetectorsMu.Unlock()
+	return append([]themeDetectorInfo(nil), _themeDetectors...)
+}
+
+// QueryThemeFiles returns the theme type of files.
+func QueryThemeFiles(files ThemeFiles) (string, bool) {
+	for _, info := range getThemeDetectors() {
+		if info.detect(files) {
+			return info.mimeType, true
+		}
 	}
+	return "", false
+}
 
+// QueryThemeArchive returns the theme type of the tar, tar.gz or tgz
+// archive, such as a theme to be installed, without extracting it. The
+// theme is at the root of the archive or in a top level directory. The
+// archives too large to inspect are reported by ErrThemeArchiveTooLarge.
+//
+// file: ex "~/Downloads/Deepin.tar.gz"
+func QueryThemeArchive(file string) (string, error) {
+	if !isTarArchive(file) {
+		return "", fmt.Errorf("'%s' is not a tar archive", file)
+	}
+	candidates, err := loadArchiveThemeFiles(file)
+	if err != nil {
+		return "", err
+	}
+	for _, files := range candidates {
+		mime, ok := QueryThemeFiles(files)
+		if ok {
+			return mime, nil
+		}
+	}
 	return "", fmt.Errorf("The mime of '%s' not supported", file)
 }
 
 // file: ex "/usr/share/themes/Deepin/index.theme"
-func isGtkTheme(file string) (bool, error) {
+func queryThemeMime(file string) (string, error) {
+	name := path.Base(file)
+	var files ThemeFiles
+	for _, info := range getThemeDetectors() {
+		if info.isEntry != nil && !info.isEntry(name) {
+			continue
+		}
+		if files == nil {
+			files = NewDirThemeFiles(path.Dir(file))
+		}
+		if info.detect(files) {
+			return info.mimeType, nil
+		}
+	}
+	return "", fmt.Errorf("The mime of '%s' not supported", file)
+}
+
+func isIndexTheme(name string) bool {
+	return name == "index.theme"
+}
+
+func isPlymouthFile(name string) bool {
+	return strings.HasSuffix(name, ".plymouth")
+}
+
+func isGrubThemeFile(name string) bool {
+	return name == "theme.txt"
+}
+
+// the descriptor files stand for the themes of the detectors registered,
+// so that the other files in the theme dir keep their own mime types.
+func isThemeDescriptor(name string) bool {
+	return isIndexTheme(name) || isGrubThemeFile(name) || isPlymouthFile(name)
+}
+
+func loadThemeKeyFile(files ThemeFiles, name string) (*keyfile.KeyFile, error) {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	kf := keyfile.NewKeyFile()
+	err = kf.LoadFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	return kf, nil
+}
+
+func detectGtkTheme(files ThemeFiles) bool {
 	var conditions = []string{
 		"gtk-2.0",
 		"gtk-3.0",
 		"metacity-1",
 	}
-	dir := path.Dir(file)
-	return isFilesInDir(conditions, dir)
+	for _, name := range conditions {
+		if !files.Exists(name) {
+			return false
+		}
+	}
+	return true
 }
 
-// file: ex "/usr/share/icons/Deepin/index.theme"
-func isIconTheme(file string) (bool, error) {
-	kfile, err := dutils.NewKeyFileFromFile(file)
+func detectIconTheme(files ThemeFiles) bool {
+	kf, err := loadThemeKeyFile(files, "index.theme")
 	if err != nil {
-		return false, err
+		return false
 	}
-	defer kfile.Free()
+	_, err = kf.GetString("Icon Theme", "Directories")
+	return err == nil
+}
 
-	_, err = kfile.GetString("Icon Theme", "Directories")
+func detectCursorTheme(files ThemeFiles) bool {
+	data, err := files.ReadFile("cursors/left_ptr")
 	if err != nil {
-		return false, err
+		return false
 	}
+	// the magic of xcursor files
+	return bytes.HasPrefix(data, []byte("Xcur"))
+}
 
-	return true, nil
+// the same as sound_effect/theme.LoadTheme requires
+func detectSoundTheme(files ThemeFiles) bool {
+	kf, err := loadThemeKeyFile(files, "index.theme")
+	if err != nil {
+		return false
+	}
+	_, err = kf.GetSection("Sound Theme")
+	return err == nil
 }
 
-// file: ex "/usr/share/icons/Deepin/index.theme"
-func isCursorTheme(file string) (bool, error) {
-	parent := path.Dir(file)
-	tmp := path.Join(parent, "cursors", "left_ptr")
-	mime, err := doQueryFile(tmp)
+// ex. /usr/share/plymouth/themes/deepin-logo/deepin-logo.plymouth
+func detectPlymouthTheme(files ThemeFiles) bool {
+	for _, name := range files.List("") {
+		if !strings.HasSuffix(name, ".plymouth") {
+			continue
+		}
+		kf, err := loadThemeKeyFile(files, name)
+		if err != nil {
+			continue
+		}
+		_, err = kf.GetSection("Plymouth Theme")
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ex. /boot/grub/themes/deepin/theme.txt
+func detectGrubTheme(files ThemeFiles) bool {
+	data, err := files.ReadFile("theme.txt")
 	if err != nil {
-		return false, err
+		return false
 	}
+	for _, keyword := range []string{"boot_menu", "title-text", "desktop-image"} {
+		if bytes.Contains(data, []byte(keyword)) {
+			return true
+		}
+	}
+	return false
+}
 
-	if mime != mimeTypeXCursor {
-		return false, fmt.Errorf("Invalid xcursor file '%s'", tmp)
+var fontFileSuffixes = []string{".ttf", ".ttc", ".otf", ".otc", ".pfb", ".pcf", ".pcf.gz", ".woff", ".woff2"}
+
+func isFontFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range fontFileSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
 	}
-	return true, nil
+	return false
 }
 
-func isFilesInDir(files []string, dir string) (bool, error) {
-	if !dutils.IsDir(dir) {
-		return false, fmt.Errorf("'%s' not a dir", dir)
+// the documents allowed beside the fonts in a font package
+func isFontDocFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"license", "licence", "copying", "readme", "fonts."} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
 	}
+	return strings.HasSuffix(name, ".txt") || strings.HasSuffix(name, ".md")
+}
 
-	for _, file := range files {
-		tmp := path.Join(dir, file)
-		if !dutils.IsFileExist(tmp) {
-			return false, fmt.Errorf("Not found the file: '%s'", tmp)
+// a font package is a directory of the font files and their documents,
+// such as the license, without any subdirectory.
+func detectFontPackage(files ThemeFiles) bool {
+	hasFont := false
+	for _, name := range files.List("") {
+		if len(files.List(name)) > 0 {
+			return false
+		}
+		switch {
+		case isFontFile(name):
+			hasFont = true
+		case !isFontDocFile(name):
+			return false
 		}
 	}
+	return hasFont
+}
+
+// file: ex "/usr/share/themes/Deepin/index.theme"
+func isGtkTheme(file string) (bool, error) {
+	return isTheme(file, detectGtkTheme)
+}
+
+// file: ex "/usr/share/icons/Deepin/index.theme"
+func isIconTheme(file string) (bool, error) {
+	return isTheme(file, detectIconTheme)
+}
+
+// file: ex "/usr/share/icons/Deepin/index.theme"
+func isCursorTheme(file string) (bool, error) {
+	return isTheme(file, detectCursorTheme)
+}
+
+func isTheme(file string, detect ThemeDetector) (bool, error) {
+	if !detect(NewDirThemeFiles(path.Dir(file))) {
+		return false, fmt.Errorf("'%s' is not the expected theme", file)
+	}
 	return true, nil
 }
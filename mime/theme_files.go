@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package mime
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/linuxdeepin/go-lib/archive/utils"
+)
+
+const (
+	// the content of larger files is not needed to detect themes
+	maxThemeFileSize = 1 << 20
+	// the archives with too many entries or too large are not inspected
+	maxArchiveEntries = 10000
+	maxArchiveSize    = 256 << 20
+	// the max size of the contents kept while scanning an archive
+	maxArchiveContentSize = 8 << 20
+	maxSymlinkDepth       = 8
+)
+
+var (
+	// ErrThemeArchiveTooLarge is returned when the theme archive has too
+	// many entries or too many bytes to be inspected.
+	ErrThemeArchiveTooLarge = errors.New("theme archive is too large")
+
+	errThemeFileTooLarge = errors.New("theme file is too large")
+	errThemeFileNotRead  = errors.New("theme file is not read from the archive")
+)
+
+// ThemeFiles gives access to the files of a theme package, which is a
+// directory or an archive. Names are slash separated and relative to the root
+// directory of the theme. The files of an archive can be read only if they
+// are at most two levels below the root, such as cursors/left_ptr.
+type ThemeFiles interface {
+	// Exists reports whether the file or directory name exists.
+	Exists(name string) bool
+	// List returns the sorted names of the entries in the directory dir.
+	List(dir string) []string
+	// ReadFile returns the content of the file name.
+	ReadFile(name string) ([]byte, error)
+}
+
+type dirThemeFiles struct {
+	root string
+}
+
+// NewDirThemeFiles returns the ThemeFiles of an extracted theme in dir.
+func NewDirThemeFiles(dir string) ThemeFiles {
+	return dirThemeFiles{root: dir}
+}
+
+func (d dirThemeFiles) path(name string) string {
+	return filepath.Join(d.root, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (d dirThemeFiles) Exists(name string) bool {
+	_, err := os.Stat(d.path(name))
+	return err == nil
+}
+
+func (d dirThemeFiles) List(dir string) []string {
+	f, err := os.Open(d.path(dir))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	names, _ := f.Readdirnames(0)
+	sort.Strings(names)
+	return names
+}
+
+func (d dirThemeFiles) ReadFile(name string) ([]byte, error) {
+	f, err := os.Open(d.path(name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readThemeFile(f)
+}
+
+func readThemeFile(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxThemeFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxThemeFileSize {
+		return nil, errThemeFileTooLarge
+	}
+	return data, nil
+}
+
+// archiveThemeFiles is a theme in a tar archive, the content of the files
+// near the roots of the themes is read while the archive is scanned, so
+// that the archive is read only once.
+type archiveThemeFiles struct {
+	root     string
+	entries  map[string]*tar.Header
+	dirs     map[string][]string
+	contents map[string][]byte
+}
+
+func isTarArchive(file string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(file, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanArchiveName(name string) string {
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+// isNearThemeRoot reports whether the content of the archive entry name is
+// kept for the detectors, which read the files at most two levels below
+// the root of a theme, such as cursors/left_ptr, and the root of a theme
+// is the archive root or a top level directory.
+func isNearThemeRoot(name string) bool {
+	return strings.Count(name, "/") <= 2
+}
+
+// loadArchiveThemeFiles returns the ThemeFiles of the archive root and of
+// each top level directory, as themes are usually packed in a directory.
+func loadArchiveThemeFiles(archive string) ([]ThemeFiles, error) {
+	reader, closer, err := utils.OpenTarFile(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	entries := make(map[string]*tar.Header)
+	dirs := make(map[string][]string)
+	contents := make(map[string][]byte)
+	addEntry := func(name string, h *tar.Header) {
+		for name != "" && name != "." {
+			if _, ok := entries[name]; ok {
+				return
+			}
+			entries[name] = h
+			parent := path.Dir(name)
+			if parent == "." {
+				parent = ""
+			}
+			dirs[parent] = append(dirs[parent], path.Base(name))
+			// the parent dirs may have no entries of their own
+			name, h = parent, &tar.Header{Name: parent, Typeflag: tar.TypeDir}
+		}
+	}
+
+	count := 0
+	var size, contentSize int64
+	err = utils.TarReaderWalk(reader, func(h *tar.Header, r io.Reader) error {
+		count++
+		size += h.Size
+		if count > maxArchiveEntries || size > maxArchiveSize {
+			// the themes are not detected by a part of the entries
+			return ErrThemeArchiveTooLarge
+		}
+		name := cleanArchiveName(h.Name)
+		if name == "" {
+			return nil
+		}
+		addEntry(name, h)
+
+		if (h.Typeflag == tar.TypeReg || h.Typeflag == tar.TypeRegA) &&
+			isNearThemeRoot(name) && h.Size <= maxThemeFileSize &&
+			contentSize+h.Size <= maxArchiveContentSize {
+			data, err := readThemeFile(r)
+			if err != nil {
+				return err
+			}
+			contents[name] = data
+			contentSize += int64(len(data))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, names := range dirs {
+		sort.Strings(names)
+	}
+
+	result := []ThemeFiles{&archiveThemeFiles{entries: entries, dirs: dirs, contents: contents}}
+	for _, name := range dirs[""] {
+		if entries[name].Typeflag == tar.TypeDir {
+			result = append(result, &archiveThemeFiles{
+				root:     name,
+				entries:  entries,
+				dirs:     dirs,
+				contents: contents,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (a *archiveThemeFiles) path(name string) string {
+	return cleanArchiveName(path.Join(a.root, name))
+}
+
+func (a *archiveThemeFiles) Exists(name string) bool {
+	_, ok := a.entries[a.path(name)]
+	return ok
+}
+
+func (a *archiveThemeFiles) List(dir string) []string {
+	return a.dirs[a.path(dir)]
+}
+
+func (a *archiveThemeFiles) ReadFile(name string) ([]byte, error) {
+	name = a.path(name)
+	for i := 0; i < maxSymlinkDepth; i++ {
+		h, ok := a.entries[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		switch h.Typeflag {
+		case tar.TypeSymlink:
+			if path.IsAbs(h.Linkname) {
+				return nil, os.ErrNotExist
+			}
+			name = cleanArchiveName(path.Join(path.Dir(name), h.Linkname))
+		case tar.TypeLink:
+			name = cleanArchiveName(h.Linkname)
+		case tar.TypeReg, tar.TypeRegA:
+			data, ok := a.contents[name]
+			if !ok {
+				return nil, errThemeFileNotRead
+			}
+			return data, nil
+		default:
+			return nil, errors.New("not a regular file")
+		}
+	}
+	return nil, errors.New("too many levels of symbolic links")
+}
@@ -5,8 +5,15 @@
 package mime
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/linuxdeepin/go-lib/archive/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,3 +35,224 @@ func TestIsCursorTheme(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, ok, true)
 }
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+}
+
+func writeTestArchive(t *testing.T, file string, files map[string]string) {
+	fw, err := os.Create(file)
+	require.NoError(t, err)
+	defer fw.Close()
+
+	gw := gzip.NewWriter(fw)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		err = tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(content)),
+		})
+		require.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+}
+
+func TestQueryThemeMime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mime-theme")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var tests = []struct {
+		name  string
+		files map[string]string
+		file  string
+		mime  string
+	}{
+		{
+			name:  "sound",
+			files: map[string]string{"index.theme": "[Sound Theme]\nName=deepin\nDirectories=stereo\n"},
+			file:  "index.theme",
+			mime:  MimeTypeSound,
+		},
+		{
+			name:  "plymouth",
+			files: map[string]string{"deepin.plymouth": "[Plymouth Theme]\nName=Deepin\nModuleName=script\n"},
+			file:  "deepin.plymouth",
+			mime:  MimeTypePlymouth,
+		},
+		{
+			name:  "grub",
+			files: map[string]string{"theme.txt": "title-text: \"\"\n+ boot_menu {\n}\n"},
+			file:  "theme.txt",
+			mime:  MimeTypeGrub,
+		},
+		{
+			name:  "font",
+			files: map[string]string{"NotoSans.TTF": "font", "LICENSE": ""},
+			file:  "NotoSans.TTF",
+			mime:  MimeTypeFont,
+		},
+		{
+			// any file in the root directory of a gtk theme
+			name: "gtk",
+			files: map[string]string{"README": "", "gtk-2.0/gtkrc": "", "gtk-3.0/gtk.css": "",
+				"metacity-1/metacity-theme-3.xml": ""},
+			file: "README",
+			mime: MimeTypeGtk,
+		},
+	}
+
+	for _, test := range tests {
+		themeDir := filepath.Join(dir, test.name)
+		writeTestFiles(t, themeDir, test.files)
+		mime, err := queryThemeMime(filepath.Join(themeDir, test.file))
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.mime, mime, test.name)
+
+		// packed in a directory
+		archiveFiles := make(map[string]string)
+		for name, content := range test.files {
+			archiveFiles["./"+test.name+"/"+name] = content
+		}
+		archive := filepath.Join(dir, test.name+".tar.gz")
+		writeTestArchive(t, archive, archiveFiles)
+		mime, err = QueryThemeArchive(archive)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.mime, mime, test.name)
+		// the archives are inspected only on demand
+		_, err = queryThemeMime(archive)
+		assert.Error(t, err, test.name)
+	}
+
+	// other files in the theme dir are not themes
+	_, err = queryThemeMime("testdata/Deepin/gtk-2.0/gtkrc")
+	assert.Error(t, err)
+
+	archive := filepath.Join(dir, "other.tar.gz")
+	writeTestArchive(t, archive, map[string]string{"a/b.txt": "hello"})
+	_, err = QueryThemeArchive(archive)
+	assert.Error(t, err)
+	_, err = QueryThemeArchive("testdata/data.txt")
+	assert.Error(t, err)
+
+	// the theme in the first entries of the archive too large
+	files := map[string]string{"a/index.theme": "[Icon Theme]\nDirectories=apps\n"}
+	for i := 0; i < maxArchiveEntries; i++ {
+		files[fmt.Sprintf("a/apps/%d.png", i)] = ""
+	}
+	archive = filepath.Join(dir, "large.tar.gz")
+	writeTestArchive(t, archive, files)
+	_, err = QueryThemeArchive(archive)
+	assert.Equal(t, ErrThemeArchiveTooLarge, err)
+}
+
+func TestDetectFontPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mime-theme")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for i, test := range []struct {
+		files map[string]string
+		font  bool
+	}{
+		{map[string]string{"fonts/NotoSans.TTF": "font", "fonts/LICENSE": "", "fonts/README.md": ""}, true},
+		{map[string]string{"NotoSans.otf": "font", "fonts.dir": ""}, true},
+		{map[string]string{"fonts/LICENSE": ""}, false},
+		// the other files, such as a program packed with a font
+		{map[string]string{"app/NotoSans.ttf": "font", "app/run.sh": ""}, false},
+		{map[string]string{"app/NotoSans.ttf": "font", "app/lib/a.so": ""}, false},
+	} {
+		archive := filepath.Join(dir, fmt.Sprintf("%d.tar.gz", i))
+		writeTestArchive(t, archive, test.files)
+		mime, err := QueryThemeArchive(archive)
+		if test.font {
+			require.NoError(t, err, test.files)
+			assert.Equal(t, MimeTypeFont, mime, test.files)
+		} else {
+			assert.Error(t, err, test.files)
+		}
+	}
+}
+
+func TestQueryThemeArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mime-theme")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "Deepin.tar")
+	fw, err := os.Create(archive)
+	require.NoError(t, err)
+	tw := tar.NewWriter(fw)
+	err = utils.TarWriterCompressFiles(tw, []string{"testdata/Deepin"})
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, fw.Close())
+
+	mime, err := QueryThemeArchive(archive)
+	require.NoError(t, err)
+	assert.Equal(t, MimeTypeGtk, mime)
+
+	candidates, err := loadArchiveThemeFiles(archive)
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	files := candidates[1]
+	assert.True(t, files.Exists("cursors"))
+	assert.Equal(t, []string{"cursors", "gtk-2.0", "gtk-3.0", "index.theme", "metacity-1"}, files.List(""))
+	assert.True(t, detectIconTheme(files))
+	assert.True(t, detectCursorTheme(files))
+	_, err = files.ReadFile("not-exist")
+	assert.Error(t, err)
+
+	// the files deep in the theme are not read
+	archive = filepath.Join(dir, "deep.tar.gz")
+	writeTestArchive(t, archive, map[string]string{"a/b/c.txt": "c", "a/b/c/d.txt": "d"})
+	candidates, err = loadArchiveThemeFiles(archive)
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	data, err := candidates[1].ReadFile("b/c.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "c", string(data))
+	assert.True(t, candidates[1].Exists("b/c/d.txt"))
+	_, err = candidates[1].ReadFile("b/c/d.txt")
+	assert.Equal(t, errThemeFileNotRead, err)
+}
+
+func TestRegisterThemeDetector(t *testing.T) {
+	const mimeType = "application/x-test-theme"
+	RegisterThemeDetector(mimeType, func(files ThemeFiles) bool {
+		return files.Exists("test-theme.conf")
+	})
+
+	dir, err := ioutil.TempDir("", "mime-theme")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{"index.theme": "", "test-theme.conf": ""})
+	mime, ok := QueryThemeFiles(NewDirThemeFiles(dir))
+	assert.True(t, ok)
+	assert.Equal(t, mimeType, mime)
+	mime, err = queryThemeMime(filepath.Join(dir, "index.theme"))
+	require.NoError(t, err)
+	assert.Equal(t, mimeType, mime)
+	_, err = queryThemeMime(filepath.Join(dir, "test-theme.conf"))
+	assert.Error(t, err)
+
+	const entryMimeType = "application/x-test-entry-theme"
+	RegisterThemeDetectorWithEntries(entryMimeType, func(files ThemeFiles) bool {
+		return files.Exists("entry.conf")
+	}, func(name string) bool {
+		return name == "entry.conf"
+	})
+	writeTestFiles(t, dir, map[string]string{"entry.conf": ""})
+	mime, err = queryThemeMime(filepath.Join(dir, "entry.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, entryMimeType, mime)
+}
@@ -0,0 +1,34 @@
+// +build ignore
+
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"log"
+
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/notify"
+	"github.com/linuxdeepin/go-lib/notify/notifyserver"
+)
+
+func main() {
+	service, err := dbusutil.NewSessionService()
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := notifyserver.NewServer(service)
+	_ = s.Notified().On(func(n *notifyserver.Notification) {
+		log.Printf("notification %d from %s: %s %s", n.ID, n.AppName, n.Summary, n.Body)
+	})
+	_ = s.Closed().On(func(id uint32, reason notify.ClosedReason) {
+		log.Printf("notification %d closed: %s", id, reason)
+	})
+	err = s.Export()
+	if err != nil {
+		log.Fatal(err)
+	}
+	service.Wait()
+}
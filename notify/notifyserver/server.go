@@ -0,0 +1,452 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package notifyserver implements the notification server of the Desktop
+// Notifications Specification, org.freedesktop.Notifications, which stores
+// the notifications instead of showing them. It is for the tests of the
+// notification clients and the minimal sessions without a notification
+// daemon, the notifications are dismissed or their actions are invoked by
+// the Go API, as the user does.
+package notifyserver
+
+//go:generate dbusutil-gen em -type Server
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/event"
+	"github.com/linuxdeepin/go-lib/notify"
+	"github.com/linuxdeepin/go-lib/strv"
+)
+
+const (
+	dbusServiceName = "org.freedesktop.Notifications"
+	dbusPath        = "/org/freedesktop/Notifications"
+	dbusInterface   = dbusServiceName
+)
+
+// the capabilities of the specification
+const (
+	CapActions        = "actions"
+	CapActionIcons    = "action-icons"
+	CapBody           = "body"
+	CapBodyHyperlinks = "body-hyperlinks"
+	CapBodyImages     = "body-images"
+	CapBodyMarkup     = "body-markup"
+	CapIconMulti      = "icon-multi"
+	CapIconStatic     = "icon-static"
+	CapPersistence    = "persistence"
+	CapSound          = "sound"
+)
+
+var (
+	ErrNotFound         = errors.New("notification not found")
+	ErrActionNotFound   = errors.New("action not found")
+	ErrNotExported      = errors.New("server is not exported")
+	ErrAlreadyExported  = errors.New("server is already exported")
+	errInvalidActionLen = errors.New("actions are not in pairs")
+)
+
+// DefaultTimeout is the time to expire the notifications which leave it to
+// the server.
+const DefaultTimeout = 5 * time.Second
+
+// Action is an action of the notification.
+type Action struct {
+	Key, Label string
+}
+
+// Notification is a notification received by the server.
+type Notification struct {
+	ID      uint32
+	AppName string
+	AppIcon string
+	Summary string
+	Body    string
+	Actions []Action
+	Hints   map[string]dbus.Variant
+	// the timeout requested in milliseconds, see notify.ExpiresDefault
+	ExpireTimeout int32
+	// the unique name of the client, empty if it is added by the Go API
+	Sender string
+}
+
+// HasAction reports whether the notification has the action.
+func (n *Notification) HasAction(key string) bool {
+	for _, a := range n.Actions {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsResident reports whether the notification is not removed after its
+// action is invoked.
+func (n *Notification) IsResident() bool {
+	v, ok := n.Hints[notify.HintResident].Value().(bool)
+	return ok && v
+}
+
+func (n *Notification) clone() *Notification {
+	c := *n
+	c.Actions = append([]Action(nil), n.Actions...)
+	c.Hints = make(map[string]dbus.Variant, len(n.Hints))
+	for k, v := range n.Hints {
+		c.Hints[k] = v
+	}
+	return &c
+}
+
+type entry struct {
+	n     *Notification
+	timer *time.Timer
+}
+
+// Server is the notification server, its D-Bus methods are Notify,
+// CloseNotification, GetCapabilities and GetServerInformation, and the
+// others are the Go API.
+type Server struct {
+	service *dbusutil.Service
+
+	mu             sync.Mutex
+	info           notify.ServerInfo
+	caps           strv.Strv
+	defaultTimeout time.Duration
+	lastID         uint32
+	entries        map[uint32]*entry
+
+	// event (n *Notification), the notification is added or replaced
+	notified *event.Event
+	// event (id uint32, reason notify.ClosedReason)
+	closed *event.Event
+	// event (id uint32, actionKey string)
+	actionInvoked *event.Event
+
+	//nolint
+	signals *struct {
+		NotificationClosed struct {
+			ID     uint32
+			Reason uint32
+		}
+		ActionInvoked struct {
+			ID        uint32
+			ActionKey string
+		}
+	}
+}
+
+// NewServer returns a server with the actions and the body capabilities,
+// service may be nil if the server is used by the Go API only.
+func NewServer(service *dbusutil.Service) *Server {
+	return &Server{
+		service: service,
+		info: notify.ServerInfo{
+			Name:        "go-lib-notifyserver",
+			Vendor:      "deepin",
+			Version:     "1.0",
+			SpecVersion: "1.2",
+		},
+		caps:           strv.Strv{CapActions, CapBody},
+		defaultTimeout: DefaultTimeout,
+		entries:        make(map[uint32]*entry),
+		notified:       event.New(func(n *Notification) {}),
+		closed:         event.New(func(id uint32, reason notify.ClosedReason) {}),
+		actionInvoked:  event.New(func(id uint32, actionKey string) {}),
+	}
+}
+
+func (*Server) GetInterfaceName() string {
+	return dbusInterface
+}
+
+// Export exports the server to the bus of the service and owns the name
+// org.freedesktop.Notifications.
+func (s *Server) Export() error {
+	if s.service == nil {
+		return errors.New("no service")
+	}
+	if s.service.IsExported(s) {
+		return ErrAlreadyExported
+	}
+	err := s.service.Export(dbusPath, s)
+	if err != nil {
+		return err
+	}
+	err = s.service.RequestName(dbusServiceName)
+	if err != nil {
+		_ = s.service.StopExport(s)
+		return err
+	}
+	return nil
+}
+
+// StopExport releases the name and stops the exporting, the notifications
+// are kept.
+func (s *Server) StopExport() error {
+	if s.service == nil || !s.service.IsExported(s) {
+		return ErrNotExported
+	}
+	err := s.service.ReleaseName(dbusServiceName)
+	if err != nil {
+		return err
+	}
+	return s.service.StopExport(s)
+}
+
+// SetServerInfo sets the information returned by GetServerInformation.
+func (s *Server) SetServerInfo(info notify.ServerInfo) {
+	s.mu.Lock()
+	s.info = info
+	s.mu.Unlock()
+}
+
+// SetCapabilities sets the capabilities, the actions of the notifications
+// are dropped without CapActions, and the body is dropped without CapBody.
+func (s *Server) SetCapabilities(caps []string) {
+	s.mu.Lock()
+	s.caps = strv.Strv(append([]string(nil), caps...))
+	s.mu.Unlock()
+}
+
+// SetDefaultTimeout sets the time to expire the notifications which leave
+// it to the server, they never expire if it is not positive.
+func (s *Server) SetDefaultTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	s.defaultTimeout = timeout
+	s.mu.Unlock()
+}
+
+// Notified returns the event (n *Notification) which is triggered after a
+// notification is added or replaced.
+func (s *Server) Notified() *event.Event {
+	return s.notified
+}
+
+// Closed returns the event (id uint32, reason notify.ClosedReason) which
+// is triggered after a notification is closed.
+func (s *Server) Closed() *event.Event {
+	return s.closed
+}
+
+// ActionInvoked returns the event (id uint32, actionKey string) which
+// is triggered after an action is invoked.
+func (s *Server) ActionInvoked() *event.Event {
+	return s.actionInvoked
+}
+
+// Add adds the notification or replaces the one of replacesID as Notify,
+// and returns the ID of the notification.
+func (s *Server) Add(appName string, replacesID uint32, appIcon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, expireTimeout int32) (uint32, error) {
+	return s.add("", appName, replacesID, appIcon, summary, body, actions, hints, expireTimeout)
+}
+
+func (s *Server) add(sender, appName string, replacesID uint32, appIcon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, expireTimeout int32) (uint32, error) {
+	if len(actions)%2 != 0 {
+		return 0, errInvalidActionLen
+	}
+
+	n := &Notification{
+		AppName:       appName,
+		AppIcon:       appIcon,
+		Summary:       summary,
+		Body:          body,
+		Hints:         make(map[string]dbus.Variant, len(hints)),
+		ExpireTimeout: expireTimeout,
+		Sender:        sender,
+	}
+	for k, v := range hints {
+		n.Hints[k] = v
+	}
+
+	s.mu.Lock()
+	if s.caps.Contains(CapActions) {
+		for i := 0; i < len(actions); i += 2 {
+			n.Actions = append(n.Actions, Action{Key: actions[i], Label: actions[i+1]})
+		}
+	}
+	if !s.caps.Contains(CapBody) {
+		n.Body = ""
+	}
+
+	// the notification which does not exist is not replaced, a new one is
+	// added as the other servers do
+	if e, ok := s.entries[replacesID]; ok {
+		n.ID = replacesID
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+	} else {
+		s.lastID++
+		if s.lastID == 0 {
+			s.lastID++
+		}
+		n.ID = s.lastID
+	}
+	e := &entry{n: n}
+	timeout := time.Duration(expireTimeout) * time.Millisecond
+	if expireTimeout < 0 {
+		timeout = s.defaultTimeout
+	}
+	if timeout > 0 {
+		id := n.ID
+		e.timer = time.AfterFunc(timeout, func() {
+			s.closeEntry(id, e, notify.ClosedReasonExpired)
+		})
+	}
+	s.entries[n.ID] = e
+	s.mu.Unlock()
+
+	_ = s.notified.Trigger(n.clone())
+	return n.ID, nil
+}
+
+// closeEntry removes the notification and emits NotificationClosed, e is
+// the entry expected if it is not nil, so that the timer of the entry
+// replaced does not close the new one.
+func (s *Server) closeEntry(id uint32, e *entry, reason notify.ClosedReason) error {
+	s.mu.Lock()
+	cur, ok := s.entries[id]
+	if !ok || (e != nil && cur != e) {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	if cur.timer != nil {
+		cur.timer.Stop()
+	}
+	delete(s.entries, id)
+	s.mu.Unlock()
+
+	s.emit("NotificationClosed", id, uint32(reason))
+	_ = s.closed.Trigger(id, reason)
+	return nil
+}
+
+func (s *Server) emit(signalName string, values ...interface{}) {
+	if s.service == nil || !s.service.IsExported(s) {
+		return
+	}
+	_ = s.service.Emit(s, signalName, values...)
+}
+
+// Close closes the notification with the reason, it is for the other
+// reasons than dismissing and expiring, such as notify.ClosedReasonUnknown.
+func (s *Server) Close(id uint32, reason notify.ClosedReason) error {
+	return s.closeEntry(id, nil, reason)
+}
+
+// Dismiss closes the notification as the user dismisses it.
+func (s *Server) Dismiss(id uint32) error {
+	return s.closeEntry(id, nil, notify.ClosedReasonDismissedByUser)
+}
+
+// Expire closes the notification as it expires, without waiting for the
+// timeout.
+func (s *Server) Expire(id uint32) error {
+	return s.closeEntry(id, nil, notify.ClosedReasonExpired)
+}
+
+// InvokeAction invokes the action of the notification as the user clicks
+// it, and then the notification is dismissed unless it is resident.
+func (s *Server) InvokeAction(id uint32, actionKey string) error {
+	s.mu.Lock()
+	e, ok := s.entries[id]
+	if !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	if !e.n.HasAction(actionKey) {
+		s.mu.Unlock()
+		return ErrActionNotFound
+	}
+	resident := e.n.IsResident()
+	s.mu.Unlock()
+
+	s.emit("ActionInvoked", id, actionKey)
+	_ = s.actionInvoked.Trigger(id, actionKey)
+	if !resident {
+		return s.closeEntry(id, e, notify.ClosedReasonDismissedByUser)
+	}
+	return nil
+}
+
+// GetNotification returns the copy of the notification.
+func (s *Server) GetNotification(id uint32) (*Notification, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	if !ok {
+		return nil, false
+	}
+	return e.n.clone(), true
+}
+
+// Notifications returns the copies of the notifications sorted by the IDs.
+func (s *Server) Notifications() []*Notification {
+	s.mu.Lock()
+	result := make([]*Notification, 0, len(s.entries))
+	for _, e := range s.entries {
+		result = append(result, e.n.clone())
+	}
+	s.mu.Unlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Reset removes all the notifications without closing them, the IDs are
+// not reused.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	for id, e := range s.entries {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+		delete(s.entries, id)
+	}
+	s.mu.Unlock()
+}
+
+// Notify is the D-Bus method to add or replace a notification.
+func (s *Server) Notify(sender dbus.Sender, appName string, replacesID uint32, appIcon string,
+	summary string, body string, actions []string, hints map[string]dbus.Variant,
+	expireTimeout int32) (id uint32, busErr *dbus.Error) {
+	id, err := s.add(string(sender), appName, replacesID, appIcon, summary, body, actions, hints, expireTimeout)
+	if err != nil {
+		return 0, dbusutil.ToError(err)
+	}
+	return id, nil
+}
+
+// CloseNotification is the D-Bus method to close a notification.
+func (s *Server) CloseNotification(id uint32) *dbus.Error {
+	err := s.closeEntry(id, nil, notify.ClosedReasonCallCloseNotification)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	return nil
+}
+
+// GetCapabilities is the D-Bus method to get the capabilities.
+func (s *Server) GetCapabilities() (caps []string, busErr *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.caps...), nil
+}
+
+// GetServerInformation is the D-Bus method to get the information of the
+// server.
+func (s *Server) GetServerInformation() (name, vendor, version, specVersion string, busErr *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info.Name, s.info.Vendor, s.info.Version, s.info.SpecVersion, nil
+}
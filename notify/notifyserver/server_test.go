@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package notifyserver
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closedEvent struct {
+	id     uint32
+	reason notify.ClosedReason
+}
+
+func watchClosed(s *Server) chan closedEvent {
+	ch := make(chan closedEvent, 10)
+	_ = s.Closed().On(func(id uint32, reason notify.ClosedReason) {
+		ch <- closedEvent{id, reason}
+	})
+	return ch
+}
+
+func TestServerAdd(t *testing.T) {
+	s := NewServer(nil)
+	var notified []uint32
+	_ = s.Notified().On(func(n *Notification) {
+		notified = append(notified, n.ID)
+	})
+
+	hints := map[string]dbus.Variant{notify.HintUrgency: dbus.MakeVariant(byte(notify.UrgencyCritical))}
+	id1, err := s.Add("app", 0, "icon", "summary", "body", []string{"ok", "OK"}, hints, notify.ExpiresNever)
+	require.NoError(t, err)
+	id2, err := s.Add("app", 0, "", "summary 2", "", nil, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), id1)
+	assert.Equal(t, uint32(2), id2)
+	assert.Equal(t, []uint32{1, 2}, notified)
+
+	n, ok := s.GetNotification(id1)
+	require.True(t, ok)
+	assert.Equal(t, &Notification{
+		ID:            1,
+		AppName:       "app",
+		AppIcon:       "icon",
+		Summary:       "summary",
+		Body:          "body",
+		Actions:       []Action{{"ok", "OK"}},
+		Hints:         hints,
+		ExpireTimeout: notify.ExpiresNever,
+	}, n)
+	// the copy is returned
+	n.Hints[notify.HintResident] = dbus.MakeVariant(true)
+	n, _ = s.GetNotification(id1)
+	assert.False(t, n.IsResident())
+
+	list := s.Notifications()
+	require.Len(t, list, 2)
+	assert.Equal(t, id1, list[0].ID)
+	assert.Equal(t, id2, list[1].ID)
+
+	_, err = s.Add("app", 0, "", "summary", "", []string{"ok"}, nil, notify.ExpiresNever)
+	assert.Error(t, err)
+
+	s.Reset()
+	assert.Empty(t, s.Notifications())
+	id3, err := s.Add("app", 0, "", "summary", "", nil, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), id3)
+}
+
+func TestServerReplace(t *testing.T) {
+	s := NewServer(nil)
+	closed := watchClosed(s)
+	id, err := s.Add("app", 0, "", "progress 10%", "", nil, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+
+	replaced, err := s.Add("app", id, "", "progress 20%", "", nil, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	assert.Equal(t, id, replaced)
+	n, ok := s.GetNotification(id)
+	require.True(t, ok)
+	assert.Equal(t, "progress 20%", n.Summary)
+	assert.Len(t, s.Notifications(), 1)
+
+	// not existing
+	other, err := s.Add("app", 100, "", "other", "", nil, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	assert.NotEqual(t, uint32(100), other)
+	assert.Len(t, s.Notifications(), 2)
+
+	assert.Empty(t, closed)
+}
+
+func TestServerClose(t *testing.T) {
+	s := NewServer(nil)
+	closed := watchClosed(s)
+	for _, test := range []struct {
+		close  func(id uint32) error
+		reason notify.ClosedReason
+	}{
+		{s.Dismiss, notify.ClosedReasonDismissedByUser},
+		{s.Expire, notify.ClosedReasonExpired},
+		{func(id uint32) error {
+			return s.Close(id, notify.ClosedReasonUnknown)
+		}, notify.ClosedReasonUnknown},
+		{func(id uint32) error {
+			if err := s.CloseNotification(id); err != nil {
+				return err
+			}
+			return nil
+		}, notify.ClosedReasonCallCloseNotification},
+	} {
+		id, err := s.Add("app", 0, "", "summary", "", nil, nil, notify.ExpiresNever)
+		require.NoError(t, err)
+		require.NoError(t, test.close(id))
+		assert.Equal(t, closedEvent{id, test.reason}, <-closed)
+		_, ok := s.GetNotification(id)
+		assert.False(t, ok)
+
+		assert.Equal(t, ErrNotFound, s.Dismiss(id))
+	}
+	assert.NotNil(t, s.CloseNotification(100))
+}
+
+func TestServerInvokeAction(t *testing.T) {
+	s := NewServer(nil)
+	closed := watchClosed(s)
+	invoked := make(chan string, 10)
+	_ = s.ActionInvoked().On(func(id uint32, actionKey string) {
+		invoked <- fmt.Sprint(id, actionKey)
+	})
+
+	actions := []string{"default", "Open", "reply", "Reply"}
+	id, err := s.Add("app", 0, "", "summary", "", actions, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	assert.Equal(t, ErrActionNotFound, s.InvokeAction(id, "delete"))
+	require.NoError(t, s.InvokeAction(id, "reply"))
+	assert.Equal(t, fmt.Sprint(id, "reply"), <-invoked)
+	assert.Equal(t, closedEvent{id, notify.ClosedReasonDismissedByUser}, <-closed)
+	assert.Equal(t, ErrNotFound, s.InvokeAction(id, "reply"))
+
+	// the resident notification is kept
+	hints := map[string]dbus.Variant{notify.HintResident: dbus.MakeVariant(true)}
+	id, err = s.Add("app", 0, "", "summary", "", actions, hints, notify.ExpiresNever)
+	require.NoError(t, err)
+	require.NoError(t, s.InvokeAction(id, "default"))
+	require.NoError(t, s.InvokeAction(id, "default"))
+	assert.Len(t, invoked, 2)
+	assert.Empty(t, closed)
+	_, ok := s.GetNotification(id)
+	assert.True(t, ok)
+}
+
+func TestServerCapabilities(t *testing.T) {
+	s := NewServer(nil)
+	caps, busErr := s.GetCapabilities()
+	assert.Nil(t, busErr)
+	assert.Equal(t, []string{CapActions, CapBody}, caps)
+
+	s.SetCapabilities([]string{CapIconStatic})
+	caps, _ = s.GetCapabilities()
+	assert.Equal(t, []string{CapIconStatic}, caps)
+	id, err := s.Add("app", 0, "", "summary", "body", []string{"ok", "OK"}, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	n, _ := s.GetNotification(id)
+	assert.Empty(t, n.Body)
+	assert.Empty(t, n.Actions)
+	assert.Equal(t, ErrActionNotFound, s.InvokeAction(id, "ok"))
+
+	s.SetServerInfo(notify.ServerInfo{Name: "test", Vendor: "deepin", Version: "2.0", SpecVersion: "1.2"})
+	name, vendor, version, specVersion, busErr := s.GetServerInformation()
+	assert.Nil(t, busErr)
+	assert.Equal(t, []string{"test", "deepin", "2.0", "1.2"}, []string{name, vendor, version, specVersion})
+}
+
+func TestServerExpiry(t *testing.T) {
+	s := NewServer(nil)
+	s.SetDefaultTimeout(50 * time.Millisecond)
+	closed := watchClosed(s)
+
+	never, err := s.Add("app", 0, "", "never", "", nil, nil, notify.ExpiresNever)
+	require.NoError(t, err)
+	id, err := s.Add("app", 0, "", "10ms", "", nil, nil, 10*notify.ExpiresMillisecond)
+	require.NoError(t, err)
+	def, err := s.Add("app", 0, "", "default", "", nil, nil, notify.ExpiresDefault)
+	require.NoError(t, err)
+
+	assert.Equal(t, closedEvent{id, notify.ClosedReasonExpired}, <-closed)
+	assert.Equal(t, closedEvent{def, notify.ClosedReasonExpired}, <-closed)
+	_, ok := s.GetNotification(never)
+	assert.True(t, ok)
+
+	// the timer is restarted by the replacing
+	id, err = s.Add("app", 0, "", "200ms", "", nil, nil, 200*notify.ExpiresMillisecond)
+	require.NoError(t, err)
+	time.Sleep(120 * time.Millisecond)
+	_, err = s.Add("app", id, "", "200ms", "", nil, nil, 200*notify.ExpiresMillisecond)
+	require.NoError(t, err)
+	time.Sleep(120 * time.Millisecond)
+	_, ok = s.GetNotification(id)
+	assert.True(t, ok)
+	assert.Equal(t, closedEvent{id, notify.ClosedReasonExpired}, <-closed)
+}
+
+func isSessionBusExists() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		return true
+	}
+	_, err := os.Stat(fmt.Sprintf("/run/user/%d/bus", os.Getuid()))
+	return err == nil
+}
+
+func TestServerDBus(t *testing.T) {
+	if !isSessionBusExists() {
+		t.Skip()
+		return
+	}
+	service, err := dbusutil.NewSessionService()
+	require.NoError(t, err)
+	s := NewServer(service)
+	if err := s.Export(); err != nil {
+		// such as the notification daemon of the session
+		t.Skip(err)
+		return
+	}
+	defer func() {
+		assert.NoError(t, s.StopExport())
+	}()
+	assert.Equal(t, ErrAlreadyExported, s.Export())
+
+	require.True(t, notify.Init("notifyserver-test"))
+	defer notify.Destroy()
+	info, err := notify.GetServerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "go-lib-notifyserver", info.Name)
+
+	notified := make(chan *Notification, 1)
+	_ = s.Notified().On(func(n *Notification) {
+		notified <- n
+	})
+	actions := make(chan string, 1)
+	reasons := make(chan notify.ClosedReason, 1)
+	n := notify.NewNotification("summary", "body", "icon")
+	n.AddAction("open", "Open", func(_ *notify.Notification, action string) {
+		actions <- action
+	})
+	_ = n.Closed().On(func(_ *notify.Notification, reason notify.ClosedReason) {
+		reasons <- reason
+	})
+	require.NoError(t, n.Show())
+
+	received := <-notified
+	assert.Equal(t, "notifyserver-test", received.AppName)
+	assert.Equal(t, []Action{{"open", "Open"}}, received.Actions)
+	assert.NotEmpty(t, received.Sender)
+
+	require.NoError(t, s.InvokeAction(received.ID, "open"))
+	select {
+	case action := <-actions:
+		assert.Equal(t, "open", action)
+	case <-time.After(5 * time.Second):
+		t.Error("ActionInvoked is not received")
+	}
+	select {
+	case reason := <-reasons:
+		assert.Equal(t, notify.ClosedReason(notify.ClosedReasonDismissedByUser), reason)
+	case <-time.After(5 * time.Second):
+		t.Error("NotificationClosed is not received")
+	}
+}
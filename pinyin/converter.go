@@ -0,0 +1,278 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+
+	gopinyin "github.com/mozillazg/go-pinyin"
+)
+
+// the Han characters are segmented in blocks of this size at most when
+// reading from a stream
+const maxBufferedHans = 1024
+
+// Token is a piece of the converted text, either a Han character with its
+// reading, or a run of other characters whose Pinyin is empty.
+type Token struct {
+	Text   string
+	Pinyin string
+}
+
+// Converter converts the Han characters to Pinyin, the readings of the
+// polyphones are picked with the phrase dictionary, see AddPhrase.
+type Converter struct {
+	style Style
+}
+
+// NewConverter returns a converter which gives the readings in the style.
+func NewConverter(style Style) *Converter {
+	return &Converter{style: style}
+}
+
+// Convert returns the readings of s in the style, see Converter.Convert.
+func Convert(s string, style Style) []string {
+	return NewConverter(style).Convert(s)
+}
+
+// Readings returns all the readings of the Han character in the style,
+// the most common reading first, or nil if r has no reading.
+func Readings(r rune, style Style) []string {
+	var result []string
+	for _, syllable := range getReadings(r) {
+		reading := formatSyllable(syllable, style)
+		if !stringInSlice(result, reading) {
+			result = append(result, reading)
+		}
+	}
+	return result
+}
+
+// ReadingsInContext returns the readings of each character of s in the
+// style, as used for searching. A character of a phrase in the dictionary
+// has only the reading of the phrase, such as "hang" for 行 in "银行", the
+// other Han characters have all their readings as Readings gives, and the
+// other characters have none.
+func ReadingsInContext(s string, style Style) [][]string {
+	chars := []rune(s)
+	inPhrases := phraseReadings(chars)
+	result := make([][]string, len(chars))
+	for i, r := range chars {
+		if inPhrases[i] != "" {
+			result[i] = []string{formatSyllable(inPhrases[i], style)}
+		} else {
+			result[i] = Readings(r, style)
+		}
+	}
+	return result
+}
+
+func stringInSlice(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func getReadings(r rune) []string {
+	if value, ok := gopinyin.PinyinDict[int(r)]; ok {
+		return strings.Split(value, ",")
+	}
+	if unicode.Is(unicode.Han, r) {
+		value := getPinyinByHan(int64(r))
+		if value[0] != "" {
+			return value
+		}
+	}
+	return nil
+}
+
+// Convert returns the reading of each Han character of s, the runs of the
+// other characters are kept as is, such as "银行app" gives
+// ["yin", "hang", "app"] in StylePlain.
+func (c *Converter) Convert(s string) []string {
+	var result []string
+	c.Walk(s, func(token Token) bool {
+		if token.Pinyin != "" {
+			result = append(result, token.Pinyin)
+		} else {
+			result = append(result, token.Text)
+		}
+		return true
+	})
+	return result
+}
+
+// Join returns the readings of s joined by sep, see Convert.
+func (c *Converter) Join(s, sep string) string {
+	return strings.Join(c.Convert(s), sep)
+}
+
+// Walk calls fn for each token of s in order, until fn returns false.
+func (c *Converter) Walk(s string, fn func(Token) bool) {
+	_ = c.ConvertReader(strings.NewReader(s), fn)
+}
+
+// ConvertReader reads the text from r and calls fn for each token in
+// order, until fn returns false. The text is converted while being read,
+// so the memory used does not grow with the length of the text.
+func (c *Converter) ConvertReader(r io.Reader, fn func(Token) bool) error {
+	br, ok := r.(io.RuneReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	initPhrases()
+	var hans []rune
+	var others strings.Builder
+	stopped := false
+
+	flushOthers := func() {
+		if others.Len() > 0 {
+			stopped = !fn(Token{Text: others.String()})
+			others.Reset()
+		}
+	}
+	// keep is the number of the characters that are not converted yet
+	// because a phrase may continue after them
+	flushHans := func(keep int) {
+		readings, starts := segment(hans)
+		// never split a phrase, the characters are converted up to the
+		// start of the phrase which is not all before the kept ones
+		end := len(hans)
+		if keep > 0 {
+			end = 0
+			for _, start := range starts {
+				if start > len(hans)-keep {
+					break
+				}
+				end = start
+			}
+		}
+		i := 0
+		for ; i < end && !stopped; i++ {
+			reading := readings[i]
+			if reading != "" {
+				reading = formatSyllable(reading, c.style)
+			}
+			stopped = !fn(Token{Text: string(hans[i]), Pinyin: reading})
+		}
+		hans = append(hans[:0], hans[i:]...)
+	}
+
+	for !stopped {
+		ch, _, err := br.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if getReadings(ch) != nil {
+			flushOthers()
+			if stopped {
+				break
+			}
+			hans = append(hans, ch)
+			if len(hans) >= maxBufferedHans {
+				flushHans(getMaxPhraseLen())
+			}
+		} else {
+			if len(hans) > 0 {
+				flushHans(0)
+				if stopped {
+					break
+				}
+			}
+			others.WriteRune(ch)
+		}
+	}
+	if !stopped && len(hans) > 0 {
+		flushHans(0)
+	}
+	if !stopped {
+		flushOthers()
+	}
+	return nil
+}
+
+// segment splits the characters into the fewest phrases of the dictionary
+// and single characters, the longer phrase is preferred when there are
+// more than one way. It returns the reading of each character and the
+// start of each segment.
+func segment(hans []rune) ([]string, []int) {
+	phrasesMu.RLock()
+	defer phrasesMu.RUnlock()
+	phraseDict, maxLen := phrases, maxPhraseLen
+
+	n := len(hans)
+	// count[i] is the fewest segments of hans[i:], next[i] is where the
+	// first segment of hans[i:] ends
+	count := make([]int, n+1)
+	next := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		count[i] = count[i+1] + 1
+		next[i] = i + 1
+		for l := 2; l <= maxLen && i+l <= n; l++ {
+			if _, ok := phraseDict[string(hans[i:i+l])]; !ok {
+				continue
+			}
+			if count[i+l]+1 <= count[i] {
+				count[i] = count[i+l] + 1
+				next[i] = i + l
+			}
+		}
+	}
+
+	readings := make([]string, n)
+	var starts []int
+	for i := 0; i < n; i = next[i] {
+		starts = append(starts, i)
+		if next[i]-i > 1 {
+			copy(readings[i:], phraseDict[string(hans[i:next[i]])])
+			continue
+		}
+		if values := getReadings(hans[i]); values != nil {
+			readings[i] = values[0]
+		}
+	}
+	return readings, starts
+}
+
+// phraseReadings returns the reading of each character which is in a
+// phrase of the dictionary, the readings of the other characters are
+// empty.
+func phraseReadings(chars []rune) []string {
+	initPhrases()
+	result := make([]string, len(chars))
+	for i := 0; i < len(chars); {
+		if getReadings(chars[i]) == nil {
+			i++
+			continue
+		}
+		// the run of the Han characters is segmented as a whole
+		j := i + 1
+		for j < len(chars) && getReadings(chars[j]) != nil {
+			j++
+		}
+		readings, starts := segment(chars[i:j])
+		for k, start := range starts {
+			end := j - i
+			if k+1 < len(starts) {
+				end = starts[k+1]
+			}
+			if end-start > 1 {
+				copy(result[i+start:i+end], readings[start:end])
+			}
+		}
+		i = j
+	}
+	return result
+}
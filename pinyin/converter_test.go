@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	var tests = []struct {
+		hans   string
+		result []string
+	}{
+		{"银行", []string{"yín", "háng"}},
+		{"重庆", []string{"chóng", "qìng"}},
+		{"长城", []string{"cháng", "chéng"}},
+		{"行长", []string{"háng", "zhǎng"}},
+		{"中国银行", []string{"zhōng", "guó", "yín", "háng"}},
+		{"重命名文件", []string{"chóng", "mìng", "míng", "wén", "jiàn"}},
+		{"统信UOS系统", []string{"tǒng", "xìn", "UOS", "xì", "tǒng"}},
+		{"", nil},
+		{"abc 123", []string{"abc 123"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.result, Convert(test.hans, StyleToneMarks), test.hans)
+	}
+}
+
+func TestConvertStyles(t *testing.T) {
+	var tests = []struct {
+		style  Style
+		result []string
+	}{
+		{StylePlain, []string{"lv", "se", "zhong", "guo", "yin", "hang"}},
+		{StyleToneMarks, []string{"lǜ", "sè", "zhōng", "guó", "yín", "háng"}},
+		{StyleToneNumbers, []string{"lv4", "se4", "zhong1", "guo2", "yin2", "hang2"}},
+		{StyleInitials, []string{"l", "s", "zh", "g", "y", "h"}},
+		{StyleFirstLetter, []string{"l", "s", "z", "g", "y", "h"}},
+		{StyleBopomofo, []string{"ㄌㄩˋ", "ㄙㄜˋ", "ㄓㄨㄥ", "ㄍㄨㄛˊ", "ㄧㄣˊ", "ㄏㄤˊ"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.result, Convert("绿色中国银行", test.style))
+	}
+}
+
+func TestFormatSyllable(t *testing.T) {
+	var tests = []struct {
+		syllable string
+		style    Style
+		result   string
+	}{
+		{"de", StyleToneNumbers, "de"},
+		{"de", StyleBopomofo, "˙ㄉㄜ"},
+		{"lüè", StyleToneNumbers, "lve4"},
+		{"xué", StyleBopomofo, "ㄒㄩㄝˊ"},
+		{"yǔ", StyleBopomofo, "ㄩˇ"},
+		{"yī", StyleBopomofo, "ㄧ"},
+		{"wǒ", StyleBopomofo, "ㄨㄛˇ"},
+		{"liù", StyleBopomofo, "ㄌㄧㄡˋ"},
+		{"guì", StyleBopomofo, "ㄍㄨㄟˋ"},
+		{"lún", StyleBopomofo, "ㄌㄨㄣˊ"},
+		{"shì", StyleBopomofo, "ㄕˋ"},
+		{"ér", StyleBopomofo, "ㄦˊ"},
+		{"ń", StyleBopomofo, "ㄋˊ"},
+		{"ān", StyleInitials, "a"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.result, formatSyllable(test.syllable, test.style), test.syllable)
+	}
+}
+
+func TestReadings(t *testing.T) {
+	assert.Equal(t, []string{"xíng", "háng", "héng", "xìng", "hàng"}, Readings('行', StyleToneMarks))
+	assert.Equal(t, []string{"xing", "hang", "heng"}, Readings('行', StylePlain))
+	assert.Nil(t, Readings('a', StylePlain))
+}
+
+func TestReadingsInContext(t *testing.T) {
+	assert.Equal(t, [][]string{{"yin"}, {"hang"}, nil}, ReadingsInContext("银行a", StylePlain))
+	assert.Equal(t, [][]string{{"xing", "hang", "heng"}}, ReadingsInContext("行", StylePlain))
+	assert.Equal(t, [][]string{{"y"}, {"h"}, {"x", "h"}}, ReadingsInContext("银行行", StyleFirstLetter))
+}
+
+func TestConverterJoin(t *testing.T) {
+	c := NewConverter(StylePlain)
+	assert.Equal(t, "chong qing", c.Join("重庆", " "))
+	assert.Equal(t, "ying yong cheng xu", c.Join("应用程序", " "))
+}
+
+func TestConvertReader(t *testing.T) {
+	c := NewConverter(StylePlain)
+
+	// a phrase across the blocks
+	hans := strings.Repeat("中", maxBufferedHans-1) + "银行" + "app"
+	var tokens []Token
+	err := c.ConvertReader(strings.NewReader(hans), func(token Token) bool {
+		tokens = append(tokens, token)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, tokens, maxBufferedHans+2)
+	assert.Equal(t, Token{Text: "中", Pinyin: "zhong"}, tokens[0])
+	assert.Equal(t, Token{Text: "银", Pinyin: "yin"}, tokens[maxBufferedHans-1])
+	assert.Equal(t, Token{Text: "行", Pinyin: "hang"}, tokens[maxBufferedHans])
+	assert.Equal(t, Token{Text: "app"}, tokens[maxBufferedHans+1])
+
+	// stop walking
+	tokens = nil
+	c.Walk("银行卡", func(token Token) bool {
+		tokens = append(tokens, token)
+		return len(tokens) < 2
+	})
+	assert.Equal(t, []Token{{"银", "yin"}, {"行", "hang"}}, tokens)
+}
+
+func TestAddPhrase(t *testing.T) {
+	assert.Equal(t, []string{"xíng", "zhǎng"}, Convert("行掌", StyleToneMarks))
+	require.NoError(t, AddPhrase("行掌", "háng", "zhǎng"))
+	assert.Equal(t, []string{"háng", "zhǎng"}, Convert("行掌", StyleToneMarks))
+	assert.Equal(t, ErrInvalidPhrase, AddPhrase("行掌", "háng"))
+
+	err := LoadPhrases(strings.NewReader("# comment\n\n行伍: háng wǔ\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hang2", "wu3"}, Convert("行伍", StyleToneNumbers))
+
+	err = LoadPhrases(strings.NewReader("行伍 háng wǔ\n"))
+	assert.Equal(t, PhraseSyntaxError{Line: 1, Msg: "missing colon"}, err)
+	err = LoadPhrases(strings.NewReader("\n行伍: háng\n"))
+	assert.Equal(t, PhraseSyntaxError{Line: 2, Msg: ErrInvalidPhrase.Error()}, err)
+
+	assert.Error(t, LoadPhraseFile("testdata/not-exist"))
+}
+
+func TestAddPhraseConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = AddPhrase(fmt.Sprintf("行%c", rune(0x9f00+i*100+j)), "háng", "yī")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Equal(t, []string{"yín", "háng"}, Convert("银行", StyleToneMarks))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var ErrInvalidPhrase = errors.New("the number of readings does not match the phrase")
+
+// PhraseSyntaxError is returned when a line of a phrase file can not be
+// parsed.
+type PhraseSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (err PhraseSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Msg)
+}
+
+var (
+	phrasesMu sync.RWMutex
+	// the readings of the phrases, one syllable for each character
+	phrases      map[string][]string
+	maxPhraseLen int
+)
+
+// the bundled phrases are loaded on the first use, the phrases are read
+// and written with phrasesMu held
+func initPhrases() {
+	phrasesMu.RLock()
+	loaded := phrases != nil
+	phrasesMu.RUnlock()
+	if loaded {
+		return
+	}
+
+	phrasesMu.Lock()
+	defer phrasesMu.Unlock()
+	if phrases == nil {
+		phrases = make(map[string][]string)
+		err := loadPhrases(strings.NewReader(builtinPhrases))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// getMaxPhraseLen returns the number of the characters of the longest
+// phrase.
+func getMaxPhraseLen() int {
+	phrasesMu.RLock()
+	defer phrasesMu.RUnlock()
+	return maxPhraseLen
+}
+
+func addPhrase(phrase string, readings []string) error {
+	n := utf8.RuneCountInString(phrase)
+	if n != len(readings) || n == 0 {
+		return ErrInvalidPhrase
+	}
+	phrases[phrase] = readings
+	if n > maxPhraseLen {
+		maxPhraseLen = n
+	}
+	return nil
+}
+
+// AddPhrase adds the reading of a phrase to the dictionary, readings are
+// the syllables of the characters, with or without tone marks, such as
+// AddPhrase("银行", "yín", "háng").
+func AddPhrase(phrase string, readings ...string) error {
+	initPhrases()
+	phrasesMu.Lock()
+	defer phrasesMu.Unlock()
+	return addPhrase(phrase, append([]string(nil), readings...))
+}
+
+// LoadPhrases adds the phrases read from r to the dictionary. Each line is
+// a phrase and its readings separated by a colon, such as
+// "银行: yín háng", the lines starting with # are comments.
+func LoadPhrases(r io.Reader) error {
+	initPhrases()
+	phrasesMu.Lock()
+	defer phrasesMu.Unlock()
+	return loadPhrases(r)
+}
+
+// LoadPhraseFile adds the phrases of the file to the dictionary, see
+// LoadPhrases for the format.
+func LoadPhraseFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return LoadPhrases(f)
+}
+
+func loadPhrases(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.IndexByte(line, ':')
+		if idx == -1 {
+			return PhraseSyntaxError{Line: lineNum, Msg: "missing colon"}
+		}
+		phrase := strings.TrimSpace(line[:idx])
+		readings := strings.Fields(line[idx+1:])
+		if addPhrase(phrase, readings) != nil {
+			return PhraseSyntaxError{Line: lineNum, Msg: ErrInvalidPhrase.Error()}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin
+
+// builtinPhrases are the common words whose readings differ from the most
+// used readings of their characters.
+const builtinPhrases = `
+银行: yín háng
+行业: háng yè
+行情: háng qíng
+行列: háng liè
+行家: háng jia
+行长: háng zhǎng
+同行: tóng háng
+内行: nèi háng
+外行: wài háng
+排行: pái háng
+排行榜: pái háng bǎng
+行李: xíng li
+重庆: chóng qìng
+重新: chóng xīn
+重复: chóng fù
+重叠: chóng dié
+重阳: chóng yáng
+重组: chóng zǔ
+重建: chóng jiàn
+重启: chóng qǐ
+重置: chóng zhì
+重装: chóng zhuāng
+重命名: chóng mìng míng
+重播: chóng bō
+重试: chóng shì
+重写: chóng xiě
+重连: chóng lián
+重做: chóng zuò
+长城: cháng chéng
+长度: cháng dù
+长期: cháng qī
+长久: cháng jiǔ
+长江: cháng jiāng
+长安: cháng ān
+长沙: cháng shā
+长春: cháng chūn
+长短: cháng duǎn
+长途: cháng tú
+长按: cháng àn
+长处: cháng chu
+擅长: shàn cháng
+延长: yán cháng
+特长: tè cháng
+音乐: yīn yuè
+乐器: yuè qì
+乐队: yuè duì
+乐曲: yuè qǔ
+乐谱: yuè pǔ
+归还: guī huán
+还款: huán kuǎn
+还原: huán yuán
+偿还: cháng huán
+睡觉: shuì jiào
+午觉: wǔ jiào
+首都: shǒu dū
+都市: dū shì
+成都: chéng dū
+京都: jīng dū
+觉得: jué de
+记得: jì de
+懂得: dǒng de
+值得: zhí de
+舍不得: shě bu de
+头发: tóu fa
+理发: lǐ fà
+白发: bái fà
+爱好: ài hào
+好奇: hào qí
+好学: hào xué
+好处: hǎo chu
+处理: chǔ lǐ
+处理器: chǔ lǐ qì
+处分: chǔ fèn
+处罚: chǔ fá
+相处: xiāng chǔ
+便宜: pián yi
+出差: chū chāi
+差别: chā bié
+差异: chā yì
+差距: chā jù
+误差: wù chā
+偏差: piān chā
+参差: cēn cī
+人参: rén shēn
+调整: tiáo zhěng
+调节: tiáo jié
+调试: tiáo shì
+调和: tiáo hé
+调皮: tiáo pí
+空调: kōng tiáo
+协调: xié tiáo
+传记: zhuàn jì
+自传: zì zhuàn
+朝阳: zhāo yáng
+朝气: zhāo qì
+朝鲜: cháo xiǎn
+西藏: xī zàng
+宝藏: bǎo zàng
+藏族: zàng zú
+勉强: miǎn qiǎng
+强迫: qiǎng pò
+倔强: jué jiàng
+角色: jué sè
+主角: zhǔ jué
+配角: pèi jué
+淹没: yān mò
+沉没: chén mò
+没收: mò shōu
+尽力: jìn lì
+尽快: jǐn kuài
+尽量: jǐn liàng
+大将: dà jiàng
+灾难: zāi nàn
+难民: nàn mín
+遇难: yù nàn
+相似: xiāng sì
+似乎: sì hū
+类似: lèi sì
+近似: jìn sì
+似的: shì de
+照相: zhào xiàng
+照相机: zhào xiàng jī
+相机: xiàng jī
+相片: xiàng piàn
+相册: xiàng cè
+首相: shǒu xiàng
+真相: zhēn xiàng
+相声: xiàng sheng
+供奉: gòng fèng
+口供: kǒu gòng
+挣扎: zhēng zhá
+扎实: zhā shi
+作坊: zuō fang
+丢三落四: diū sān là sì
+率领: shuài lǐng
+草率: cǎo shuài
+坦率: tǎn shuài
+几乎: jī hū
+茶几: chá jī
+供给: gōng jǐ
+给予: jǐ yǔ
+自给自足: zì jǐ zì zú
+模样: mú yàng
+模具: mú jù
+投降: tóu xiáng
+放假: fàng jià
+假期: jià qī
+请假: qǐng jià
+暑假: shǔ jià
+寒假: hán jià
+更新: gēng xīn
+更换: gēng huàn
+更改: gēng gǎi
+变更: biàn gēng
+游说: yóu shuì
+削皮: xiāo pí
+剥削: bō xuē
+会计: kuài jì
+成为: chéng wéi
+作为: zuò wéi
+认为: rèn wéi
+行为: xíng wéi
+以为: yǐ wéi
+为难: wéi nán
+少年: shào nián
+少女: shào nǚ
+种植: zhòng zhí
+种地: zhòng dì
+种田: zhòng tián
+教书: jiāo shū
+干净: gān jìng
+干燥: gān zào
+饼干: bǐng gān
+干杯: gān bēi
+一只: yī zhī
+当作: dàng zuò
+上当: shàng dàng
+恰当: qià dàng
+适当: shì dàng
+要求: yāo qiú
+看守: kān shǒu
+空闲: kòng xián
+空白: kòng bái
+空格: kòng gé
+填空: tián kòng
+测量: cè liáng
+成分: chéng fèn
+部分: bù fen
+过分: guò fèn
+正月: zhēng yuè
+应用: yìng yòng
+应用程序: yìng yòng chéng xù
+答应: dā ying
+反应: fǎn yìng
+响应: xiǎng yìng
+适应: shì yìng
+背包: bēi bāo
+背负: bēi fù
+单于: chán yú
+了解: liǎo jiě
+了不起: liǎo bu qǐ
+目的: mù dì
+的确: dí què
+的士: dí shì
+着急: zháo jí
+着火: zháo huǒ
+睡着: shuì zháo
+着陆: zhuó lù
+着手: zhuó shǒu
+着想: zhuó xiǎng
+大夫: dài fu
+厦门: xià mén
+薄荷: bò he
+暖和: nuǎn huo
+附和: fù hè
+地方: dì fang
+中奖: zhòng jiǎng
+命中: mìng zhòng
+中毒: zhòng dú
+关系: guān xi
+系鞋带: jì xié dài
+宿舍: sù shè
+星宿: xīng xiù
+校对: jiào duì
+关卡: guān qiǎ
+仔细: zǐ xì
+转载: zhuǎn zǎi
+记载: jì zǎi
+旋转: xuán zhuàn
+转动: zhuàn dòng
+转速: zhuàn sù
+蔓延: màn yán
+`
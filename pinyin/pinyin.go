@@ -10,14 +10,26 @@ import (
 	"unicode"
 )
 
+// HansToPinyin returns all the combinations of the readings of hans, the
+// characters of a phrase in the dictionary have only the reading of the
+// phrase, such as "yinhang" for "银行".
 func HansToPinyin(hans string) []string {
 	return getPinyinFromKey(hans)
 }
 
 func getPinyinFromKey(key string) []string {
 	rets := []string{}
-	for _, c := range key {
-		if unicode.Is(unicode.Scripts["Han"], c) {
+	chars := []rune(key)
+	inPhrases := phraseReadings(chars)
+	for i, c := range chars {
+		if inPhrases[i] != "" {
+			array := []string{formatSyllable(inPhrases[i], StylePlain)}
+			if len(rets) == 0 {
+				rets = array
+				continue
+			}
+			rets = rangeArray(rets, array)
+		} else if unicode.Is(unicode.Scripts["Han"], c) {
 			array := getPinyinByHan(int64(c))
 			if len(rets) == 0 {
 				rets = array
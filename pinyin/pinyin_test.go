@@ -18,4 +18,6 @@ func Test_HansToPinyin(t *testing.T) {
 	assert.Len(t, pinyinMap, 2)
 	assert.Equal(t, pinyinMap[0], "tongxinruanjian")
 	assert.Equal(t, pinyinMap[1], "tongshenruanjian")
+
+	assert.Equal(t, []string{"zhongguoyinhang"}, HansToPinyin("中国银行"))
 }
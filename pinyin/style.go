@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Style is the output style of the readings.
+type Style int
+
+const (
+	// StylePlain is without tones, ü is written as v, such as "lv".
+	StylePlain Style = iota
+	// StyleToneMarks has the tone marks, such as "lǜ".
+	StyleToneMarks
+	// StyleToneNumbers has the tone numbers after the syllables, such as
+	// "lv4", the neutral tone has no number.
+	StyleToneNumbers
+	// StyleInitials is the initial consonant, such as "zh" for "zhong",
+	// the syllables without initial consonants give their first letters,
+	// such as "y" for "yin" and "a" for "an".
+	StyleInitials
+	// StyleFirstLetter is the first letter, such as "z" for "zhong".
+	StyleFirstLetter
+	// StyleBopomofo is the zhuyin symbols, such as "ㄓㄨㄥ".
+	StyleBopomofo
+)
+
+// the vowels with the tone marks and their tones
+var toneMarks = map[rune]struct {
+	base rune
+	tone int
+}{
+	'ā': {'a', 1}, 'á': {'a', 2}, 'ǎ': {'a', 3}, 'à': {'a', 4},
+	'ē': {'e', 1}, 'é': {'e', 2}, 'ě': {'e', 3}, 'è': {'e', 4},
+	'ī': {'i', 1}, 'í': {'i', 2}, 'ǐ': {'i', 3}, 'ì': {'i', 4},
+	'ō': {'o', 1}, 'ó': {'o', 2}, 'ǒ': {'o', 3}, 'ò': {'o', 4},
+	'ū': {'u', 1}, 'ú': {'u', 2}, 'ǔ': {'u', 3}, 'ù': {'u', 4},
+	'ǖ': {'ü', 1}, 'ǘ': {'ü', 2}, 'ǚ': {'ü', 3}, 'ǜ': {'ü', 4},
+	'ế': {'ê', 2}, 'ề': {'ê', 4},
+	'ḿ': {'m', 2},
+	'ń': {'n', 2}, 'ň': {'n', 3}, 'ǹ': {'n', 4},
+}
+
+// the combining tone marks
+var combiningToneMarks = map[rune]int{
+	'̄': 1,
+	'́': 2,
+	'̌': 3,
+	'̀': 4,
+}
+
+// parseSyllable splits a syllable with the tone mark, such as "lǜ", into
+// the syllable without the tone, "lü", and the tone, 4. The tone is 0 for
+// the neutral tone. The v of the toneless syllables is read as ü.
+func parseSyllable(syllable string) (string, int) {
+	var sb strings.Builder
+	tone := 0
+	for _, r := range strings.ToLower(syllable) {
+		if mark, ok := toneMarks[r]; ok {
+			sb.WriteRune(mark.base)
+			tone = mark.tone
+		} else if t, ok := combiningToneMarks[r]; ok {
+			tone = t
+		} else if r == 'v' {
+			sb.WriteRune('ü')
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), tone
+}
+
+func plainSyllable(base string) string {
+	return strings.NewReplacer("ü", "v", "ê", "e").Replace(base)
+}
+
+var initialConsonants = []string{"zh", "ch", "sh",
+	"b", "p", "m", "f", "d", "t", "n", "l", "g", "k", "h", "j", "q", "x", "r", "z", "c", "s"}
+
+func getInitial(base string) string {
+	for _, initial := range initialConsonants {
+		if strings.HasPrefix(base, initial) {
+			return initial
+		}
+	}
+	return ""
+}
+
+// formatSyllable formats the syllable with the tone mark in the style.
+func formatSyllable(syllable string, style Style) string {
+	if style == StyleToneMarks {
+		return syllable
+	}
+	base, tone := parseSyllable(syllable)
+	plain := plainSyllable(base)
+	switch style {
+	case StyleToneNumbers:
+		if tone != 0 {
+			return plain + strconv.Itoa(tone)
+		}
+		return plain
+	case StyleInitials:
+		if initial := getInitial(base); initial != "" {
+			return initial
+		}
+		return plain[:1]
+	case StyleFirstLetter:
+		return plain[:1]
+	case StyleBopomofo:
+		if s, ok := toBopomofo(base, tone); ok {
+			return s
+		}
+		return plain
+	}
+	return plain
+}
+
+var bopomofoInitials = map[string]string{
+	"b": "ㄅ", "p": "ㄆ", "m": "ㄇ", "f": "ㄈ",
+	"d": "ㄉ", "t": "ㄊ", "n": "ㄋ", "l": "ㄌ",
+	"g": "ㄍ", "k": "ㄎ", "h": "ㄏ",
+	"j": "ㄐ", "q": "ㄑ", "x": "ㄒ",
+	"zh": "ㄓ", "ch": "ㄔ", "sh": "ㄕ", "r": "ㄖ",
+	"z": "ㄗ", "c": "ㄘ", "s": "ㄙ",
+}
+
+var bopomofoFinals = map[string]string{
+	"":  "",
+	"a": "ㄚ", "o": "ㄛ", "e": "ㄜ", "ê": "ㄝ",
+	"ai": "ㄞ", "ei": "ㄟ", "ao": "ㄠ", "ou": "ㄡ",
+	"an": "ㄢ", "en": "ㄣ", "ang": "ㄤ", "eng": "ㄥ", "ong": "ㄨㄥ", "er": "ㄦ",
+	"i": "ㄧ", "ia": "ㄧㄚ", "io": "ㄧㄛ", "ie": "ㄧㄝ", "iai": "ㄧㄞ", "iao": "ㄧㄠ", "iou": "ㄧㄡ",
+	"ian": "ㄧㄢ", "in": "ㄧㄣ", "iang": "ㄧㄤ", "ing": "ㄧㄥ", "iong": "ㄩㄥ",
+	"u": "ㄨ", "ua": "ㄨㄚ", "uo": "ㄨㄛ", "uai": "ㄨㄞ", "uei": "ㄨㄟ",
+	"uan": "ㄨㄢ", "uen": "ㄨㄣ", "uang": "ㄨㄤ", "ueng": "ㄨㄥ",
+	"ü": "ㄩ", "üe": "ㄩㄝ", "üan": "ㄩㄢ", "ün": "ㄩㄣ",
+}
+
+// the syllables which are not an initial and a final
+var bopomofoSyllables = map[string]string{
+	"m": "ㄇ", "n": "ㄋ", "ng": "ㄫ", "hm": "ㄏㄇ", "hng": "ㄏㄫ",
+}
+
+var bopomofoTones = []string{"", "", "ˊ", "ˇ", "ˋ"}
+
+// toBopomofo converts the syllable without the tone, such as "zhong", to
+// the zhuyin symbols.
+func toBopomofo(base string, tone int) (string, bool) {
+	symbols, ok := bopomofoSyllables[base]
+	if !ok {
+		initial := getInitial(base)
+		final := base[len(initial):]
+		if initial == "" {
+			// y and w are written for the finals without initials
+			switch {
+			case strings.HasPrefix(final, "yu"):
+				final = "ü" + final[2:]
+			case strings.HasPrefix(final, "yi"):
+				final = "i" + final[2:]
+			case strings.HasPrefix(final, "y"):
+				final = "i" + final[1:]
+			case strings.HasPrefix(final, "wu"):
+				final = "u" + final[2:]
+			case strings.HasPrefix(final, "w"):
+				final = "u" + final[1:]
+			}
+		} else {
+			switch initial {
+			case "j", "q", "x":
+				if strings.HasPrefix(final, "u") {
+					final = "ü" + final[1:]
+				}
+			case "zh", "ch", "sh", "r", "z", "c", "s":
+				if final == "i" {
+					final = ""
+				}
+			}
+			// the abbreviated finals
+			switch final {
+			case "iu":
+				final = "iou"
+			case "ui":
+				final = "uei"
+			case "un":
+				final = "uen"
+			}
+		}
+		finalSymbols, ok := bopomofoFinals[final]
+		if !ok || (initial == "" && final == "") {
+			return "", false
+		}
+		symbols = bopomofoInitials[initial] + finalSymbols
+	}
+
+	if tone == 0 {
+		return "˙" + symbols, true
+	}
+	return symbols + bopomofoTones[tone], true
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin_search
+
+import (
+	"strings"
+)
+
+// FuzzyRules are the pairs of the sounds that are typed interchangeably,
+// each rule is enabled separately.
+type FuzzyRules struct {
+	// ZZh treats z and zh as the same initial.
+	ZZh bool
+	// CCh treats c and ch as the same initial.
+	CCh bool
+	// SSh treats s and sh as the same initial.
+	SSh bool
+	// NL treats n and l as the same initial.
+	NL bool
+	// AnAng treats an and ang as the same final, also ian and iang, uan
+	// and uang.
+	AnAng bool
+	// EnEng treats en and eng as the same final.
+	EnEng bool
+}
+
+// AllFuzzyRules enables all the rules.
+var AllFuzzyRules = FuzzyRules{
+	ZZh:   true,
+	CCh:   true,
+	SSh:   true,
+	NL:    true,
+	AnAng: true,
+	EnEng: true,
+}
+
+// MatchOptions are the options of Blocks.MatchWithOptions.
+type MatchOptions struct {
+	Fuzzy     FuzzyRules
+	Shuangpin ShuangpinScheme
+}
+
+var fuzzyInitials = map[string]string{
+	"z": "zh", "zh": "z",
+	"c": "ch", "ch": "c",
+	"s": "sh", "sh": "s",
+	"n": "l", "l": "n",
+}
+
+// getFuzzyVariants returns the syllable and the syllables equivalent to it
+// by the rules, such as "zhan", "zan", "zhang" and "zang" for "zhan" if
+// ZZh and AnAng are enabled.
+func getFuzzyVariants(py string, rules FuzzyRules) []string {
+	initial := ""
+	switch {
+	case len(py) >= 2 && py[1] == 'h' && strings.IndexByte("zcs", py[0]) != -1:
+		initial = py[:2]
+	case len(py) >= 1 && strings.IndexByte("bpmfdtnlgkhjqxrzcsyw", py[0]) != -1:
+		initial = py[:1]
+	}
+	final := py[len(initial):]
+	if strings.IndexAny(final, "aeiouv") == -1 {
+		// the syllables without vowels such as "m" and "ng"
+		return []string{py}
+	}
+
+	initials := []string{initial}
+	var enabled bool
+	switch initial {
+	case "z", "zh":
+		enabled = rules.ZZh
+	case "c", "ch":
+		enabled = rules.CCh
+	case "s", "sh":
+		enabled = rules.SSh
+	case "n", "l":
+		enabled = rules.NL
+	}
+	if enabled {
+		initials = append(initials, fuzzyInitials[initial])
+	}
+
+	finals := []string{final}
+	switch {
+	case rules.AnAng && strings.HasSuffix(final, "ang"):
+		finals = append(finals, strings.TrimSuffix(final, "g"))
+	case rules.AnAng && strings.HasSuffix(final, "an"):
+		finals = append(finals, final+"g")
+	case rules.EnEng && strings.HasSuffix(final, "eng"):
+		finals = append(finals, strings.TrimSuffix(final, "g"))
+	case rules.EnEng && strings.HasSuffix(final, "en"):
+		finals = append(finals, final+"g")
+	}
+
+	result := make([]string, 0, len(initials)*len(finals))
+	for _, i := range initials {
+		for _, f := range finals {
+			result = append(result, i+f)
+		}
+	}
+	return result
+}
+
+// Fuzzy returns the blocks whose Han characters also have the readings
+// equivalent by the rules, so that Match accepts the fuzzy queries.
+func (blocks Blocks) Fuzzy(rules FuzzyRules) Blocks {
+	if rules == (FuzzyRules{}) {
+		return blocks
+	}
+	result := make(Blocks, len(blocks))
+	for i, b := range blocks {
+		zb, ok := b.(zhBlock)
+		if !ok {
+			result[i] = b
+			continue
+		}
+		var pys []string
+		for _, py := range zb.pys {
+			pys = append(pys, getFuzzyVariants(py, rules)...)
+		}
+		result[i] = zhBlock{
+			zh:  zb.zh,
+			pys: strSliceUniq(pys),
+		}
+	}
+	return result
+}
+
+// MatchWithOptions is like Match, with the fuzzy rules and the shuangpin
+// scheme of the query. The shuangpin query is also matched as is, so that
+// the latin words of the blocks are still found.
+func (blocks Blocks) MatchWithOptions(query string, opts MatchOptions) bool {
+	blocks = blocks.Fuzzy(opts.Fuzzy)
+	if blocks.Match(query) {
+		return true
+	}
+	if opts.Shuangpin == ShuangpinNone {
+		return false
+	}
+	for _, q := range DecodeShuangpin(query, opts.Shuangpin) {
+		if blocks.Match(q) {
+			return true
+		}
+	}
+	return false
+}
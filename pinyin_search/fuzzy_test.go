@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin_search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getFuzzyVariants(t *testing.T) {
+	rules := FuzzyRules{ZZh: true, AnAng: true}
+	assert.Equal(t, []string{"zhan", "zhang", "zan", "zang"}, getFuzzyVariants("zhan", rules))
+	assert.Equal(t, []string{"zang", "zan", "zhang", "zhan"}, getFuzzyVariants("zang", rules))
+	assert.Equal(t, []string{"xian", "xiang"}, getFuzzyVariants("xian", rules))
+	assert.Equal(t, []string{"chen"}, getFuzzyVariants("chen", rules))
+	assert.Equal(t, []string{"ng"}, getFuzzyVariants("ng", AllFuzzyRules))
+	assert.Equal(t, []string{"en", "eng"}, getFuzzyVariants("en", AllFuzzyRules))
+	assert.Equal(t, []string{"nv", "lv"}, getFuzzyVariants("nv", AllFuzzyRules))
+	assert.Equal(t, []string{"zhan"}, getFuzzyVariants("zhan", FuzzyRules{}))
+}
+
+func TestBlocksMatchFuzzy(t *testing.T) {
+	var tests = []struct {
+		text  string
+		query string
+		rules FuzzyRules
+	}{
+		{"中国", "zongguo", FuzzyRules{ZZh: true}},
+		{"自己", "zhiji", FuzzyRules{ZZh: true}},
+		{"程序", "cengxu", FuzzyRules{CCh: true}},
+		{"上海", "sanghai", FuzzyRules{SSh: true}},
+		{"牛奶", "liulai", FuzzyRules{NL: true}},
+		{"蓝色", "nanse", FuzzyRules{NL: true}},
+		{"文件管理器", "wenjiangguangliqi", FuzzyRules{AnAng: true}},
+		{"深度", "shengdu", FuzzyRules{EnEng: true}},
+		{"森林", "senglin", FuzzyRules{EnEng: true}},
+		{"深度终端", "sengdzd", AllFuzzyRules},
+	}
+	for _, test := range tests {
+		blocks := Split(test.text)
+		assert.False(t, blocks.Match(test.query), test.query)
+		assert.True(t, blocks.MatchWithOptions(test.query, MatchOptions{Fuzzy: test.rules}), test.query)
+	}
+
+	// each rule is enabled separately
+	blocks := Split("中国")
+	assert.False(t, blocks.MatchWithOptions("zongguo", MatchOptions{Fuzzy: FuzzyRules{CCh: true, SSh: true}}))
+	assert.False(t, blocks.MatchWithOptions("zongguang", MatchOptions{Fuzzy: FuzzyRules{ZZh: true}}))
+	// the initials still match
+	assert.True(t, blocks.MatchWithOptions("zg", MatchOptions{Fuzzy: AllFuzzyRules}))
+	// the blocks are not changed
+	blocks.Fuzzy(AllFuzzyRules)
+	assert.False(t, blocks.Match("zongguo"))
+}
+
+func TestDecodeShuangpin(t *testing.T) {
+	var tests = []struct {
+		query  string
+		scheme ShuangpinScheme
+		result []string
+	}{
+		{"vsgo", ShuangpinMicrosoft, []string{"zhongguo"}},
+		{"vsgo", ShuangpinXiaohe, []string{"zhongguo"}},
+		{"ojhv", ShuangpinMicrosoft, []string{"anhui"}},
+		{"anhv", ShuangpinXiaohe, []string{"anhui"}},
+		{"ahdd", ShuangpinXiaohe, []string{"angdai"}},
+		{"orgg", ShuangpinMicrosoft, []string{"ergeng"}},
+		{"b;", ShuangpinMicrosoft, []string{"bing"}},
+		{"bk", ShuangpinXiaohe, []string{"bing"}},
+		{"ly", ShuangpinMicrosoft, []string{"lv"}},
+		{"lv", ShuangpinXiaohe, []string{"lv"}},
+		{"lo", ShuangpinXiaohe, []string{"lo", "luo"}},
+		{"wfjmgr", ShuangpinMicrosoft, []string{"wenjianguan"}},
+		{"Wf Jm", ShuangpinXiaohe, []string{"wenjian"}},
+		{"wfj", ShuangpinMicrosoft, []string{"wenj"}},
+		{"uu", ShuangpinXiaohe, []string{"shu"}},
+		{"iu", ShuangpinXiaohe, []string{"chu"}},
+		{"文jm", ShuangpinMicrosoft, []string{"文jian"}},
+		{"ws", ShuangpinMicrosoft, nil},
+		{"vsgo", ShuangpinNone, nil},
+		{"", ShuangpinXiaohe, nil},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.result, DecodeShuangpin(test.query, test.scheme), test.query)
+	}
+
+	// the candidates are limited
+	assert.Len(t, DecodeShuangpin("lololololololo", ShuangpinXiaohe), maxShuangpinCandidates)
+}
+
+func TestBlocksMatchShuangpin(t *testing.T) {
+	blocks := Split("文件管理器")
+	assert.True(t, blocks.MatchWithOptions("wfjm", MatchOptions{Shuangpin: ShuangpinMicrosoft}))
+	assert.True(t, blocks.MatchWithOptions("wfjmgrli", MatchOptions{Shuangpin: ShuangpinXiaohe}))
+	assert.True(t, blocks.MatchWithOptions("jmgr", MatchOptions{Shuangpin: ShuangpinXiaohe}))
+	assert.False(t, blocks.MatchWithOptions("wfjm", MatchOptions{}))
+	// the query as is
+	assert.True(t, blocks.MatchWithOptions("wjgl", MatchOptions{Shuangpin: ShuangpinXiaohe}))
+
+	// with the fuzzy rules
+	blocks = Split("深度终端")
+	opts := MatchOptions{
+		Fuzzy:     FuzzyRules{SSh: true, ZZh: true},
+		Shuangpin: ShuangpinMicrosoft,
+	}
+	assert.True(t, blocks.MatchWithOptions("sfduvsdr", opts))
+	assert.True(t, blocks.MatchWithOptions("sfduzs", opts))
+	assert.False(t, blocks.MatchWithOptions("sfduzs", MatchOptions{Shuangpin: ShuangpinMicrosoft}))
+
+	blocks = Split("Firefox 网络浏览器")
+	assert.True(t, blocks.MatchWithOptions("firefox", MatchOptions{Shuangpin: ShuangpinXiaohe}))
+	assert.True(t, blocks.MatchWithOptions("whlolqljqi", MatchOptions{Shuangpin: ShuangpinMicrosoft}))
+}
@@ -0,0 +1,372 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin_search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/linuxdeepin/go-lib/pinyin"
+)
+
+// MatchKind is how a query matches the text of an entry, the results of
+// the better kinds are sorted first.
+type MatchKind int
+
+const (
+	// MatchPrefix is the query at the beginning of the text, such as "fire"
+	// for "Firefox" and "文字" for "文字处理".
+	MatchPrefix MatchKind = iota
+	// MatchInitials is the initials of the characters, such as "wbbj" for
+	// "文本编辑器".
+	MatchInitials
+	// MatchFullPinyin is the full pinyin of the characters, the last one
+	// may be incomplete, such as "wenbenbian" for "文本编辑器".
+	MatchFullPinyin
+	// MatchSubstring is the query in the middle of the text, such as "fox"
+	// for "Firefox".
+	MatchSubstring
+	// MatchMixed is a mix of the characters, the full pinyin, the initials
+	// and the latin letters, such as "wpswenz" for "WPS文字".
+	MatchMixed
+)
+
+func (k MatchKind) String() string {
+	switch k {
+	case MatchPrefix:
+		return "prefix"
+	case MatchInitials:
+		return "initials"
+	case MatchFullPinyin:
+		return "full pinyin"
+	case MatchSubstring:
+		return "substring"
+	case MatchMixed:
+		return "mixed"
+	}
+	return "unknown"
+}
+
+// Range is the matched characters [Start, End) of the text, the indexes
+// count the characters, not the bytes.
+type Range struct {
+	Start int
+	End   int
+}
+
+// Result is an entry matching the query.
+type Result struct {
+	ID     string
+	Text   string
+	Kind   MatchKind
+	Ranges []Range
+}
+
+// the characters of the text which can be matched, the spaces and the
+// punctuations are skipped like GeneralizeQuery does
+type unit struct {
+	// the index of the character in the text
+	pos int
+	// the lower case character
+	r rune
+	// the readings without tones, only for the Han characters
+	pys []string
+	// the first letter of a latin word
+	wordStart bool
+}
+
+type indexEntry struct {
+	id    string
+	text  string
+	units []unit
+	// the number of the characters of the text
+	length int
+}
+
+func newIndexEntry(id, text string) *indexEntry {
+	e := &indexEntry{
+		id:   id,
+		text: text,
+	}
+	pos := 0
+	wordStart := true
+	readings := pinyin.ReadingsInContext(text, pinyin.StylePlain)
+	for i, r := range []rune(text) {
+		pys := readings[i]
+		if len(pys) > 0 {
+			e.units = append(e.units, unit{pos: pos, r: r, pys: pys})
+			wordStart = true
+		} else if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			wordStart = true
+		} else {
+			e.units = append(e.units, unit{pos: pos, r: unicode.ToLower(r), wordStart: wordStart})
+			wordStart = false
+		}
+		pos++
+	}
+	e.length = pos
+	return e
+}
+
+// Index searches many entries of texts by the characters and the pinyin
+// of the texts. It is safe for concurrent use.
+type Index struct {
+	mu      sync.RWMutex
+	entries map[string]*indexEntry
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		entries: make(map[string]*indexEntry),
+	}
+}
+
+// Add adds the entry to the index, the old entry with the same id is
+// replaced.
+func (idx *Index) Add(id, text string) {
+	e := newIndexEntry(id, text)
+	idx.mu.Lock()
+	idx.entries[id] = e
+	idx.mu.Unlock()
+}
+
+// Remove removes the entry from the index, it returns false if there is
+// no entry with the id.
+func (idx *Index) Remove(id string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	_, ok := idx.entries[id]
+	delete(idx.entries, id)
+	return ok
+}
+
+// Len returns the number of the entries.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.entries)
+}
+
+// Search returns the entries matching the query, the case, the spaces and
+// the punctuations of the query are ignored. The results are sorted by the
+// kind of the match, then the position of the match and the length of the
+// text, so that the best ones are the first.
+func (idx *Index) Search(query string) []Result {
+	query = GeneralizeQuery(query)
+	if query == "" {
+		return nil
+	}
+
+	type rankedResult struct {
+		Result
+		start  int
+		length int
+	}
+	var results []rankedResult
+	idx.mu.RLock()
+	for _, e := range idx.entries {
+		kind, matched, ok := e.match(query)
+		if !ok {
+			continue
+		}
+		results = append(results, rankedResult{
+			Result: Result{
+				ID:     e.id,
+				Text:   e.text,
+				Kind:   kind,
+				Ranges: e.ranges(matched),
+			},
+			start:  e.units[matched[0]].pos,
+			length: e.length,
+		})
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		if a.length != b.length {
+			return a.length < b.length
+		}
+		if a.Text != b.Text {
+			return a.Text < b.Text
+		}
+		return a.ID < b.ID
+	})
+	ret := make([]Result, len(results))
+	for i := range results {
+		ret[i] = results[i].Result
+	}
+	return ret
+}
+
+// the ways to match the units
+type matchMode int
+
+const (
+	// the characters only
+	modeLiteral matchMode = iota
+	// the initials of the Han characters only
+	modeInitials
+	// the full pinyin of the Han characters only
+	modeFullPinyin
+	// anything
+	modeMixed
+)
+
+// match returns the kind of the match and the indexes of the matched
+// units.
+func (e *indexEntry) match(query string) (MatchKind, []int, bool) {
+	literal, ok := e.find(query, modeLiteral)
+	if ok && literal[0] == 0 {
+		return MatchPrefix, literal, true
+	}
+	if matched, ok := e.find(query, modeInitials); ok {
+		return MatchInitials, matched, true
+	}
+	if matched, ok := e.find(query, modeFullPinyin); ok {
+		return MatchFullPinyin, matched, true
+	}
+	if ok {
+		return MatchSubstring, literal, true
+	}
+	if matched, ok := e.find(query, modeMixed); ok {
+		return MatchMixed, matched, true
+	}
+	return 0, nil, false
+}
+
+// find returns the matched units beginning at the first unit possible.
+func (e *indexEntry) find(query string, mode matchMode) ([]int, bool) {
+	m := matcher{
+		units:  e.units,
+		query:  query,
+		mode:   mode,
+		failed: make(map[[2]int]struct{}),
+	}
+	for i, u := range e.units {
+		if mode == modeMixed && u.pys == nil && !u.wordStart {
+			continue
+		}
+		if m.matchFrom(i, 0) {
+			return m.matched, true
+		}
+	}
+	return nil, false
+}
+
+type matcher struct {
+	units []unit
+	query string
+	mode  matchMode
+	// the units and the query offsets that are known not to match
+	failed map[[2]int]struct{}
+	// the matched units in the reverse order, then in order when the
+	// matching succeeds
+	matched []int
+}
+
+// matchFrom matches the query from the offset qIdx at the unit i.
+func (m *matcher) matchFrom(i, qIdx int) bool {
+	key := [2]int{i, qIdx}
+	if _, ok := m.failed[key]; ok {
+		return false
+	}
+	if m.matchUnit(i, qIdx) {
+		return true
+	}
+	m.failed[key] = struct{}{}
+	return false
+}
+
+func (m *matcher) matchUnit(i, qIdx int) bool {
+	u := m.units[i]
+	query := m.query[qIdx:]
+
+	// next is called when the unit matches n bytes of the query
+	next := func(n int) bool {
+		if n == len(query) {
+			m.matched = []int{i}
+			return true
+		}
+		if i+1 < len(m.units) && m.matchFrom(i+1, qIdx+n) {
+			m.matched = append([]int{i}, m.matched...)
+			return true
+		}
+		return false
+	}
+
+	r, size := utf8.DecodeRuneInString(query)
+	if u.pys == nil {
+		return (m.mode == modeLiteral || m.mode == modeMixed) && r == u.r && next(size)
+	}
+
+	if m.mode == modeLiteral || m.mode == modeMixed {
+		if r == u.r && next(size) {
+			return true
+		}
+		if m.mode == modeLiteral {
+			return false
+		}
+	}
+
+	for _, py := range u.pys {
+		switch m.mode {
+		case modeInitials:
+			for _, n := range getInitialLens(py) {
+				if strings.HasPrefix(query, py[:n]) && next(n) {
+					return true
+				}
+			}
+		case modeFullPinyin:
+			if strings.HasPrefix(query, py) && next(len(py)) {
+				return true
+			}
+			// the last syllable may be incomplete
+			if len(query) < len(py) && strings.HasPrefix(py, query) {
+				return next(len(query))
+			}
+		case modeMixed:
+			for _, prefix := range getPyList(py) {
+				if strings.HasPrefix(query, prefix) && next(len(prefix)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// getInitialLens returns the lengths of the initials of the syllable, such
+// as 1 and 2 for "zhong", "z" and "zh".
+func getInitialLens(py string) []int {
+	if len(py) > 2 && py[1] == 'h' && strings.IndexByte("zcs", py[0]) != -1 {
+		return []int{2, 1}
+	}
+	return []int{1}
+}
+
+// ranges merges the matched units into the ranges of the characters.
+func (e *indexEntry) ranges(matched []int) []Range {
+	var result []Range
+	for _, i := range matched {
+		pos := e.units[i].pos
+		if n := len(result); n > 0 && result[n-1].End == pos {
+			result[n-1].End++
+		} else {
+			result = append(result, Range{Start: pos, End: pos + 1})
+		}
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin_search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Add("firefox", "Firefox 网络浏览器")
+	idx.Add("editor", "文本编辑器")
+	idx.Add("wps", "WPS文字")
+	idx.Add("music", "QQ音乐")
+	idx.Add("terminal", "终端")
+	idx.Add("files", "文件管理器")
+	return idx
+}
+
+func getResultIDs(results []Result) []string {
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := newTestIndex()
+	require.Equal(t, 6, idx.Len())
+
+	var tests = []struct {
+		query  string
+		id     string
+		kind   MatchKind
+		ranges []Range
+	}{
+		{"fire", "firefox", MatchPrefix, []Range{{0, 4}}},
+		{"Firefox 网络", "firefox", MatchPrefix, []Range{{0, 7}, {8, 10}}},
+		{"fox", "firefox", MatchSubstring, []Range{{4, 7}}},
+		{"浏览", "firefox", MatchSubstring, []Range{{10, 12}}},
+		{"wbbj", "editor", MatchInitials, []Range{{0, 4}}},
+		{"zhd", "terminal", MatchInitials, []Range{{0, 2}}},
+		{"wenbenbian", "editor", MatchFullPinyin, []Range{{0, 3}}},
+		{"yinyue", "music", MatchFullPinyin, []Range{{2, 4}}},
+		{"wenbbianji", "editor", MatchMixed, []Range{{0, 4}}},
+		{"wpswenz", "wps", MatchMixed, []Range{{0, 5}}},
+		{"qqyy", "music", MatchMixed, []Range{{0, 4}}},
+		{"文ben", "editor", MatchMixed, []Range{{0, 2}}},
+		{"liulanqi", "firefox", MatchFullPinyin, []Range{{10, 13}}},
+	}
+	for _, test := range tests {
+		results := idx.Search(test.query)
+		require.NotEmpty(t, results, test.query)
+		var result *Result
+		for i := range results {
+			if results[i].ID == test.id {
+				result = &results[i]
+			}
+		}
+		require.NotNil(t, result, test.query)
+		assert.Equal(t, test.kind, result.Kind, test.query)
+		assert.Equal(t, test.ranges, result.Ranges, test.query)
+	}
+
+	assert.Empty(t, idx.Search("xyz"))
+	assert.Empty(t, idx.Search(""))
+	assert.Empty(t, idx.Search(" - "))
+	// the latin words are not matched from the middle except as is
+	assert.Empty(t, idx.Search("irefoxwang"))
+}
+
+func TestIndexSearchRanking(t *testing.T) {
+	idx := newTestIndex()
+	idx.Add("wb", "wb")
+
+	// prefix before initials, the full pinyin at the beginning before the
+	// one in the middle
+	assert.Equal(t, []string{"wb", "editor"}, getResultIDs(idx.Search("wb")))
+	assert.Equal(t, []string{"files", "editor", "wps"}, getResultIDs(idx.Search("wen")))
+
+	// shorter texts first
+	idx.Add("editor2", "文本编辑")
+	assert.Equal(t, []string{"editor2", "editor"}, getResultIDs(idx.Search("wbbj")))
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	idx := newTestIndex()
+	assert.Equal(t, []string{"terminal"}, getResultIDs(idx.Search("zd")))
+
+	idx.Add("terminal", "深度终端")
+	results := idx.Search("zd")
+	require.Len(t, results, 1)
+	assert.Equal(t, "深度终端", results[0].Text)
+	assert.Equal(t, []Range{{2, 4}}, results[0].Ranges)
+
+	assert.True(t, idx.Remove("terminal"))
+	assert.False(t, idx.Remove("terminal"))
+	assert.Empty(t, idx.Search("zd"))
+	assert.Equal(t, 5, idx.Len())
+}
+
+func TestIndexSearchPhrases(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("bank", "中国银行")
+	idx.Add("walk", "行走")
+
+	// 行 in 银行 is read hang only
+	assert.Equal(t, []string{"bank"}, getResultIDs(idx.Search("yinhang")))
+	assert.Equal(t, []string{"bank"}, getResultIDs(idx.Search("zgyh")))
+	assert.Empty(t, idx.Search("yinxing"))
+	assert.Empty(t, idx.Search("zgyx"))
+	// the other readings are kept out of the phrases
+	assert.Equal(t, []string{"walk"}, getResultIDs(idx.Search("xingzou")))
+
+	assert.True(t, Split("中国银行").Match("yinhang"))
+	assert.False(t, Split("中国银行").Match("yinxing"))
+}
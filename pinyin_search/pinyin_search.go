@@ -10,7 +10,7 @@ import (
 	"strings"
 	"unicode"
 
-	"github.com/mozillazg/go-pinyin"
+	"github.com/linuxdeepin/go-lib/pinyin"
 )
 
 func GeneralizeQuery(q string) string {
@@ -61,13 +61,6 @@ func strSliceUniqAux(in []string) []string {
 
 type Blocks []block
 
-var pinyinArgs pinyin.Args
-
-func init() {
-	pinyinArgs = pinyin.NewArgs()
-	pinyinArgs.Heteronym = true
-}
-
 func Split(str string) Blocks {
 	if str == "" {
 		return nil
@@ -75,9 +68,9 @@ func Split(str string) Blocks {
 	var result Blocks
 	var buf bytes.Buffer
 
-	for _, r := range str {
-		pys := pinyin.SinglePinyin(r, pinyinArgs)
-		pys = strSliceUniq(pys)
+	readings := pinyin.ReadingsInContext(str, pinyin.StylePlain)
+	for i, r := range []rune(str) {
+		pys := readings[i]
 		if len(pys) > 0 {
 			// 是汉字
 			if buf.Len() > 0 {
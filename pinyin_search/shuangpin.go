@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pinyin_search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ShuangpinScheme is the layout of the shuangpin input, each syllable is
+// typed with two keys, the initial and the final.
+type ShuangpinScheme int
+
+const (
+	// ShuangpinNone is the full pinyin input.
+	ShuangpinNone ShuangpinScheme = iota
+	// ShuangpinMicrosoft is the scheme of Microsoft Pinyin.
+	ShuangpinMicrosoft
+	// ShuangpinXiaohe is the Xiaohe scheme.
+	ShuangpinXiaohe
+)
+
+// the decoded queries are at most this many
+const maxShuangpinCandidates = 64
+
+type shuangpinLayout struct {
+	finals map[byte][]string
+	// zeroInitial returns the syllables without initials typed with the
+	// two keys
+	zeroInitial func(k1, k2 byte, finals map[byte][]string) []string
+}
+
+var shuangpinInitials = map[byte]string{
+	'v': "zh",
+	'i': "ch",
+	'u': "sh",
+}
+
+var shuangpinLayouts = map[ShuangpinScheme]*shuangpinLayout{
+	ShuangpinMicrosoft: {
+		finals: map[byte][]string{
+			'q': {"iu"}, 'w': {"ia", "ua"}, 'e': {"e"}, 'r': {"uan", "van"},
+			't': {"ue", "ve"}, 'y': {"uai", "v"}, 'u': {"u"}, 'i': {"i"},
+			'o': {"o", "uo"}, 'p': {"un", "vn"}, 'a': {"a"}, 's': {"ong", "iong"},
+			'd': {"iang", "uang"}, 'f': {"en"}, 'g': {"eng"}, 'h': {"ang"},
+			'j': {"an"}, 'k': {"ao"}, 'l': {"ai"}, ';': {"ing"},
+			'z': {"ei"}, 'x': {"ie"}, 'c': {"iao"}, 'v': {"ui", "ve"},
+			'b': {"ou"}, 'n': {"in"}, 'm': {"ian"},
+		},
+		// o is the initial of the syllables without initials, such as "oj"
+		// for "an", "or" is "er"
+		zeroInitial: func(k1, k2 byte, finals map[byte][]string) []string {
+			if k1 != 'o' {
+				return nil
+			}
+			if k2 == 'r' {
+				return []string{"er"}
+			}
+			return finals[k2]
+		},
+	},
+	ShuangpinXiaohe: {
+		finals: map[byte][]string{
+			'q': {"iu"}, 'w': {"ei"}, 'e': {"e"}, 'r': {"uan"},
+			't': {"ue", "ve"}, 'y': {"un"}, 'u': {"u"}, 'i': {"i"},
+			'o': {"o", "uo"}, 'p': {"ie"}, 'a': {"a"}, 's': {"ong", "iong"},
+			'd': {"ai"}, 'f': {"en"}, 'g': {"eng"}, 'h': {"ang"},
+			'j': {"an"}, 'k': {"ing", "uai"}, 'l': {"iang", "uang"},
+			'z': {"ou"}, 'x': {"ia", "ua"}, 'c': {"ao"}, 'v': {"ui", "v"},
+			'b': {"in"}, 'n': {"iao"}, 'm': {"ian"},
+		},
+		// the first letter of the final is the initial key, the finals of
+		// two letters are typed as is, such as "an", the others with the
+		// final key, such as "aa" for "a" and "ah" for "ang"
+		zeroInitial: func(k1, k2 byte, finals map[byte][]string) []string {
+			if strings.IndexByte("aoe", k1) == -1 {
+				return nil
+			}
+			result := []string{string([]byte{k1, k2})}
+			for _, final := range finals[k2] {
+				if final[0] == k1 {
+					result = append(result, final)
+				}
+			}
+			return result
+		},
+	},
+}
+
+// all the syllables of Mandarin, ü is written as v
+const allSyllables = `
+a ai an ang ao e ei en eng er o ou
+ba bai ban bang bao bei ben beng bi bian biao bie bin bing bo bu
+pa pai pan pang pao pei pen peng pi pian piao pie pin ping po pou pu
+m ma mai man mang mao me mei men meng mi mian miao mie min ming miu mo mou mu
+fa fan fang fei fen feng fo fou fu
+da dai dan dang dao de dei den deng di dia dian diao die ding diu dong dou du duan dui dun duo
+ta tai tan tang tao te teng ti tian tiao tie ting tong tou tu tuan tui tun tuo
+n na nai nan nang nao ne nei nen neng ng ni nian niang niao nie nin ning niu nong nou nu nuan nuo nv nve
+la lai lan lang lao le lei leng li lia lian liang liao lie lin ling liu lo long lou lu luan lun luo lv lve
+ga gai gan gang gao ge gei gen geng gong gou gu gua guai guan guang gui gun guo
+ka kai kan kang kao ke kei ken keng kong kou ku kua kuai kuan kuang kui kun kuo
+ha hai han hang hao he hei hen heng hm hng hong hou hu hua huai huan huang hui hun huo
+ji jia jian jiang jiao jie jin jing jiong jiu ju juan jue jun
+qi qia qian qiang qiao qie qin qing qiong qiu qu quan que qun
+xi xia xian xiang xiao xie xin xing xiong xiu xu xuan xue xun
+zha zhai zhan zhang zhao zhe zhei zhen zheng zhi zhong zhou zhu zhua zhuai zhuan zhuang zhui zhun zhuo
+cha chai chan chang chao che chen cheng chi chong chou chu chua chuai chuan chuang chui chun chuo
+sha shai shan shang shao she shei shen sheng shi shou shu shua shuai shuan shuang shui shun shuo
+ran rang rao re ren reng ri rong rou ru rua ruan rui run ruo
+za zai zan zang zao ze zei zen zeng zi zong zou zu zuan zui zun zuo
+ca cai can cang cao ce cen ceng ci cong cou cu cuan cui cun cuo
+sa sai san sang sao se sen seng si song sou su suan sui sun suo
+ya yan yang yao ye yi yin ying yo yong you yu yuan yue yun
+wa wai wan wang wei wen weng wo wu
+`
+
+var validSyllables = make(map[string]struct{})
+
+func init() {
+	for _, syllable := range strings.Fields(allSyllables) {
+		validSyllables[syllable] = struct{}{}
+	}
+}
+
+func isValidSyllable(py string) bool {
+	_, ok := validSyllables[py]
+	return ok
+}
+
+// decodePair returns the syllables typed with the two keys.
+func (l *shuangpinLayout) decodePair(k1, k2 byte) []string {
+	var candidates []string
+	if initial, ok := shuangpinInitials[k1]; ok {
+		for _, final := range l.finals[k2] {
+			candidates = append(candidates, initial+final)
+		}
+	} else if syllables := l.zeroInitial(k1, k2, l.finals); syllables != nil {
+		candidates = syllables
+	} else {
+		for _, final := range l.finals[k2] {
+			candidates = append(candidates, string(k1)+final)
+		}
+	}
+
+	var result []string
+	for _, py := range candidates {
+		if isValidSyllable(py) {
+			result = append(result, py)
+		}
+	}
+	return result
+}
+
+// decodeKeys returns the full pinyin of the keys of a run of letters, the
+// last key alone is the initial of an incomplete syllable.
+func (l *shuangpinLayout) decodeKeys(keys string) [][]string {
+	var result [][]string
+	for i := 0; i < len(keys); i += 2 {
+		if i+1 == len(keys) {
+			initial, ok := shuangpinInitials[keys[i]]
+			if !ok {
+				initial = string(keys[i])
+			}
+			result = append(result, []string{initial})
+			break
+		}
+		syllables := l.decodePair(keys[i], keys[i+1])
+		if syllables == nil {
+			return nil
+		}
+		result = append(result, syllables)
+	}
+	return result
+}
+
+// DecodeShuangpin returns the full pinyin queries the shuangpin query may
+// be typed for, such as "zhongguo" for "vsgo" in both the Microsoft and
+// the Xiaohe schemes. The characters other than the keys are kept as is,
+// the spaces and the punctuations are ignored. It returns nil if the query
+// is not a valid shuangpin input.
+func DecodeShuangpin(query string, scheme ShuangpinScheme) []string {
+	layout, ok := shuangpinLayouts[scheme]
+	if !ok {
+		return nil
+	}
+
+	// each part of the query is some choices
+	var parts [][]string
+	var keys []byte
+	flushKeys := func() bool {
+		if len(keys) == 0 {
+			return true
+		}
+		syllables := layout.decodeKeys(string(keys))
+		keys = keys[:0]
+		if syllables == nil {
+			return false
+		}
+		parts = append(parts, syllables...)
+		return true
+	}
+	for _, r := range query {
+		r = unicode.ToLower(r)
+		if ('a' <= r && r <= 'z') || (r == ';' && layout.finals[';'] != nil) {
+			keys = append(keys, byte(r))
+			continue
+		}
+		if !flushKeys() {
+			return nil
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		parts = append(parts, []string{string(r)})
+	}
+	if !flushKeys() || len(parts) == 0 {
+		return nil
+	}
+
+	result := []string{""}
+	for _, part := range parts {
+		var next []string
+		for _, prefix := range result {
+			for _, s := range part {
+				if len(next) == maxShuangpinCandidates {
+					break
+				}
+				next = append(next, prefix+s)
+			}
+		}
+		result = next
+	}
+	return result
+}
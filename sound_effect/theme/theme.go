@@ -43,9 +43,9 @@ type Finder struct {
 
 func NewFinder() *Finder {
 	const soundsDir = "sounds"
-	dataDirs := strv.Strv{filepath.Join(basedir.GetUserDataDir(), soundsDir)}
-	for _, dir := range basedir.GetSystemDataDirs() {
-		dataDirs, _ = dataDirs.Add(filepath.Join(dir, soundsDir))
+	var dataDirs []string
+	for _, dir := range basedir.GetDataDirs() {
+		dataDirs = append(dataDirs, filepath.Join(dir, soundsDir))
 	}
 	return &Finder{
 		dataDirs: dataDirs,
@@ -55,6 +55,44 @@ func GetUserCacheDir() string {
 	return getUserDir("XDG_CACHE_HOME", defaultDir)
 }
 
+func GetUserStateDir() string {
+	// default $HOME/.local/state
+	defaultDir := filepath.Join(GetUserHomeDir(), ".local/state")
+	return getUserDir("XDG_STATE_HOME", defaultDir)
+}
+
+// GetUserBinDir returns $HOME/.local/bin, the spec defines no env var for it.
+func GetUserBinDir() string {
+	return filepath.Join(GetUserHomeDir(), ".local/bin")
+}
+
+// GetDataDirs returns the user data dir followed by the system data dirs.
+func GetDataDirs() []string {
+	return uniqDirs(append([]string{GetUserDataDir()}, GetSystemDataDirs()...))
+}
+
+// GetConfigDirs returns the user config dir followed by the system config dirs.
+func GetConfigDirs() []string {
+	return uniqDirs(append([]string{GetUserConfigDir()}, GetSystemConfigDirs()...))
+}
+
+func uniqDirs(dirs []string) []string {
+	result := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		found := false
+		for _, v := range result {
+			if v == dir {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
 func GetUserRuntimeDir(strict bool) (string, error) {
 	dir := os.Getenv("XDG_RUNTIME_DIR")
 	if dir == "" || !filepath.IsAbs(dir) {
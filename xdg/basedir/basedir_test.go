@@ -115,3 +115,33 @@ func TestGetUserRuntimeDir(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, dir, fmt.Sprintf("/tmp/goxdg-runtime-dir-fallback-%d", os.Getuid()))
 }
+
+func TestGetUserStateDir(t *testing.T) {
+	os.Setenv("XDG_STATE_HOME", "/state/user/a")
+	dir := GetUserStateDir()
+	assert.Equal(t, dir, "/state/user/a")
+
+	os.Setenv("XDG_STATE_HOME", "a invalid path")
+	os.Setenv("HOME", "/home/test")
+	dir = GetUserStateDir()
+	assert.Equal(t, dir, "/home/test/.local/state")
+
+	os.Setenv("XDG_STATE_HOME", "")
+	dir = GetUserStateDir()
+	assert.Equal(t, dir, "/home/test/.local/state")
+}
+
+func TestGetUserBinDir(t *testing.T) {
+	os.Setenv("HOME", "/home/test")
+	assert.Equal(t, GetUserBinDir(), "/home/test/.local/bin")
+}
+
+func TestGetDataDirs(t *testing.T) {
+	os.Setenv("XDG_DATA_HOME", "/a")
+	os.Setenv("XDG_DATA_DIRS", "/b:/a:relative:/c")
+	assert.Equal(t, GetDataDirs(), []string{"/a", "/b", "/c"})
+
+	os.Setenv("XDG_CONFIG_HOME", "/a")
+	os.Setenv("XDG_CONFIG_DIRS", "/b")
+	assert.Equal(t, GetConfigDirs(), []string{"/a", "/b"})
+}
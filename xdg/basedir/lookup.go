@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package basedir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidResource = errors.New("resource must be a relative path inside the base dir")
+
+// LookupFile returns the first existing file dir/name in dirs, or an empty
+// string.
+func LookupFile(dirs []string, name string) string {
+	if !isResourceOk(name) {
+		return ""
+	}
+	for _, dir := range dirs {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return ""
+}
+
+// LookupAllFiles returns all existing files dir/name in dirs, in the order
+// of dirs.
+func LookupAllFiles(dirs []string, name string) []string {
+	if !isResourceOk(name) {
+		return nil
+	}
+	var result []string
+	for _, dir := range dirs {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// GlobAllFiles returns the files matching dir/pattern in dirs, in the order
+// of dirs.
+func GlobAllFiles(dirs []string, pattern string) []string {
+	if !isResourceOk(pattern) {
+		return nil
+	}
+	var result []string
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil
+		}
+		result = append(result, files...)
+	}
+	return result
+}
+
+// LookupDataFile returns the first existing file name in the data dirs, ex.
+// LookupDataFile("mime/mime.cache").
+func LookupDataFile(name string) string {
+	return LookupFile(GetDataDirs(), name)
+}
+
+// LookupAllDataFiles returns all existing files name in the data dirs.
+func LookupAllDataFiles(name string) []string {
+	return LookupAllFiles(GetDataDirs(), name)
+}
+
+// LookupConfigFile returns the first existing file name in the config dirs.
+func LookupConfigFile(name string) string {
+	return LookupFile(GetConfigDirs(), name)
+}
+
+// LookupAllConfigFiles returns all existing files name in the config dirs.
+func LookupAllConfigFiles(name string) []string {
+	return LookupAllFiles(GetConfigDirs(), name)
+}
+
+// SaveDataPath returns the dir resource in the user data dir, which is
+// created if it does not exist.
+func SaveDataPath(resource string) (string, error) {
+	return savePath(GetUserDataDir(), resource)
+}
+
+// SaveConfigPath returns the dir resource in the user config dir, which is
+// created if it does not exist.
+func SaveConfigPath(resource string) (string, error) {
+	return savePath(GetUserConfigDir(), resource)
+}
+
+// SaveCachePath returns the dir resource in the user cache dir, which is
+// created if it does not exist.
+func SaveCachePath(resource string) (string, error) {
+	return savePath(GetUserCacheDir(), resource)
+}
+
+// SaveStatePath returns the dir resource in the user state dir, which is
+// created if it does not exist.
+func SaveStatePath(resource string) (string, error) {
+	return savePath(GetUserStateDir(), resource)
+}
+
+func savePath(baseDir, resource string) (string, error) {
+	if !filepath.IsAbs(baseDir) {
+		return "", fmt.Errorf("base dir %q is not an absolute path", baseDir)
+	}
+	if !isResourceOk(resource) {
+		return "", ErrInvalidResource
+	}
+
+	// the base dir must be created with permissions 0700
+	err := os.MkdirAll(baseDir, 0700)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(baseDir, resource)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func isResourceOk(resource string) bool {
+	if resource == "" || filepath.IsAbs(resource) {
+		return false
+	}
+	resource = filepath.Clean(resource)
+	return resource != ".." && !strings.HasPrefix(resource, "../")
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2022 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package basedir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "basedir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dirs := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+	for _, d := range dirs[1:] {
+		require.NoError(t, os.MkdirAll(filepath.Join(d, "app"), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(d, "app", "x.conf"), nil, 0644))
+	}
+
+	assert.Equal(t, filepath.Join(dir, "b/app/x.conf"), LookupFile(dirs, "app/x.conf"))
+	assert.Equal(t, "", LookupFile(dirs, "app/y.conf"))
+	assert.Equal(t, "", LookupFile(dirs, "../b/app/x.conf"))
+	assert.Equal(t, []string{
+		filepath.Join(dir, "b/app/x.conf"),
+		filepath.Join(dir, "c/app/x.conf"),
+	}, LookupAllFiles(dirs, "app/x.conf"))
+	assert.Equal(t, []string{
+		filepath.Join(dir, "b/app/x.conf"),
+		filepath.Join(dir, "c/app/x.conf"),
+	}, GlobAllFiles(dirs, "app/*.conf"))
+	assert.Nil(t, LookupAllFiles(dirs, "/app/x.conf"))
+
+	os.Setenv("XDG_DATA_HOME", dirs[0])
+	os.Setenv("XDG_DATA_DIRS", dirs[1]+":"+dirs[2])
+	assert.Equal(t, filepath.Join(dir, "b/app/x.conf"), LookupDataFile("app/x.conf"))
+	assert.Len(t, LookupAllDataFiles("app/x.conf"), 2)
+
+	os.Setenv("XDG_CONFIG_HOME", dirs[2])
+	os.Setenv("XDG_CONFIG_DIRS", dirs[1])
+	assert.Equal(t, filepath.Join(dir, "c/app/x.conf"), LookupConfigFile("app/x.conf"))
+	assert.Len(t, LookupAllConfigFiles("app/x.conf"), 2)
+}
+
+func TestSavePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "basedir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	path, err := SaveStatePath("app/history")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "state/app/history"), path)
+
+	for _, d := range []string{"state", "state/app", "state/app/history"} {
+		fi, err := os.Stat(filepath.Join(dir, d))
+		require.NoError(t, err)
+		assert.True(t, fi.IsDir())
+		assert.Equal(t, os.FileMode(0700), fi.Mode().Perm(), d)
+	}
+
+	_, err = SaveStatePath("../escape")
+	assert.Equal(t, ErrInvalidResource, err)
+	_, err = SaveStatePath("")
+	assert.Equal(t, ErrInvalidResource, err)
+
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	path, err = SaveConfigPath("app")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "config/app"), path)
+
+	// relative paths in env vars are ignored
+	os.Setenv("XDG_CACHE_HOME", "relative/cache")
+	os.Setenv("HOME", dir)
+	path, err = SaveCachePath("app")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".cache/app"), path)
+
+	os.Setenv("XDG_DATA_HOME", "")
+	os.Setenv("HOME", "relative/home")
+	_, err = SaveDataPath("app")
+	assert.Error(t, err)
+}